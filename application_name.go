@@ -0,0 +1,50 @@
+package pop
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+// applicationNamable is implemented by the dialects able to name the
+// sessions of a connection.
+type applicationNamable interface {
+	withApplicationName(name string) (dialect, error)
+}
+
+var applicationNameX = regexp.MustCompile(`^[A-Za-z0-9_.:-]{1,63}$`)
+
+// ApplicationName returns a new connection whose sessions are named name,
+// to tell which service is running a query: on PostgreSQL and CockroachDB,
+// it's the application_name of the sessions, shown by pg_stat_activity.
+// The original connection is left untouched. The connections with the same
+// name share the same connection pool, opened by the first call and owned
+// by c.
+//
+//	wc, err := c.ApplicationName("worker-42")
+//	wc.All(ctx, &jobs)
+//
+// The name is made of up to 63 letters, digits, and "_", ".", ":" or "-".
+// On the other dialects, ApplicationName returns a copy of c, using its
+// pool.
+func (c *Connection) ApplicationName(name string) (*Connection, error) {
+	an, ok := c.Dialect.(applicationNamable)
+	if !ok {
+		cn := c.copy()
+		cn.borrowed = true
+		return cn, nil
+	}
+	cn, err := c.derive("application_name="+name, func() (dialect, error) {
+		return an.withApplicationName(name)
+	})
+	return cn, errors.Wrapf(err, "unable to name connection %s", name)
+}
+
+// withApplicationName returns a copy of the connection details, with the
+// PostgreSQL application_name run-time parameter set to name.
+func withApplicationName(cd *ConnectionDetails, name string) (*ConnectionDetails, error) {
+	if !applicationNameX.MatchString(name) {
+		return nil, errors.Errorf("invalid application name '%s'", name)
+	}
+	return withRuntimeParameter(cd, "application_name", name)
+}
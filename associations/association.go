@@ -1,7 +1,9 @@
 package associations
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/gobuffalo/nulls"
 	"github.com/gobuffalo/pop/columns"
@@ -10,11 +12,25 @@ import (
 // Association represents a definition of a model association
 // field. It can represent a association of the type has_many
 // belongs_to or has_one, and other customized types.
+//
+// Association, AssociationSortable, AssociationBeforeCreatable,
+// AssociationAfterCreatable and AssociationCreatableStatement are stable:
+// they're implemented by the associations added with RegisterBuilder.
 type Association interface {
+	// Kind is the kind of the association field: the associated models
+	// are loaded with All for a slice or an array, with First for a struct.
 	Kind() reflect.Kind
+	// Interface returns a pointer to the field the associated models are
+	// loaded into.
 	Interface() interface{}
+	// Constraint returns the where clause selecting the associated models,
+	// and its args.
 	Constraint() (string, []interface{})
+	// InnerAssociations returns the nested associations to load in the
+	// associated models, e.g. Composer for the "Songs.Composer" field.
 	InnerAssociations() InnerAssociations
+	// Skipped tells if the association can't be loaded or saved, e.g.
+	// because the model has no ID yet.
 	Skipped() bool
 }
 
@@ -49,12 +65,21 @@ type InnerAssociation struct {
 // InnerAssociations is a group of InnerAssociation.
 type InnerAssociations []InnerAssociation
 
-// AssociationSortable allows a type to be sortable.
+// AssociationSortable allows a type to be sortable. OrderBy returns the
+// order clause of the associated models, if any.
 type AssociationSortable interface {
 	OrderBy() string
 	Association
 }
 
+// AssociationKeyed tells the columns matching the associated models with
+// the model, e.g. the user_id column of the posts of a user. They're always
+// loaded, even when only some columns of the associated models are selected.
+type AssociationKeyed interface {
+	KeyColumns() []string
+	Association
+}
+
 // AssociationBeforeCreatable allows an association to be created before
 // the parent structure.
 type AssociationBeforeCreatable interface {
@@ -64,7 +89,9 @@ type AssociationBeforeCreatable interface {
 }
 
 // AssociationAfterCreatable allows an association to be created after
-// the parent structure.
+// the parent structure. AfterSetup sets the ids of the parent in the
+// associated models, AfterInterface returns the models to create, and
+// AfterProcess the statement to run once they're created, if any.
 type AssociationAfterCreatable interface {
 	AfterInterface() interface{}
 	AfterSetup() error
@@ -145,6 +172,55 @@ type associationParams struct {
 // see the builder defined in ./has_many_association.go as a guide of how to use it.
 type associationBuilder func(associationParams) (Association, error)
 
+// foreignKey returns the columns of the foreign key of the association of
+// p, and the values of the owner matching them: fk, matching the owner ID,
+// or the columns of its fk_on tag, for a composite foreign key, e.g.
+// fk_on:"tenant_id,user_id". Each column of fk_on matches the owner field
+// mapped to the same column, fk still matching the owner ID.
+func foreignKey(p associationParams, fk string, ownerID interface{}) ([]string, []interface{}, error) {
+	tag := p.popTags.Find("fk_on")
+	if tag.Empty() {
+		return []string{fk}, []interface{}{ownerID}, nil
+	}
+	var cols []string
+	var args []interface{}
+	for _, col := range strings.Split(tag.Value, ",") {
+		col = strings.TrimSpace(col)
+		if col == fk {
+			cols, args = append(cols, col), append(args, ownerID)
+			continue
+		}
+		f, ok := columnField(p.modelType, col)
+		if !ok {
+			return nil, nil, fmt.Errorf("%s.%s: fk_on column '%s' does not exist in %s", p.modelType.Name(), p.field.Name, col, p.modelType.Name())
+		}
+		cols, args = append(cols, col), append(args, p.modelValue.FieldByIndex(f.Index).Interface())
+	}
+	return cols, args, nil
+}
+
+// columnField returns the exported field of the struct type t mapped to
+// the column col by its db tag.
+func columnField(t reflect.Type, col string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath == "" && columns.TagsFor(f).Find("db").Value == col {
+			return f, true
+		}
+	}
+	return reflect.StructField{}, false
+}
+
+// keyConstraint returns the where clause matching the columns of a
+// foreign key, e.g. "tenant_id = ? AND user_id = ?".
+func keyConstraint(cols []string) string {
+	conds := make([]string, len(cols))
+	for i, col := range cols {
+		conds[i] = fmt.Sprintf("%s = ?", col)
+	}
+	return strings.Join(conds, " AND ")
+}
+
 // fieldIsNil validates if a field has a nil reference. Also
 // it validates if a field implements nullable interface and
 // it has a nil value.
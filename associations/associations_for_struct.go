@@ -20,8 +20,68 @@ var validAssociationExpRegexp = regexp.MustCompile(`^(([a-zA-Z0-9]*)(\.[a-zA-Z0-
 // associationBuilders is a map that helps to aisle associations finding process
 // with the associations implementation. Every association MUST register its builder
 // in this map using its init() method. see ./has_many_association.go as a guide.
+// The associations defined outside of this package are added by RegisterBuilder.
 var associationBuilders = map[string]associationBuilder{}
 
+// AssociationParams describes a model field defining an association, for
+// the AssociationBuilder of its tag.
+type AssociationParams struct {
+	Field             reflect.StructField // the association field defined in the model.
+	ModelType         reflect.Type        // the model type where this field is defined.
+	ModelValue        reflect.Value       // the model value where this field is defined.
+	Tags              columns.Tags        // the pop tags defined in this association field.
+	Model             interface{}         // the model, owner of the association.
+	InnerAssociations InnerAssociations   // the data for the deep level associations.
+}
+
+// AssociationBuilder builds the association defined by a model field.
+type AssociationBuilder func(AssociationParams) (Association, error)
+
+// RegisterBuilder adds a kind of association, defined by the fields with the
+// given tag: ForStruct builds their Association with the builder, so they're
+// loaded by Eager and Load like the has_many or belongs_to associations.
+// The tag is registered as a pop tag too, so the fields aren't mapped to a
+// column.
+//
+// It's meant to be called from an init function, before any model is used.
+// The tag of a registered association can't be registered again.
+//
+//	func init() {
+//		associations.RegisterBuilder("latest_child", latestChildBuilder)
+//	}
+func RegisterBuilder(tag string, b AssociationBuilder) error {
+	if tag == "" || strings.ContainsAny(tag, " \t\n\":") {
+		return fmt.Errorf("invalid association tag %q", tag)
+	}
+	if _, ok := associationBuilders[tag]; ok {
+		return fmt.Errorf("association tag %q is already registered", tag)
+	}
+	columns.RegisterTag(tag)
+	associationBuilders[tag] = func(p associationParams) (Association, error) {
+		return b(AssociationParams{
+			Field:             p.field,
+			ModelType:         p.modelType,
+			ModelValue:        p.modelValue,
+			Tags:              p.popTags,
+			Model:             p.model,
+			InnerAssociations: p.innerAssociations,
+		})
+	}
+	return nil
+}
+
+// ErrUnexportedField is returned by ForStruct for an unexported field
+// tagged with an association, which can't be loaded by reflection.
+type ErrUnexportedField struct {
+	Model string
+	Field string
+	Tag   string
+}
+
+func (e ErrUnexportedField) Error() string {
+	return fmt.Sprintf("%s.%s: the %s association field must be exported", e.Model, e.Field, e.Tag)
+}
+
 // AssociationsForStruct returns all associations for
 // the struct specified. It takes into account tags
 // associations like has_many, belongs_to, has_one.
@@ -38,7 +98,8 @@ func AssociationsForStruct(s interface{}, fields ...string) (Associations, error
 // the struct specified. It takes into account tags
 // associations like has_many, belongs_to, has_one.
 // it throws an error when it finds a field that does
-// not exist for a model.
+// not exist for a model, and ErrUnexportedField for an
+// unexported association field.
 func ForStruct(s interface{}, fields ...string) (Associations, error) {
 	associations := Associations{}
 	innerAssociations := InnerAssociations{}
@@ -82,6 +143,9 @@ func ForStruct(s interface{}, fields ...string) (Associations, error) {
 		for name, builder := range associationBuilders {
 			tag := tags.Find(name)
 			if !tag.Empty() {
+				if f.PkgPath != "" {
+					return associations, ErrUnexportedField{Model: t.Name(), Field: f.Name, Tag: name}
+				}
 				params := associationParams{
 					field:             f,
 					model:             s,
@@ -0,0 +1,113 @@
+package associations_test
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/gobuffalo/pop/associations"
+	"github.com/gobuffalo/pop/columns"
+	"github.com/stretchr/testify/require"
+)
+
+// latestChildAssociation loads the most recent child of a model.
+type latestChildAssociation struct {
+	table string
+	field reflect.Value
+	id    interface{}
+}
+
+func (a *latestChildAssociation) Kind() reflect.Kind {
+	return a.field.Kind()
+}
+
+func (a *latestChildAssociation) Interface() interface{} {
+	return a.field.Addr().Interface()
+}
+
+func (a *latestChildAssociation) Constraint() (string, []interface{}) {
+	return fmt.Sprintf("id = (SELECT MAX(id) FROM %s WHERE owner_id = ?)", a.table), []interface{}{a.id}
+}
+
+func (a *latestChildAssociation) InnerAssociations() associations.InnerAssociations {
+	return nil
+}
+
+func (a *latestChildAssociation) Skipped() bool {
+	return a.id == 0
+}
+
+func init() {
+	err := associations.RegisterBuilder("latest_child", func(p associations.AssociationParams) (associations.Association, error) {
+		return &latestChildAssociation{
+			table: p.Tags.Find("latest_child").Value,
+			field: p.ModelValue.FieldByName(p.Field.Name),
+			id:    p.ModelValue.FieldByName("ID").Interface(),
+		}, nil
+	})
+	if err != nil {
+		panic(err)
+	}
+}
+
+type latestChild struct {
+	ID      int `db:"id"`
+	OwnerID int `db:"owner_id"`
+}
+
+type fooLatestChild struct {
+	ID     int         `db:"id"`
+	Name   string      `db:"name"`
+	Latest latestChild `latest_child:"children"`
+}
+
+func Test_RegisterBuilder(t *testing.T) {
+	r := require.New(t)
+
+	foo := fooLatestChild{ID: 1}
+	as, err := associations.ForStruct(&foo, "Latest")
+	r.NoError(err)
+	r.Len(as, 1)
+	r.Equal(reflect.Struct, as[0].Kind())
+	r.False(as[0].Skipped())
+	r.Equal(&foo.Latest, as[0].Interface())
+
+	where, args := as[0].Constraint()
+	r.Equal("id = (SELECT MAX(id) FROM children WHERE owner_id = ?)", where)
+	r.Equal([]interface{}{1}, args)
+
+	// the association field isn't a column
+	cols := columns.ForStruct(&foo, "foos")
+	r.Len(cols.Cols, 2)
+	r.NotContains(cols.Cols, "Latest")
+
+	r.Error(associations.RegisterBuilder("latest_child", nil))
+	r.Error(associations.RegisterBuilder("has_many", nil))
+	r.Error(associations.RegisterBuilder("", nil))
+}
+
+type fooUnexported struct {
+	ID       int        `db:"id"`
+	children []fooChild `has_many:"children"`
+}
+
+type fooChild struct {
+	ID              int `db:"id"`
+	FooUnexportedID int `db:"foo_unexported_id"`
+}
+
+func Test_ForStruct_UnexportedField(t *testing.T) {
+	r := require.New(t)
+
+	_, err := associations.ForStruct(&fooUnexported{ID: 1})
+	r.Error(err)
+	ferr, ok := err.(associations.ErrUnexportedField)
+	r.True(ok)
+	r.Equal(associations.ErrUnexportedField{Model: "fooUnexported", Field: "children", Tag: "has_many"}, ferr)
+	r.Equal("fooUnexported.children: the has_many association field must be exported", err.Error())
+
+	// the fields not loaded aren't checked
+	as, err := associations.ForStruct(&fooUnexported{ID: 1}, "ID")
+	r.NoError(err)
+	r.Len(as, 0)
+}
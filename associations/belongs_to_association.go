@@ -92,6 +92,10 @@ func (b *belongsToAssociation) Constraint() (string, []interface{}) {
 	return fmt.Sprintf("%s = ?", b.primaryTableID), []interface{}{b.ownerID.Interface()}
 }
 
+func (b *belongsToAssociation) KeyColumns() []string {
+	return []string{b.primaryTableID}
+}
+
 func (b *belongsToAssociation) BeforeInterface() interface{} {
 	// if the owner field is set, don't try to create the association to prevent conflicts.
 	if !b.skipped {
@@ -37,6 +37,10 @@ func Test_Belongs_To_Association(t *testing.T) {
 	a.Equal("id = ?", where)
 	a.Equal(id, args[0].(uuid.UUID))
 
+	k, ok := as[0].(associations.AssociationKeyed)
+	a.True(ok)
+	a.Equal([]string{"id"}, k.KeyColumns())
+
 	bar2 := barBelongsTo{FooID: uuid.Nil}
 	as, err = associations.ForStruct(&bar2, "Foo")
 
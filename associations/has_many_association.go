@@ -6,6 +6,7 @@ import (
 
 	"github.com/gobuffalo/flect"
 	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/x/defaults"
 	"github.com/jmoiron/sqlx"
 )
 
@@ -19,6 +20,8 @@ type hasManyAssociation struct {
 	ownerID   interface{}
 	owner     interface{}
 	fkID      string
+	fkColumns []string
+	fkArgs    []interface{}
 	orderBy   string
 	*associationSkipable
 	*associationComposite
@@ -29,6 +32,10 @@ func init() {
 }
 
 func hasManyAssociationBuilder(p associationParams) (Association, error) {
+	if !p.popTags.Find("through").Empty() {
+		return hasManyThroughAssociationBuilder(p)
+	}
+
 	// Validates if ownerID is nil, this association will be skipped.
 	var skipped bool
 	ownerID := p.modelValue.FieldByName("ID")
@@ -36,6 +43,11 @@ func hasManyAssociationBuilder(p associationParams) (Association, error) {
 		skipped = true
 	}
 
+	fkColumns, fkArgs, err := foreignKey(p, defaults.String(p.popTags.Find("fk_id").Value, flect.Underscore(p.modelType.Name())+"_id"), ownerID.Interface())
+	if err != nil {
+		return nil, err
+	}
+
 	return &hasManyAssociation{
 		owner:     p.model,
 		tableName: p.popTags.Find("has_many").Value,
@@ -44,6 +56,8 @@ func hasManyAssociationBuilder(p associationParams) (Association, error) {
 		ownerName: p.modelType.Name(),
 		ownerID:   ownerID.Interface(),
 		fkID:      p.popTags.Find("fk_id").Value,
+		fkColumns: fkColumns,
+		fkArgs:    fkArgs,
 		orderBy:   p.popTags.Find("order_by").Value,
 		associationSkipable: &associationSkipable{
 			skipped: skipped,
@@ -77,14 +91,14 @@ func (a *hasManyAssociation) Interface() interface{} {
 }
 
 // Constraint returns the content for a where clause, and the args
-// needed to execute it.
+// needed to execute it: the columns of the foreign key, e.g. "user_id = ?",
+// or "tenant_id = ? AND user_id = ?" with an fk_on tag.
 func (a *hasManyAssociation) Constraint() (string, []interface{}) {
-	tn := flect.Underscore(a.ownerName)
-	condition := fmt.Sprintf("%s_id = ?", tn)
-	if a.fkID != "" {
-		condition = fmt.Sprintf("%s = ?", a.fkID)
-	}
-	return condition, []interface{}{a.ownerID}
+	return keyConstraint(a.fkColumns), a.fkArgs
+}
+
+func (a *hasManyAssociation) KeyColumns() []string {
+	return a.fkColumns
 }
 
 func (a *hasManyAssociation) OrderBy() string {
@@ -36,6 +36,10 @@ func Test_Has_Many_Association(t *testing.T) {
 	where, args := as[0].Constraint()
 	a.Equal("foo_has_many_id = ?", where)
 	a.Equal(id, args[0].(int))
+
+	k, ok := as[0].(associations.AssociationKeyed)
+	a.True(ok)
+	a.Equal([]string{"foo_has_many_id"}, k.KeyColumns())
 }
 
 func Test_Has_Many_SetValue(t *testing.T) {
@@ -51,3 +55,46 @@ func Test_Has_Many_SetValue(t *testing.T) {
 	a.NoError(ca.AfterSetup())
 	a.Equal(foo.ID, (*foo.BarHasManies)[0].FooHasManyID.Interface().(int))
 }
+
+type tenantUser struct {
+	ID       int            `db:"id"`
+	TenantID int            `db:"tenant_id"`
+	Posts    []tenantPost   `has_many:"tenant_posts" fk_on:"tenant_id, tenant_user_id"`
+	Invalid  []tenantPost   `has_many:"tenant_posts" fk_on:"region_id,tenant_user_id"`
+	Profile  *tenantProfile `has_one:"tenant_profile" fk_id:"user_id" fk_on:"user_id,tenant_id"`
+}
+
+type tenantPost struct {
+	ID           int `db:"id"`
+	TenantID     int `db:"tenant_id"`
+	TenantUserID int `db:"tenant_user_id"`
+}
+
+type tenantProfile struct {
+	ID       int `db:"id"`
+	TenantID int `db:"tenant_id"`
+	UserID   int `db:"user_id"`
+}
+
+func Test_Has_Many_CompositeForeignKey(t *testing.T) {
+	a := require.New(t)
+	u := tenantUser{ID: 1, TenantID: 7}
+
+	as, err := associations.ForStruct(&u, "Posts", "Profile")
+	a.NoError(err)
+	a.Len(as, 2)
+
+	where, args := as[0].Constraint()
+	a.Equal("tenant_id = ? AND tenant_user_id = ?", where)
+	a.Equal([]interface{}{7, 1}, args)
+	a.Equal([]string{"tenant_id", "tenant_user_id"}, as[0].(associations.AssociationKeyed).KeyColumns())
+
+	where, args = as[1].Constraint()
+	a.Equal("user_id = ? AND tenant_id = ?", where)
+	a.Equal([]interface{}{1, 7}, args)
+	a.Equal([]string{"user_id", "tenant_id"}, as[1].(associations.AssociationKeyed).KeyColumns())
+
+	_, err = associations.ForStruct(&u, "Invalid")
+	a.Error(err)
+	a.Equal("tenantUser.Invalid: fk_on column 'region_id' does not exist in tenantUser", err.Error())
+}
@@ -0,0 +1,89 @@
+package associations
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gobuffalo/flect"
+	"github.com/gobuffalo/x/defaults"
+)
+
+// hasManyThroughAssociation is the implementation for the has_many
+// association type going through an intermediate model, e.g.
+//
+//	Posts Posts `has_many:"posts" through:"memberships"`
+//
+// loads the posts whose id is in the post_id column of the memberships of
+// the model. The fk_id tag overrides the column of the model id in the
+// intermediate table, defaulting to <model>_id. The associated models are
+// only read: they aren't created or linked with the model.
+type hasManyThroughAssociation struct {
+	through   string
+	field     reflect.StructField
+	value     reflect.Value
+	ownerName string
+	ownerID   interface{}
+	fkID      string
+	orderBy   string
+	*associationSkipable
+	*associationComposite
+}
+
+func hasManyThroughAssociationBuilder(p associationParams) (Association, error) {
+	// Validates if ownerID is nil, this association will be skipped.
+	var skipped bool
+	ownerID := p.modelValue.FieldByName("ID")
+	if fieldIsNil(ownerID) {
+		skipped = true
+	}
+
+	return &hasManyThroughAssociation{
+		through:   p.popTags.Find("through").Value,
+		field:     p.field,
+		value:     p.modelValue.FieldByName(p.field.Name),
+		ownerName: p.modelType.Name(),
+		ownerID:   ownerID.Interface(),
+		fkID:      p.popTags.Find("fk_id").Value,
+		orderBy:   p.popTags.Find("order_by").Value,
+		associationSkipable: &associationSkipable{
+			skipped: skipped,
+		},
+		associationComposite: &associationComposite{innerAssociations: p.innerAssociations},
+	}, nil
+}
+
+func (a *hasManyThroughAssociation) Kind() reflect.Kind {
+	if a.field.Type.Kind() == reflect.Ptr {
+		return a.field.Type.Elem().Kind()
+	}
+	return a.field.Type.Kind()
+}
+
+func (a *hasManyThroughAssociation) Interface() interface{} {
+	if a.value.Kind() == reflect.Ptr {
+		val := reflect.New(a.field.Type.Elem())
+		a.value.Set(val)
+		return a.value.Interface()
+	}
+
+	// This piece of code clears a slice in case it is filled with elements.
+	valPointer := a.value.Addr()
+	valPointer.Elem().Set(reflect.MakeSlice(valPointer.Type().Elem(), 0, valPointer.Elem().Cap()))
+	return valPointer.Interface()
+}
+
+// Constraint returns the content for a where clause, and the args
+// needed to execute it. The associated models reached through several
+// intermediate models are only selected once.
+func (a *hasManyThroughAssociation) Constraint() (string, []interface{}) {
+	fk := defaults.String(a.fkID, flect.Underscore(a.ownerName)+"_id")
+	elem, _ := sliceElemType(a.field.Type)
+	farFK := flect.Underscore(elem.Name()) + "_id"
+
+	subQuery := fmt.Sprintf("select %s from %s where %s = ?", farFK, a.through, fk)
+	return fmt.Sprintf("id in (%s)", subQuery), []interface{}{a.ownerID}
+}
+
+func (a *hasManyThroughAssociation) OrderBy() string {
+	return a.orderBy
+}
@@ -0,0 +1,64 @@
+package associations_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gobuffalo/pop/associations"
+	"github.com/stretchr/testify/require"
+)
+
+type FooHasManyThrough struct {
+	ID   int        `db:"id"`
+	Bars barThrough `has_many:"bars" through:"memberships" order_by:"title asc"`
+}
+
+type Bar struct {
+	ID    int    `db:"id"`
+	Title string `db:"title"`
+}
+
+type barThrough []Bar
+
+func Test_Has_Many_Through_Association(t *testing.T) {
+	a := require.New(t)
+
+	foo := FooHasManyThrough{ID: 1, Bars: barThrough{{ID: 3}}}
+
+	as, err := associations.ForStruct(&foo)
+	a.NoError(err)
+	a.Len(as, 1)
+	a.Equal(reflect.Slice, as[0].Kind())
+	a.False(as[0].Skipped())
+
+	where, args := as[0].Constraint()
+	a.Equal("id in (select bar_id from memberships where foo_has_many_through_id = ?)", where)
+	a.Equal([]interface{}{1}, args)
+
+	s, ok := as[0].(associations.AssociationSortable)
+	a.True(ok)
+	a.Equal("title asc", s.OrderBy())
+
+	// the associated models are only read
+	_, ok = as[0].(associations.AssociationAfterCreatable)
+	a.False(ok)
+	a.Empty(as.AssociationsBeforeCreatable())
+
+	a.Equal(&foo.Bars, as[0].Interface())
+	a.Empty(foo.Bars)
+}
+
+func Test_Has_Many_Through_Association_FkID(t *testing.T) {
+	a := require.New(t)
+
+	type user struct {
+		ID    int        `db:"id"`
+		Posts barThrough `has_many:"bars" through:"memberships" fk_id:"member_id"`
+	}
+
+	as, err := associations.ForStruct(&user{})
+	a.NoError(err)
+
+	where, _ := as[0].Constraint()
+	a.Equal("id in (select bar_id from memberships where member_id = ?)", where)
+}
@@ -21,6 +21,8 @@ type hasOneAssociation struct {
 	ownerName      string
 	owner          interface{}
 	fkID           string
+	fkColumns      []string
+	fkArgs         []interface{}
 	*associationSkipable
 	*associationComposite
 }
@@ -39,6 +41,10 @@ func hasOneAssociationBuilder(p associationParams) (Association, error) {
 
 	ownerName := p.modelType.Name()
 	fk := defaults.String(p.popTags.Find("fk_id").Value, flect.Underscore(ownerName)+"_id")
+	fkColumns, fkArgs, err := foreignKey(p, fk, ownerID.Interface())
+	if err != nil {
+		return nil, err
+	}
 
 	fval := p.modelValue.FieldByName(p.field.Name)
 	return &hasOneAssociation{
@@ -49,6 +55,8 @@ func hasOneAssociationBuilder(p associationParams) (Association, error) {
 		ownerID:        ownerID.Interface(),
 		ownerName:      ownerName,
 		fkID:           fk,
+		fkColumns:      fkColumns,
+		fkArgs:         fkArgs,
 		associationSkipable: &associationSkipable{
 			skipped: skipped,
 		},
@@ -70,9 +78,14 @@ func (h *hasOneAssociation) Interface() interface{} {
 }
 
 // Constraint returns the content for the WHERE clause, and the args
-// needed to execute it.
+// needed to execute it: the columns of the foreign key, several with an
+// fk_on tag.
 func (h *hasOneAssociation) Constraint() (string, []interface{}) {
-	return fmt.Sprintf("%s = ?", h.fkID), []interface{}{h.ownerID}
+	return keyConstraint(h.fkColumns), h.fkArgs
+}
+
+func (h *hasOneAssociation) KeyColumns() []string {
+	return h.fkColumns
 }
 
 func (h *hasOneAssociation) AfterSetup() error {
@@ -36,6 +36,10 @@ func Test_Has_One_Association(t *testing.T) {
 	a.Equal("foo_has_one_id = ?", where)
 	a.Equal(id, args[0].(uuid.UUID))
 
+	k, ok := as[0].(associations.AssociationKeyed)
+	a.True(ok)
+	a.Equal([]string{"foo_has_one_id"}, k.KeyColumns())
+
 	foo2 := FooHasOne{}
 
 	as, err = associations.ForStruct(&foo2)
@@ -0,0 +1,186 @@
+package associations
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/gobuffalo/flect"
+	"github.com/gobuffalo/pop/columns"
+	"github.com/gobuffalo/x/defaults"
+)
+
+// tableNameRegexp matches plausible table names, optionally schema qualified.
+var tableNameRegexp = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)?$`)
+
+// TagError describes a problem with an association tag.
+type TagError struct {
+	Model   string
+	Field   string
+	Message string
+}
+
+func (e TagError) Error() string {
+	return fmt.Sprintf("%s.%s: %s", e.Model, e.Field, e.Message)
+}
+
+// TagErrors is a list of association tag problems.
+type TagErrors []TagError
+
+func (e TagErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i := range e {
+		msgs[i] = e[i].Error()
+	}
+	return fmt.Sprintf("invalid associations: %s", strings.Join(msgs, "; "))
+}
+
+// ValidateStruct checks the association tags of the struct specified,
+// without touching the database: the associated types must be structs,
+// the foreign keys must exist on the right struct, and the table names
+// must be plausible. It returns all the problems found as TagErrors,
+// or nil.
+func ValidateStruct(s interface{}) error {
+	t := reflect.TypeOf(s)
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return TagErrors{{Model: t.String(), Message: "model is not a struct"}}
+	}
+
+	var errs TagErrors
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		add := func(msg string, args ...interface{}) {
+			errs = append(errs, TagError{Model: t.Name(), Field: f.Name, Message: fmt.Sprintf(msg, args...)})
+		}
+		tags := columns.TagsFor(f)
+
+		switch {
+		case !tags.Find("belongs_to").Empty():
+			if _, ok := structType(f.Type); !ok {
+				add("belongs_to field must be a struct or a pointer to a struct, not %s", f.Type)
+				continue
+			}
+			fk := defaults.String(tags.Find("fk_id").Value, f.Name+"ID")
+			if _, ok := t.FieldByName(fk); !ok {
+				add("belongs_to requires a field '%s' holding the foreign key in %s", fk, t.Name())
+			}
+			if pk := tags.Find("primary_id").Value; pk != "" {
+				owner, _ := structType(f.Type)
+				if _, ok := owner.FieldByName(pk); !ok {
+					add("primary_id field '%s' does not exist in %s", pk, owner.Name())
+				}
+			}
+		case !tags.Find("has_many").Empty():
+			elem, ok := sliceElemType(f.Type)
+			if !ok {
+				add("has_many field must be a slice of structs, not %s", f.Type)
+				continue
+			}
+			checkTableName(add, "has_many", tags.Find("has_many").Value)
+			checkOwnerID(add, t, "has_many")
+			if through := tags.Find("through").Value; through != "" {
+				// the foreign keys are in the intermediate table
+				checkTableName(add, "through", through)
+				continue
+			}
+			fk := defaults.String(tags.Find("fk_id").Value, flect.Underscore(t.Name())+"_id")
+			if !hasColumn(elem, fk) {
+				add("has_many foreign key '%s' does not exist in %s", fk, elem.Name())
+			}
+			checkCompositeKey(add, t, elem, "has_many", fk, tags.Find("fk_on").Value)
+		case !tags.Find("has_one").Empty():
+			owned, ok := structType(f.Type)
+			if !ok {
+				add("has_one field must be a struct or a pointer to a struct, not %s", f.Type)
+				continue
+			}
+			checkOwnerID(add, t, "has_one")
+			fk := defaults.String(tags.Find("fk_id").Value, flect.Underscore(t.Name())+"_id")
+			if !hasColumn(owned, fk) {
+				add("has_one foreign key '%s' does not exist in %s", fk, owned.Name())
+			}
+			checkCompositeKey(add, t, owned, "has_one", fk, tags.Find("fk_on").Value)
+		case !tags.Find("many_to_many").Empty():
+			if _, ok := sliceElemType(f.Type); !ok {
+				add("many_to_many field must be a slice of structs, not %s", f.Type)
+				continue
+			}
+			checkTableName(add, "many_to_many", tags.Find("many_to_many").Value)
+			checkOwnerID(add, t, "many_to_many")
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+func checkTableName(add func(string, ...interface{}), kind string, name string) {
+	if !tableNameRegexp.MatchString(name) {
+		add("%s table name '%s' is not a valid table name", kind, name)
+	}
+}
+
+func checkOwnerID(add func(string, ...interface{}), t reflect.Type, kind string) {
+	if _, ok := t.FieldByName("ID"); !ok {
+		add("%s requires an ID field in %s", kind, t.Name())
+	}
+}
+
+// checkCompositeKey checks the columns of the fk_on tag: each must exist
+// in the associated struct and, but for the foreign key fk, in the owner t.
+func checkCompositeKey(add func(string, ...interface{}), t reflect.Type, elem reflect.Type, kind string, fk string, fkOn string) {
+	if fkOn == "" {
+		return
+	}
+	for _, col := range strings.Split(fkOn, ",") {
+		col = strings.TrimSpace(col)
+		if !hasColumn(elem, col) {
+			add("%s fk_on column '%s' does not exist in %s", kind, col, elem.Name())
+		}
+		if _, ok := columnField(t, col); !ok && col != fk {
+			add("%s fk_on column '%s' does not exist in %s", kind, col, t.Name())
+		}
+	}
+}
+
+// structType returns the struct type of t, dereferencing pointers.
+func structType(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t, t.Kind() == reflect.Struct
+}
+
+// sliceElemType returns the struct type of the elements of a slice or
+// array type, dereferencing pointers.
+func sliceElemType(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		return t, false
+	}
+	return structType(t.Elem())
+}
+
+// hasColumn checks if the struct has a field mapped to the column.
+func hasColumn(t reflect.Type, column string) bool {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.Anonymous {
+			if st, ok := structType(f.Type); ok && hasColumn(st, column) {
+				return true
+			}
+		}
+		if columns.TagsFor(f).Find("db").Value == column {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package associations_test
+
+import (
+	"testing"
+
+	"github.com/gobuffalo/pop/associations"
+	"github.com/stretchr/testify/require"
+)
+
+type validOwner struct {
+	ID       int            `db:"id"`
+	Items    []validItem    `has_many:"valid_items"`
+	Profile  *validItem     `has_one:"valid_item" fk_id:"owner_id"`
+	Tags     []validItem    `many_to_many:"owners_tags"`
+	Parent   validItem      `belongs_to:"valid_item"`
+	ParentID int            `db:"parent_id"`
+	Children []*validItem   `has_many:"valid_items" fk_id:"owner_id"`
+	Members  []validItem    `has_many:"valid_items" through:"memberships"`
+	Ignored  map[string]int `db:"-"`
+}
+
+type validItem struct {
+	ID           int `db:"id"`
+	ValidOwnerID int `db:"valid_owner_id"`
+	OwnerID      int `db:"owner_id"`
+}
+
+type invalidOwner struct {
+	Items   []validItem `has_many:"valid items"`
+	Profile int         `has_one:"valid_item"`
+	Parent  validItem   `belongs_to:"valid_item" fk_id:"ParentUUID"`
+	Tags    validItem   `many_to_many:"owners_tags"`
+	Members []validItem `has_many:"valid_items" through:"member ships"`
+}
+
+func Test_ValidateStruct(t *testing.T) {
+	r := require.New(t)
+
+	r.NoError(associations.ValidateStruct(&validOwner{}))
+	r.NoError(associations.ValidateStruct(&[]validOwner{}))
+}
+
+func Test_ValidateStruct_Errors(t *testing.T) {
+	r := require.New(t)
+
+	err := associations.ValidateStruct(&invalidOwner{})
+	r.Error(err)
+	errs, ok := err.(associations.TagErrors)
+	r.True(ok)
+	r.Equal(associations.TagErrors{
+		{Model: "invalidOwner", Field: "Items", Message: "has_many table name 'valid items' is not a valid table name"},
+		{Model: "invalidOwner", Field: "Items", Message: "has_many requires an ID field in invalidOwner"},
+		{Model: "invalidOwner", Field: "Items", Message: "has_many foreign key 'invalid_owner_id' does not exist in validItem"},
+		{Model: "invalidOwner", Field: "Profile", Message: "has_one field must be a struct or a pointer to a struct, not int"},
+		{Model: "invalidOwner", Field: "Parent", Message: "belongs_to requires a field 'ParentUUID' holding the foreign key in invalidOwner"},
+		{Model: "invalidOwner", Field: "Tags", Message: "many_to_many field must be a slice of structs, not associations_test.validItem"},
+		{Model: "invalidOwner", Field: "Members", Message: "has_many requires an ID field in invalidOwner"},
+		{Model: "invalidOwner", Field: "Members", Message: "through table name 'member ships' is not a valid table name"},
+	}, errs)
+	r.Contains(err.Error(), "invalidOwner.Profile: has_one field must be a struct")
+}
+
+func Test_ValidateStruct_CompositeKey(t *testing.T) {
+	r := require.New(t)
+
+	err := associations.ValidateStruct(&tenantUser{})
+	r.Equal(associations.TagErrors{
+		{Model: "tenantUser", Field: "Invalid", Message: "has_many fk_on column 'region_id' does not exist in tenantPost"},
+		{Model: "tenantUser", Field: "Invalid", Message: "has_many fk_on column 'region_id' does not exist in tenantUser"},
+	}, err)
+}
@@ -0,0 +1,264 @@
+package pop
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gobuffalo/pop/columns"
+	"github.com/gobuffalo/pop/logging"
+	"github.com/markbates/going/defaults"
+	"github.com/pkg/errors"
+)
+
+// AuditChange is the value of a column before and after a write. Old is
+// nil for the created rows, and for the updated rows when the connection
+// doesn't read the old values, see AuditOldValues, and the model doesn't
+// embed a ChangeTracker. New is nil for the
+// destroyed rows.
+type AuditChange struct {
+	Old interface{} `json:"old"`
+	New interface{} `json:"new"`
+}
+
+// AuditEvent describes a row written by Create, Update or Destroy.
+type AuditEvent struct {
+	// Op is the operation: "Create", "Update" or "Destroy".
+	Op string
+	// Model is the type name of the model, e.g. "User".
+	Model string
+	// Table is the table of the model.
+	Table string
+	// ID is the primary key of the row.
+	ID interface{}
+	// Changes are the values of the written columns, by column name:
+	//
+	//   - Create: all the inserted columns.
+	//   - Update: the updated columns, or only the columns whose value
+	//     changed when the old values are read or tracked.
+	//   - Destroy: the columns of the deleted row, with the values of the
+	//     model, or the values of the row when the old values are read or
+	//     tracked.
+	Changes map[string]AuditChange
+}
+
+// Auditor records the rows written by a connection, e.g. in a change
+// history. Audit is called after the write of each model, with the
+// connection of the write and the context of its transaction, e.g. to read
+// the user making the change. The writes outside of a transaction run in a
+// new one, so the write and its audit are committed together: an error
+// fails the write, and rolls its transaction back.
+type Auditor interface {
+	Audit(ctx context.Context, tx *Connection, e AuditEvent) error
+}
+
+// AuditOption configures the auditing of a connection.
+type AuditOption func(*auditing)
+
+// AuditOldValues reads the columns of the rows before they're updated or
+// destroyed, to report their old values. It costs a SELECT of the row
+// before each Update and Destroy.
+func AuditOldValues() AuditOption {
+	return func(a *auditing) {
+		a.oldValues = true
+	}
+}
+
+type auditing struct {
+	auditor   Auditor
+	oldValues bool
+}
+
+// WithAuditor returns a copy of the connection reporting its Create,
+// Update and Destroy to a. The transactions and copies of the returned
+// connection report to a too. The other writes, e.g. Upsert, Touch or the
+// raw queries, aren't reported.
+//
+//	ac := c.WithAuditor(pop.TableAuditor{Actor: currentUser}, pop.AuditOldValues())
+//	err := ac.Transaction(ctx, func(ctx context.Context, tx *pop.Connection) error {
+//		return tx.Update(&user)
+//	})
+func (c *Connection) WithAuditor(a Auditor, opts ...AuditOption) *Connection {
+	cn := c.copy()
+	cn.auditing = &auditing{auditor: a}
+	for _, opt := range opts {
+		opt(cn.auditing)
+	}
+	return cn
+}
+
+// auditedValues returns the values of the columns of the row of the model
+// before it's written: the values recorded by its ChangeTracker, or the
+// values read from the row when the connection reports the old values.
+func (c *Connection) auditedValues(m *Model, cols []string) (map[string]interface{}, error) {
+	if c.auditing == nil || len(cols) == 0 {
+		return nil, nil
+	}
+	if old := m.snapshotValues(cols); old != nil {
+		return old, nil
+	}
+	if !c.auditing.oldValues {
+		return nil, nil
+	}
+	old := reflect.New(reflect.Indirect(reflect.ValueOf(m.Value)).Type())
+	query := c.Dialect.TranslateSQL(fmt.Sprintf("SELECT %s FROM %s WHERE %s", strings.Join(cols, ", "), m.TableName(), m.whereID()))
+	c.log(logging.SQL, query, m.ID())
+	if err := c.Store.Get(old.Interface(), query, m.ID()); err != nil {
+		return nil, errors.Wrap(err, "could not read the audited values")
+	}
+	return columnValues(old.Interface(), cols), nil
+}
+
+// audit reports the write op of the model columns to the auditor of the
+// connection, if any. old are the values read by auditedValues.
+func (c *Connection) audit(ctx context.Context, op string, m *Model, cols []string, old map[string]interface{}) error {
+	if c.auditing == nil {
+		return nil
+	}
+	e := AuditEvent{
+		Op:      op,
+		Model:   reflect.Indirect(reflect.ValueOf(m.Value)).Type().Name(),
+		Table:   m.TableName(),
+		ID:      m.ID(),
+		Changes: map[string]AuditChange{},
+	}
+	values := columnValues(m.Value, cols)
+	for _, col := range cols {
+		v, ok := values[col]
+		if !ok {
+			continue
+		}
+		switch {
+		case op == "Create":
+			e.Changes[col] = AuditChange{New: v}
+		case op == "Destroy" && old != nil:
+			e.Changes[col] = AuditChange{Old: old[col]}
+		case op == "Destroy":
+			e.Changes[col] = AuditChange{Old: v}
+		case old == nil:
+			e.Changes[col] = AuditChange{New: v}
+		case !valuesEqual(old[col], v):
+			e.Changes[col] = AuditChange{Old: old[col], New: v}
+		}
+	}
+	return errors.Wrapf(c.auditing.auditor.Audit(ctx, c, e), "could not audit the %s of %s", op, e.Model)
+}
+
+// auditedColumns returns the sorted names of the writeable, or readable,
+// columns of the model, and nil if the connection has no auditor. The
+// encrypted columns aren't audited: their plaintexts would leak to the
+// audit trail, and their ciphertexts change with each write.
+func (c *Connection) auditedColumns(m *Model, cols columns.Columns, writeable bool) ([]string, error) {
+	if c.auditing == nil {
+		return nil, nil
+	}
+	fields, err := encryptedFields(m.Value)
+	if err != nil {
+		return nil, err
+	}
+	names := columnNames(cols, writeable)
+	for _, ef := range fields {
+		for i, n := range names {
+			if n == ef.column {
+				names = append(names[:i], names[i+1:]...)
+				break
+			}
+		}
+	}
+	return names, nil
+}
+
+// columnNames returns the sorted names of the writeable, or readable,
+// columns.
+func columnNames(cols columns.Columns, writeable bool) []string {
+	names := make([]string, 0, len(cols.Cols))
+	for _, col := range cols.Cols {
+		if (writeable && col.Writeable) || (!writeable && col.Readable) {
+			names = append(names, col.Name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// columnValues returns the values of the fields of the model mapped to
+// the columns.
+func columnValues(model interface{}, cols []string) map[string]interface{} {
+	fm := strictMapper.FieldMap(reflect.ValueOf(model))
+	values := make(map[string]interface{}, len(cols))
+	for _, col := range cols {
+		if f, ok := fm[col]; ok {
+			values[col] = f.Interface()
+		}
+	}
+	return values
+}
+
+// valuesEqual tells if the column values a and b are equal, as written to
+// the database.
+func valuesEqual(a, b interface{}) bool {
+	a, b = dbValue(a), dbValue(b)
+	if ta, ok := a.(time.Time); ok {
+		tb, ok := b.(time.Time)
+		return ok && ta.Equal(tb)
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// dbValue returns the column value v as written to the database.
+func dbValue(v interface{}) interface{} {
+	if dv, ok := v.(driver.Valuer); ok {
+		if value, err := dv.Value(); err == nil {
+			return value
+		}
+	}
+	return v
+}
+
+// TableAuditor is an Auditor writing the events to a table, with the
+// connection of the write: in its transaction, if any. The changes are
+// written as JSON, with the values written to the database, e.g. null for
+// an invalid nulls.String. The table needs the columns:
+//
+//	create_table("audits") {
+//		t.Column("id", "int", {primary: true})
+//		t.Column("operation", "string", {"size": 16})
+//		t.Column("model", "string", {})
+//		t.Column("table_name", "string", {})
+//		t.Column("record_id", "string", {})
+//		t.Column("actor", "string", {"null": true})
+//		t.Column("changes", "text", {})
+//		t.Column("created_at", "timestamp", {})
+//		t.DisableTimestamps()
+//	}
+type TableAuditor struct {
+	// Table is the name of the table, "audits" by default.
+	Table string
+	// Actor returns the actor of the writes run with ctx, e.g. the id of
+	// the current user. The actor is null without it.
+	Actor func(ctx context.Context) string
+}
+
+// Audit writes e to the table of the auditor.
+func (a TableAuditor) Audit(ctx context.Context, tx *Connection, e AuditEvent) error {
+	values := make(map[string]AuditChange, len(e.Changes))
+	for col, ch := range e.Changes {
+		values[col] = AuditChange{Old: dbValue(ch.Old), New: dbValue(ch.New)}
+	}
+	changes, err := json.Marshal(values)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	var actor interface{}
+	if a.Actor != nil {
+		actor = a.Actor(ctx)
+	}
+	query := fmt.Sprintf("INSERT INTO %s (operation, model, table_name, record_id, actor, changes, created_at) VALUES (?, ?, ?, ?, ?, ?, ?)", defaults.String(a.Table, "audits"))
+	_, err = tx.ExecRaw(ctx, query, e.Op, e.Model, e.Table, fmt.Sprint(e.ID), actor, string(changes), tx.dbTime(tx.now()))
+	return err
+}
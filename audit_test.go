@@ -0,0 +1,103 @@
+package pop
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type recordingAuditor struct {
+	events []AuditEvent
+}
+
+func (a *recordingAuditor) Audit(ctx context.Context, tx *Connection, e AuditEvent) error {
+	a.events = append(a.events, e)
+	return nil
+}
+
+func Test_Auditor(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+
+		a := &recordingAuditor{}
+		ac := tx.WithAuditor(a, AuditOldValues())
+		b := &Book{Title: "Audited", Isbn: "A1"}
+		r.NoError(ac.Create(b))
+		b.Title = "Audited, 2nd edition"
+		r.NoError(ac.Update(b))
+		r.NoError(ac.Destroy(b))
+		r.Len(a.events, 3)
+
+		created := a.events[0]
+		r.Equal("Create", created.Op)
+		r.Equal("Book", created.Model)
+		r.Equal("books", created.Table)
+		r.Equal(b.ID, created.ID)
+		r.Equal(AuditChange{New: "Audited"}, created.Changes["title"])
+		r.Equal(AuditChange{New: b.ID}, created.Changes["id"])
+
+		updated := a.events[1]
+		r.Equal("Update", updated.Op)
+		r.Equal(AuditChange{Old: "Audited", New: "Audited, 2nd edition"}, updated.Changes["title"])
+		r.NotContains(updated.Changes, "isbn")
+
+		destroyed := a.events[2]
+		r.Equal("Destroy", destroyed.Op)
+		r.Equal(AuditChange{Old: "Audited, 2nd edition"}, destroyed.Changes["title"])
+		r.Equal(AuditChange{Old: "A1"}, destroyed.Changes["isbn"])
+
+		// without the old values, all the updated columns are reported
+		a.events = nil
+		b = &Book{Title: "Audited", Isbn: "A2"}
+		r.NoError(tx.Create(b))
+		r.Empty(a.events)
+		r.NoError(tx.WithAuditor(a).Update(b))
+		r.Len(a.events, 1)
+		r.Equal(AuditChange{New: "A2"}, a.events[0].Changes["isbn"])
+	})
+}
+
+type auditActorKey struct{}
+
+func Test_TableAuditor(t *testing.T) {
+	r := require.New(t)
+
+	rollback := errors.New("rollback")
+	ac := PDB.WithAuditor(TableAuditor{Actor: func(ctx context.Context) string {
+		return fmt.Sprint(ctx.Value(auditActorKey{}))
+	}})
+	ctx := context.WithValue(context.Background(), auditActorKey{}, "admin")
+	err := ac.Transaction(ctx, func(ctx context.Context, tx *Connection) error {
+		b := &Book{Title: "Audited", Isbn: "A3"}
+		r.NoError(tx.Create(b))
+
+		var actor, changes string
+		r.NoError(tx.QueryRow(ctx, "SELECT actor, changes FROM audits WHERE operation = ? AND record_id = ?", "Create", fmt.Sprint(b.ID)).Scan(&actor, &changes))
+		r.Equal("admin", actor)
+		values := map[string]AuditChange{}
+		r.NoError(json.Unmarshal([]byte(changes), &values))
+		r.Equal(AuditChange{New: "A3"}, values["isbn"])
+		return rollback
+	})
+	r.Equal(rollback, errors.Cause(err))
+}
+
+type failingAuditor struct{}
+
+func (failingAuditor) Audit(ctx context.Context, tx *Connection, e AuditEvent) error {
+	return errors.New("audit failed")
+}
+
+func Test_Auditor_RollsBackWrite(t *testing.T) {
+	r := require.New(t)
+
+	// outside of a transaction, the write and its audit are committed together
+	r.Error(PDB.WithAuditor(failingAuditor{}).Create(&Book{Title: "Unaudited", Isbn: "A4"}))
+	exists, err := PDB.Where("isbn = ?", "A4").Exists(&Book{})
+	r.NoError(err)
+	r.False(exists)
+}
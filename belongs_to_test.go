@@ -13,7 +13,7 @@ func Test_BelongsTo(t *testing.T) {
 
 	m := &Model{Value: &Enemy{}}
 
-	sql, _ := q.ToSQL(m)
+	sql := q.ToSQLString(m)
 	r.Equal(ts("SELECT enemies.A FROM enemies AS enemies WHERE user_id = ?"), sql)
 }
 
@@ -24,7 +24,7 @@ func Test_BelongsToAs(t *testing.T) {
 
 	m := &Model{Value: &Enemy{}}
 
-	sql, _ := q.ToSQL(m)
+	sql := q.ToSQLString(m)
 	r.Equal(ts("SELECT enemies.A FROM enemies AS enemies WHERE u_id = ?"), sql)
 }
 
@@ -35,6 +35,6 @@ func Test_BelongsToThrough(t *testing.T) {
 	qs := "SELECT enemies.A FROM enemies AS enemies, good_friends AS good_friends WHERE good_friends.user_id = ? AND enemies.id = good_friends.enemy_id"
 
 	m := &Model{Value: &Enemy{}}
-	sql, _ := q.ToSQL(m)
+	sql := q.ToSQLString(m)
 	r.Equal(ts(qs), sql)
 }
@@ -2,10 +2,12 @@ package pop
 
 import (
 	"fmt"
+	"reflect"
 	"strconv"
 	"testing"
 
 	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/associations"
 )
 
 func Benchmark_Create_Pop(b *testing.B) {
@@ -50,7 +52,7 @@ func Benchmark_Find_Pop(b *testing.B) {
 		}
 		tx.Create(u)
 		for n := 0; n < b.N; n++ {
-			tx.Find(u, u.ID)
+			tx.Find(tx.txContext(), u, u.ID)
 		}
 	})
 }
@@ -67,6 +69,29 @@ func Benchmark_Find_Raw(b *testing.B) {
 	})
 }
 
+func Benchmark_All(b *testing.B) {
+	transaction(func(tx *Connection) {
+		for i := 0; i < 10000; i += 500 {
+			users := make(Users, 500)
+			for j := range users {
+				users[j].Name = nulls.NewString(fmt.Sprintf("User %d", i+j))
+			}
+			if err := tx.Create(&users); err != nil {
+				b.Fatal(err)
+			}
+		}
+		ctx := tx.txContext()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for n := 0; n < b.N; n++ {
+			users := Users{}
+			if err := tx.Limit(10000).All(ctx, &users); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
 func Benchmark_translateOne(b *testing.B) {
 	q := "select * from users where id = ? and name = ? and email = ? and a = ? and b = ? and c = ? and d = ? and e = ? and f = ?"
 	for n := 0; n < b.N; n++ {
@@ -81,6 +106,33 @@ func Benchmark_translateTwo(b *testing.B) {
 	}
 }
 
+func Benchmark_associationOrder(b *testing.B) {
+	var a associations.Association = sortedAssociation{order: "title asc"}
+	for n := 0; n < b.N; n++ {
+		associationOrder(a)
+	}
+}
+
+func Benchmark_associationOrder_Reflection(b *testing.B) {
+	var a associations.Association = sortedAssociation{order: "title asc"}
+	for n := 0; n < b.N; n++ {
+		reflectAssociationOrder(a)
+	}
+}
+
+// reflectAssociationOrder is the former associationOrder, finding OrderBy
+// with reflection.
+func reflectAssociationOrder(association associations.Association) string {
+	sortable := (*associations.AssociationSortable)(nil)
+	t := reflect.TypeOf(association)
+	if t.Implements(reflect.TypeOf(sortable).Elem()) {
+		m := reflect.ValueOf(association).MethodByName("OrderBy")
+		out := m.Call([]reflect.Value{})
+		return out[0].String()
+	}
+	return ""
+}
+
 func translateOne(sql string) string {
 	curr := 1
 	out := make([]byte, 0, len(sql))
@@ -6,7 +6,6 @@ import (
 
 	"github.com/pkg/errors"
 	"golang.org/x/sync/errgroup"
-	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
 
 // AfterFindable callback will be called after a record, or records,
@@ -15,10 +14,16 @@ type AfterFindable interface {
 	AfterFind(*Connection) error
 }
 
-func (m *Model) afterFind(ctx context.Context, c *Connection) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "pop/callbacks/afterFind")
-	defer span.Finish()
+var afterFindableType = reflect.TypeOf((*AfterFindable)(nil)).Elem()
 
+func (m *Model) afterFind(ctx context.Context, c *Connection) error {
+	if err := c.decryptFields(m); err != nil {
+		return err
+	}
+	if err := m.scanEnums(c); err != nil {
+		return err
+	}
+	m.snapshot()
 	if x, ok := m.Value.(AfterFindable); ok {
 		if err := x.AfterFind(c); err != nil {
 			return errors.WithStack(err)
@@ -33,6 +38,10 @@ func (m *Model) afterFind(ctx context.Context, c *Connection) error {
 	if kind != reflect.Slice && kind != reflect.Array {
 		return nil
 	}
+	// the type of the elements is checked once, rather than each element
+	if !reflect.PtrTo(rv.Type().Elem()).Implements(afterFindableType) {
+		return nil
+	}
 
 	wg := &errgroup.Group{}
 	for i := 0; i < rv.Len(); i++ {
@@ -65,26 +74,46 @@ func (m *Model) beforeSave(c *Connection) error {
 }
 
 // BeforeCreateable callback will be called before a record is
-// created in the database.
+// created in the database. Prefer BeforeCreater, which gets the
+// context of the operation.
 type BeforeCreateable interface {
 	BeforeCreate(*Connection) error
 }
 
-func (m *Model) beforeCreate(c *Connection) error {
-	if x, ok := m.Value.(BeforeCreateable); ok {
+// BeforeCreater callback will be called before a record is
+// created in the database.
+type BeforeCreater interface {
+	BeforeCreate(context.Context, *Connection) error
+}
+
+func (m *Model) beforeCreate(ctx context.Context, c *Connection) error {
+	switch x := m.Value.(type) {
+	case BeforeCreater:
+		return x.BeforeCreate(ctx, c)
+	case BeforeCreateable:
 		return x.BeforeCreate(c)
 	}
 	return nil
 }
 
 // BeforeUpdateable callback will be called before a record is
-// updated in the database.
+// updated in the database. Prefer BeforeUpdater, which gets the
+// context of the operation.
 type BeforeUpdateable interface {
 	BeforeUpdate(*Connection) error
 }
 
-func (m *Model) beforeUpdate(c *Connection) error {
-	if x, ok := m.Value.(BeforeUpdateable); ok {
+// BeforeUpdater callback will be called before a record is
+// updated in the database.
+type BeforeUpdater interface {
+	BeforeUpdate(context.Context, *Connection) error
+}
+
+func (m *Model) beforeUpdate(ctx context.Context, c *Connection) error {
+	switch x := m.Value.(type) {
+	case BeforeUpdater:
+		return x.BeforeUpdate(ctx, c)
+	case BeforeUpdateable:
 		return x.BeforeUpdate(c)
 	}
 	return nil
@@ -96,7 +125,18 @@ type BeforeDestroyable interface {
 	BeforeDestroy(*Connection) error
 }
 
-func (m *Model) beforeDestroy(c *Connection) error {
+// BeforeDeleter callback will be called before a record is
+// destroyed in the database, before BeforeDestroy.
+type BeforeDeleter interface {
+	BeforeDelete(context.Context, *Connection) error
+}
+
+func (m *Model) beforeDestroy(ctx context.Context, c *Connection) error {
+	if x, ok := m.Value.(BeforeDeleter); ok {
+		if err := x.BeforeDelete(ctx, c); err != nil {
+			return err
+		}
+	}
 	if x, ok := m.Value.(BeforeDestroyable); ok {
 		return x.BeforeDestroy(c)
 	}
@@ -109,7 +149,18 @@ type AfterDestroyable interface {
 	AfterDestroy(*Connection) error
 }
 
-func (m *Model) afterDestroy(c *Connection) error {
+// AfterDeleter callback will be called after a record is
+// destroyed in the database, before AfterDestroy.
+type AfterDeleter interface {
+	AfterDelete(context.Context, *Connection) error
+}
+
+func (m *Model) afterDestroy(ctx context.Context, c *Connection) error {
+	if x, ok := m.Value.(AfterDeleter); ok {
+		if err := x.AfterDelete(ctx, c); err != nil {
+			return err
+		}
+	}
 	if x, ok := m.Value.(AfterDestroyable); ok {
 		return x.AfterDestroy(c)
 	}
@@ -117,26 +168,46 @@ func (m *Model) afterDestroy(c *Connection) error {
 }
 
 // AfterUpdateable callback will be called after a record is
-// updated in the database.
+// updated in the database. Prefer AfterUpdater, which gets the
+// context of the operation.
 type AfterUpdateable interface {
 	AfterUpdate(*Connection) error
 }
 
-func (m *Model) afterUpdate(c *Connection) error {
-	if x, ok := m.Value.(AfterUpdateable); ok {
+// AfterUpdater callback will be called after a record is
+// updated in the database.
+type AfterUpdater interface {
+	AfterUpdate(context.Context, *Connection) error
+}
+
+func (m *Model) afterUpdate(ctx context.Context, c *Connection) error {
+	switch x := m.Value.(type) {
+	case AfterUpdater:
+		return x.AfterUpdate(ctx, c)
+	case AfterUpdateable:
 		return x.AfterUpdate(c)
 	}
 	return nil
 }
 
 // AfterCreateable callback will be called after a record is
-// created in the database.
+// created in the database. Prefer AfterCreater, which gets the
+// context of the operation.
 type AfterCreateable interface {
 	AfterCreate(*Connection) error
 }
 
-func (m *Model) afterCreate(c *Connection) error {
-	if x, ok := m.Value.(AfterCreateable); ok {
+// AfterCreater callback will be called after a record is
+// created in the database, with the ID set by the database.
+type AfterCreater interface {
+	AfterCreate(context.Context, *Connection) error
+}
+
+func (m *Model) afterCreate(ctx context.Context, c *Connection) error {
+	switch x := m.Value.(type) {
+	case AfterCreater:
+		return x.AfterCreate(ctx, c)
+	case AfterCreateable:
 		return x.AfterCreate(c)
 	}
 	return nil
@@ -2,8 +2,10 @@ package pop
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -68,3 +70,43 @@ func Test_Callbacks_on_Slice(t *testing.T) {
 		}
 	})
 }
+
+func Test_Callbacks_Context(t *testing.T) {
+	r := require.New(t)
+
+	ctx := context.WithValue(context.Background(), callbackKey{}, "ctx")
+	err := PDB.Transaction(ctx, func(ctx context.Context, tx *Connection) error {
+		user := &ContextCallbacksUser{}
+		r.NoError(tx.Create(user))
+		r.Equal("ctx", user.BeforeC)
+		r.NotZero(user.ID)
+		r.Equal(fmt.Sprintf("ctx %d", user.ID), user.AfterC)
+		r.Empty(user.BeforeU)
+
+		r.NoError(tx.Update(user))
+		r.Equal("ctx", user.BeforeU)
+		r.Equal("ctx", user.AfterU)
+
+		r.NoError(tx.Destroy(user))
+		r.Equal("ctx", user.BeforeD)
+		r.Equal("ctx", user.AfterD)
+		return errors.New("rollback")
+	})
+	r.EqualError(errors.Cause(err), "rollback")
+}
+
+func Test_Callbacks_WithContext(t *testing.T) {
+	r := require.New(t)
+
+	// outside a transaction, the callbacks get the context of WithContext
+	ctx := context.WithValue(context.Background(), callbackKey{}, "ctx")
+	c := PDB.WithContext(ctx)
+	user := &ContextCallbacksUser{}
+	r.NoError(c.Create(user))
+	r.Equal("ctx", user.BeforeC)
+	r.Equal(fmt.Sprintf("ctx %d", user.ID), user.AfterC)
+
+	r.NoError(c.Destroy(user))
+	r.Equal("ctx", user.BeforeD)
+	r.Equal("ctx", user.AfterD)
+}
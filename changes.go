@@ -0,0 +1,199 @@
+package pop
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/gobuffalo/pop/columns"
+)
+
+// ChangeTracker tracks the changes of the columns of the models embedding
+// it: the values of the columns are recorded when the model is loaded by
+// a finder, created or updated, and compared to the values of its fields
+// by Changes and UpdateChanged. It's ignored as a column with db:"-":
+//
+//	type User struct {
+//		pop.ChangeTracker `db:"-"`
+//		ID    int    `db:"id"`
+//		Email string `db:"email"`
+//	}
+//
+// The recorded values are copies of the values of the fields, held by the
+// model, and released with it.
+type ChangeTracker struct {
+	snapshot map[string]interface{}
+}
+
+func (t *ChangeTracker) changeTracker() *ChangeTracker {
+	return t
+}
+
+// changeTracked is implemented by the models embedding a ChangeTracker.
+type changeTracked interface {
+	changeTracker() *ChangeTracker
+}
+
+var changeTrackedType = reflect.TypeOf((*changeTracked)(nil)).Elem()
+
+// Changes returns the columns of the model whose field changed since the
+// model was loaded or saved, with their old and new values:
+//
+//	c.Find(ctx, &user, id)
+//	user.Email = "mark@example.com"
+//	c.Changes(&user) // map[email:[old@example.com mark@example.com]]
+//
+// It returns nil if the model doesn't embed a ChangeTracker, or wasn't
+// loaded or saved yet.
+func (c *Connection) Changes(model interface{}) map[string][2]interface{} {
+	changes, _ := (&Model{Value: model}).changes()
+	return changes
+}
+
+// UpdateChanged updates the columns of the model whose field changed since
+// the model was loaded or saved, see Changes, and its updated_at column.
+// The columns are compared after the BeforeSave and BeforeUpdate callbacks:
+// when no column changed, no statement is run, and the after callbacks
+// aren't run. The models not tracked are updated like with Update.
+//
+//	user.Email = email
+//	err := c.UpdateChanged(ctx, &user) // UPDATE users SET email = ?, updated_at = ? WHERE users.id = ?
+func (c *Connection) UpdateChanged(ctx context.Context, model interface{}) error {
+	return c.update(ctx, model, true)
+}
+
+// snapshot records the values of the columns of the tracked models.
+func (m *Model) snapshot() {
+	rv := reflect.Indirect(reflect.ValueOf(m.Value))
+	if rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		et := rv.Type().Elem()
+		if et.Kind() != reflect.Ptr {
+			et = reflect.PtrTo(et)
+		}
+		if !et.Implements(changeTrackedType) {
+			return
+		}
+		for i := 0; i < rv.Len(); i++ {
+			el := rv.Index(i)
+			if el.Kind() != reflect.Ptr {
+				el = el.Addr()
+			} else if el.IsNil() {
+				continue
+			}
+			(&Model{Value: el.Interface(), schema: m.schema, ctx: m.ctx}).snapshot()
+		}
+		return
+	}
+	t, ok := m.Value.(changeTracked)
+	if !ok {
+		return
+	}
+	cols := columnNames(columns.ForStruct(m.Value, m.TableName()), false)
+	values := columnValues(m.Value, cols)
+	for col, v := range values {
+		values[col] = copyValue(v)
+	}
+	t.changeTracker().snapshot = values
+}
+
+// copyValue returns a deep copy of the column value v, so the recorded
+// value isn't changed through the pointers, slices and maps it shares with
+// the field, e.g. a *string or a JSON map.
+func copyValue(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(v)).Interface()
+}
+
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem()))
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Array:
+		c := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+		return c
+	case reflect.Struct:
+		// the unexported fields, e.g. the location of a time.Time, are shared
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		return c
+	}
+	return v
+}
+
+// changes returns the changed columns of the model, and false if it isn't
+// tracked.
+func (m *Model) changes() (map[string][2]interface{}, bool) {
+	t, ok := m.Value.(changeTracked)
+	if !ok || t.changeTracker().snapshot == nil {
+		return nil, false
+	}
+	snapshot := t.changeTracker().snapshot
+	cols := make([]string, 0, len(snapshot))
+	for col := range snapshot {
+		cols = append(cols, col)
+	}
+	values := columnValues(m.Value, cols)
+	changes := map[string][2]interface{}{}
+	for col, old := range snapshot {
+		if !valuesEqual(old, values[col]) {
+			changes[col] = [2]interface{}{old, values[col]}
+		}
+	}
+	return changes, true
+}
+
+// snapshotValues returns the recorded values of the columns of the model,
+// and nil if it isn't tracked.
+func (m *Model) snapshotValues(cols []string) map[string]interface{} {
+	t, ok := m.Value.(changeTracked)
+	if !ok || t.changeTracker().snapshot == nil {
+		return nil
+	}
+	values := make(map[string]interface{}, len(cols))
+	for _, col := range cols {
+		if v, ok := t.changeTracker().snapshot[col]; ok {
+			values[col] = v
+		}
+	}
+	return values
+}
@@ -0,0 +1,103 @@
+package pop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type trackedBook struct {
+	ChangeTracker `db:"-"`
+	ID            int       `db:"id"`
+	Title         string    `db:"title"`
+	Isbn          string    `db:"isbn"`
+	CreatedAt     time.Time `db:"created_at"`
+	UpdatedAt     time.Time `db:"updated_at"`
+}
+
+func (trackedBook) TableName() string {
+	return "books"
+}
+
+func Test_Changes(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		r.Nil(tx.Changes(&Book{}))
+		r.Nil(tx.Changes(&trackedBook{}))
+
+		b := &trackedBook{Title: "Tracked", Isbn: "T1"}
+		r.NoError(tx.Create(b))
+		r.Empty(tx.Changes(b))
+
+		books := []trackedBook{}
+		r.NoError(tx.Where("id = ?", b.ID).All(ctx, &books))
+		r.Len(books, 1)
+		books[0].Title = "Tracked, 2nd edition"
+		r.Equal(map[string][2]interface{}{"title": {"Tracked", "Tracked, 2nd edition"}}, tx.Changes(&books[0]))
+	})
+}
+
+func Test_UpdateChanged(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		tx = tx.WithClock(func() time.Time { return created })
+		r.NoError(tx.Create(&trackedBook{Title: "Tracked", Isbn: "T2"}))
+
+		b := &trackedBook{}
+		r.NoError(tx.Where("isbn = ?", "T2").First(ctx, b))
+		// a concurrent write, not overwritten by UpdateChanged
+		_, err := tx.ExecRaw(ctx, "UPDATE books SET isbn = ? WHERE id = ?", "T3", b.ID)
+		r.NoError(err)
+
+		updated := created.Add(time.Hour)
+		tx = tx.WithClock(func() time.Time { return updated })
+		// nothing changed: no statement
+		r.NoError(tx.UpdateChanged(ctx, b))
+		r.Equal(created, b.UpdatedAt)
+
+		a := &recordingAuditor{}
+		b.Title = "Tracked, 2nd edition"
+		r.NoError(tx.WithAuditor(a).UpdateChanged(ctx, b))
+		r.Empty(tx.Changes(b))
+
+		reloaded := &trackedBook{}
+		r.NoError(tx.Find(ctx, reloaded, b.ID))
+		r.Equal("Tracked, 2nd edition", reloaded.Title)
+		r.Equal("T3", reloaded.Isbn)
+		r.Equal(updated, reloaded.UpdatedAt.UTC())
+
+		// the tracked values are the old values of the audit
+		r.Len(a.events, 1)
+		r.Equal(AuditChange{Old: "Tracked", New: "Tracked, 2nd edition"}, a.events[0].Changes["title"])
+		r.NotContains(a.events[0].Changes, "isbn")
+	})
+}
+
+func Test_copyValue(t *testing.T) {
+	r := require.New(t)
+
+	s := "old"
+	ps := copyValue(&s).(*string)
+	s = "new"
+	r.Equal("old", *ps)
+
+	b := []byte("old")
+	cb := copyValue(b).([]byte)
+	b[0] = 'n'
+	r.Equal("old", string(cb))
+
+	m := map[string]interface{}{"tags": []string{"old"}}
+	cm := copyValue(m).(map[string]interface{})
+	m["tags"].([]string)[0] = "new"
+	m["name"] = "new"
+	r.Equal(map[string]interface{}{"tags": []string{"old"}}, cm)
+
+	r.Nil(copyValue(nil))
+	r.Nil(copyValue((*string)(nil)).(*string))
+}
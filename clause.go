@@ -30,11 +30,16 @@ func (c clauses) Args() (args []interface{}) {
 type fromClause struct {
 	From string
 	As   string
+	// Hint is rendered after the table, e.g. an index hint.
+	Hint string
 }
 
 type fromClauses []fromClause
 
 func (c fromClause) String() string {
+	if c.Hint != "" {
+		return fmt.Sprintf("%s AS %s %s", c.From, c.As, c.Hint)
+	}
 	return fmt.Sprintf("%s AS %s", c.From, c.As)
 }
 
@@ -52,3 +57,27 @@ type belongsToThroughClause struct {
 }
 
 type belongsToThroughClauses []belongsToThroughClause
+
+// clone returns a copy of the clause, not sharing its arguments.
+func (c clause) clone() clause {
+	c.Arguments = cloneArgs(c.Arguments)
+	return c
+}
+
+func (c clauses) clone() clauses {
+	if c == nil {
+		return nil
+	}
+	out := make(clauses, len(c))
+	for i := range c {
+		out[i] = c[i].clone()
+	}
+	return out
+}
+
+func cloneArgs(args []interface{}) []interface{} {
+	if args == nil {
+		return nil
+	}
+	return append(make([]interface{}, 0, len(args)), args...)
+}
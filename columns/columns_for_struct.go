@@ -2,6 +2,7 @@ package columns
 
 import (
 	"reflect"
+	"strings"
 
 	"github.com/markbates/oncer"
 )
@@ -51,20 +52,36 @@ func ForStructWithAlias(s interface{}, tableName string, tableAlias string) (col
 		}
 	}
 
-	fieldCount := st.NumField()
+	addStructColumns(&columns, st)
+	return columns
+}
 
-	for i := 0; i < fieldCount; i++ {
+// addStructColumns adds the columns of the fields of st, and of the
+// fields of the structs it embeds with the `pop:",embed"` tag.
+func addStructColumns(columns *Columns, st reflect.Type) {
+	for i := 0; i < st.NumField(); i++ {
 		field := st.Field(i)
 
+		if embedded(field) {
+			et := field.Type
+			if et.Kind() == reflect.Ptr {
+				et = et.Elem()
+			}
+			addStructColumns(columns, et)
+			continue
+		}
+
 		popTags := TagsFor(field)
 		tag := popTags.Find("db")
 
 		if !tag.Ignored() && !tag.Empty() {
-			col := tag.Value
+			col, computed := computedColumn(field, tag.Value)
 
 			// add writable or readable.
 			tag := popTags.Find("rw")
-			if !tag.Empty() {
+			if computed {
+				col = col + ",r"
+			} else if !tag.Empty() {
 				col = col + "," + tag.Value
 			}
 
@@ -78,6 +95,49 @@ func ForStructWithAlias(s interface{}, tableName string, tableAlias string) (col
 			}
 		}
 	}
+}
 
-	return columns
+// embedded tells if the field is an embedded struct, or pointer to a
+// struct, with the `pop:",embed"` tag: its fields are columns of the
+// table of the model, like sqlx maps them.
+//
+//	type User struct {
+//		ID      int    `db:"id"`
+//		Address `pop:",embed"`
+//	}
+func embedded(field reflect.StructField) bool {
+	if !field.Anonymous || field.Tag.Get("db") != "" {
+		return false
+	}
+	t := field.Type
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for _, opt := range strings.Split(field.Tag.Get("pop"), ",")[1:] {
+		if opt == "embed" {
+			return true
+		}
+	}
+	return false
+}
+
+// computedColumn returns the column of a db tag with the computed option,
+// e.g. `db:"full_name,computed"` for a generated column: it's read, but
+// never written by INSERT and UPDATE. The column of `db:",computed"` is
+// the field name mapped like sqlx does.
+func computedColumn(field reflect.StructField, value string) (string, bool) {
+	xs := strings.Split(value, ",")
+	for _, opt := range xs[1:] {
+		if opt != "computed" {
+			continue
+		}
+		if xs[0] == "" {
+			return strings.ToLower(field.Name), true
+		}
+		return xs[0], true
+	}
+	return value, false
 }
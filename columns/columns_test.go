@@ -17,6 +17,29 @@ type foo struct {
 
 type foos []foo
 
+type Address struct {
+	Street string `db:"street"`
+	City   string `db:"city" rw:"r"`
+}
+
+type Geo struct {
+	Lat float64 `db:"lat"`
+}
+
+type located struct {
+	ID      int `db:"id"`
+	Address `pop:",embed"`
+	*Geo    `pop:",embed"`
+	Other   Address
+}
+
+type computed struct {
+	ID       int    `db:"id"`
+	Name     string `db:"name"`
+	FullName string `db:"full_name,computed"`
+	Initials string `db:",computed" rw:"w"`
+}
+
 func Test_Column_MapsSlice(t *testing.T) {
 	r := require.New(t)
 
@@ -60,3 +83,23 @@ func Test_Columns_Remove(t *testing.T) {
 		r.Equal(len(c.Cols), 3)
 	}
 }
+
+func Test_Columns_Computed(t *testing.T) {
+	r := require.New(t)
+
+	c := columns.ForStruct(&computed{}, "people")
+	r.Equal(len(c.Cols), 4)
+	r.Equal(c.Cols["full_name"], &columns.Column{Name: "full_name", Writeable: false, Readable: true, SelectSQL: "people.full_name"})
+	r.Equal(c.Cols["initials"], &columns.Column{Name: "initials", Writeable: false, Readable: true, SelectSQL: "people.initials"})
+	r.Equal("name", c.Writeable().String())
+	r.Equal("full_name, id, initials, name", c.Readable().String())
+}
+
+func Test_Columns_Embedded(t *testing.T) {
+	r := require.New(t)
+
+	c := columns.ForStruct(&located{}, "places")
+	r.Equal("Other, city, id, lat, street", c.String())
+	r.Equal(c.Cols["city"], &columns.Column{Name: "city", Writeable: false, Readable: true, SelectSQL: "places.city"})
+	r.Equal("Other, lat, street", c.Writeable().String())
+}
@@ -5,7 +5,19 @@ import (
 	"strings"
 )
 
-var tags = "db rw select belongs_to has_many has_one fk_id primary_id order_by many_to_many"
+var tags = "db rw select belongs_to has_many has_one fk_id fk_on primary_id order_by many_to_many through touch"
+
+// RegisterTag adds a tag to the pop tags, e.g. the tag of a custom
+// association, so the fields defined by it aren't mapped to a column.
+// It's meant to be called from an init function, before any model is used.
+func RegisterTag(name string) {
+	for _, tag := range strings.Fields(tags) {
+		if tag == name {
+			return
+		}
+	}
+	tags += " " + name
+}
 
 // Tag represents a field tag defined exclusively for pop package.
 type Tag struct {
@@ -20,3 +20,20 @@ func Test_Tags_TagsFor(t *testing.T) {
 	r.Equal(tags.Find("db").Value, "first_name")
 	r.Equal(tags.Find("select").Value, "first_name as f")
 }
+
+func Test_Tags_RegisterTag(t *testing.T) {
+	r := require.New(t)
+
+	type bar struct {
+		Latest string `latest_child:"posts"`
+	}
+	f, _ := reflect.TypeOf(bar{}).FieldByName("Latest")
+	r.Equal("Latest", columns.TagsFor(f).Find("db").Value)
+
+	columns.RegisterTag("latest_child")
+	columns.RegisterTag("latest_child")
+	tags := columns.TagsFor(f)
+	r.Len(tags, 1)
+	r.Equal("posts", tags.Find("latest_child").Value)
+	r.True(tags.Find("db").Empty())
+}
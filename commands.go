@@ -11,7 +11,7 @@ import (
 func CreateDB(c *Connection) error {
 	deets := c.Dialect.Details()
 	if deets.Database != "" {
-		log(logging.Info, fmt.Sprintf("create %s (%s)", deets.Database, c.URL()))
+		c.log(logging.Info, fmt.Sprintf("create %s (%s)", deets.Database, c.URL()))
 		return errors.Wrapf(c.Dialect.CreateDB(), "couldn't create database %s", deets.Database)
 	}
 	return nil
@@ -21,7 +21,7 @@ func CreateDB(c *Connection) error {
 func DropDB(c *Connection) error {
 	deets := c.Dialect.Details()
 	if deets.Database != "" {
-		log(logging.Info, fmt.Sprintf("drop %s (%s)", deets.Database, c.URL()))
+		c.log(logging.Info, fmt.Sprintf("drop %s (%s)", deets.Database, c.URL()))
 		return errors.Wrapf(c.Dialect.DropDB(), "couldn't drop database %s", deets.Database)
 	}
 	return nil
@@ -85,6 +85,7 @@ func LoadFrom(r io.Reader) error {
 			log(logging.Warn, "unable to load connection %s: %v", n, err)
 			continue
 		}
+		con.name = n
 		Connections[n] = con
 	}
 	return nil
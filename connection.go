@@ -1,13 +1,15 @@
 package pop
 
 import (
-	"fmt"
-	"sync/atomic"
+	"context"
+	"database/sql"
 	"time"
 
+	"github.com/gobuffalo/pop/logging"
 	"github.com/jmoiron/sqlx"
 	"github.com/markbates/going/defaults"
 	"github.com/markbates/going/randx"
+	"github.com/markbates/oncer"
 	"github.com/pkg/errors"
 )
 
@@ -17,12 +19,45 @@ var Connections = map[string]*Connection{}
 // Connection represents all necessary details to talk with a datastore
 type Connection struct {
 	ID          string
-	Store       store
+	Store       Store
 	Dialect     dialect
 	Elapsed     int64
 	TX          *Tx
 	eager       bool
 	eagerFields []string
+	schemaCache *schemaCache
+	middlewares []QueryMiddleware
+	queryStats  *queryStats
+	name        string
+	// ctx is the context of the operations not taking one: the context
+	// the transaction was started with, or the one of WithContext
+	ctx         context.Context
+	retryPolicy *RetryPolicy
+	idempotent  bool
+	blind       bool
+	clock       func() time.Time
+	dbClock     bool
+	auditing    *auditing
+	cipher      Cipher
+	maxPerPage  int
+	debugEager  bool
+	logger      Logger
+	observers   []QueryObserver
+	rewriters   []QueryRewriter
+	// op is the record of the operation run by the connection, see
+	// operation.
+	op *QueryInfo
+	// shard is the shard of the connections of a ShardedConnection.
+	shard *connectionShard
+	// schemaName is the schema of WithSchema, qualifying the table names
+	// instead of the Schema of the ConnectionDetails.
+	schemaName string
+	// derived are the connections derived from the connection, e.g. by
+	// ApplicationName, whose pools it owns.
+	derived *derivedConnections
+	// borrowed is set on the connections whose pool is owned by another
+	// connection, see derivedConnections: Close doesn't close it.
+	borrowed bool
 }
 
 func (c *Connection) String() string {
@@ -52,7 +87,11 @@ func NewConnection(deets *ConnectionDetails) (*Connection, error) {
 		return nil, errors.WithStack(err)
 	}
 	c := &Connection{
-		ID: randx.String(30),
+		ID:          randx.String(30),
+		schemaCache: newSchemaCache(),
+		middlewares: []QueryMiddleware{TracingMiddleware},
+		queryStats:  newQueryStats(deets),
+		derived:     newDerivedConnections(),
 	}
 
 	if nc, ok := newConnection[deets.Dialect]; ok {
@@ -95,52 +134,156 @@ func (c *Connection) Open() error {
 		return errors.New("invalid connection instance")
 	}
 	details := c.Dialect.Details()
-	db, err := sqlx.Open(details.Dialect, c.Dialect.URL())
+	db, err := openDB(c.Dialect)
 	if err != nil {
 		return errors.Wrap(err, "could not open database connection")
 	}
-	db.SetMaxOpenConns(details.Pool)
-	db.SetMaxIdleConns(details.IdlePool)
-	c.Store = &dB{db}
+	if err := ping(db, details); err != nil {
+		db.Close()
+		return err
+	}
+	store := newDB(db)
+	c.Store = withLogger(withQueryStats(withSlowQueryLog(store, c.logger, details), c.queryStats), c.logger, details)
 
 	if d, ok := c.Dialect.(afterOpenable); ok {
 		err = d.AfterOpen(c)
 		if err != nil {
+			store.Close()
 			c.Store = nil
 		}
 	}
-	return errors.Wrap(err, "could not open database connection")
+	if err != nil {
+		return errors.Wrap(err, "could not open database connection")
+	}
+	if details.HealthCheckInterval > 0 {
+		c.startHealthCheck(store, details.HealthCheckInterval)
+	}
+	return nil
+}
+
+// openDB opens a database pool with the details of the dialect. It
+// doesn't connect to the database.
+func openDB(d dialect) (*sqlx.DB, error) {
+	details := d.Details()
+	// checks the TLS configuration now, rather than on the first query
+	dsn, err := configureDSN(d, d.URL())
+	if err != nil {
+		return nil, err
+	}
+	stmts, err := sessionStatements(d)
+	if err != nil {
+		return nil, err
+	}
+	var db *sqlx.DB
+	switch {
+	case details.CredentialsProvider != nil:
+		db, err = openWithCredentials(d, stmts)
+	case len(stmts) > 0:
+		db, err = openWithSession(d, dsn, stmts)
+	default:
+		db, err = sqlx.Open(details.driverName(), dsn)
+	}
+	if err != nil {
+		return nil, err
+	}
+	db.SetMaxOpenConns(details.Pool)
+	db.SetMaxIdleConns(details.IdlePool)
+	db.SetConnMaxLifetime(details.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(details.ConnMaxIdleTime)
+	return db, nil
 }
 
-// Close destroys an active datasource connection
+// configureDSN returns the dsn of the dialect, with the TLS, time zone and
+// dial timeout parameters of its details.
+func configureDSN(d dialect, dsn string) (string, error) {
+	dsn, err := withTLS(d, dsn)
+	if err != nil {
+		return "", err
+	}
+	if dsn, err = withUTC(d, dsn); err != nil {
+		return "", err
+	}
+	return withDialTimeout(d, dsn)
+}
+
+// Close destroys an active datasource connection, and the connections
+// derived from it, e.g. by ApplicationName. Closing a derived connection is a
+// no-op: its pool is closed with the connection it's derived from.
 func (c *Connection) Close() error {
-	fmt.Println("pop is stupid")
-	return nil
+	if c.borrowed {
+		return nil
+	}
+	if c.derived != nil {
+		if err := c.derived.closeAll(); err != nil {
+			c.log(logging.Warn, "%v", err)
+		}
+	}
 	return errors.Wrap(c.Store.Close(), "couldn't close connection")
 }
 
+// PoolStats returns the statistics of the connection pool, to monitor the
+// pool settings of the ConnectionDetails. They're empty for a transaction
+// or a connection not opened yet.
+func (c *Connection) PoolStats() sql.DBStats {
+	s := unwrapLogger(c.Store)
+	if ds, ok := s.(*dryRunStore); ok {
+		s = ds.reads
+	}
+	db, ok := rawDB(s)
+	if !ok {
+		return sql.DBStats{}
+	}
+	return db.pool().Stats()
+}
+
 // Transaction will start a new transaction on the connection. If the inner function
 // returns an error then the transaction will be rolled back, otherwise the transaction
-// will automatically commit at the end.
-func (c *Connection) Transaction(fn func(tx *Connection) error) error {
+// will automatically commit at the end. The transaction is rolled back if ctx is
+// canceled, and the queries run in the inner function are traced with ctx.
+//
+//	err := c.Transaction(ctx, func(ctx context.Context, tx *pop.Connection) error {
+//		return tx.Create(&user)
+//	})
+//
+// On CockroachDB, the transactions failing with a serialization failure
+// are retried, with the retry policy of the connection or up to 5 times:
+// the inner function must be safe to run again.
+func (c *Connection) Transaction(ctx context.Context, fn func(ctx context.Context, tx *Connection) error) error {
 	return c.Dialect.Lock(func() error {
-		var dberr error
-		cn, err := c.NewTransaction()
-		if err != nil {
-			return err
-		}
-		err = fn(cn)
-		if err != nil {
-			dberr = cn.TX.Rollback()
-		} else {
-			dberr = cn.TX.Commit()
-		}
-		if err != nil {
-			return errors.WithStack(err)
-		}
-		return errors.Wrap(dberr, "error committing or rolling back transaction")
+		return c.withTransactionRetries(ctx, func() error {
+			return c.transaction(ctx, fn)
+		})
 	})
+}
 
+// transaction runs fn in a new transaction, see Transaction.
+func (c *Connection) transaction(ctx context.Context, fn func(ctx context.Context, tx *Connection) error) error {
+	var dberr error
+	cn, err := c.NewTransactionContext(ctx)
+	if err != nil {
+		return err
+	}
+	err = fn(ctx, cn)
+	if err != nil {
+		dberr = cn.TX.rollback(err)
+	} else {
+		dberr = cn.TX.Commit()
+	}
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	return errors.Wrap(dberr, "error committing or rolling back transaction")
+}
+
+// TransactionWithoutContext will start a new transaction on the connection,
+// like Transaction, with a background context.
+//
+// Deprecated: use Transaction instead.
+func (c *Connection) TransactionWithoutContext(fn func(tx *Connection) error) error {
+	oncer.Deprecate(0, "pop.Connection#TransactionWithoutContext", "Use pop.Connection#Transaction instead.")
+	return c.Transaction(context.Background(), func(_ context.Context, tx *Connection) error {
+		return fn(tx)
+	})
 }
 
 // Rollback will open a new transaction and automatically rollback that transaction
@@ -156,17 +299,38 @@ func (c *Connection) Rollback(fn func(tx *Connection)) error {
 
 // NewTransaction starts a new transaction on the connection
 func (c *Connection) NewTransaction() (*Connection, error) {
+	return c.NewTransactionContext(context.Background())
+}
+
+// NewTransactionContext starts a new transaction on the connection, bound
+// to ctx: the transaction is rolled back if ctx is canceled before it's
+// committed.
+func (c *Connection) NewTransactionContext(ctx context.Context) (*Connection, error) {
 	var cn *Connection
 	if c.TX == nil {
-		tx, err := c.Store.Transaction()
+		tx, err := c.Store.TransactionContext(ctx)
 		if err != nil {
 			return cn, errors.Wrap(err, "couldn't start a new transaction")
 		}
-		cn = &Connection{
-			ID:      randx.String(30),
-			Store:   tx,
-			Dialect: c.Dialect,
-			TX:      tx,
+		var ts Store = tx
+		if w, ok := unwrapLogger(c.Store).(TxWrapper); ok {
+			ts = w.WrapTx(tx)
+		}
+		ts = withQueryStats(withSlowQueryLog(ts, c.logger, c.Dialect.Details()), c.queryStats)
+		if ds, ok := unwrapLogger(c.Store).(*dryRunStore); ok {
+			ts = ds.withWrites(tx)
+		}
+		ts = withLogger(ts, c.logger, c.Dialect.Details())
+		// the other fields, e.g. the retry policy, are inherited
+		cn = c.copy()
+		cn.Store = ts
+		cn.TX = tx
+		cn.ctx = ctx
+		// the pool is closed by c
+		cn.borrowed = true
+		if err := cn.switchSchema(ctx); err != nil {
+			tx.Rollback()
+			return nil, err
 		}
 	} else {
 		cn = c
@@ -176,11 +340,119 @@ func (c *Connection) NewTransaction() (*Connection, error) {
 
 func (c *Connection) copy() *Connection {
 	return &Connection{
-		ID:      randx.String(30),
-		Store:   c.Store,
-		Dialect: c.Dialect,
-		TX:      c.TX,
+		ID:          randx.String(30),
+		Store:       c.Store,
+		Dialect:     c.Dialect,
+		TX:          c.TX,
+		schemaCache: c.schemaCache,
+		middlewares: c.middlewares,
+		queryStats:  c.queryStats,
+		name:        c.name,
+		ctx:         c.ctx,
+		retryPolicy: c.retryPolicy,
+		idempotent:  c.idempotent,
+		blind:       c.blind,
+		clock:       c.clock,
+		dbClock:     c.dbClock,
+		auditing:    c.auditing,
+		cipher:      c.cipher,
+		maxPerPage:  c.maxPerPage,
+		debugEager:  c.debugEager,
+		logger:      c.logger,
+		observers:   c.observers,
+		rewriters:   c.rewriters,
+		op:          c.op,
+		shard:       c.shard,
+		schemaName:  c.schemaName,
+		derived:     c.derived,
+		borrowed:    c.borrowed,
+	}
+}
+
+// WithContext returns a copy of the connection whose operations not taking
+// a context, e.g. Create, Update or Destroy, run in ctx: their callbacks
+// get ctx, and they're traced with it.
+//
+//	err := c.WithContext(r.Context()).Create(&user)
+func (c *Connection) WithContext(ctx context.Context) *Connection {
+	cn := c.copy()
+	cn.ctx = ctx
+	return cn
+}
+
+// txContext returns the context of the connection, for the operations
+// which don't take one, see WithContext.
+func (c *Connection) txContext() context.Context {
+	if c.ctx != nil {
+		return c.ctx
+	}
+	return context.TODO()
+}
+
+// inContext returns the connection running an operation in ctx, so the
+// operations run by its callbacks with it run in ctx too.
+func (c *Connection) inContext(ctx context.Context) *Connection {
+	if c.ctx == ctx {
+		return c
+	}
+	cn := c.copy()
+	cn.ID = c.ID
+	cn.eager = c.eager
+	cn.eagerFields = c.eagerFields
+	cn.ctx = ctx
+	return cn
+}
+
+// schema returns the default schema of the table names, see WithSchema
+// and ConnectionDetails.Schema.
+func (c *Connection) schema() string {
+	if c == nil {
+		return ""
+	}
+	if c.schemaName != "" {
+		return c.schemaName
+	}
+	if c.Dialect == nil || c.Dialect.Details() == nil {
+		return ""
+	}
+	return c.Dialect.Details().Schema
+}
+
+// details returns the details of the connection, or nil.
+func (c *Connection) details() *ConnectionDetails {
+	if c == nil || c.Dialect == nil {
+		return nil
+	}
+	return c.Dialect.Details()
+}
+
+// model returns the model of value, its table name being qualified by
+// the default schema of the connection, and resolved in the context of its
+// transaction, see ContextTableNameAble.
+func (c *Connection) model(value interface{}) *Model {
+	var ctx context.Context
+	if c != nil {
+		ctx = c.txContext()
 	}
+	return c.modelContext(ctx, value)
+}
+
+// checkTableContext returns an error if the table of model is chosen from
+// the context, see ContextTableNameAble, and c has none to resolve it with
+// in the operations not taking one: it's in no transaction, and wasn't
+// given one by WithContext.
+func (c *Connection) checkTableContext(model interface{}) error {
+	if _, ok := contextTableNameAble(model); ok && (c == nil || c.ctx == nil) {
+		return errors.Errorf("the table name of %T depends on the context: run the operation in a transaction, or with WithContext", model)
+	}
+	return nil
+}
+
+// modelContext returns the model of value, like model, its table name
+// being resolved in ctx. The redacted fields of the model are registered.
+func (c *Connection) modelContext(ctx context.Context, value interface{}) *Model {
+	registerRedactedFields(value)
+	return &Model{Value: value, schema: c.schema(), ctx: ctx}
 }
 
 // Q creates a new "empty" query for the current connection.
@@ -194,17 +466,77 @@ func (c *Connection) disableEager() {
 	c.eagerFields = []string{}
 }
 
-// TruncateAll truncates all data from the datasource
-func (c *Connection) TruncateAll() error {
-	return c.Dialect.TruncateAll(c)
+// TruncateAll truncates all data from the datasource, except for the
+// migrations table. Identity sequences are reset where the dialect supports it.
+//
+// TruncateAll refuses to run against a database which doesn't look like a
+// test database, whose name ends with "_test", unless ForceTruncate is set
+// in its ConnectionDetails.
+func (c *Connection) TruncateAll(ctx context.Context) error {
+	if err := c.Dialect.Details().checkTruncatable(); err != nil {
+		return err
+	}
+	return c.Dialect.TruncateAll(ctx, c)
+}
+
+// TruncateOptions are the options of TruncateWithOptions.
+type TruncateOptions struct {
+	// ResetSequence resets the identity sequences of the tables, e.g. the
+	// AUTO_INCREMENT of MySQL, or the sqlite_sequence of SQLite, so the
+	// next ids start over. Otherwise the ids keep increasing.
+	ResetSequence bool
 }
 
-func (c *Connection) timeFunc(name string, fn func() error) error {
-	start := time.Now()
-	err := fn()
-	atomic.AddInt64(&c.Elapsed, int64(time.Since(start)))
+// Truncate removes all data from the tables of the given models, and resets
+// their identity sequences where the dialect supports it.
+//
+//	c.Truncate(ctx, &User{}, &[]Book{})
+//
+// Like TruncateAll, Truncate only runs against test databases unless forced.
+func (c *Connection) Truncate(ctx context.Context, models ...interface{}) error {
+	return c.TruncateWithOptions(ctx, TruncateOptions{ResetSequence: true}, models...)
+}
+
+// TruncateWithOptions removes all data from the tables of the given models,
+// like Truncate, resetting their identity sequences only if
+// opts.ResetSequence is set.
+//
+//	c.TruncateWithOptions(ctx, pop.TruncateOptions{}, &User{})
+func (c *Connection) TruncateWithOptions(ctx context.Context, opts TruncateOptions, models ...interface{}) error {
+	if err := c.Dialect.Details().checkTruncatable(); err != nil {
+		return err
+	}
+	if len(models) == 0 {
+		return nil
+	}
+	tables := make([]string, 0, len(models))
+	for _, model := range models {
+		m := c.model(model)
+		tables = append(tables, m.TableName())
+	}
+	return c.Dialect.Truncate(ctx, c, opts, tables...)
+}
+
+// timeFunc runs the operation fn, named after name, with the connection
+// running it, see operation, through the retries and middlewares of c, in
+// the context given by the middlewares. Its kind tells if it's retried,
+// see RetryPolicy. Its record is then given to the observers.
+func (c *Connection) timeFunc(ctx context.Context, name string, kind opKind, table string, fn func(ctx context.Context, c *Connection) error) error {
+	info := c.newQueryInfo(name, table)
+	oc := c.operation(ctx, info)
+	err := c.withRetries(ctx, name, kind, func() error {
+		if os, ok := oc.Store.(*operationStore); ok {
+			os.reset()
+		}
+		return c.runMiddlewares(ctx, name, func(ctx context.Context) error {
+			return fn(ctx, oc.inContext(ctx))
+		})
+	})
+	info.Duration = time.Since(info.StartedAt)
 	if err != nil {
-		return errors.WithStack(err)
+		err = errors.WithStack(withConstraintError(c.Dialect, err))
 	}
-	return nil
+	info.Err = err
+	c.observe(ctx, info)
+	return err
 }
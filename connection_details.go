@@ -1,8 +1,10 @@
 package pop
 
 import (
+	"database/sql"
 	"fmt"
 	"net/url"
+	"path"
 	"regexp"
 	"strconv"
 	"strings"
@@ -18,6 +20,11 @@ import (
 type ConnectionDetails struct {
 	// Example: "postgres" or "sqlite3" or "mysql"
 	Dialect string
+	// The name of the database/sql driver, registered by the application,
+	// to use instead of the default driver of the dialect. Example: "pgx"
+	// for github.com/jackc/pgx/v4/stdlib with the "postgres" dialect. The
+	// dialect defaults to the one of the driver, if known.
+	Driver string
 	// The name of your database. Example: "foo_development"
 	Database string
 	// The host of your database. Example: "127.0.0.1"
@@ -39,13 +46,98 @@ type ConnectionDetails struct {
 	Pool int
 	// Defaults to 0 "unlimited". See https://golang.org/pkg/database/sql/#DB.SetMaxIdleConns
 	IdlePool int
-	Options  map[string]string
+	// Defaults to 0 "unlimited". See https://golang.org/pkg/database/sql/#DB.SetConnMaxLifetime
+	ConnMaxLifetime time.Duration
+	// Defaults to 0 "unlimited". See https://golang.org/pkg/database/sql/#DB.SetConnMaxIdleTime
+	ConnMaxIdleTime time.Duration
+	// Maximum time to establish the network connection to the database,
+	// passed to the driver. For SQLite, it's the time to wait for a locked
+	// database, defaulting to 5s. Defaults to 0, the driver default.
+	DialTimeout time.Duration
+	// Maximum time for Open to connect to the database and check it's
+	// ready. Open doesn't connect when both timeouts are 0, the default.
+	ConnectionTimeout time.Duration
+	// Ping the database at this interval, and reconnect when the ping
+	// fails, e.g. after a database restart. Defaults to 0, disabled.
+	HealthCheckInterval time.Duration
+	// Write the time.Time fields of the models in UTC, and set the time
+	// zone of the sessions, and of the times read by the driver, to UTC.
+	// Models can opt out with LocalTimeAble. Defaults to false.
+	UTC bool
+	// Schema qualifies the table names of the models which aren't
+	// qualified, e.g. "billing" for "billing.invoices": the schema for
+	// PostgreSQL and CockroachDB, the database for MySQL. Defaults to "",
+	// the tables being resolved by the database, e.g. with its search_path.
+	Schema  string
+	Options map[string]string
 	// Query string encoded options from URL. Example: "sslmode=disable"
 	RawOptions string
+	// Fail the selects when a returned column has no destination field,
+	// or when a db tagged field has no returned column. Defaults to false.
+	StrictMapping bool
+	// Log a warning for the queries running longer than this duration.
+	// Defaults to 0, disabled.
+	SlowQueryThreshold time.Duration
+	// Show the query args in the slow queries warnings. Defaults to false,
+	// as the args may contain sensitive data.
+	LogSQL bool
+	// Columns whose args are replaced by a placeholder in the logs and the
+	// spans, along with the fields tagged `log:"redact"`, see Redact.
+	RedactColumns []string
+	// Positions of the args, from 0, replaced by a placeholder in the logs
+	// and the spans for all the statements, see Redact.
+	RedactArgs []int
+	// Collect the statistics of the statements, see Connection.QueryStats.
+	// Defaults to false.
+	CollectQueryStats bool
+	// Maximum number of statements in the statistics, the ones with the
+	// lowest total time being dropped. Defaults to 100.
+	QueryStatsLimit int
+	// CredentialsProvider returns the user and password to open each new
+	// connection of the pool with, instead of User and Password. It's meant
+	// for the short-lived credentials, e.g. the IAM authentication tokens of
+	// Amazon RDS, regenerated every 15 minutes, or the database secrets of
+	// Vault. CreateDB and DropDB still use User and Password.
+	CredentialsProvider CredentialsProvider
+	// TLS configures the encrypted connections to the database, instead of
+	// the driver specific options. The certificate files are checked when
+	// the connection is opened.
+	TLS *TLSConfig
+	// SQLitePragmas are set with PRAGMA statements on each new SQLite
+	// connection of the pool, for the pragmas which aren't persisted in the
+	// database file. Example: {"foreign_keys": "ON"}, or SQLiteWAL().
+	SQLitePragmas map[string]string
+	// Let TruncateAll truncate a database whose name doesn't end with
+	// "_test". Defaults to false.
+	ForceTruncate bool
 }
 
 var dialectX = regexp.MustCompile(`\S+://`)
 
+// driverDialects are the dialects of the known database/sql drivers,
+// which aren't the default drivers of their dialect.
+var driverDialects = map[string]string{
+	"pgx": namePostgreSQL,
+}
+
+// driverName returns the name of the database/sql driver to open the
+// database with.
+func (cd *ConnectionDetails) driverName() string {
+	if cd.Driver != "" {
+		return cd.Driver
+	}
+	return cd.Dialect
+}
+
+// driverDialect returns the dialect of the details, or the one of the
+// driver if it's not set.
+func (cd *ConnectionDetails) driverDialect() string {
+	if cd.Dialect == "" {
+		return driverDialects[cd.Driver]
+	}
+	return normalizeSynonyms(cd.Dialect)
+}
+
 // withURL parses and overrides all connection details with values
 // from standard URL except Dialect. It also calls dialect specific
 // URL parser if exists.
@@ -102,7 +194,7 @@ func (cd *ConnectionDetails) withURL() error {
 // Finalize cleans up the connection details by normalizing names,
 // filling in default values, etc...
 func (cd *ConnectionDetails) Finalize() error {
-	cd.Dialect = normalizeSynonyms(cd.Dialect)
+	cd.Dialect = cd.driverDialect()
 
 	if cd.Options == nil { // for safety
 		cd.Options = make(map[string]string)
@@ -159,6 +251,160 @@ func (cd *ConnectionDetails) MigrationTableName() string {
 	return defaults.String(cd.Options["migration_table_name"], "schema_migration")
 }
 
+// ValidationError describes a problem with a single ConnectionDetails field,
+// or PageRequest field.
+type ValidationError struct {
+	Field   string
+	Message string
+}
+
+func (e ValidationError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// ValidationErrors is the list of problems found by ConnectionDetails.Validate.
+type ValidationErrors []ValidationError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, ve := range e {
+		msgs = append(msgs, ve.Error())
+	}
+	return "invalid connection details: " + strings.Join(msgs, "; ")
+}
+
+// Validate checks the connection details for configuration errors,
+// taking into account the values Finalize would fill in. It doesn't
+// modify the connection details. If any problem is found, the returned
+// error is a ValidationErrors listing every invalid field.
+func (cd *ConnectionDetails) Validate() error {
+	var verrs ValidationErrors
+	add := func(field, msg string, args ...interface{}) {
+		verrs = append(verrs, ValidationError{Field: field, Message: fmt.Sprintf(msg, args...)})
+	}
+
+	// work on a copy, so the URL parsing and finalizers don't leak into cd.
+	c := *cd
+	c.Options = make(map[string]string, len(cd.Options))
+	for k, v := range cd.Options {
+		c.Options[k] = v
+	}
+	c.Dialect = c.driverDialect()
+
+	switch {
+	case c.Dialect == "" && c.URL == "":
+		add("Dialect", "dialect is required")
+	case c.Dialect != "" && !DialectSupported(c.Dialect):
+		add("Dialect", "unsupported dialect '%s'", c.Dialect)
+	case c.URL != "":
+		if err := c.withURL(); err != nil {
+			add("URL", "%v", err)
+		}
+	}
+	if len(verrs) > 0 {
+		return verrs
+	}
+
+	if fin, ok := finalizer[c.Dialect]; ok {
+		fin(&c)
+	}
+
+	if c.Database == "" {
+		add("Database", "database name is required")
+	}
+	if c.Dialect != nameSQLite3 {
+		if c.Host == "" {
+			add("Host", "host is required")
+		} else if c.Port != "socket" && strings.ContainsAny(c.Host, " /") {
+			add("Host", "invalid host '%s'", c.Host)
+		}
+		if c.Port != "" && c.Port != "socket" {
+			if p, err := strconv.Atoi(c.Port); err != nil || p < 1 || p > 65535 {
+				add("Port", "port must be a number between 1 and 65535, got '%s'", c.Port)
+			}
+		}
+	}
+	if c.Driver != "" && !driverRegistered(c.Driver) {
+		add("Driver", "unknown driver '%s', the driver package must be imported", c.Driver)
+	}
+	if c.Pool < 0 {
+		add("Pool", "pool size must be positive, got %d", c.Pool)
+	}
+	if c.IdlePool < 0 {
+		add("IdlePool", "idle pool size must be positive, got %d", c.IdlePool)
+	}
+	if c.ConnMaxLifetime < 0 {
+		add("ConnMaxLifetime", "connection max lifetime must be positive, got %s", c.ConnMaxLifetime)
+	}
+	if c.ConnMaxIdleTime < 0 {
+		add("ConnMaxIdleTime", "connection max idle time must be positive, got %s", c.ConnMaxIdleTime)
+	}
+	if c.DialTimeout < 0 {
+		add("DialTimeout", "dial timeout must be positive, got %s", c.DialTimeout)
+	}
+	if c.ConnectionTimeout < 0 {
+		add("ConnectionTimeout", "connection timeout must be positive, got %s", c.ConnectionTimeout)
+	}
+	if c.HealthCheckInterval < 0 {
+		add("HealthCheckInterval", "health check interval must be positive, got %s", c.HealthCheckInterval)
+	}
+	if c.TLS != nil {
+		if c.Dialect == nameSQLite3 {
+			add("TLS", "%s doesn't support TLS", c.Dialect)
+		}
+		if (c.TLS.CertFile == "") != (c.TLS.KeyFile == "") {
+			add("TLS", "the client certificate needs both CertFile and KeyFile")
+		}
+	}
+	if len(c.SQLitePragmas) > 0 {
+		if c.Dialect != nameSQLite3 {
+			add("SQLitePragmas", "%s doesn't support the SQLite pragmas", c.Dialect)
+		} else if _, err := pragmaStatements(c.SQLitePragmas); err != nil {
+			add("SQLitePragmas", "%v", err)
+		}
+	}
+
+	if len(verrs) > 0 {
+		return verrs
+	}
+	return nil
+}
+
+func driverRegistered(name string) bool {
+	for _, d := range sql.Drivers() {
+		if d == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrNotTestDatabase is returned when trying to truncate a database which
+// doesn't look like a test database.
+var ErrNotTestDatabase = errors.New("refusing to truncate a database which doesn't look like a test database, set ForceTruncate to override")
+
+// checkTruncatable ensures the connection targets a test database, that is a
+// database whose name, or the file name of a SQLite database, is "test" or
+// ends with "_test". Setting ForceTruncate disables this check.
+func (cd *ConnectionDetails) checkTruncatable() error {
+	if cd.ForceTruncate {
+		return nil
+	}
+	name := cd.Database
+	if name == "" {
+		name = cd.URL
+	}
+	if i := strings.Index(name, "?"); i >= 0 {
+		name = name[:i]
+	}
+	name = strings.ToLower(path.Base(name))
+	name = strings.TrimSuffix(name, path.Ext(name))
+	if name == "test" || strings.HasSuffix(name, "_test") {
+		return nil
+	}
+	return ErrNotTestDatabase
+}
+
 // OptionsString returns URL parameter encoded string from options.
 func (cd *ConnectionDetails) OptionsString(s string) string {
 	if cd.RawOptions != "" {
@@ -0,0 +1,114 @@
+package pop
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// ConnectionPool holds the named connections of an application, e.g. its
+// primary database and an analytics read replica.
+//
+//	pool, err := pop.NewConnectionPool("primary", map[string]*pop.Connection{
+//		"primary":   primary,
+//		"analytics": replica,
+//	})
+//	if err != nil {
+//		return err
+//	}
+//	if err := pool.WarmAll(ctx); err != nil {
+//		return err
+//	}
+//	analytics, err := pool.Get("analytics")
+type ConnectionPool struct {
+	conns   map[string]*Connection
+	primary string
+}
+
+// NewConnectionPool returns a pool of the connections, whose default one
+// is named primary. The connections are named after their key, e.g. for
+// the connection name of the middlewares, unless they have a name.
+func NewConnectionPool(primary string, conns map[string]*Connection) (*ConnectionPool, error) {
+	p := &ConnectionPool{conns: map[string]*Connection{}, primary: primary}
+	for name, c := range conns {
+		if c == nil {
+			return nil, errors.Errorf("connection %s is nil", name)
+		}
+		if c.name == "" {
+			c.name = name
+		}
+		p.conns[name] = c
+	}
+	if _, ok := p.conns[primary]; !ok {
+		return nil, errors.Errorf("could not find the primary connection %s", primary)
+	}
+	return p, nil
+}
+
+// Get returns the named connection.
+func (p *ConnectionPool) Get(name string) (*Connection, error) {
+	c, ok := p.conns[name]
+	if !ok {
+		return nil, errors.Errorf("could not find connection named %s", name)
+	}
+	return c, nil
+}
+
+// Primary returns the default connection of the pool.
+func (p *ConnectionPool) Primary() *Connection {
+	return p.conns[p.primary]
+}
+
+// Names returns the names of the connections, ordered.
+func (p *ConnectionPool) Names() []string {
+	names := make([]string, 0, len(p.conns))
+	for n := range p.conns {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// WarmAll opens the connections and pings their databases concurrently,
+// e.g. to fail fast at startup. The error lists the connections which
+// couldn't connect.
+func (p *ConnectionPool) WarmAll(ctx context.Context) error {
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	errs := map[string]error{}
+	for name, c := range p.conns {
+		wg.Add(1)
+		go func(name string, c *Connection) {
+			defer wg.Done()
+			if err := warm(ctx, c); err != nil {
+				mu.Lock()
+				errs[name] = err
+				mu.Unlock()
+			}
+		}(name, c)
+	}
+	wg.Wait()
+
+	if len(errs) == 0 {
+		return nil
+	}
+	msgs := make([]string, 0, len(errs))
+	for _, name := range p.Names() {
+		if err, ok := errs[name]; ok {
+			msgs = append(msgs, fmt.Sprintf("%s: %v", name, err))
+		}
+	}
+	return errors.Errorf("could not connect to the databases of the pool: %s", strings.Join(msgs, "; "))
+}
+
+// warm opens c, and pings its database.
+func warm(ctx context.Context, c *Connection) error {
+	if err := c.Open(); err != nil {
+		return err
+	}
+	return pingStore(ctx, c.Store)
+}
@@ -0,0 +1,47 @@
+package pop
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ConnectionPool(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	primary, err := NewConnection(&ConnectionDetails{URL: "sqlite:///tmp/pool_primary.db"})
+	r.NoError(err)
+	analytics, err := NewConnection(&ConnectionDetails{URL: "sqlite:///tmp/pool_analytics.db"})
+	r.NoError(err)
+
+	_, err = NewConnectionPool("main", map[string]*Connection{"primary": primary})
+	r.Error(err)
+
+	pool, err := NewConnectionPool("primary", map[string]*Connection{"primary": primary, "analytics": analytics})
+	r.NoError(err)
+	r.True(pool.Primary() == primary)
+	c, err := pool.Get("analytics")
+	r.NoError(err)
+	r.True(c == analytics)
+	r.Equal("analytics", c.name)
+	_, err = pool.Get("audit")
+	r.Error(err)
+	r.Equal([]string{"analytics", "primary"}, pool.Names())
+
+	r.NoError(pool.WarmAll(ctx))
+	defer primary.Close()
+	defer analytics.Close()
+	r.NotNil(analytics.Store)
+	r.NoError(pool.WarmAll(ctx))
+
+	broken, err := NewConnection(&ConnectionDetails{URL: "sqlite:///nonexistent/dir/pool.db"})
+	r.NoError(err)
+	pool, err = NewConnectionPool("primary", map[string]*Connection{"primary": primary, "broken": broken})
+	r.NoError(err)
+	err = pool.WarmAll(ctx)
+	r.Error(err)
+	r.Contains(err.Error(), "broken: ")
+	r.NotContains(err.Error(), "primary: ")
+}
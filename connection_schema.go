@@ -0,0 +1,137 @@
+package pop
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/gobuffalo/pop/logging"
+	"github.com/pkg/errors"
+)
+
+// schemaSwitchable is implemented by the dialects whose transactions can be
+// switched to a schema, e.g. by setting their search_path.
+type schemaSwitchable interface {
+	// switchSchemaStatement returns the statement switching the current
+	// transaction to the schema name.
+	switchSchemaStatement(name string) string
+}
+
+var schemaNameX = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+
+// WithSchema returns a copy of the connection bound to the given schema.
+// The original connection is left untouched, so it's safe to call
+// WithSchema concurrently, e.g. once per request in a multi-tenant
+// application:
+//
+//	tc := c.WithSchema("tenant_42")
+//	tc.All(ctx, &users) // SELECT ... FROM tenant_42.users
+//
+// The copy shares the pool of c, whatever the number of schemas: the
+// unqualified table names of the models are qualified by the schema,
+// instead of the Schema of the ConnectionDetails, and on PostgreSQL and
+// CockroachDB its transactions start with SET LOCAL search_path, so their
+// raw queries use the tables of the schema too. The raw queries run
+// outside of a transaction aren't switched: their table names must be
+// qualified. The Close of the copy is a no-op.
+//
+// The name is made of letters, digits, "_" and "$", and doesn't start with
+// a digit: WithSchema panics otherwise.
+func (c *Connection) WithSchema(name string) *Connection {
+	if !schemaNameX.MatchString(name) {
+		panic(fmt.Sprintf("pop: invalid schema name '%s'", name))
+	}
+	cn := c.copy()
+	cn.schemaName = name
+	// the pool is closed by c
+	cn.borrowed = true
+	return cn
+}
+
+// switchSchema switches the transaction just started by c to the schema
+// of WithSchema, if any, on the dialects supporting it.
+func (c *Connection) switchSchema(ctx context.Context) error {
+	ss, ok := c.Dialect.(schemaSwitchable)
+	if c.schemaName == "" || !ok {
+		return nil
+	}
+	stmt := ss.switchSchemaStatement(c.schemaName)
+	c.log(logging.SQL, stmt)
+	_, err := c.Store.ExecContext(ctx, stmt)
+	return errors.Wrapf(err, "unable to switch the transaction to schema %s", c.schemaName)
+}
+
+type schemaContextKey struct{}
+
+// SchemaMiddleware returns an HTTP middleware switching the connection to
+// the schema returned by fn for each request. The schema bound connection
+// can then be retrieved with ConnectionFromContext. If fn returns an empty
+// name, the request uses c as is. The requests whose schema name is
+// invalid, see WithSchema, fail with a 500, the name being logged.
+//
+//	mw := pop.SchemaMiddleware(c, func(r *http.Request) string {
+//		return "tenant_" + r.Header.Get("X-Tenant-ID")
+//	})
+//	http.Handle("/", mw(handler))
+func SchemaMiddleware(c *Connection, fn func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			cn := c
+			if name := fn(r); name != "" {
+				if !schemaNameX.MatchString(name) {
+					c.log(logging.Error, "invalid schema name '%s' for %s %s", name, r.Method, r.URL.Path)
+					http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+					return
+				}
+				cn = c.WithSchema(name)
+			}
+			ctx := context.WithValue(r.Context(), schemaContextKey{}, cn)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ConnectionFromContext returns the connection stored in the context by
+// SchemaMiddleware.
+func ConnectionFromContext(ctx context.Context) (*Connection, bool) {
+	c, ok := ctx.Value(schemaContextKey{}).(*Connection)
+	return c, ok
+}
+
+// withRuntimeParameter returns a copy of the connection details, with the
+// PostgreSQL run-time parameter key set to value, in its options and its
+// URL. The value must not need to be quoted.
+func withRuntimeParameter(cd *ConnectionDetails, key, value string) (*ConnectionDetails, error) {
+	deets := *cd
+	deets.Options = make(map[string]string, len(cd.Options)+1)
+	for k, v := range cd.Options {
+		deets.Options[k] = v
+	}
+	deets.Options[key] = value
+
+	switch {
+	case deets.URL != "" && dialectX.MatchString(deets.URL):
+		u, err := url.Parse(deets.URL)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't parse %s", deets.URL)
+		}
+		q := u.Query()
+		q.Set(key, value)
+		u.RawQuery = q.Encode()
+		deets.URL = u.String()
+	case deets.URL != "":
+		// key=value connection string
+		deets.URL = strings.TrimSpace(deets.URL) + " " + key + "=" + value
+	case deets.RawOptions != "":
+		q, err := url.ParseQuery(deets.RawOptions)
+		if err != nil {
+			return nil, errors.Wrapf(err, "couldn't parse %s", deets.RawOptions)
+		}
+		q.Set(key, value)
+		deets.RawOptions = q.Encode()
+	}
+	return &deets, nil
+}
@@ -0,0 +1,84 @@
+package pop
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Connection_WithSchema(t *testing.T) {
+	r := require.New(t)
+
+	tc := PDB.WithSchema("pop_tenant")
+	r.Equal("pop_tenant.users", tc.model(&User{}).TableName())
+	r.Equal("billing.invoices", tc.model("billing.invoices").TableName())
+	r.NotEqual("pop_tenant.users", PDB.model(&User{}).TableName())
+
+	// the copy shares the pool of PDB
+	r.NoError(tc.Close())
+	_, err := PDB.Count(&User{})
+	r.NoError(err)
+
+	r.Panics(func() { PDB.WithSchema("tenant; DROP TABLE users") })
+}
+
+func Test_Connection_WithSchema_Transaction(t *testing.T) {
+	if _, ok := PDB.Dialect.(schemaSwitchable); !ok {
+		t.Skipf("%s has no search_path", PDB.Dialect.Name())
+	}
+	r := require.New(t)
+
+	searchPath := func(c *Connection) string {
+		var path string
+		r.NoError(c.QueryRow(context.Background(), "SELECT current_setting('search_path')").Scan(&path))
+		return path
+	}
+	r.NoError(PDB.WithSchema("pop_tenant").Rollback(func(tx *Connection) {
+		r.Contains(searchPath(tx), "pop_tenant")
+	}))
+	r.NotContains(searchPath(PDB), "pop_tenant")
+}
+
+func Test_SchemaMiddleware(t *testing.T) {
+	r := require.New(t)
+
+	var got *Connection
+	mw := SchemaMiddleware(PDB, func(req *http.Request) string {
+		return req.Header.Get("X-Tenant")
+	})
+	h := mw(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		c, ok := ConnectionFromContext(req.Context())
+		r.True(ok)
+		got = c
+	}))
+
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Tenant", "tenant_1")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	r.Equal(http.StatusOK, w.Code)
+	r.Equal("tenant_1", got.schema())
+
+	// no schema, the connection is used as is
+	got = nil
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest("GET", "/", nil))
+	r.Equal(http.StatusOK, w.Code)
+	r.True(got == PDB)
+
+	// the invalid names don't reach the handler, nor the response
+	got = nil
+	req = httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("X-Tenant", "tenant'; --")
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, req)
+	r.Equal(http.StatusInternalServerError, w.Code)
+	r.NotContains(w.Body.String(), "tenant")
+	r.Nil(got)
+
+	_, ok := ConnectionFromContext(context.Background())
+	r.False(ok)
+}
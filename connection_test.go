@@ -1,8 +1,15 @@
 package pop
 
 import (
+	"context"
+	"database/sql"
 	"testing"
+	"time"
 
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/logging"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -50,3 +57,303 @@ func Test_Connection_Open_BadDialect(t *testing.T) {
 	err = c.Open()
 	r.Error(err)
 }
+
+func Test_Connection_PoolStats(t *testing.T) {
+	r := require.New(t)
+
+	c, err := NewConnection(&ConnectionDetails{
+		URL:             "sqlite:///tmp/pool_stats.db",
+		Pool:            3,
+		IdlePool:        2,
+		ConnMaxLifetime: time.Hour,
+		ConnMaxIdleTime: time.Minute,
+	})
+	r.NoError(err)
+	r.Equal(sql.DBStats{}, c.PoolStats())
+
+	r.NoError(c.Open())
+	defer c.Close()
+	r.NoError(c.RawQuery("SELECT 1").Exec())
+	stats := c.PoolStats()
+	r.Equal(3, stats.MaxOpenConnections)
+	r.Equal(1, stats.OpenConnections)
+	r.Equal(stats, c.DryRun().PoolStats())
+
+	r.NoError(c.Rollback(func(tx *Connection) {
+		r.Equal(sql.DBStats{}, tx.PoolStats())
+	}))
+}
+
+func Test_Connection_Transaction(t *testing.T) {
+	r := require.New(t)
+
+	type key struct{}
+	var got []interface{}
+	c := PDB.copy()
+	c.Use(func(ctx context.Context, op string, next func(ctx context.Context) error) error {
+		got = append(got, ctx.Value(key{}))
+		return next(ctx)
+	})
+
+	count, err := c.Count(&User{})
+	r.NoError(err)
+	got = nil
+
+	boom := errors.New("boom")
+	ctx := context.WithValue(context.Background(), key{}, "tx")
+	err = c.Transaction(ctx, func(ctx context.Context, tx *Connection) error {
+		r.Equal("tx", ctx.Value(key{}))
+		r.NoError(tx.Create(&User{Name: nulls.NewString("Mark")}))
+		r.NoError(tx.RawQuery("DELETE FROM users WHERE name = ?", "Mark").Exec())
+		return boom
+	})
+	r.Equal(boom, errors.Cause(err))
+	// the queries of the transaction ran with its context
+	r.Equal([]interface{}{"tx", "tx"}, got)
+
+	// rolled back
+	c2, err := c.Count(&User{})
+	r.NoError(err)
+	r.Equal(count, c2)
+}
+
+func Test_Connection_Transaction_Inherits(t *testing.T) {
+	r := require.New(t)
+
+	c := PDB.Idempotent().BlindWrites()
+	err := c.Transaction(context.Background(), func(ctx context.Context, tx *Connection) error {
+		r.True(tx.idempotent)
+		r.True(tx.blind)
+		r.True(tx.borrowed)
+		return nil
+	})
+	r.NoError(err)
+}
+
+func Test_Connection_Transaction_Canceled(t *testing.T) {
+	r := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	called := false
+	err := PDB.Transaction(ctx, func(ctx context.Context, tx *Connection) error {
+		called = true
+		return nil
+	})
+	r.Error(err)
+	r.False(called)
+}
+
+func Test_Connection_TransactionWithoutContext(t *testing.T) {
+	r := require.New(t)
+
+	boom := errors.New("boom")
+	err := PDB.TransactionWithoutContext(func(tx *Connection) error {
+		r.NotNil(tx.TX)
+		return boom
+	})
+	r.Equal(boom, errors.Cause(err))
+}
+
+func Test_Connection_OnCommit(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	var calls []string
+	err := PDB.Transaction(ctx, func(ctx context.Context, tx *Connection) error {
+		tx.OnCommit(func() { calls = append(calls, "first") })
+		tx.OnCommit(func() { calls = append(calls, "second") })
+		tx.OnRollback(func(err error) { calls = append(calls, "rollback") })
+		r.Empty(calls)
+		return nil
+	})
+	r.NoError(err)
+	r.Equal([]string{"first", "second"}, calls)
+
+	// called right away outside of a transaction
+	calls = nil
+	PDB.OnCommit(func() { calls = append(calls, "now") })
+	r.Equal([]string{"now"}, calls)
+}
+
+func Test_Connection_OnRollback(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	var calls []string
+	var causes []error
+	boom := errors.New("boom")
+	err := PDB.Transaction(ctx, func(ctx context.Context, tx *Connection) error {
+		tx.OnCommit(func() { calls = append(calls, "commit") })
+		tx.OnRollback(func(err error) {
+			calls = append(calls, "first")
+			causes = append(causes, err)
+		})
+		tx.OnRollback(func(err error) {
+			calls = append(calls, "second")
+			causes = append(causes, err)
+		})
+		return boom
+	})
+	r.Equal(boom, errors.Cause(err))
+	r.Equal([]string{"first", "second"}, calls)
+	r.Equal([]error{boom, boom}, causes)
+
+	calls = nil
+	r.NoError(PDB.Rollback(func(tx *Connection) {
+		tx.OnRollback(func(err error) {
+			r.NoError(err)
+			calls = append(calls, "rollback")
+		})
+	}))
+	r.Equal([]string{"rollback"}, calls)
+}
+
+func Test_Connection_WithLogger(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	var global, own []string
+	oldLog := log
+	defer func() { log = oldLog }()
+	SetLogger(func(lvl logging.Level, s string, args ...interface{}) {
+		if lvl == logging.SQL {
+			global = append(global, s)
+		}
+	})
+
+	c := PDB.WithLogger(func(lvl logging.Level, s string, args ...interface{}) {
+		if lvl == logging.SQL {
+			own = append(own, s)
+		}
+	})
+	r.NoError(c.Transaction(ctx, func(ctx context.Context, tx *Connection) error {
+		user := User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(&user))
+		r.NoError(tx.Find(ctx, &User{}, user.ID))
+		r.NoError(tx.RawQuery("DELETE FROM users WHERE id = ?", user.ID).Exec())
+		return nil
+	}))
+	r.Len(own, 3)
+	r.Contains(own[0], "INSERT INTO users")
+	r.Contains(own[1], "SELECT")
+	r.Contains(own[2], "DELETE FROM users")
+	r.Empty(global)
+
+	// the other connections still use the package logger
+	r.NoError(PDB.Where("id = ?", -1).All(ctx, &Users{}))
+	r.Len(global, 1)
+	r.Len(own, 3)
+
+	// the dry run and pool helpers see through the logger
+	r.Equal(PDB.PoolStats().MaxOpenConnections, c.PoolStats().MaxOpenConnections)
+	dr := c.DryRun()
+	r.NoError(dr.RawQuery("DELETE FROM users").Exec())
+	r.Len(dr.Statements(), 1)
+	r.Len(own, 4)
+}
+
+// recordingStore records the statements run by its store, and by the ones
+// of its transactions.
+type recordingStore struct {
+	Store
+	queries *[]string
+}
+
+func (s recordingStore) record(query string) {
+	*s.queries = append(*s.queries, query)
+}
+
+func (s recordingStore) WrapTx(tx *Tx) Store {
+	return recordingStore{Store: tx, queries: s.queries}
+}
+
+func (s recordingStore) Unwrap() Store {
+	return s.Store
+}
+
+func (s recordingStore) Select(dest interface{}, query string, args ...interface{}) error {
+	s.record(query)
+	return s.Store.Select(dest, query, args...)
+}
+
+func (s recordingStore) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	s.record(query)
+	return s.Store.SelectContext(ctx, dest, query, args...)
+}
+
+func (s recordingStore) Get(dest interface{}, query string, args ...interface{}) error {
+	s.record(query)
+	return s.Store.Get(dest, query, args...)
+}
+
+func (s recordingStore) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	s.record(query)
+	return s.Store.Queryx(query, args...)
+}
+
+func (s recordingStore) NamedExec(query string, arg interface{}) (sql.Result, error) {
+	s.record(query)
+	return s.Store.NamedExec(query, arg)
+}
+
+func (s recordingStore) Exec(query string, args ...interface{}) (sql.Result, error) {
+	s.record(query)
+	return s.Store.Exec(query, args...)
+}
+
+func (s recordingStore) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	s.record(query)
+	return s.Store.ExecContext(ctx, query, args...)
+}
+
+func (s recordingStore) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	s.record(query)
+	return s.Store.QueryRowContext(ctx, query, args...)
+}
+
+func (s recordingStore) PrepareNamed(query string) (*sqlx.NamedStmt, error) {
+	s.record(query)
+	return s.Store.PrepareNamed(query)
+}
+
+func Test_Connection_WithStore(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	var queries []string
+	c, err := PDB.WithStore(recordingStore{Store: PDB.Store, queries: &queries})
+	r.NoError(err)
+
+	user := User{Name: nulls.NewString("Mark")}
+	r.NoError(c.Create(&user))
+	defer PDB.RawQuery("DELETE FROM users WHERE id = ?", user.ID).Exec()
+	r.NoError(c.Find(ctx, &User{}, user.ID))
+	r.NoError(c.Where("id = ?", user.ID).All(ctx, &Users{}))
+	n, err := c.Where("id = ?", user.ID).Count(&User{})
+	r.NoError(err)
+	r.Equal(1, n)
+	r.NoError(c.RawQuery("UPDATE users SET alive = ? WHERE id = ?", true, user.ID).Exec())
+	r.Len(queries, 5)
+	r.Contains(queries[0], "INSERT INTO users")
+	r.Contains(queries[4], "UPDATE users")
+
+	// the statements of the transactions go through the store too
+	queries = nil
+	r.NoError(c.Transaction(ctx, func(ctx context.Context, tx *Connection) error {
+		return tx.Find(ctx, &User{}, user.ID)
+	}))
+	r.Len(queries, 1)
+
+	// the other connections are left untouched
+	queries = nil
+	r.NoError(PDB.Find(ctx, &User{}, user.ID))
+	r.Empty(queries)
+
+	// the pool is reached through the store
+	r.Equal(PDB.PoolStats().MaxOpenConnections, c.PoolStats().MaxOpenConnections)
+
+	// the stores bypassed by the transactions or the pool are refused
+	_, err = PDB.WithStore(struct{ Store }{PDB.Store})
+	r.Error(err)
+}
@@ -0,0 +1,200 @@
+package pop
+
+import (
+	"regexp"
+	"strings"
+
+	_mysql "github.com/go-sql-driver/mysql"
+	pg "github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+// The kinds of the constraints of a ConstraintError.
+const (
+	ConstraintUnique     = "unique"
+	ConstraintForeignKey = "foreign_key"
+	ConstraintNotNull    = "not_null"
+	ConstraintCheck      = "check"
+)
+
+// ConstraintError is a violation of a constraint of the database, e.g. a
+// duplicate value of a unique index, returned by the queries of a
+// connection. Its message is the message of the database, and errors.Cause
+// still returns the error of the driver: the ConstraintError is found with
+// AsConstraintError.
+//
+//	err := c.Create(&user)
+//	if ce, ok := pop.AsConstraintError(err); ok && ce.Kind == pop.ConstraintUnique {
+//		// the email is already taken
+//	}
+type ConstraintError struct {
+	// Kind is the kind of the constraint, e.g. ConstraintUnique.
+	Kind string
+	// Constraint is the name of the constraint, if the database reports it.
+	Constraint string
+	// Table is the table of the constraint, if the database reports it.
+	Table string
+	// Columns are the columns of the constraint, if the database reports
+	// them: SQLite and PostgreSQL do, MySQL doesn't for the unique indexes.
+	Columns []string
+	// Err is the error of the query.
+	Err error
+}
+
+func (e *ConstraintError) Error() string {
+	return e.Err.Error()
+}
+
+// Cause returns the error of the query.
+func (e *ConstraintError) Cause() error {
+	return e.Err
+}
+
+// Unwrap returns the error of the query.
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}
+
+// Code returns the machine readable code of the violation, e.g.
+// "unique_violation".
+func (e *ConstraintError) Code() string {
+	return e.Kind + "_violation"
+}
+
+// AsConstraintError returns the ConstraintError in the causes of err, if
+// any.
+func AsConstraintError(err error) (*ConstraintError, bool) {
+	for err != nil {
+		if ce, ok := err.(*ConstraintError); ok {
+			return ce, true
+		}
+		c, ok := err.(interface{ Cause() error })
+		if !ok {
+			return nil, false
+		}
+		err = c.Cause()
+	}
+	return nil, false
+}
+
+// constraintTranslator is implemented by the dialects recognizing the
+// constraint violations of their driver.
+type constraintTranslator interface {
+	// constraintError returns the ConstraintError of the driver error
+	// err, if it's a constraint violation.
+	constraintError(err error) (*ConstraintError, bool)
+}
+
+// withConstraintError returns err as a ConstraintError, if it's caused by
+// a constraint violation.
+func withConstraintError(d dialect, err error) error {
+	if _, ok := AsConstraintError(err); ok {
+		return err
+	}
+	ct, ok := d.(constraintTranslator)
+	if !ok {
+		return err
+	}
+	ce, ok := ct.constraintError(errors.Cause(err))
+	if !ok {
+		return err
+	}
+	ce.Err = err
+	return ce
+}
+
+var rPostgresKey = regexp.MustCompile(`^Key \(([^)]*)\)=`)
+
+// postgresConstraintError returns the ConstraintError of a PostgreSQL or
+// CockroachDB error.
+func postgresConstraintError(err error) (*ConstraintError, bool) {
+	code, ok := postgresErrorCode(err)
+	if !ok {
+		return nil, false
+	}
+	ce := &ConstraintError{}
+	switch code {
+	case "23505": // unique_violation
+		ce.Kind = ConstraintUnique
+	case "23503": // foreign_key_violation
+		ce.Kind = ConstraintForeignKey
+	case "23502": // not_null_violation
+		ce.Kind = ConstraintNotNull
+	case "23514": // check_violation
+		ce.Kind = ConstraintCheck
+	default:
+		return nil, false
+	}
+	if pe, ok := err.(*pg.Error); ok {
+		ce.Constraint, ce.Table = pe.Constraint, pe.Table
+		if pe.Column != "" {
+			ce.Columns = []string{pe.Column}
+		} else if m := rPostgresKey.FindStringSubmatch(pe.Detail); m != nil {
+			ce.Columns = splitColumns(m[1])
+		}
+	}
+	return ce, true
+}
+
+func (p *postgresql) constraintError(err error) (*ConstraintError, bool) {
+	return postgresConstraintError(err)
+}
+
+func (p *cockroach) constraintError(err error) (*ConstraintError, bool) {
+	return postgresConstraintError(err)
+}
+
+var (
+	rMySQLKey        = regexp.MustCompile(`for key '([^']*)'`)
+	rMySQLColumn     = regexp.MustCompile(`^Column '([^']*)'`)
+	rMySQLForeignKey = regexp.MustCompile("`([^`]*)`, CONSTRAINT `([^`]*)` FOREIGN KEY \\(([^)]*)\\)")
+	rMySQLCheck      = regexp.MustCompile(`^Check constraint '([^']*)'`)
+)
+
+func (m *mysql) constraintError(err error) (*ConstraintError, bool) {
+	me, ok := err.(*_mysql.MySQLError)
+	if !ok {
+		return nil, false
+	}
+	ce := &ConstraintError{}
+	switch me.Number {
+	case 1062: // ER_DUP_ENTRY
+		ce.Kind = ConstraintUnique
+		if k := rMySQLKey.FindStringSubmatch(me.Message); k != nil {
+			// MySQL 8 qualifies the key with its table
+			ce.Constraint = k[1][strings.LastIndex(k[1], ".")+1:]
+			if ce.Constraint == "PRIMARY" {
+				ce.Columns = []string{"id"}
+			}
+		}
+	case 1451, 1452: // ER_ROW_IS_REFERENCED_2, ER_NO_REFERENCED_ROW_2
+		ce.Kind = ConstraintForeignKey
+		if fk := rMySQLForeignKey.FindStringSubmatch(me.Message); fk != nil {
+			ce.Table, ce.Constraint, ce.Columns = fk[1], fk[2], splitColumns(fk[3])
+		}
+	case 1048: // ER_BAD_NULL_ERROR
+		ce.Kind = ConstraintNotNull
+		if c := rMySQLColumn.FindStringSubmatch(me.Message); c != nil {
+			ce.Columns = []string{c[1]}
+		}
+	case 3819: // ER_CHECK_CONSTRAINT_VIOLATED
+		ce.Kind = ConstraintCheck
+		if c := rMySQLCheck.FindStringSubmatch(me.Message); c != nil {
+			ce.Constraint = c[1]
+		}
+	default:
+		return nil, false
+	}
+	return ce, true
+}
+
+// splitColumns splits a list of columns reported by a database, e.g.
+// "`user_id`, `name`", and removes their quotes and table.
+func splitColumns(s string) []string {
+	var cols []string
+	for _, c := range strings.Split(s, ",") {
+		c = strings.Trim(strings.TrimSpace(c), "`\"")
+		cols = append(cols, c[strings.LastIndex(c, ".")+1:])
+	}
+	return cols
+}
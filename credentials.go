@@ -0,0 +1,83 @@
+package pop
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"net/url"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// CredentialsProvider returns the user and password to open a new
+// connection to the database with. See ConnectionDetails.CredentialsProvider.
+type CredentialsProvider func(ctx context.Context) (user, password string, err error)
+
+// openWithCredentials opens a database pool whose connections are opened
+// with the credentials of the ConnectionDetails.CredentialsProvider, and
+// run the session statements of the dialect.
+func openWithCredentials(d dialect, stmts []string) (*sqlx.DB, error) {
+	deets := d.Details()
+	drv, err := openDriver(deets.driverName())
+	if err != nil {
+		return nil, err
+	}
+	var cn driver.Connector = credentialsConnector{driver: drv, dialect: d}
+	if len(stmts) > 0 {
+		cn = sessionConnector{connector: cn, stmts: stmts}
+	}
+	return sqlx.NewDb(sql.OpenDB(cn), deets.driverName()), nil
+}
+
+// credentialsConnector is a database/sql connector asking the credentials
+// to the provider for each new connection.
+type credentialsConnector struct {
+	driver  driver.Driver
+	dialect dialect
+}
+
+func (c credentialsConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	user, password, err := c.dialect.Details().CredentialsProvider(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get the database credentials")
+	}
+	dsn, err := credentialsURL(c.dialect, user, password)
+	if err != nil {
+		return nil, err
+	}
+	return dsnConnector{driver: c.driver, dsn: dsn}.Connect(ctx)
+}
+
+func (c credentialsConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// credentialsURL returns the URL of the dialect, with the given credentials.
+func credentialsURL(d dialect, user, password string) (string, error) {
+	deets := *d.Details()
+	if deets.Dialect == nameSQLite3 {
+		return configureDSN(d, d.URL())
+	}
+	deets.URL = ""
+	// PostgreSQL URLs are built without escaping the credentials, they're
+	// set apart as the tokens used as passwords need to be escaped.
+	escape := deets.Dialect == namePostgreSQL
+	if !escape {
+		deets.User = user
+		deets.Password = password
+	}
+	nd, err := newConnection[deets.Dialect](&deets)
+	if err != nil {
+		return "", errors.Wrap(err, "could not build the database URL")
+	}
+	if !escape {
+		return configureDSN(d, nd.URL())
+	}
+	u, err := url.Parse(nd.URL())
+	if err != nil {
+		return "", errors.Wrap(err, "could not build the database URL")
+	}
+	u.User = url.UserPassword(user, password)
+	return configureDSN(d, u.String())
+}
@@ -0,0 +1,112 @@
+package pop
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DateType is the fizz type of the DATE columns of the Date fields:
+//
+//	t.Column("birth_date", pop.DateType, {})
+const DateType = "date"
+
+const dateLayout = "2006-01-02"
+
+// Date is a date without time of day nor location, for the DATE columns:
+// written as YYYY-MM-DD, and marshaled to JSON as a "YYYY-MM-DD" string.
+type Date struct {
+	Year  int
+	Month time.Month
+	Day   int
+}
+
+// NewDate returns the date of the year, month and day.
+func NewDate(year int, month time.Month, day int) Date {
+	return Date{Year: year, Month: month, Day: day}
+}
+
+// DateOf returns the date of t, in the location of t.
+func DateOf(t time.Time) Date {
+	y, m, d := t.Date()
+	return Date{Year: y, Month: m, Day: d}
+}
+
+// ParseDate parses a YYYY-MM-DD date.
+func ParseDate(s string) (Date, error) {
+	t, err := time.Parse(dateLayout, s)
+	if err != nil {
+		return Date{}, errors.WithStack(err)
+	}
+	return DateOf(t), nil
+}
+
+// Time returns the midnight of the date, in loc.
+func (d Date) Time(loc *time.Location) time.Time {
+	return time.Date(d.Year, d.Month, d.Day, 0, 0, 0, 0, loc)
+}
+
+// IsZero tells if the date is the zero Date.
+func (d Date) IsZero() bool {
+	return d == Date{}
+}
+
+// String returns the date as YYYY-MM-DD.
+func (d Date) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+// Value writes the date as YYYY-MM-DD.
+func (d Date) Value() (driver.Value, error) {
+	return d.String(), nil
+}
+
+// Scan reads a DATE column, returned as a time.Time or a string by the
+// drivers, e.g. by SQLite which stores the dates as text.
+func (d *Date) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case time.Time:
+		*d = DateOf(v)
+		return nil
+	case []byte:
+		return d.scanString(string(v))
+	case string:
+		return d.scanString(v)
+	}
+	return errors.Errorf("can't scan %T into a pop.Date", src)
+}
+
+func (d *Date) scanString(s string) error {
+	if len(s) > len(dateLayout) {
+		// the time of day of a TIMESTAMP or text column
+		s = s[:len(dateLayout)]
+	}
+	date, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
+}
+
+// MarshalJSON marshals the date as a "YYYY-MM-DD" string.
+func (d Date) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON unmarshals a "YYYY-MM-DD" string.
+func (d *Date) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	date, err := ParseDate(s)
+	if err != nil {
+		return err
+	}
+	*d = date
+	return nil
+}
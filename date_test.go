@@ -0,0 +1,69 @@
+package pop
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type datedUser struct {
+	ID        int       `db:"id"`
+	Name      string    `db:"name"`
+	BirthDate Date      `db:"birth_date"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (datedUser) TableName() string {
+	return "users"
+}
+
+func Test_Date(t *testing.T) {
+	r := require.New(t)
+
+	d := NewDate(1990, time.May, 7)
+	r.Equal("1990-05-07", d.String())
+	v, err := d.Value()
+	r.NoError(err)
+	r.Equal("1990-05-07", v)
+
+	b, err := json.Marshal(d)
+	r.NoError(err)
+	r.Equal(`"1990-05-07"`, string(b))
+	var u Date
+	r.NoError(json.Unmarshal(b, &u))
+	r.Equal(d, u)
+	r.Error(json.Unmarshal([]byte(`"1990-05-07T10:00:00Z"`), &u))
+
+	loc := time.FixedZone("UTC+10", 10*60*60)
+	r.Equal(d, DateOf(time.Date(1990, time.May, 7, 23, 30, 0, 0, loc)))
+	r.Equal(time.Date(1990, time.May, 7, 0, 0, 0, 0, loc), d.Time(loc))
+
+	for _, src := range []interface{}{"1990-05-07", []byte("1990-05-07 00:00:00"), time.Date(1990, time.May, 7, 0, 0, 0, 0, time.UTC)} {
+		var s Date
+		r.NoError(s.Scan(src))
+		r.Equal(d, s)
+	}
+	r.Error(u.Scan(nil))
+	r.True(Date{}.IsZero())
+}
+
+func Test_Date_Column(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		u := &datedUser{Name: "Dated", BirthDate: NewDate(1990, time.May, 7)}
+		r.NoError(tx.Create(u))
+
+		found := &datedUser{}
+		r.NoError(tx.Find(ctx, found, u.ID))
+		r.Equal(u.BirthDate, found.BirthDate)
+
+		count, err := tx.Where("birth_date = ?", NewDate(1990, time.May, 7)).Count(&datedUser{})
+		r.NoError(err)
+		r.Equal(1, count)
+	})
+}
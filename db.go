@@ -1,13 +1,111 @@
 package pop
 
-import "github.com/jmoiron/sqlx"
+import (
+	"context"
+	"database/sql"
+	"sync"
 
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// dB is the store of a connection pool, shared by the copies and the
+// transactions of the connection which opened it. The pool is replaced by
+// Connection.Reconnect, and checked by the health check of the connection,
+// which is stopped when the pool is closed.
 type dB struct {
-	*sqlx.DB
+	mu     sync.RWMutex
+	db     *sqlx.DB
+	closed bool
+	// stopHealthCheck stops the health check of the pool, if any
+	stopHealthCheck context.CancelFunc
+}
+
+func newDB(db *sqlx.DB) *dB {
+	return &dB{db: db}
+}
+
+// pool returns the current connection pool.
+func (db *dB) pool() *sqlx.DB {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+	return db.db
+}
+
+// swap replaces the connection pool, and returns the previous one. A
+// closed pool isn't replaced.
+func (db *dB) swap(p *sqlx.DB) (*sqlx.DB, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if db.closed {
+		return nil, errors.New("the connection is closed")
+	}
+	old := db.db
+	db.db = p
+	return old, nil
+}
+
+func (db *dB) Select(dest interface{}, query string, args ...interface{}) error {
+	return db.pool().Select(dest, query, args...)
+}
+
+func (db *dB) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return db.pool().SelectContext(ctx, dest, query, args...)
+}
+
+func (db *dB) Get(dest interface{}, query string, args ...interface{}) error {
+	return db.pool().Get(dest, query, args...)
+}
+
+func (db *dB) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	return db.pool().Queryx(query, args...)
+}
+
+func (db *dB) NamedExec(query string, arg interface{}) (sql.Result, error) {
+	return db.pool().NamedExec(query, arg)
+}
+
+func (db *dB) Exec(query string, args ...interface{}) (sql.Result, error) {
+	return db.pool().Exec(query, args...)
+}
+
+func (db *dB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return db.pool().ExecContext(ctx, query, args...)
+}
+
+func (db *dB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	return db.pool().QueryRowContext(ctx, query, args...)
+}
+
+func (db *dB) PrepareNamed(query string) (*sqlx.NamedStmt, error) {
+	return db.pool().PrepareNamed(query)
+}
+
+func (db *dB) PingContext(ctx context.Context) error {
+	return db.pool().PingContext(ctx)
+}
+
+func (db *dB) Conn(ctx context.Context) (*sql.Conn, error) {
+	return db.pool().Conn(ctx)
+}
+
+// Close stops the health check of the pool, and closes it.
+func (db *dB) Close() error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	db.closed = true
+	if db.stopHealthCheck != nil {
+		db.stopHealthCheck()
+	}
+	return db.db.Close()
 }
 
 func (db *dB) Transaction() (*Tx, error) {
-	return newTX(db)
+	return newTX(context.Background(), db)
+}
+
+func (db *dB) TransactionContext(ctx context.Context) (*Tx, error) {
+	return newTX(ctx, db)
 }
 
 func (db *dB) Rollback() error {
@@ -0,0 +1,57 @@
+package pop
+
+import (
+	"github.com/gobuffalo/pop/columns"
+)
+
+// DatabaseTimestampsAble is implemented by the models whose created_at and
+// updated_at columns are set by the database, see
+// Connection.DatabaseTimestamps.
+//
+//	func (Order) DatabaseTimestamps() bool {
+//		return true
+//	}
+type DatabaseTimestampsAble interface {
+	DatabaseTimestamps() bool
+}
+
+// DatabaseTimestamps returns a copy of the connection leaving the
+// created_at and updated_at timestamps of the models to the database, e.g.
+// to its DEFAULT now() and its triggers, so the timestamps of the app
+// servers don't skew with their clocks. The transactions of the copy
+// inherit it.
+//
+// Create doesn't write the timestamps, so the defaults of their columns
+// apply, and reads them back: with INSERT ... RETURNING on PostgreSQL and
+// CockroachDB, with a follow-up SELECT on the other dialects. Update
+// doesn't write updated_at, which must be set by a trigger, and reads it
+// back. The blind writes don't read them back, see BlindWrites.
+//
+//	err := c.DatabaseTimestamps().Create(&order)
+func (c *Connection) DatabaseTimestamps() *Connection {
+	cn := c.copy()
+	cn.dbClock = true
+	return cn
+}
+
+// databaseTimestamps tells if the timestamps of the model are set by the
+// database.
+func (c *Connection) databaseTimestamps(m *Model) bool {
+	if c.dbClock {
+		return true
+	}
+	dt, ok := m.Value.(DatabaseTimestampsAble)
+	return ok && dt.DatabaseTimestamps()
+}
+
+// timestampColumns returns the timestamp columns, among created_at and
+// updated_at, which are columns of cols.
+func timestampColumns(cols columns.Columns, names ...string) []string {
+	var ts []string
+	for _, n := range names {
+		if _, ok := cols.Cols[n]; ok {
+			ts = append(ts, n)
+		}
+	}
+	return ts
+}
@@ -0,0 +1,75 @@
+package pop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type stampedOrder struct {
+	ID        int       `db:"id"`
+	Total     int       `db:"total"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type stampedInvoice struct {
+	ID        int       `db:"id"`
+	Total     int       `db:"total"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (stampedInvoice) TableName() string {
+	return "stamped_orders"
+}
+
+func (stampedInvoice) DatabaseTimestamps() bool {
+	return true
+}
+
+func Test_Connection_DatabaseTimestamps(t *testing.T) {
+	// the timestamps are set by the defaults of stamped_orders, and
+	// updated_at by a trigger, or ON UPDATE
+	transaction(func(c *Connection) {
+		r := require.New(t)
+		ctx := context.Background()
+
+		// the clock of the app is ignored
+		past := time.Date(2001, 1, 1, 0, 0, 0, 0, time.UTC)
+		dc := c.WithClock(func() time.Time { return past }).DatabaseTimestamps()
+
+		o := &stampedOrder{Total: 10}
+		r.NoError(dc.Create(o))
+		r.True(o.CreatedAt.After(past))
+		found := &stampedOrder{}
+		r.NoError(c.Find(ctx, found, o.ID))
+		r.Equal(found, o)
+
+		created := o.CreatedAt
+		time.Sleep(5 * time.Millisecond)
+		r.NoError(dc.Transaction(ctx, func(ctx context.Context, tx *Connection) error {
+			o.Total = 20
+			return tx.Update(o)
+		}))
+		r.Equal(created, o.CreatedAt)
+		r.True(o.UpdatedAt.After(created))
+		r.NoError(c.Find(ctx, found, o.ID))
+		r.Equal(found, o)
+
+		// per model
+		i := &stampedInvoice{Total: 30}
+		r.NoError(c.WithClock(func() time.Time { return past }).Create(i))
+		r.True(i.CreatedAt.After(past))
+		foundInvoice := &stampedInvoice{}
+		r.NoError(c.Find(ctx, foundInvoice, i.ID))
+		r.Equal(foundInvoice, i)
+
+		// the other models keep the clock of the app
+		o = &stampedOrder{Total: 40}
+		r.NoError(c.WithClock(func() time.Time { return past }).Create(o))
+		r.Equal(past, o.CreatedAt)
+	})
+}
@@ -0,0 +1,102 @@
+package pop
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// debugQueryLabel prefixes the SQL returned by DebugQuery. It isn't a SQL
+// comment, so the statement fails if it's run by mistake.
+const debugQueryLabel = "[pop debug, NOT SAFE TO EXECUTE] "
+
+// DebugQuery returns the SQL run by the query with its args inlined, to be
+// read while debugging: strings and times are quoted, numbers formatted and
+// nil values replaced with NULL. The inlining is best-effort and doesn't
+// escape the args like the database driver does, so the result is labeled
+// as not safe for execution, and must never be run.
+//
+// The model is the one the query would load, e.g. the model passed to All.
+// It's only needed for the queries which aren't built with RawQuery.
+//
+//	log.Println(pop.DebugQuery(ctx, c.Where("name = ?", "Mark"), &users))
+//	// [pop debug, NOT SAFE TO EXECUTE] SELECT ... FROM users AS users WHERE name = 'Mark'
+func DebugQuery(ctx context.Context, q *Query, model ...interface{}) string {
+	m := &Model{}
+	if len(model) > 0 {
+		m.Value = model[0]
+	}
+	if q.RawSQL.Fragment == "" && m.Value == nil {
+		return debugQueryLabel + "no model given for the query"
+	}
+
+	query, args, err := q.ToSQL(m)
+	if err != nil {
+		return debugQueryLabel + err.Error()
+	}
+	query = replacePlaceholders(query, q.Connection.Dialect.Name(), func(n int, marker string) string {
+		if n < 0 || n >= len(args) {
+			return marker
+		}
+		return debugArg(args[n])
+	})
+	return debugQueryLabel + query
+}
+
+// debugArg formats arg the way it would appear in a SQL statement.
+func debugArg(arg interface{}) string {
+	if v, ok := arg.(driver.Valuer); ok {
+		dv, err := v.Value()
+		if err != nil {
+			return fmt.Sprintf("<%T: %s>", arg, err)
+		}
+		arg = dv
+	}
+
+	v := reflect.ValueOf(arg)
+	if v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return "NULL"
+		}
+		return debugArg(v.Elem().Interface())
+	}
+	if _, ok := arg.([]byte); !ok && (v.Kind() == reflect.Slice || v.Kind() == reflect.Array) {
+		// the args of an "in (?)" clause which weren't expanded
+		elems := make([]string, v.Len())
+		for i := range elems {
+			elems[i] = debugArg(v.Index(i).Interface())
+		}
+		return strings.Join(elems, ", ")
+	}
+
+	switch a := arg.(type) {
+	case nil:
+		return "NULL"
+	case string:
+		return debugQuote(a)
+	case []byte:
+		return debugQuote(string(a))
+	case bool:
+		if a {
+			return "TRUE"
+		}
+		return "FALSE"
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprintf("%d", a)
+	case float32:
+		return strconv.FormatFloat(float64(a), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(a, 'g', -1, 64)
+	case time.Time:
+		return debugQuote(a.Format("2006-01-02 15:04:05.999999999Z07:00"))
+	}
+	return debugQuote(fmt.Sprint(arg))
+}
+
+func debugQuote(s string) string {
+	return "'" + strings.Replace(s, "'", "''", -1) + "'"
+}
@@ -0,0 +1,49 @@
+package pop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DebugQuery(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	q := PDB.RawQuery("select * from users where name = ? and note <> '?' and id in (?) and alive = ?", "O'Neil", []int{1, 2}, true)
+	r.Equal("[pop debug, NOT SAFE TO EXECUTE] select * from users where name = 'O''Neil' and note <> '?' and id in (1, 2) and alive = TRUE", DebugQuery(ctx, q))
+
+	q = PDB.Where("name = ?", nulls.String{}).Where("price > ?", 9.5).Order("id desc")
+	r.Equal("[pop debug, NOT SAFE TO EXECUTE] SELECT name as full_name, users.alive, users.bio, users.birth_date, users.created_at, users.email, users.id, users.name, users.price, users.updated_at, users.user_name FROM users AS users WHERE name = NULL AND price > 9.5 ORDER BY id desc", DebugQuery(ctx, q, &User{}))
+
+	r.Contains(DebugQuery(ctx, PDB.Where("id = ?", 1)), "NOT SAFE TO EXECUTE")
+}
+
+func Test_DebugArg(t *testing.T) {
+	table := []struct {
+		arg interface{}
+		out string
+	}{
+		{nil, "NULL"},
+		{"it's", "'it''s'"},
+		{[]byte("bytes"), "'bytes'"},
+		{false, "FALSE"},
+		{int64(-42), "-42"},
+		{uint8(7), "7"},
+		{float32(0.25), "0.25"},
+		{1e21, "1e+21"},
+		{time.Date(2019, 1, 2, 3, 4, 5, 600, time.UTC), "'2019-01-02 03:04:05.0000006Z'"},
+		{nulls.NewString("Mark"), "'Mark'"},
+		{nulls.Int{}, "NULL"},
+		{(*int)(nil), "NULL"},
+		{[]string{"a", "b"}, "'a', 'b'"},
+		{struct{ A int }{1}, "'{1}'"},
+	}
+
+	for _, tt := range table {
+		require.Equal(t, tt.out, debugArg(tt.arg))
+	}
+}
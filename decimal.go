@@ -0,0 +1,93 @@
+package pop
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/shopspring/decimal"
+)
+
+// DecimalType returns the fizz type of the DECIMAL columns of the Decimal
+// fields, with the precision and scale of their values:
+//
+//	t.Column("price", pop.DecimalType(10, 2), {})
+func DecimalType(precision, scale int) string {
+	return fmt.Sprintf("DECIMAL(%d,%d)", precision, scale)
+}
+
+// Decimal is an exact decimal number, e.g. an amount of money, for the
+// NUMERIC and DECIMAL columns: written as its string, and marshaled to
+// JSON as a string. Its arithmetic is exact, except for Div which rounds
+// the quotient to decimal.DivisionPrecision digits; the other methods of
+// decimal.Decimal are available on its Decimal field.
+type Decimal struct {
+	decimal.Decimal
+}
+
+// NewDecimal returns the Decimal of d.
+func NewDecimal(d decimal.Decimal) Decimal {
+	return Decimal{d}
+}
+
+// ParseDecimal parses a decimal number, e.g. "12.50".
+func ParseDecimal(s string) (Decimal, error) {
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return Decimal{}, errors.WithStack(err)
+	}
+	return Decimal{d}, nil
+}
+
+// Add returns d+o.
+func (d Decimal) Add(o Decimal) Decimal {
+	return Decimal{d.Decimal.Add(o.Decimal)}
+}
+
+// Sub returns d-o.
+func (d Decimal) Sub(o Decimal) Decimal {
+	return Decimal{d.Decimal.Sub(o.Decimal)}
+}
+
+// Mul returns d*o.
+func (d Decimal) Mul(o Decimal) Decimal {
+	return Decimal{d.Decimal.Mul(o.Decimal)}
+}
+
+// Div returns d/o, rounded to decimal.DivisionPrecision digits after the
+// decimal point. It panics if o is zero.
+func (d Decimal) Div(o Decimal) Decimal {
+	return Decimal{d.Decimal.Div(o.Decimal)}
+}
+
+// DivRound returns d/o, rounded to precision digits after the decimal
+// point. It panics if o is zero.
+func (d Decimal) DivRound(o Decimal, precision int32) Decimal {
+	return Decimal{d.Decimal.DivRound(o.Decimal, precision)}
+}
+
+// Neg returns -d.
+func (d Decimal) Neg() Decimal {
+	return Decimal{d.Decimal.Neg()}
+}
+
+// Round returns d rounded to places digits after the decimal point.
+func (d Decimal) Round(places int32) Decimal {
+	return Decimal{d.Decimal.Round(places)}
+}
+
+// Cmp compares d and o: -1 if d < o, 0 if d == o, +1 if d > o.
+func (d Decimal) Cmp(o Decimal) int {
+	return d.Decimal.Cmp(o.Decimal)
+}
+
+// Equal tells if d and o are the same number, e.g. 1.5 and 1.50.
+func (d Decimal) Equal(o Decimal) bool {
+	return d.Decimal.Equal(o.Decimal)
+}
+
+// MarshalJSON marshals the number as a string, whatever
+// decimal.MarshalJSONWithoutQuotes.
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
@@ -0,0 +1,73 @@
+package pop
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/require"
+)
+
+type pricedUser struct {
+	ID        int       `db:"id"`
+	Name      string    `db:"name"`
+	Price     Decimal   `db:"price"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (pricedUser) TableName() string {
+	return "users"
+}
+
+func Test_Decimal(t *testing.T) {
+	r := require.New(t)
+
+	a, err := ParseDecimal("0.1")
+	r.NoError(err)
+	b, err := ParseDecimal("0.2")
+	r.NoError(err)
+	sum := a.Add(b)
+	r.Equal("0.3", sum.String())
+	r.Equal("0.02", a.Mul(b).String())
+	r.Equal("-0.1", a.Sub(b).String())
+	r.Equal("0.33", a.DivRound(sum, 2).String())
+	r.Equal(-1, a.Cmp(b))
+	r.True(sum.Equal(sum.Mul(NewDecimal(decimal.New(10, -1)))))
+
+	v, err := sum.Value()
+	r.NoError(err)
+	r.Equal("0.3", v)
+
+	j, err := json.Marshal(sum)
+	r.NoError(err)
+	r.Equal(`"0.3"`, string(j))
+	var u Decimal
+	r.NoError(json.Unmarshal([]byte(`"12.50"`), &u))
+	r.Equal("12.5", u.String())
+
+	var s Decimal
+	r.NoError(s.Scan([]byte("1234567890.123456789")))
+	r.Equal("1234567890.123456789", s.String())
+
+	_, err = ParseDecimal("1,5")
+	r.Error(err)
+	r.Equal("DECIMAL(10,2)", DecimalType(10, 2))
+}
+
+func Test_Decimal_Column(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		price, err := ParseDecimal("19.99")
+		r.NoError(err)
+		u := &pricedUser{Name: "Priced", Price: price}
+		r.NoError(tx.Create(u))
+
+		found := &pricedUser{}
+		r.NoError(tx.Find(ctx, found, u.ID))
+		r.True(price.Equal(found.Price), found.Price.String())
+	})
+}
@@ -0,0 +1,71 @@
+package pop
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// sqlDefaultX matches the defaults which are SQL expressions, e.g. now()
+// or CURRENT_TIMESTAMP, left to the database.
+var sqlDefaultX = regexp.MustCompile(`^(?i:current_timestamp|current_date|current_time|localtimestamp|localtime)$|^[A-Za-z_][A-Za-z0-9_.]*\(.*\)$`)
+
+// sqlDefault tells if the default def is a SQL expression.
+func sqlDefault(def string) bool {
+	return sqlDefaultX.MatchString(strings.TrimSpace(def))
+}
+
+// applyDefaults sets the unset fields of the model which have a default
+// to it, see Connection.Create, and returns the columns of the unset
+// fields whose default is a SQL expression, left to the database.
+func (m *Model) applyDefaults() ([]string, error) {
+	v := reflect.ValueOf(m.Value)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, nil
+	}
+	v = v.Elem()
+	var dbDefaults []string
+	for _, fi := range strictMapper.TypeMap(v.Type()).Index {
+		if fi.Embedded || strings.Contains(fi.Path, ".") {
+			continue
+		}
+		def, ok := defaultTag(fi.Field)
+		if !ok {
+			continue
+		}
+		f, err := v.FieldByIndexErr(fi.Index)
+		if err != nil {
+			// a field of a nil embedded struct
+			continue
+		}
+		if !unsetField(f, fi.Field.Tag.Get("default_zero") == "true") {
+			continue
+		}
+		if sqlDefault(def) {
+			dbDefaults = append(dbDefaults, fi.Name)
+			continue
+		}
+		if err := setDefault(f, def); err != nil {
+			return nil, errors.Wrapf(err, "invalid default %q of %s", def, fi.Field.Name)
+		}
+	}
+	return dbDefaults, nil
+}
+
+// unsetField tells if the field f is unset, so its default applies: a nil
+// pointer, or an invalid nulls type such as nulls.String. The zero values
+// of the other fields, e.g. 0, "" or false, are values: they're unset only
+// if zeroDefault is true.
+func unsetField(f reflect.Value, zeroDefault bool) bool {
+	switch f.Kind() {
+	case reflect.Ptr:
+		return f.IsNil()
+	case reflect.Struct:
+		if valid := f.FieldByName("Valid"); valid.IsValid() && valid.Kind() == reflect.Bool {
+			return !valid.Bool()
+		}
+	}
+	return zeroDefault && f.IsZero()
+}
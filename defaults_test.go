@@ -0,0 +1,84 @@
+package pop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/validate"
+	"github.com/gobuffalo/validate/validators"
+	"github.com/stretchr/testify/require"
+)
+
+type defaultedOrder struct {
+	ID        int          `db:"id"`
+	Status    string       `db:"status" default:"pending" default_zero:"true"`
+	Quantity  int          `db:"quantity" default:"1" default_zero:"true"`
+	Discount  int          `db:"discount" default:"10"`
+	Gift      bool         `db:"gift" default:"true"`
+	Paid      bool         `db:"paid" default:"true" default_zero:"true"`
+	Express   *bool        `db:"express" default:"false"`
+	Note      nulls.String `db:"note" default:"none"`
+	PlacedAt  time.Time    `db:"placed_at" default:"CURRENT_TIMESTAMP" default_zero:"true"`
+	CreatedAt time.Time    `db:"created_at"`
+	UpdatedAt time.Time    `db:"updated_at"`
+
+	created string `db:"-"`
+}
+
+func (o *defaultedOrder) Validate(*Connection) (*validate.Errors, error) {
+	return validate.Validate(&validators.StringIsPresent{Field: o.Status, Name: "Status"}), nil
+}
+
+func (o *defaultedOrder) BeforeCreate(*Connection) error {
+	o.created = o.Status
+	return nil
+}
+
+func Test_Create_Defaults(t *testing.T) {
+	transaction(func(c *Connection) {
+		r := require.New(t)
+		ctx := context.Background()
+
+		o := &defaultedOrder{}
+		verrs, err := c.ValidateAndCreate(o)
+		r.NoError(err)
+		r.False(verrs.HasAny())
+		r.Equal("pending", o.Status)
+		r.Equal("pending", o.created)
+		r.Equal(1, o.Quantity)
+		// a zero value is a value, unless default_zero is set
+		r.Zero(o.Discount)
+		r.False(o.Gift)
+		r.True(o.Paid)
+		r.NotNil(o.Express)
+		r.False(*o.Express)
+		r.Equal(nulls.NewString("none"), o.Note)
+		// the SQL defaults are read back
+		r.False(o.PlacedAt.IsZero())
+
+		found := &defaultedOrder{}
+		r.NoError(c.Find(ctx, found, o.ID))
+		r.True(o.PlacedAt.Equal(found.PlacedAt))
+		r.Equal("pending", found.Status)
+
+		// the set fields are kept
+		placed := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		express := true
+		o = &defaultedOrder{Status: "paid", Quantity: 3, Express: &express, Note: nulls.NewString(""), PlacedAt: placed}
+		r.NoError(c.Create(o))
+		r.NoError(c.Find(ctx, found, o.ID))
+		r.Equal("paid", found.Status)
+		r.Equal(3, found.Quantity)
+		r.True(*found.Express)
+		r.Equal(nulls.NewString(""), found.Note)
+		r.True(placed.Equal(found.PlacedAt))
+
+		r.True(sqlDefault("now()"))
+		r.True(sqlDefault("gen_random_uuid()"))
+		r.True(sqlDefault("current_timestamp"))
+		r.False(sqlDefault("pending"))
+		r.False(sqlDefault("(none)"))
+	})
+}
@@ -0,0 +1,112 @@
+package pop
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// derivedConnections are the connections derived from a connection by
+// ApplicationName, by key. Each of them has its own pool, shared by the
+// connections returned for its key, and owned by the connection they're
+// derived from: the pools are closed by its Close.
+type derivedConnections struct {
+	mu    sync.Mutex
+	conns map[string]*derivedConnection
+}
+
+// derivedConnection is a derived connection, ready once opened.
+type derivedConnection struct {
+	ready chan struct{}
+	conn  *Connection
+	err   error
+}
+
+func newDerivedConnections() *derivedConnections {
+	return &derivedConnections{conns: map[string]*derivedConnection{}}
+}
+
+// get returns the connection derived under key, opened with open on the
+// first call. It's opened without holding the lock, the concurrent calls
+// for the same key waiting for it, and opened again on the next call if it
+// fails. The returned connection doesn't own its pool, see
+// Connection.borrowed.
+func (dc *derivedConnections) get(key string, open func() (*Connection, error)) (*Connection, error) {
+	dc.mu.Lock()
+	d, ok := dc.conns[key]
+	if !ok {
+		d = &derivedConnection{ready: make(chan struct{})}
+		dc.conns[key] = d
+	}
+	dc.mu.Unlock()
+
+	if !ok {
+		d.conn, d.err = open()
+		if d.err != nil {
+			dc.mu.Lock()
+			delete(dc.conns, key)
+			dc.mu.Unlock()
+		}
+		close(d.ready)
+	}
+	<-d.ready
+	if d.err != nil {
+		return nil, d.err
+	}
+	cn := d.conn.copy()
+	cn.borrowed = true
+	return cn, nil
+}
+
+// closeAll closes the pools of the derived connections, and forgets them:
+// they're opened again on the next get.
+func (dc *derivedConnections) closeAll() error {
+	dc.mu.Lock()
+	conns := dc.conns
+	dc.conns = map[string]*derivedConnection{}
+	dc.mu.Unlock()
+
+	var errs []string
+	for key, d := range conns {
+		<-d.ready
+		if d.err != nil {
+			continue
+		}
+		if err := d.conn.Close(); err != nil {
+			errs = append(errs, key)
+		}
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return errors.Errorf("could not close the derived connections %v", errs)
+	}
+	return nil
+}
+
+// derive returns the connection derived from c under key, with the
+// dialect returned by newDialect, see derivedConnections. It's opened on
+// the first call for key, with its own schema cache and derived
+// connections.
+func (c *Connection) derive(key string, newDialect func() (dialect, error)) (*Connection, error) {
+	if c.derived == nil {
+		return nil, errors.New("the connection wasn't created by NewConnection")
+	}
+	return c.derived.get(c.URL()+"#"+key, func() (*Connection, error) {
+		d, err := newDialect()
+		if err != nil {
+			return nil, err
+		}
+		cn := c.copy()
+		cn.Store = nil
+		cn.TX = nil
+		cn.borrowed = false
+		cn.schemaCache = newSchemaCache()
+		cn.derived = newDerivedConnections()
+		cn.Dialect = d
+		if err := cn.Open(); err != nil {
+			return nil, err
+		}
+		return cn, nil
+	})
+}
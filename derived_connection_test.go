@@ -0,0 +1,57 @@
+package pop
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_derivedConnections_get(t *testing.T) {
+	r := require.New(t)
+	dc := newDerivedConnections()
+
+	var opened int32
+	open := func() (*Connection, error) {
+		atomic.AddInt32(&opened, 1)
+		return &Connection{name: "tenant"}, nil
+	}
+	conns := make([]*Connection, 10)
+	var wg sync.WaitGroup
+	for i := range conns {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			cn, err := dc.get("tenant", open)
+			r.NoError(err)
+			conns[i] = cn
+		}(i)
+	}
+	wg.Wait()
+	r.Equal(int32(1), opened)
+	for _, cn := range conns {
+		r.Equal("tenant", cn.name)
+		r.True(cn.borrowed)
+		// the pool is owned by the connection it's derived from
+		r.NoError(cn.Close())
+	}
+}
+
+func Test_derivedConnections_get_Error(t *testing.T) {
+	r := require.New(t)
+	dc := newDerivedConnections()
+
+	_, err := dc.get("tenant", func() (*Connection, error) {
+		return nil, errors.New("unreachable")
+	})
+	r.Error(err)
+
+	// the failed connection is opened again
+	cn, err := dc.get("tenant", func() (*Connection, error) {
+		return &Connection{name: "tenant"}, nil
+	})
+	r.NoError(err)
+	r.Equal("tenant", cn.name)
+}
@@ -1,6 +1,7 @@
 package pop
 
 import (
+	"context"
 	"io"
 
 	"github.com/gobuffalo/fizz"
@@ -13,18 +14,21 @@ type dialect interface {
 	MigrationURL() string
 	Details() *ConnectionDetails
 	TranslateSQL(string) string
-	Create(store, *Model, columns.Columns) error
-	Update(store, *Model, columns.Columns) error
-	Destroy(store, *Model) error
-	SelectOne(store, *Model, Query) error
-	SelectMany(store, *Model, Query) error
+	Create(Store, *Model, columns.Columns) error
+	Update(Store, *Model, columns.Columns) error
+	Destroy(Store, *Model) error
+	SelectOne(Store, *Model, Query) error
+	SelectMany(Store, *Model, Query) error
 	CreateDB() error
 	DropDB() error
 	DumpSchema(io.Writer) error
 	LoadSchema(io.Reader) error
 	FizzTranslator() fizz.Translator
 	Lock(func() error) error
-	TruncateAll(*Connection) error
+	TruncateAll(context.Context, *Connection) error
+	Truncate(context.Context, *Connection, TruncateOptions, ...string) error
+	TableInfo(context.Context, *Connection, string) (*TableInfo, error)
+	TableNames(context.Context, *Connection) ([]string, error)
 	Quote(key string) string
 }
 
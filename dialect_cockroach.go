@@ -10,13 +10,13 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	_ "github.com/cockroachdb/cockroach-go/crdb" // Load CockroachdbQL/postgres Go driver which also loads github.com/lib/pq
 	"github.com/gobuffalo/fizz"
 	"github.com/gobuffalo/fizz/translators"
 	"github.com/gobuffalo/pop/columns"
 	"github.com/gobuffalo/pop/logging"
-	"github.com/jmoiron/sqlx"
 	"github.com/markbates/going/defaults"
 	"github.com/pkg/errors"
 )
@@ -59,7 +59,10 @@ func (p *cockroach) Details() *ConnectionDetails {
 	return p.ConnectionDetails
 }
 
-func (p *cockroach) Create(s store, model *Model, cols columns.Columns) error {
+func (p *cockroach) Create(s Store, model *Model, cols columns.Columns) error {
+	if model.blind {
+		return p.createBlind(s, model, cols)
+	}
 	keyType := model.PrimaryKeyType()
 	switch keyType {
 	case "int", "int64":
@@ -70,50 +73,97 @@ func (p *cockroach) Create(s store, model *Model, cols columns.Columns) error {
 		w := cols.Writeable()
 		var query string
 		if len(w.Cols) > 0 {
-			query = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) returning id", model.TableName(), w.String(), w.SymbolizedString())
+			query = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) returning %s", model.TableName(), w.String(), w.SymbolizedString(), returningColumns(model))
 		} else {
-			query = fmt.Sprintf("INSERT INTO %s DEFAULT VALUES returning id", model.TableName())
+			query = fmt.Sprintf("INSERT INTO %s DEFAULT VALUES returning %s", model.TableName(), returningColumns(model))
 		}
-		log(logging.SQL, query)
+		storeLog(s)(logging.SQL, query)
 		stmt, err := s.PrepareNamed(query)
 		if err != nil {
 			return errors.WithStack(err)
 		}
-		err = stmt.Get(&id, model.Value)
+		returning := len(model.returning) > 0
+		if returning {
+			// scan the id and the returning columns in the model
+			err = stmt.Get(model.Value, model.bindArg())
+		} else {
+			err = stmt.Get(&id, model.bindArg())
+		}
 		if err != nil {
 			if err := stmt.Close(); err != nil {
 				return errors.WithMessage(err, "failed to close statement")
 			}
 			return errors.WithStack(err)
 		}
-		model.setID(id.ID)
+		if returning {
+			model.returning = nil
+		} else {
+			model.setID(id.ID)
+		}
 		return errors.WithMessage(stmt.Close(), "failed to close statement")
 	}
 	return genericCreate(s, model, cols)
 }
 
-func (p *cockroach) Update(s store, model *Model, cols columns.Columns) error {
-	return genericUpdate(s, model, cols)
+// createBlind inserts the model with RETURNING NOTHING: the id of an int
+// key isn't known, and the returning columns aren't read.
+func (p *cockroach) createBlind(s Store, model *Model, cols columns.Columns) error {
+	cols.Remove("id")
+	w := cols.Writeable()
+	switch keyType := model.PrimaryKeyType(); keyType {
+	case "int", "int64":
+	case "UUID", "string":
+		if err := setClientID(model, keyType); err != nil {
+			return err
+		}
+		w.Add("id")
+	default:
+		return errors.Errorf("can not use %s as a primary key type!", keyType)
+	}
+	model.returning = nil
+	var query string
+	if len(w.Cols) > 0 {
+		query = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) RETURNING NOTHING", model.TableName(), w.String(), w.SymbolizedString())
+	} else {
+		query = fmt.Sprintf("INSERT INTO %s DEFAULT VALUES RETURNING NOTHING", model.TableName())
+	}
+	storeLog(s)(logging.SQL, query)
+	_, err := s.NamedExec(query, model.bindArg())
+	return errors.WithStack(err)
+}
+
+func (p *cockroach) Update(s Store, model *Model, cols columns.Columns) error {
+	if !model.blind {
+		return genericUpdate(s, model, cols)
+	}
+	stmt := fmt.Sprintf("UPDATE %s SET %s WHERE %s RETURNING NOTHING", model.TableName(), cols.Writeable().UpdateString(), model.whereNamedID())
+	storeLog(s)(logging.SQL, stmt, model.ID())
+	_, err := s.NamedExec(stmt, model.bindArg())
+	return errors.WithStack(err)
 }
 
-func (p *cockroach) Destroy(s store, model *Model) error {
-	stmt := p.TranslateSQL(fmt.Sprintf("DELETE FROM %s WHERE %s", model.TableName(), model.whereID()))
+func (p *cockroach) Destroy(s Store, model *Model) error {
+	stmt := fmt.Sprintf("DELETE FROM %s WHERE %s", model.TableName(), model.whereID())
+	if model.blind {
+		stmt += " RETURNING NOTHING"
+	}
+	stmt = p.TranslateSQL(stmt)
 	_, err := genericExec(s, stmt, model.ID())
 	return errors.WithStack(err)
 }
 
-func (p *cockroach) SelectOne(s store, model *Model, query Query) error {
+func (p *cockroach) SelectOne(s Store, model *Model, query Query) error {
 	return genericSelectOne(s, model, query)
 }
 
-func (p *cockroach) SelectMany(s store, models *Model, query Query) error {
+func (p *cockroach) SelectMany(s Store, models *Model, query Query) error {
 	return genericSelectMany(s, models, query)
 }
 
 func (p *cockroach) CreateDB() error {
 	// createdb -h db -p 5432 -U cockroach enterprise_development
 	deets := p.ConnectionDetails
-	db, err := sql.Open(deets.Dialect, p.urlWithoutDb())
+	db, err := sql.Open(deets.driverName(), p.urlWithoutDb())
 	if err != nil {
 		return errors.Wrapf(err, "error creating Cockroach database %s", deets.Database)
 	}
@@ -132,7 +182,7 @@ func (p *cockroach) CreateDB() error {
 
 func (p *cockroach) DropDB() error {
 	deets := p.ConnectionDetails
-	db, err := sql.Open(deets.Dialect, p.urlWithoutDb())
+	db, err := sql.Open(deets.driverName(), p.urlWithoutDb())
 	if err != nil {
 		return errors.Wrapf(err, "error dropping Cockroach database %s", deets.Database)
 	}
@@ -175,7 +225,7 @@ func (p *cockroach) TranslateSQL(sql string) string {
 	if csql, ok := p.translateCache[sql]; ok {
 		return csql
 	}
-	csql := sqlx.Rebind(sqlx.DOLLAR, sql)
+	csql := NormalizePlaceholders(sql, p.Name())
 
 	p.translateCache[sql] = csql
 	return csql
@@ -199,18 +249,18 @@ func (p *cockroach) LoadSchema(r io.Reader) error {
 	return genericLoadSchema(p.ConnectionDetails, p.MigrationURL(), r)
 }
 
-func (p *cockroach) TruncateAll(tx *Connection) error {
+func (p *cockroach) TruncateAll(ctx context.Context, tx *Connection) error {
 	type table struct {
 		TableName string `db:"table_name"`
 	}
 
-	tableQuery := "select table_name from information_schema.tables where table_schema = 'public' and table_type = 'BASE TABLE' and table_catalog = ?"
+	tableQuery := "select table_name from information_schema.tables where table_schema = 'public' and table_type = 'BASE TABLE' and table_catalog = ? and table_name <> ?"
 	if strings.HasPrefix(p.info.version, "v1") {
-		tableQuery = "select table_name from information_schema.tables where table_schema = ?"
+		tableQuery = "select table_name from information_schema.tables where table_schema = ? and table_name <> ?"
 	}
 
 	var tables []table
-	if err := tx.RawQuery(tableQuery, tx.Dialect.Details().Database).All(context.TODO(), &tables); err != nil {
+	if err := tx.RawQuery(tableQuery, tx.Dialect.Details().Database, tx.MigrationTableName()).All(ctx, &tables); err != nil {
 		return err
 	}
 
@@ -221,16 +271,35 @@ func (p *cockroach) TruncateAll(tx *Connection) error {
 	tableNames := make([]string, len(tables))
 	for i, t := range tables {
 		tableNames[i] = t.TableName
+	}
+	return p.Truncate(ctx, tx, TruncateOptions{ResetSequence: true}, tableNames...)
+}
+
+// Truncate deletes all rows of the given tables. Their ids aren't
+// sequences, by default, so opts.ResetSequence is ignored.
+func (p *cockroach) Truncate(ctx context.Context, tx *Connection, opts TruncateOptions, tables ...string) error {
+	for _, t := range tables {
 		//! work around for current limitation of DDL and DML at the same transaction.
 		//  it should be fixed when cockroach support it or with other approach.
 		//  https://www.cockroachlabs.com/docs/stable/known-limitations.html#schema-changes-within-transactions
-		if err := tx.RawQuery(fmt.Sprintf("delete from %s", t.TableName)).Exec(); err != nil {
+		if err := tx.RawQuery(fmt.Sprintf("delete from %s", p.Quote(t))).Exec(); err != nil {
 			return err
 		}
 	}
 	return nil
-	// TODO!
-	// return tx3.RawQuery(fmt.Sprintf("truncate %s cascade;", strings.Join(tableNames, ", "))).Exec()
+}
+
+const cockroachIndexesInfo = `SELECT index_name AS name, NOT non_unique AS is_unique, column_name
+FROM information_schema.statistics
+WHERE table_schema = COALESCE(NULLIF($1, ''), current_schema()) AND table_name = $2 AND NOT storing
+ORDER BY index_name, seq_in_index`
+
+func (p *cockroach) TableInfo(ctx context.Context, c *Connection, table string) (*TableInfo, error) {
+	return genericTableInfo(ctx, c.Store, table, schemaTableArgs(table), pgColumnsInfo, cockroachIndexesInfo, pgForeignKeysInfo)
+}
+
+func (p *cockroach) TableNames(ctx context.Context, c *Connection) ([]string, error) {
+	return genericTableNames(c, pgTableNames)
 }
 
 func (p *cockroach) AfterOpen(c *Connection) error {
@@ -243,7 +312,7 @@ func (p *cockroach) AfterOpen(c *Connection) error {
 		p.info.version = s[2]
 		p.info.buildInfo = s[3]
 	}
-	log(logging.Debug, "server: %v %v %v", p.info.product, p.info.license, p.info.version)
+	c.log(logging.Debug, "server: %v %v %v", p.info.product, p.info.license, p.info.version)
 
 	return nil
 }
@@ -264,3 +333,32 @@ func finalizerCockroach(cd *ConnectionDetails) {
 	cd.Options["application_name"] = defaults.String(cd.Options["application_name"], appName)
 	cd.Port = defaults.String(cd.Port, portCockroach)
 }
+
+func (p *cockroach) switchSchemaStatement(name string) string {
+	return "SET LOCAL search_path TO " + p.Quote(name)
+}
+
+func (p *cockroach) withApplicationName(name string) (dialect, error) {
+	deets, err := withApplicationName(p.ConnectionDetails, name)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return newCockroach(deets)
+}
+
+func (p *cockroach) lockMigrations(ctx context.Context, c *Connection, conn *sql.Conn, key string, timeout time.Duration) (func() error, error) {
+	return rowLockMigrations(ctx, c, conn, key, timeout)
+}
+
+// isTransientError also retries the serialization failures: CockroachDB
+// asks to retry the statements running in implicit transactions.
+func (p *cockroach) isTransientError(err error) bool {
+	return isPostgresTransientError(err, "40001")
+}
+
+// isRetryableTransactionError tells if err is a serialization failure,
+// CockroachDB asking to retry the transaction.
+func (p *cockroach) isRetryableTransactionError(err error) bool {
+	code, ok := postgresErrorCode(err)
+	return ok && code == "40001"
+}
@@ -3,6 +3,7 @@ package pop
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -62,3 +63,45 @@ func Test_Cockroach_URL_UserDefinedAppName(t *testing.T) {
 	r.Contains(m.URL(), "database?application_name=myapp")
 	r.Contains(m.urlWithoutDb(), "/?application_name=myapp")
 }
+
+func Test_Cockroach_AsOf(t *testing.T) {
+	r := require.New(t)
+
+	c, err := NewConnection(&ConnectionDetails{Dialect: "cockroach", Host: "db.local", Database: "pop_test"})
+	r.NoError(err)
+	at := time.Date(2020, 3, 1, 9, 30, 0, 500000000, time.FixedZone("CET", 3600))
+	q := c.Where("user_id = ?", 1).Join("users", "users.id = books.user_id").AsOf(at)
+	sql, _, err := q.ToSQL(&Model{Value: &latestBook{}})
+	r.NoError(err)
+	r.Equal("SELECT books.id, books.title, books.user_id FROM books AS books JOIN users ON users.id = books.user_id AS OF SYSTEM TIME '2020-03-01 08:30:00.5' WHERE user_id = $1", sql)
+
+	sb := q.toSQLBuilder(&Model{Value: &latestBook{}})
+	asOf, query := sb.hoistAsOf(sb.String())
+	r.Equal(" AS OF SYSTEM TIME '2020-03-01 08:30:00.5'", asOf)
+	r.NotContains(query, "AS OF")
+
+	postgres, err := NewConnection(&ConnectionDetails{Dialect: "postgres", Host: "db.local", Database: "pop_test"})
+	r.NoError(err)
+	_, _, err = postgres.Q().AsOf(at).ToSQL(&Model{Value: &latestBook{}})
+	r.EqualError(err, "postgres doesn't support AS OF SYSTEM TIME")
+}
+
+func Test_Cockroach_BlindWrites(t *testing.T) {
+	r := require.New(t)
+
+	c, err := NewConnection(&ConnectionDetails{Dialect: "cockroach", Host: "db.local", Database: "pop_test"})
+	r.NoError(err)
+	dr := c.BlindWrites().DryRun()
+	b := &latestBook{Title: "blind"}
+	r.NoError(dr.Create(b))
+	r.Zero(b.ID)
+	b.ID = 1
+	r.NoError(dr.Update(b))
+	r.NoError(dr.Destroy(b))
+
+	stmts := dr.Statements()
+	r.Len(stmts, 3)
+	r.Equal("INSERT INTO books (title, user_id) VALUES ($1, $2) RETURNING NOTHING", stmts[0].SQL)
+	r.Equal("UPDATE books SET title = $1, user_id = $2 WHERE books.id = $3 RETURNING NOTHING", stmts[1].SQL)
+	r.Equal("DELETE FROM books WHERE books.id = $1 RETURNING NOTHING", stmts[2].SQL)
+}
@@ -31,18 +31,28 @@ func (commonDialect) Lock(fn func() error) error {
 }
 
 func (commonDialect) Quote(key string) string {
-	return fmt.Sprintf(`"%s"`, key)
+	return quoteIdentifier(key, `"`)
 }
 
-func genericCreate(s store, model *Model, cols columns.Columns) error {
+// quoteIdentifier quotes each part of a qualified identifier with q, e.g.
+// "billing.invoices" as `"billing"."invoices"`.
+func quoteIdentifier(key string, q string) string {
+	parts := strings.Split(key, ".")
+	for i, p := range parts {
+		parts[i] = q + p + q
+	}
+	return strings.Join(parts, ".")
+}
+
+func genericCreate(s Store, model *Model, cols columns.Columns) error {
 	keyType := model.PrimaryKeyType()
 	switch keyType {
 	case "int", "int64":
 		var id int64
 		w := cols.Writeable()
 		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", model.TableName(), w.String(), w.SymbolizedString())
-		log(logging.SQL, query)
-		res, err := s.NamedExec(query, model.Value)
+		storeLog(s)(logging.SQL, query)
+		res, err := s.NamedExec(query, model.bindArg())
 		if err != nil {
 			return errors.WithStack(err)
 		}
@@ -55,26 +65,18 @@ func genericCreate(s store, model *Model, cols columns.Columns) error {
 		}
 		return nil
 	case "UUID", "string":
-		if keyType == "UUID" {
-			if model.ID() == emptyUUID {
-				u, err := uuid.NewV4()
-				if err != nil {
-					return errors.WithStack(err)
-				}
-				model.setID(u)
-			}
-		} else if model.ID() == "" {
-			return fmt.Errorf("missing ID value")
+		if err := setClientID(model, keyType); err != nil {
+			return err
 		}
 		w := cols.Writeable()
 		w.Add("id")
 		query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", model.TableName(), w.String(), w.SymbolizedString())
-		log(logging.SQL, query)
+		storeLog(s)(logging.SQL, query)
 		stmt, err := s.PrepareNamed(query)
 		if err != nil {
 			return errors.WithStack(err)
 		}
-		_, err = stmt.Exec(model.Value)
+		_, err = stmt.Exec(model.bindArg())
 		if err != nil {
 			if err := stmt.Close(); err != nil {
 				return errors.WithMessage(err, "failed to close statement")
@@ -86,17 +88,46 @@ func genericCreate(s store, model *Model, cols columns.Columns) error {
 	return errors.Errorf("can not use %s as a primary key type!", keyType)
 }
 
-func genericUpdate(s store, model *Model, cols columns.Columns) error {
+// setClientID generates the id of a model with an UUID key, if it's not
+// set. The string keys must be set.
+func setClientID(model *Model, keyType string) error {
+	if keyType == "UUID" {
+		if model.ID() == emptyUUID {
+			u, err := uuid.NewV4()
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			model.setID(u)
+		}
+	} else if model.ID() == "" {
+		return fmt.Errorf("missing ID value")
+	}
+	return nil
+}
+
+// returningColumns returns the RETURNING list of an insert: the id and
+// the model returning columns.
+func returningColumns(model *Model) string {
+	cols := []string{"id"}
+	for _, c := range model.returning {
+		if c != "id" {
+			cols = append(cols, c)
+		}
+	}
+	return strings.Join(cols, ", ")
+}
+
+func genericUpdate(s Store, model *Model, cols columns.Columns) error {
 	stmt := fmt.Sprintf("UPDATE %s SET %s WHERE %s", model.TableName(), cols.Writeable().UpdateString(), model.whereNamedID())
-	log(logging.SQL, stmt, model.ID())
-	_, err := s.NamedExec(stmt, model.Value)
+	storeLog(s)(logging.SQL, stmt, model.ID())
+	_, err := s.NamedExec(stmt, model.bindArg())
 	if err != nil {
 		return errors.WithStack(err)
 	}
 	return nil
 }
 
-func genericDestroy(s store, model *Model) error {
+func genericDestroy(s Store, model *Model) error {
 	stmt := fmt.Sprintf("DELETE FROM %s WHERE %s", model.TableName(), model.whereID())
 	_, err := genericExec(s, stmt, model.ID())
 	if err != nil {
@@ -105,35 +136,52 @@ func genericDestroy(s store, model *Model) error {
 	return nil
 }
 
-func genericExec(s store, stmt string, args ...interface{}) (sql.Result, error) {
-	log(logging.SQL, stmt, args...)
+// writeLocker is implemented by the dialects serializing their writes, like
+// SQLite: lockWrite runs fn holding the lock of the writes.
+type writeLocker interface {
+	lockWrite(fn func() error) error
+}
+
+func genericExec(s Store, stmt string, args ...interface{}) (sql.Result, error) {
+	storeLog(s)(logging.SQL, stmt, args...)
 	res, err := s.Exec(stmt, args...)
 	return res, errors.WithStack(err)
 }
 
-func genericSelectOne(s store, model *Model, query Query) error {
-	sql, args := query.ToSQL(model)
-	log(logging.SQL, sql, args...)
-	err := s.Get(model.Value, sql, args...)
+func genericSelectOne(s Store, model *Model, query Query) error {
+	sql, args, err := query.ToSQL(model)
+	if err != nil {
+		return err
+	}
+	storeLog(s)(logging.SQL, sql, args...)
+	if query.strictMapping() {
+		return strictSelect(s, model, false, len(query.addColumns) > 0, sql, args...)
+	}
+	if Types.hasFields(model.Value) {
+		return selectRows(s, model, false, sql, args...)
+	}
+	err = s.Get(model.Value, sql, args...)
 	if err != nil {
 		return errors.WithStack(err)
 	}
 	return nil
 }
 
-func genericSelectMany(s store, models *Model, query Query) error {
-	sql, args := query.ToSQL(models)
-	log(logging.SQL, sql, args...)
-	err := s.Select(models.Value, sql, args...)
+func genericSelectMany(s Store, models *Model, query Query) error {
+	sql, args, err := query.ToSQL(models)
 	if err != nil {
-		return errors.WithStack(err)
+		return err
 	}
-	return nil
+	storeLog(s)(logging.SQL, sql, args...)
+	if query.strictMapping() {
+		return strictSelect(s, models, true, len(query.addColumns) > 0, sql, args...)
+	}
+	return selectRows(s, models, true, sql, args...)
 }
 
 func genericLoadSchema(deets *ConnectionDetails, migrationURL string, r io.Reader) error {
 	// Open DB connection on the target DB
-	db, err := sqlx.Open(deets.Dialect, migrationURL)
+	db, err := sqlx.Open(deets.driverName(), migrationURL)
 	if err != nil {
 		return errors.WithMessage(err, fmt.Sprintf("unable to load schema for %s", deets.Database))
 	}
@@ -8,6 +8,8 @@ import (
 	"io"
 	"os/exec"
 	"strings"
+	"sync/atomic"
+	"time"
 
 	// Load MySQL Go driver
 	_mysql "github.com/go-sql-driver/mysql"
@@ -35,6 +37,21 @@ var _ dialect = &mysql{}
 
 type mysql struct {
 	commonDialect
+	// version of the server, read when the connection is opened. The
+	// dialect is shared by the copies and transactions of the connection,
+	// so it's stored atomically.
+	version atomic.Value
+}
+
+// serverVersion returns the version of the server, empty until the
+// connection is opened.
+func (m *mysql) serverVersion() string {
+	v, _ := m.version.Load().(string)
+	return v
+}
+
+func (m *mysql) setServerVersion(v string) {
+	m.version.Store(v)
 }
 
 func (m *mysql) Name() string {
@@ -42,7 +59,7 @@ func (m *mysql) Name() string {
 }
 
 func (mysql) Quote(key string) string {
-	return fmt.Sprintf("`%s`", key)
+	return quoteIdentifier(key, "`")
 }
 
 func (m *mysql) Details() *ConnectionDetails {
@@ -77,34 +94,64 @@ func (m *mysql) urlWithoutDb() string {
 	return strings.Replace(m.URL(), "/"+cd.Database+"?", "/?", 1)
 }
 
+// AfterOpen reads the version of the server. The connection is still
+// opened if it can't be read, e.g. when the server isn't started yet.
+func (m *mysql) AfterOpen(c *Connection) error {
+	v := struct {
+		Version string `db:"version"`
+	}{}
+	if err := c.RawQuery("SELECT VERSION() AS version").First(context.TODO(), &v); err != nil {
+		c.log(logging.Warn, "could not read the server version: %v", err)
+		return nil
+	}
+	m.setServerVersion(v.Version)
+	c.log(logging.Debug, "server: %s", v.Version)
+	return nil
+}
+
+// windowFunctions tells if the server supports the window functions, added
+// in MySQL 8.0 and MariaDB 10.2. It's assumed they're not before the
+// connection is opened.
+func (m *mysql) windowFunctions() bool {
+	version := m.serverVersion()
+	var major, minor int
+	if _, err := fmt.Sscanf(version, "%d.%d", &major, &minor); err != nil {
+		return false
+	}
+	if strings.Contains(version, "MariaDB") {
+		return major > 10 || (major == 10 && minor >= 2)
+	}
+	return major >= 8
+}
+
 func (m *mysql) MigrationURL() string {
 	return m.URL()
 }
 
-func (m *mysql) Create(s store, model *Model, cols columns.Columns) error {
+func (m *mysql) Create(s Store, model *Model, cols columns.Columns) error {
 	return errors.Wrap(genericCreate(s, model, cols), "mysql create")
 }
 
-func (m *mysql) Update(s store, model *Model, cols columns.Columns) error {
+func (m *mysql) Update(s Store, model *Model, cols columns.Columns) error {
 	return errors.Wrap(genericUpdate(s, model, cols), "mysql update")
 }
 
-func (m *mysql) Destroy(s store, model *Model) error {
+func (m *mysql) Destroy(s Store, model *Model) error {
 	return errors.Wrap(genericDestroy(s, model), "mysql destroy")
 }
 
-func (m *mysql) SelectOne(s store, model *Model, query Query) error {
+func (m *mysql) SelectOne(s Store, model *Model, query Query) error {
 	return errors.Wrap(genericSelectOne(s, model, query), "mysql select one")
 }
 
-func (m *mysql) SelectMany(s store, models *Model, query Query) error {
+func (m *mysql) SelectMany(s Store, models *Model, query Query) error {
 	return errors.Wrap(genericSelectMany(s, models, query), "mysql select many")
 }
 
 // CreateDB creates a new database, from the given connection credentials
 func (m *mysql) CreateDB() error {
 	deets := m.ConnectionDetails
-	db, err := sql.Open(deets.Dialect, m.urlWithoutDb())
+	db, err := sql.Open(deets.driverName(), m.urlWithoutDb())
 	if err != nil {
 		return errors.Wrapf(err, "error creating MySQL database %s", deets.Database)
 	}
@@ -125,7 +172,7 @@ func (m *mysql) CreateDB() error {
 // DropDB drops an existing database, from the given connection credentials
 func (m *mysql) DropDB() error {
 	deets := m.ConnectionDetails
-	db, err := sql.Open(deets.Dialect, m.urlWithoutDb())
+	db, err := sql.Open(deets.driverName(), m.urlWithoutDb())
 	if err != nil {
 		return errors.Wrapf(err, "error dropping MySQL database %s", deets.Database)
 	}
@@ -166,12 +213,31 @@ func (m *mysql) LoadSchema(r io.Reader) error {
 }
 
 // TruncateAll truncates all tables for the given connection.
-func (m *mysql) TruncateAll(tx *Connection) error {
+func (m *mysql) TruncateAll(ctx context.Context, tx *Connection) error {
 	var stmts []string
-	err := tx.RawQuery(mysqlTruncate, m.Details().Database).All(context.TODO(), &stmts)
+	err := tx.RawQuery(mysqlTruncate, m.Details().Database, tx.MigrationTableName()).All(ctx, &stmts)
 	if err != nil {
 		return err
 	}
+	return m.truncate(tx, stmts)
+}
+
+// Truncate truncates the given tables, resetting their AUTO_INCREMENT
+// counters if opts.ResetSequence is set: their rows are deleted otherwise,
+// TRUNCATE always resetting them.
+func (m *mysql) Truncate(ctx context.Context, tx *Connection, opts TruncateOptions, tables ...string) error {
+	stmts := make([]string, 0, len(tables))
+	for _, t := range tables {
+		if opts.ResetSequence {
+			stmts = append(stmts, fmt.Sprintf("TRUNCATE TABLE %s;", m.Quote(t)))
+		} else {
+			stmts = append(stmts, fmt.Sprintf("DELETE FROM %s;", m.Quote(t)))
+		}
+	}
+	return m.truncate(tx, stmts)
+}
+
+func (m *mysql) truncate(tx *Connection, stmts []string) error {
 	if len(stmts) == 0 {
 		return nil
 	}
@@ -186,6 +252,33 @@ func (m *mysql) TruncateAll(tx *Connection) error {
 	return tx.RawQuery(qb.String()).Exec()
 }
 
+const mysqlColumnsInfo = `SELECT COLUMN_NAME AS name, COLUMN_TYPE AS type, IS_NULLABLE = 'YES' AS nullable, COLUMN_DEFAULT AS default_value
+FROM information_schema.COLUMNS
+WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND TABLE_NAME = ?
+ORDER BY ORDINAL_POSITION`
+
+const mysqlIndexesInfo = `SELECT INDEX_NAME AS name, NON_UNIQUE = 0 AS is_unique, COLUMN_NAME AS column_name
+FROM information_schema.STATISTICS
+WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND TABLE_NAME = ?
+ORDER BY INDEX_NAME, SEQ_IN_INDEX`
+
+const mysqlForeignKeysInfo = `SELECT COLUMN_NAME AS column_name, REFERENCED_TABLE_NAME AS ref_table, REFERENCED_COLUMN_NAME AS ref_column
+FROM information_schema.KEY_COLUMN_USAGE
+WHERE TABLE_SCHEMA = COALESCE(NULLIF(?, ''), DATABASE()) AND TABLE_NAME = ? AND REFERENCED_TABLE_NAME IS NOT NULL
+ORDER BY ORDINAL_POSITION`
+
+const mysqlTableNames = `SELECT TABLE_NAME FROM information_schema.TABLES
+WHERE TABLE_SCHEMA = DATABASE() AND TABLE_TYPE = 'BASE TABLE'
+ORDER BY TABLE_NAME`
+
+func (m *mysql) TableInfo(ctx context.Context, c *Connection, table string) (*TableInfo, error) {
+	return genericTableInfo(ctx, c.Store, table, schemaTableArgs(table), mysqlColumnsInfo, mysqlIndexesInfo, mysqlForeignKeysInfo)
+}
+
+func (m *mysql) TableNames(ctx context.Context, c *Connection) ([]string, error) {
+	return genericTableNames(c, mysqlTableNames)
+}
+
 func newMySQL(deets *ConnectionDetails) (dialect, error) {
 	cd := &mysql{
 		commonDialect: commonDialect{ConnectionDetails: deets},
@@ -262,4 +355,42 @@ func finalizerMySQL(cd *ConnectionDetails) {
 	}
 }
 
-const mysqlTruncate = "SELECT concat('TRUNCATE TABLE `', TABLE_NAME, '`;') as stmt FROM INFORMATION_SCHEMA.TABLES WHERE table_schema = ? AND table_type <> 'VIEW'"
+const mysqlTruncate = "SELECT concat('TRUNCATE TABLE `', TABLE_NAME, '`;') as stmt FROM INFORMATION_SCHEMA.TABLES WHERE table_schema = ? AND TABLE_NAME <> ? AND table_type <> 'VIEW'"
+
+func (m *mysql) lockMigrations(ctx context.Context, c *Connection, conn *sql.Conn, key string, timeout time.Duration) (func() error, error) {
+	if len(key) > 64 {
+		// MySQL lock names are limited to 64 characters
+		key = fmt.Sprintf("pop_migrations_%x", uint64(lockID(key)))
+	}
+	// GET_LOCK waits for whole seconds
+	secs := int64((timeout + time.Second - 1) / time.Second)
+	var ok sql.NullInt64
+	c.log(logging.SQL, "SELECT GET_LOCK(?, ?)", key, secs)
+	err := conn.QueryRowContext(ctx, "SELECT GET_LOCK(?, ?)", key, secs).Scan(&ok)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if ok.Int64 != 1 {
+		return nil, ErrMigrationInProgress
+	}
+	return func() error {
+		c.log(logging.SQL, "SELECT RELEASE_LOCK(?)", key)
+		_, err := conn.ExecContext(context.Background(), "SELECT RELEASE_LOCK(?)", key)
+		return err
+	}, nil
+}
+
+func (m *mysql) isTransientError(err error) bool {
+	if err == _mysql.ErrInvalidConn {
+		return true
+	}
+	me, ok := err.(*_mysql.MySQLError)
+	if !ok {
+		return false
+	}
+	switch me.Number {
+	case 1053, 1927: // ER_SERVER_SHUTDOWN, ER_CONNECTION_KILLED
+		return true
+	}
+	return false
+}
@@ -20,7 +20,7 @@ func Test_MySQL_URL_As_Is(t *testing.T) {
 	err := cd.Finalize()
 	r.NoError(err)
 
-	m := &mysql{commonDialect{ConnectionDetails: cd}}
+	m := &mysql{commonDialect: commonDialect{ConnectionDetails: cd}}
 	r.Equal("user:pass@(host:port)/dbase?opt=value", m.URL())
 	r.Equal("user:pass@(host:port)/?opt=value", m.urlWithoutDb())
 	r.Equal("user:pass@(host:port)/dbase?opt=value", m.MigrationURL())
@@ -40,7 +40,7 @@ func Test_MySQL_URL_Override_withURL(t *testing.T) {
 	err := cd.Finalize()
 	r.NoError(err)
 
-	m := &mysql{commonDialect{ConnectionDetails: cd}}
+	m := &mysql{commonDialect: commonDialect{ConnectionDetails: cd}}
 	r.Equal("user:pass@(host:port)/dbase?opt=value", m.URL())
 	r.Equal("user:pass@(host:port)/?opt=value", m.urlWithoutDb())
 	r.Equal("user:pass@(host:port)/dbase?opt=value", m.MigrationURL())
@@ -48,7 +48,7 @@ func Test_MySQL_URL_Override_withURL(t *testing.T) {
 
 func Test_MySQL_URL_With_Values(t *testing.T) {
 	r := require.New(t)
-	m := &mysql{commonDialect{ConnectionDetails: &ConnectionDetails{
+	m := &mysql{commonDialect: commonDialect{ConnectionDetails: &ConnectionDetails{
 		Database: "dbase",
 		Host:     "host",
 		Port:     "port",
@@ -63,7 +63,7 @@ func Test_MySQL_URL_With_Values(t *testing.T) {
 
 func Test_MySQL_URL_Without_User(t *testing.T) {
 	r := require.New(t)
-	m := &mysql{commonDialect{ConnectionDetails: &ConnectionDetails{
+	m := &mysql{commonDialect: commonDialect{ConnectionDetails: &ConnectionDetails{
 		Password: "pass",
 		Database: "dbase",
 	}}}
@@ -74,7 +74,7 @@ func Test_MySQL_URL_Without_User(t *testing.T) {
 
 func Test_MySQL_URL_Without_Password(t *testing.T) {
 	r := require.New(t)
-	m := &mysql{commonDialect{ConnectionDetails: &ConnectionDetails{
+	m := &mysql{commonDialect: commonDialect{ConnectionDetails: &ConnectionDetails{
 		User:     "user",
 		Database: "dbase",
 	}}}
@@ -125,7 +125,7 @@ func Test_MySQL_URL_urlParserMySQL_Socket(t *testing.T) {
 
 	// additional test without URL
 	cd.URL = ""
-	m := &mysql{commonDialect{ConnectionDetails: cd}}
+	m := &mysql{commonDialect: commonDialect{ConnectionDetails: cd}}
 	r.True(strings.HasPrefix(m.URL(), "unix(/tmp/socket)/dbase?"))
 	r.True(strings.HasPrefix(m.urlWithoutDb(), "unix(/tmp/socket)/?"))
 }
@@ -141,7 +141,7 @@ func Test_MySQL_URL_urlParserMySQL_Unsupported(t *testing.T) {
 
 func Test_MySQL_Database_Open_Failure(t *testing.T) {
 	r := require.New(t)
-	m := &mysql{commonDialect{ConnectionDetails: &ConnectionDetails{}}}
+	m := &mysql{commonDialect: commonDialect{ConnectionDetails: &ConnectionDetails{}}}
 	err := m.CreateDB()
 	r.Error(err)
 	err = m.DropDB()
@@ -151,7 +151,7 @@ func Test_MySQL_Database_Open_Failure(t *testing.T) {
 func Test_MySQL_FizzTranslator(t *testing.T) {
 	r := require.New(t)
 	cd := &ConnectionDetails{}
-	m := &mysql{commonDialect{ConnectionDetails: cd}}
+	m := &mysql{commonDialect: commonDialect{ConnectionDetails: cd}}
 	ft := m.FizzTranslator()
 	r.IsType(&translators.MySQL{}, ft)
 	r.Implements((*fizz.Translator)(nil), ft)
@@ -159,7 +159,7 @@ func Test_MySQL_FizzTranslator(t *testing.T) {
 
 func Test_MySQL_Finalizer_Default_CD(t *testing.T) {
 	r := require.New(t)
-	m := &mysql{commonDialect{ConnectionDetails: &ConnectionDetails{}}}
+	m := &mysql{commonDialect: commonDialect{ConnectionDetails: &ConnectionDetails{}}}
 	finalizerMySQL(m.ConnectionDetails)
 	r.Equal(hostMySQL, m.ConnectionDetails.Host)
 	r.Equal(portMySQL, m.ConnectionDetails.Port)
@@ -167,7 +167,7 @@ func Test_MySQL_Finalizer_Default_CD(t *testing.T) {
 
 func Test_MySQL_Finalizer_Default_Options(t *testing.T) {
 	r := require.New(t)
-	m := &mysql{commonDialect{ConnectionDetails: &ConnectionDetails{}}}
+	m := &mysql{commonDialect: commonDialect{ConnectionDetails: &ConnectionDetails{}}}
 	finalizerMySQL(m.ConnectionDetails)
 	r.Contains(m.URL(), "multiStatements=true")
 	r.Contains(m.URL(), "parseTime=true")
@@ -177,7 +177,7 @@ func Test_MySQL_Finalizer_Default_Options(t *testing.T) {
 
 func Test_MySQL_Finalizer_Preserve_User_Defined_Options(t *testing.T) {
 	r := require.New(t)
-	m := &mysql{commonDialect{ConnectionDetails: &ConnectionDetails{
+	m := &mysql{commonDialect: commonDialect{ConnectionDetails: &ConnectionDetails{
 		Options: map[string]string{
 			"multiStatements": "false",
 			"parseTime":       "false",
@@ -254,3 +254,4 @@ func Test_MySQL_Deprecated_CD_Encoding(t *testing.T) {
 	r.Equal("myEncoding", cd.Encoding)
 	r.Equal("myEncoding", cd.Options["collation"])
 }
+
@@ -1,19 +1,20 @@
 package pop
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
 	"io"
 	"os/exec"
 	"strings"
 	"sync"
+	"time"
 	"unicode"
 
 	"github.com/gobuffalo/fizz"
 	"github.com/gobuffalo/fizz/translators"
 	"github.com/gobuffalo/pop/columns"
 	"github.com/gobuffalo/pop/logging"
-	"github.com/jmoiron/sqlx"
 	pg "github.com/lib/pq"
 	"github.com/markbates/going/defaults"
 	"github.com/pkg/errors"
@@ -47,7 +48,7 @@ func (p *postgresql) Details() *ConnectionDetails {
 	return p.ConnectionDetails
 }
 
-func (p *postgresql) Create(s store, model *Model, cols columns.Columns) error {
+func (p *postgresql) Create(s Store, model *Model, cols columns.Columns) error {
 	keyType := model.PrimaryKeyType()
 	switch keyType {
 	case "int", "int64":
@@ -58,33 +59,43 @@ func (p *postgresql) Create(s store, model *Model, cols columns.Columns) error {
 		w := cols.Writeable()
 		var query string
 		if len(w.Cols) > 0 {
-			query = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) returning id", model.TableName(), w.String(), w.SymbolizedString())
+			query = fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) returning %s", model.TableName(), w.String(), w.SymbolizedString(), returningColumns(model))
 		} else {
-			query = fmt.Sprintf("INSERT INTO %s DEFAULT VALUES returning id", model.TableName())
+			query = fmt.Sprintf("INSERT INTO %s DEFAULT VALUES returning %s", model.TableName(), returningColumns(model))
 		}
-		log(logging.SQL, query)
+		storeLog(s)(logging.SQL, query)
 		stmt, err := s.PrepareNamed(query)
 		if err != nil {
 			return errors.WithStack(err)
 		}
-		err = stmt.Get(&id, model.Value)
+		returning := len(model.returning) > 0
+		if returning {
+			// scan the id and the returning columns in the model
+			err = stmt.Get(model.Value, model.bindArg())
+		} else {
+			err = stmt.Get(&id, model.bindArg())
+		}
 		if err != nil {
 			if err := stmt.Close(); err != nil {
 				return errors.WithMessage(err, "failed to close statement")
 			}
 			return errors.WithStack(err)
 		}
-		model.setID(id.ID)
+		if returning {
+			model.returning = nil
+		} else {
+			model.setID(id.ID)
+		}
 		return errors.WithMessage(stmt.Close(), "failed to close statement")
 	}
 	return genericCreate(s, model, cols)
 }
 
-func (p *postgresql) Update(s store, model *Model, cols columns.Columns) error {
+func (p *postgresql) Update(s Store, model *Model, cols columns.Columns) error {
 	return genericUpdate(s, model, cols)
 }
 
-func (p *postgresql) Destroy(s store, model *Model) error {
+func (p *postgresql) Destroy(s Store, model *Model) error {
 	stmt := p.TranslateSQL(fmt.Sprintf("DELETE FROM %s WHERE %s", model.TableName(), model.whereID()))
 	_, err := genericExec(s, stmt, model.ID())
 	if err != nil {
@@ -93,18 +104,18 @@ func (p *postgresql) Destroy(s store, model *Model) error {
 	return nil
 }
 
-func (p *postgresql) SelectOne(s store, model *Model, query Query) error {
+func (p *postgresql) SelectOne(s Store, model *Model, query Query) error {
 	return genericSelectOne(s, model, query)
 }
 
-func (p *postgresql) SelectMany(s store, models *Model, query Query) error {
+func (p *postgresql) SelectMany(s Store, models *Model, query Query) error {
 	return genericSelectMany(s, models, query)
 }
 
 func (p *postgresql) CreateDB() error {
 	// createdb -h db -p 5432 -U postgres enterprise_development
 	deets := p.ConnectionDetails
-	db, err := sql.Open(deets.Dialect, p.urlWithoutDb())
+	db, err := sql.Open(deets.driverName(), p.urlWithoutDb())
 	if err != nil {
 		return errors.Wrapf(err, "error creating PostgreSQL database %s", deets.Database)
 	}
@@ -123,7 +134,7 @@ func (p *postgresql) CreateDB() error {
 
 func (p *postgresql) DropDB() error {
 	deets := p.ConnectionDetails
-	db, err := sql.Open(deets.Dialect, p.urlWithoutDb())
+	db, err := sql.Open(deets.driverName(), p.urlWithoutDb())
 	if err != nil {
 		return errors.Wrapf(err, "error dropping PostgreSQL database %s", deets.Database)
 	}
@@ -170,7 +181,7 @@ func (p *postgresql) TranslateSQL(sql string) string {
 	if csql, ok := p.translateCache[sql]; ok {
 		return csql
 	}
-	csql := sqlx.Rebind(sqlx.DOLLAR, sql)
+	csql := NormalizePlaceholders(sql, p.Name())
 
 	p.translateCache[sql] = csql
 	return csql
@@ -190,11 +201,59 @@ func (p *postgresql) LoadSchema(r io.Reader) error {
 	return genericLoadSchema(p.ConnectionDetails, p.MigrationURL(), r)
 }
 
-// TruncateAll truncates all tables for the given connection.
-func (p *postgresql) TruncateAll(tx *Connection) error {
+// TruncateAll truncates all tables of the current schema for the given connection.
+func (p *postgresql) TruncateAll(ctx context.Context, tx *Connection) error {
 	return tx.RawQuery(fmt.Sprintf(pgTruncate, tx.MigrationTableName())).Exec()
 }
 
+// Truncate truncates the given tables, restarting their identity sequences
+// if opts.ResetSequence is set.
+func (p *postgresql) Truncate(ctx context.Context, tx *Connection, opts TruncateOptions, tables ...string) error {
+	identity := "CONTINUE IDENTITY"
+	if opts.ResetSequence {
+		identity = "RESTART IDENTITY"
+	}
+	quoted := make([]string, 0, len(tables))
+	for _, t := range tables {
+		quoted = append(quoted, p.Quote(t))
+	}
+	return tx.RawQuery(fmt.Sprintf("TRUNCATE TABLE %s %s CASCADE", strings.Join(quoted, ", "), identity)).Exec()
+}
+
+// the user-defined types, e.g. citext or the enums, are named after their
+// type rather than USER-DEFINED
+const pgColumnsInfo = `SELECT column_name AS name, CASE WHEN data_type = 'USER-DEFINED' THEN udt_name ELSE data_type END AS type, is_nullable = 'YES' AS nullable, column_default AS default_value
+FROM information_schema.columns
+WHERE table_schema = COALESCE(NULLIF($1, ''), current_schema()) AND table_name = $2
+ORDER BY ordinal_position`
+
+const pgIndexesInfo = `SELECT i.relname AS name, ix.indisunique AS is_unique, a.attname AS column_name
+FROM pg_index ix
+JOIN pg_class t ON t.oid = ix.indrelid
+JOIN pg_class i ON i.oid = ix.indexrelid
+JOIN pg_attribute a ON a.attrelid = t.oid AND a.attnum = ANY(ix.indkey)
+WHERE t.relname = $2 AND t.relnamespace = COALESCE(NULLIF($1, ''), current_schema())::regnamespace
+ORDER BY i.relname, array_position(ix.indkey::int2[], a.attnum)`
+
+const pgForeignKeysInfo = `SELECT kcu.column_name AS column_name, ccu.table_name AS ref_table, ccu.column_name AS ref_column
+FROM information_schema.table_constraints tc
+JOIN information_schema.key_column_usage kcu ON kcu.constraint_name = tc.constraint_name AND kcu.table_schema = tc.table_schema
+JOIN information_schema.constraint_column_usage ccu ON ccu.constraint_name = tc.constraint_name AND ccu.table_schema = tc.table_schema
+WHERE tc.constraint_type = 'FOREIGN KEY' AND tc.table_schema = COALESCE(NULLIF($1, ''), current_schema()) AND tc.table_name = $2
+ORDER BY kcu.ordinal_position`
+
+const pgTableNames = `SELECT table_name FROM information_schema.tables
+WHERE table_schema = current_schema() AND table_type = 'BASE TABLE'
+ORDER BY table_name`
+
+func (p *postgresql) TableInfo(ctx context.Context, c *Connection, table string) (*TableInfo, error) {
+	return genericTableInfo(ctx, c.Store, table, schemaTableArgs(table), pgColumnsInfo, pgIndexesInfo, pgForeignKeysInfo)
+}
+
+func (p *postgresql) TableNames(ctx context.Context, c *Connection) ([]string, error) {
+	return genericTableNames(c, pgTableNames)
+}
+
 func newPostgreSQL(deets *ConnectionDetails) (dialect, error) {
 	cd := &postgresql{
 		commonDialect:  commonDialect{ConnectionDetails: deets},
@@ -263,11 +322,11 @@ BEGIN
    FOR _sch, _tbl IN
       SELECT schemaname, tablename
       FROM   pg_tables
-      WHERE  tablename <> '%s' AND schemaname NOT IN ('pg_catalog', 'information_schema') AND tableowner = current_user
+      WHERE  tablename <> '%s' AND schemaname = current_schema() AND tableowner = current_user
    LOOP
       --RAISE ERROR '%%',
       EXECUTE  -- dangerous, test before you execute!
-         format('TRUNCATE TABLE %%I.%%I CASCADE', _sch, _tbl);
+         format('TRUNCATE TABLE %%I.%%I RESTART IDENTITY CASCADE', _sch, _tbl);
    END LOOP;
 END
 $func$;`
@@ -385,3 +444,70 @@ func parseOpts(name string, o values) error {
 
 	return nil
 }
+
+func (p *postgresql) switchSchemaStatement(name string) string {
+	return "SET LOCAL search_path TO " + p.Quote(name)
+}
+
+func (p *postgresql) withApplicationName(name string) (dialect, error) {
+	deets, err := withApplicationName(p.ConnectionDetails, name)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return newPostgreSQL(deets)
+}
+
+func (p *postgresql) lockMigrations(ctx context.Context, c *Connection, conn *sql.Conn, key string, timeout time.Duration) (func() error, error) {
+	id := lockID(key)
+	err := pollLock(ctx, timeout, func() (bool, error) {
+		var ok bool
+		c.log(logging.SQL, "SELECT pg_try_advisory_lock($1)", id)
+		err := conn.QueryRowContext(ctx, "SELECT pg_try_advisory_lock($1)", id).Scan(&ok)
+		return ok, err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return func() error {
+		c.log(logging.SQL, "SELECT pg_advisory_unlock($1)", id)
+		_, err := conn.ExecContext(context.Background(), "SELECT pg_advisory_unlock($1)", id)
+		return err
+	}, nil
+}
+
+// postgresErrorCode returns the SQLSTATE code of a PostgreSQL error, of
+// lib/pq or of the drivers having a SQLState method, like pgx.
+func postgresErrorCode(err error) (string, bool) {
+	switch pe := err.(type) {
+	case *pg.Error:
+		return string(pe.Code), true
+	case interface{ SQLState() string }:
+		return pe.SQLState(), true
+	}
+	return "", false
+}
+
+// isPostgresTransientError tells if err is a PostgreSQL error caused by a
+// lost connection, or a server shutting down. The codes are also
+// considered transient.
+func isPostgresTransientError(err error, codes ...string) bool {
+	code, ok := postgresErrorCode(err)
+	if !ok {
+		return false
+	}
+	for _, c := range codes {
+		if code == c {
+			return true
+		}
+	}
+	switch code {
+	case "57P01", "57P02", "57P03": // admin_shutdown, crash_shutdown, cannot_connect_now
+		return true
+	}
+	// connection_exception
+	return strings.HasPrefix(code, "08")
+}
+
+func (p *postgresql) isTransientError(err error) bool {
+	return isPostgresTransientError(err)
+}
@@ -79,3 +79,10 @@ func Test_PostgreSQL_Connection_String_Failure(t *testing.T) {
 	r.Error(err)
 	r.Equal("postgres", cd.Dialect)
 }
+
+func Test_PostgreSQL_switchSchemaStatement(t *testing.T) {
+	r := require.New(t)
+
+	p := &postgresql{}
+	r.Equal(`SET LOCAL search_path TO "tenant_1"`, p.switchSchemaStatement("tenant_1"))
+}
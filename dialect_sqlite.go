@@ -6,9 +6,11 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"net/url"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -18,7 +20,7 @@ import (
 	"github.com/gobuffalo/pop/columns"
 	"github.com/gobuffalo/pop/logging"
 	"github.com/markbates/going/defaults"
-	_ "github.com/mattn/go-sqlite3" // Load SQLite3 CGo driver
+	sqlite3 "github.com/mattn/go-sqlite3" // Load SQLite3 CGo driver
 	"github.com/pkg/errors"
 )
 
@@ -51,11 +53,86 @@ func (m *sqlite) URL() string {
 	return m.ConnectionDetails.Database + "?_busy_timeout=5000"
 }
 
+// configureDialTimeout sets the busy timeout of the SQLite driver, in
+// milliseconds: SQLite has no network connection, but waits for the locks
+// of the other connections on the database file.
+func (m *sqlite) configureDialTimeout(dsn string, timeout time.Duration) (string, error) {
+	return sqliteWithParam(dsn, "_busy_timeout", strconv.FormatInt(int64(timeout/time.Millisecond), 10))
+}
+
+// configureUTC sets the location of the times parsed by the SQLite driver.
+func (m *sqlite) configureUTC(dsn string) (string, error) {
+	return sqliteWithParam(dsn, "_loc", "UTC")
+}
+
+// sessionStatements sets the SQLitePragmas on each new connection.
+func (m *sqlite) sessionStatements() ([]string, error) {
+	return pragmaStatements(m.Details().SQLitePragmas)
+}
+
+// constraintError translates the constraint violations of SQLite, whose
+// messages name the columns, e.g. "UNIQUE constraint failed: books.isbn".
+func (m *sqlite) constraintError(err error) (*ConstraintError, bool) {
+	se, ok := err.(sqlite3.Error)
+	if !ok || se.Code != sqlite3.ErrConstraint {
+		return nil, false
+	}
+	ce := &ConstraintError{}
+	switch se.ExtendedCode {
+	case sqlite3.ErrConstraintUnique, sqlite3.ErrConstraintPrimaryKey:
+		ce.Kind = ConstraintUnique
+	case sqlite3.ErrConstraintForeignKey:
+		ce.Kind = ConstraintForeignKey
+	case sqlite3.ErrConstraintNotNull:
+		ce.Kind = ConstraintNotNull
+	case sqlite3.ErrConstraintCheck:
+		ce.Kind = ConstraintCheck
+	default:
+		return nil, false
+	}
+	i := strings.Index(se.Error(), "constraint failed: ")
+	if i < 0 {
+		return ce, true
+	}
+	detail := se.Error()[i+len("constraint failed: "):]
+	if ce.Kind == ConstraintCheck {
+		ce.Constraint = detail
+		return ce, true
+	}
+	if j := strings.Index(detail, "."); j > 0 {
+		ce.Table = detail[:j]
+	}
+	ce.Columns = splitColumns(detail)
+	return ce, true
+}
+
+// sqliteWithParam returns the dsn, a file name with an optional query
+// string, with the given parameter.
+func sqliteWithParam(dsn string, key string, value string) (string, error) {
+	path, query := dsn, ""
+	if i := strings.Index(dsn, "?"); i >= 0 {
+		path, query = dsn[:i], dsn[i+1:]
+	}
+	q, err := url.ParseQuery(query)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	q.Set(key, value)
+	return path + "?" + q.Encode(), nil
+}
+
+// windowFunctions tells if the SQLite library supports the window
+// functions, added in 3.25.0.
+func (m *sqlite) windowFunctions() bool {
+	_, version, _ := sqlite3.Version()
+	return version >= 3025000
+}
+
 func (m *sqlite) MigrationURL() string {
 	return m.ConnectionDetails.URL
 }
 
-func (m *sqlite) Create(s store, model *Model, cols columns.Columns) error {
+func (m *sqlite) Create(s Store, model *Model, cols columns.Columns) error {
 	return m.locker(m.smGil, func() error {
 		keyType := model.PrimaryKeyType()
 		switch keyType {
@@ -68,8 +145,8 @@ func (m *sqlite) Create(s store, model *Model, cols columns.Columns) error {
 			} else {
 				query = fmt.Sprintf("INSERT INTO %s DEFAULT VALUES", model.TableName())
 			}
-			log(logging.SQL, query)
-			res, err := s.NamedExec(query, model.Value)
+			storeLog(s)(logging.SQL, query)
+			res, err := s.NamedExec(query, model.bindArg())
 			if err != nil {
 				return errors.WithStack(err)
 			}
@@ -86,25 +163,32 @@ func (m *sqlite) Create(s store, model *Model, cols columns.Columns) error {
 	})
 }
 
-func (m *sqlite) Update(s store, model *Model, cols columns.Columns) error {
+func (m *sqlite) Update(s Store, model *Model, cols columns.Columns) error {
 	return m.locker(m.smGil, func() error {
 		return errors.Wrap(genericUpdate(s, model, cols), "sqlite update")
 	})
 }
 
-func (m *sqlite) Destroy(s store, model *Model) error {
+// upsert needs SQLite 3.24, which added ON CONFLICT DO UPDATE.
+func (m *sqlite) upsert(s Store, model *Model, cols columns.Columns, conflict []string) error {
+	return m.locker(m.smGil, func() error {
+		return errors.Wrap(genericUpsert(s, model, cols, conflict, false), "sqlite upsert")
+	})
+}
+
+func (m *sqlite) Destroy(s Store, model *Model) error {
 	return m.locker(m.smGil, func() error {
 		return errors.Wrap(genericDestroy(s, model), "sqlite destroy")
 	})
 }
 
-func (m *sqlite) SelectOne(s store, model *Model, query Query) error {
+func (m *sqlite) SelectOne(s Store, model *Model, query Query) error {
 	return m.locker(m.smGil, func() error {
 		return errors.Wrap(genericSelectOne(s, model, query), "sqlite select one")
 	})
 }
 
-func (m *sqlite) SelectMany(s store, models *Model, query Query) error {
+func (m *sqlite) SelectMany(s Store, models *Model, query Query) error {
 	return m.locker(m.smGil, func() error {
 		return errors.Wrap(genericSelectMany(s, models, query), "sqlite select many")
 	})
@@ -114,6 +198,10 @@ func (m *sqlite) Lock(fn func() error) error {
 	return m.locker(m.gil, fn)
 }
 
+func (m *sqlite) lockWrite(fn func() error) error {
+	return m.locker(m.smGil, fn)
+}
+
 func (m *sqlite) locker(l *sync.Mutex, fn func() error) error {
 	if defaults.String(m.Details().Options["lock"], "true") == "true" {
 		defer l.Unlock()
@@ -191,24 +279,83 @@ func (m *sqlite) LoadSchema(r io.Reader) error {
 	return nil
 }
 
-func (m *sqlite) TruncateAll(tx *Connection) error {
-	const tableNames = `SELECT name FROM sqlite_master WHERE type = "table"`
+// TruncateAll deletes all rows of all tables for the given connection,
+// except for the migrations table.
+func (m *sqlite) TruncateAll(ctx context.Context, tx *Connection) error {
+	const tableNames = `SELECT name FROM sqlite_master WHERE type = "table" AND name NOT IN ("sqlite_sequence", ?)`
 	names := []struct {
 		Name string `db:"name"`
 	}{}
 
-	err := tx.RawQuery(tableNames).All(context.TODO(), &names)
+	err := tx.RawQuery(tableNames, tx.MigrationTableName()).All(ctx, &names)
 	if err != nil {
 		return err
 	}
 	if len(names) == 0 {
 		return nil
 	}
-	stmts := []string{}
+	tables := make([]string, 0, len(names))
 	for _, n := range names {
-		stmts = append(stmts, fmt.Sprintf("DELETE FROM %s", n.Name))
+		tables = append(tables, n.Name)
 	}
-	return tx.RawQuery(strings.Join(stmts, "; ")).Exec()
+	return m.Truncate(ctx, tx, TruncateOptions{ResetSequence: true}, tables...)
+}
+
+// Truncate deletes all rows of the given tables. SQLite doesn't support
+// TRUNCATE, so the AUTOINCREMENT counters are reset through sqlite_sequence,
+// if opts.ResetSequence is set.
+func (m *sqlite) Truncate(ctx context.Context, tx *Connection, opts TruncateOptions, tables ...string) error {
+	const hasSequence = `SELECT COUNT(*) FROM sqlite_master WHERE type = "table" AND name = "sqlite_sequence"`
+	return m.locker(m.smGil, func() error {
+		stmts := make([]string, 0, len(tables)+1)
+		quoted := make([]string, 0, len(tables))
+		for _, t := range tables {
+			stmts = append(stmts, fmt.Sprintf("DELETE FROM %s", m.Quote(t)))
+			quoted = append(quoted, fmt.Sprintf("'%s'", t))
+		}
+
+		var seq int
+		if opts.ResetSequence {
+			tx.log(logging.SQL, hasSequence)
+			if err := tx.Store.Get(&seq, hasSequence); err != nil {
+				return errors.Wrap(err, "sqlite truncate")
+			}
+		}
+		if seq > 0 {
+			stmts = append(stmts, fmt.Sprintf("DELETE FROM sqlite_sequence WHERE name IN (%s)", strings.Join(quoted, ", ")))
+		}
+
+		stmt := strings.Join(stmts, "; ")
+		tx.log(logging.SQL, stmt)
+		_, err := tx.Store.Exec(stmt)
+		return errors.Wrap(err, "sqlite truncate")
+	})
+}
+
+// SQLite reports primary key columns as nullable, unless explicitly declared NOT NULL.
+const sqliteColumnsInfo = `SELECT name, type, "notnull" = 0 AND pk = 0 AS nullable, dflt_value AS default_value
+FROM pragma_table_info(?)
+ORDER BY cid`
+
+const sqliteIndexesInfo = `SELECT il.name AS name, il."unique" AS is_unique, ii.name AS column_name
+FROM pragma_index_list(?) AS il, pragma_index_info(il.name) AS ii
+ORDER BY il.name, ii.seqno`
+
+// SQLite leaves the referenced column empty when it's the primary key.
+const sqliteForeignKeysInfo = `SELECT "from" AS column_name, "table" AS ref_table, COALESCE("to", 'id') AS ref_column
+FROM pragma_foreign_key_list(?)
+ORDER BY id, seq`
+
+const sqliteTableNames = `SELECT name FROM sqlite_master
+WHERE type = 'table' AND name NOT LIKE 'sqlite_%'
+ORDER BY name`
+
+func (m *sqlite) TableInfo(ctx context.Context, c *Connection, table string) (*TableInfo, error) {
+	return genericTableInfo(ctx, c.Store, table, []interface{}{table}, sqliteColumnsInfo, sqliteIndexesInfo, sqliteForeignKeysInfo)
+}
+
+func (m *sqlite) TableNames(ctx context.Context, c *Connection) ([]string, error) {
+	return genericTableNames(c, sqliteTableNames)
 }
 
 func newSQLite(deets *ConnectionDetails) (dialect, error) {
@@ -1,10 +1,12 @@
 package pop
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 )
@@ -107,3 +109,115 @@ func Test_ConnectionDetails_FinalizeOSPath(t *testing.T) {
 	r.Equal("sqlite3", cd.Dialect)
 	r.Equal(p, cd.Database)
 }
+
+func Test_SQLite_Pragmas(t *testing.T) {
+	r := require.New(t)
+	d, err := ioutil.TempDir("", "")
+	r.NoError(err)
+	defer os.RemoveAll(d)
+
+	c, err := NewConnection(&ConnectionDetails{
+		Dialect:       "sqlite3",
+		Database:      filepath.Join(d, "pragmas.sqlite"),
+		Pool:          2,
+		SQLitePragmas: SQLiteWAL(),
+	})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+
+	// the pragmas are set on all the connections of the pool
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		tx, err := c.NewTransactionContext(ctx)
+		r.NoError(err)
+		defer tx.TX.Rollback()
+
+		var mode string
+		var synchronous, foreignKeys int
+		r.NoError(tx.QueryRow(ctx, "PRAGMA journal_mode").Scan(&mode))
+		r.NoError(tx.QueryRow(ctx, "PRAGMA synchronous").Scan(&synchronous))
+		r.NoError(tx.QueryRow(ctx, "PRAGMA foreign_keys").Scan(&foreignKeys))
+		r.Equal("wal", mode)
+		r.Equal(1, synchronous)
+		r.Equal(1, foreignKeys)
+	}
+
+	_, err = pragmaStatements(map[string]string{"journal_mode": "WAL; DROP TABLE users"})
+	r.Error(err)
+	stmts, err := pragmaStatements(map[string]string{"main.cache_size": "-2000", "foreign_keys": "ON"})
+	r.NoError(err)
+	r.Equal([]string{"PRAGMA foreign_keys = ON", "PRAGMA main.cache_size = -2000"}, stmts)
+}
+
+type schemaPost struct {
+	ID             int       `db:"id"`
+	SchemaAuthorID int       `db:"schema_author_id"`
+	Title          string    `db:"title"`
+	CreatedAt      time.Time `db:"created_at"`
+	UpdatedAt      time.Time `db:"updated_at"`
+}
+
+type schemaAuthor struct {
+	ID        int          `db:"id"`
+	Name      string       `db:"name"`
+	Posts     []schemaPost `has_many:"schema_posts"`
+	CreatedAt time.Time    `db:"created_at"`
+	UpdatedAt time.Time    `db:"updated_at"`
+}
+
+func Test_SQLite_Schema(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	d, err := ioutil.TempDir("", "")
+	r.NoError(err)
+	defer os.RemoveAll(d)
+
+	c, err := NewConnection(&ConnectionDetails{
+		Dialect:  "sqlite3",
+		Database: filepath.Join(d, "schema.sqlite"),
+		Schema:   "main",
+	})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+	r.NoError(c.RawQuery(`CREATE TABLE schema_authors (id integer primary key autoincrement, name text, created_at DATETIME NOT NULL, updated_at DATETIME NOT NULL)`).Exec())
+	r.NoError(c.RawQuery(`CREATE TABLE schema_posts (id integer primary key autoincrement, schema_author_id integer, title text, created_at DATETIME NOT NULL, updated_at DATETIME NOT NULL)`).Exec())
+
+	var sqls []string
+	c.Observe(func(ctx context.Context, info QueryInfo) {
+		sqls = append(sqls, info.SQL)
+	})
+
+	a := &schemaAuthor{Name: "Mark"}
+	r.NoError(c.Create(a))
+	r.Contains(sqls[0], "INSERT INTO main.schema_authors")
+	r.NoError(c.Create(&schemaPost{SchemaAuthorID: a.ID, Title: "Pop"}))
+
+	found := &schemaAuthor{}
+	r.NoError(c.Eager().Find(ctx, found, a.ID))
+	r.Equal("Mark", found.Name)
+	r.Len(found.Posts, 1)
+	r.Contains(sqls[2], "FROM main.schema_authors AS schema_authors")
+
+	// the unqualified aliases still work
+	n, err := c.Where("schema_authors.name = ?", "Mark").CountByField(&schemaAuthor{}, "schema_authors.id")
+	r.NoError(err)
+	r.Equal(1, n)
+	ok, err := c.Where("schema_authors.id = ?", a.ID).Exists(&schemaAuthor{})
+	r.NoError(err)
+	r.True(ok)
+
+	posts := []schemaPost{}
+	r.NoError(c.BelongsTo(a).All(ctx, &posts))
+	r.Len(posts, 1)
+
+	a.Name = "Ringo"
+	r.NoError(c.Update(a))
+	r.NoError(c.Destroy(&posts))
+	r.NoError(c.Destroy(a))
+	n, err = c.Count(&schemaAuthor{})
+	r.NoError(err)
+	r.Equal(0, n)
+	r.Contains(sqls[len(sqls)-2], "DELETE FROM main.schema_authors")
+}
@@ -32,3 +32,12 @@ func Test_genericDumpSchema(t *testing.T) {
 		})
 	}
 }
+
+func Test_Dialect_Quote(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal(`"users"`, commonDialect{}.Quote("users"))
+	r.Equal(`"billing"."invoices"`, commonDialect{}.Quote("billing.invoices"))
+	r.Equal("`users`", mysql{}.Quote("users"))
+	r.Equal("`billing`.`invoices`", mysql{}.Quote("billing.invoices"))
+}
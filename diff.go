@@ -0,0 +1,129 @@
+package pop
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// DiffOption configures a Diff.
+type DiffOption func(*diffOptions)
+
+type diffOptions struct {
+	timeTolerance time.Duration
+}
+
+// DiffTimeTolerance considers the times equal when they differ by d at
+// most, e.g. a time truncated to the microsecond by the database and the
+// time it was set to.
+func DiffTimeTolerance(d time.Duration) DiffOption {
+	return func(o *diffOptions) {
+		o.timeTolerance = d
+	}
+}
+
+// Diff returns the columns whose values differ between the models a and b,
+// typically a model before and after it's changed, with their values in a
+// and b:
+//
+//	before := user
+//	user.Email = "mark@example.com"
+//	pop.Diff(&before, &user) // map[email:map[from:old@example.com to:mark@example.com]]
+//
+// The columns are named by the db tags of the fields, the fields of the
+// embedded structs included, and the fields tagged db:"-" are skipped. The
+// values are compared as written to the database, e.g. two invalid
+// nulls.String are equal, and the times with time.Time.Equal, or within
+// the DiffTimeTolerance. A column of only one of the models is compared to
+// nil. Diff returns nil if a or b isn't a struct or a pointer to a struct.
+//
+// With the row of the model read by its BeforeUpdate callback, the changes
+// of an update can be reported by its AfterUpdate callback:
+//
+//	type User struct {
+//		ID     int    `db:"id"`
+//		Email  string `db:"email"`
+//		before *User  `db:"-"`
+//	}
+//
+//	func (u *User) BeforeUpdate(tx *pop.Connection) error {
+//		u.before = &User{}
+//		return tx.Find(context.Background(), u.before, u.ID)
+//	}
+//
+//	func (u *User) AfterUpdate(tx *pop.Connection) error {
+//		return audit.Log("users", u.ID, pop.Diff(u.before, u))
+//	}
+func Diff(a, b interface{}, opts ...DiffOption) map[string]interface{} {
+	var o diffOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	av, bv := reflect.Indirect(reflect.ValueOf(a)), reflect.Indirect(reflect.ValueOf(b))
+	if av.Kind() != reflect.Struct || bv.Kind() != reflect.Struct {
+		return nil
+	}
+	from, to := diffColumns(av), diffColumns(bv)
+	for col := range to {
+		if _, ok := from[col]; !ok {
+			from[col] = nil
+		}
+	}
+	diff := map[string]interface{}{}
+	for col, old := range from {
+		if o.equal(old, to[col]) {
+			continue
+		}
+		diff[col] = map[string]interface{}{"from": old, "to": to[col]}
+	}
+	return diff
+}
+
+// diffColumns returns the values of the columns of the struct v, nil for
+// the fields of a nil embedded struct.
+func diffColumns(v reflect.Value) map[string]interface{} {
+	values := map[string]interface{}{}
+	for _, fi := range strictMapper.TypeMap(v.Type()).Index {
+		if fi.Embedded || strings.Contains(fi.Path, ".") {
+			continue
+		}
+		f, err := v.FieldByIndexErr(fi.Index)
+		if err != nil {
+			values[fi.Name] = nil
+			continue
+		}
+		values[fi.Name] = f.Interface()
+	}
+	return values
+}
+
+// equal tells if the column values a and b are equal, as written to the
+// database, with the time tolerance of o.
+func (o diffOptions) equal(a, b interface{}) bool {
+	a, b = diffValue(a), diffValue(b)
+	if ta, ok := a.(time.Time); ok {
+		tb, ok := b.(time.Time)
+		if !ok {
+			return false
+		}
+		d := ta.Sub(tb)
+		if d < 0 {
+			d = -d
+		}
+		return d <= o.timeTolerance
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// diffValue returns the column value v as written to the database, nil for
+// a nil pointer.
+func diffValue(v interface{}) interface{} {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		v = rv.Elem().Interface()
+	}
+	return dbValue(v)
+}
@@ -0,0 +1,68 @@
+package pop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+type diffTimestamps struct {
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type diffUser struct {
+	diffTimestamps
+	ID       int          `db:"id"`
+	Email    string       `db:"email"`
+	Bio      nulls.String `db:"bio"`
+	Nickname *string      `db:"nickname"`
+	Password string       `db:"-"`
+}
+
+func Test_Diff(t *testing.T) {
+	r := require.New(t)
+
+	now := time.Now()
+	before := diffUser{ID: 1, Email: "old@example.com", Password: "secret"}
+	before.UpdatedAt = now
+	after := before
+
+	r.Empty(Diff(&before, &after))
+	r.Nil(Diff(before, 1))
+
+	nick := "mark"
+	after.Email = "mark@example.com"
+	after.Password = "changed"
+	after.Nickname = &nick
+	after.UpdatedAt = now.Add(time.Millisecond)
+	r.Equal(map[string]interface{}{
+		"email":      map[string]interface{}{"from": "old@example.com", "to": "mark@example.com"},
+		"nickname":   map[string]interface{}{"from": (*string)(nil), "to": &nick},
+		"updated_at": map[string]interface{}{"from": now, "to": now.Add(time.Millisecond)},
+	}, Diff(before, &after))
+
+	diff := Diff(&before, &after, DiffTimeTolerance(time.Second))
+	r.NotContains(diff, "updated_at")
+	r.Contains(diff, "email")
+
+	// two invalid nulls are equal, whatever their strings
+	after = before
+	after.Bio.String = "ignored"
+	r.Empty(Diff(&before, &after))
+	after.Bio = nulls.NewString("")
+	r.Equal(map[string]interface{}{
+		"bio": map[string]interface{}{"from": nulls.String{}, "to": nulls.NewString("")},
+	}, Diff(&before, &after))
+
+	r.Equal(map[string]interface{}{
+		"email": map[string]interface{}{"from": nil, "to": "old@example.com"},
+	}, Diff(&struct {
+		ID int `db:"id"`
+	}{ID: 1}, &struct {
+		ID    int    `db:"id"`
+		Email string `db:"email"`
+	}{ID: 1, Email: "old@example.com"}))
+}
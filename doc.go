@@ -9,5 +9,12 @@ Pop, by default, follows conventions that were defined by the ActiveRecord Ruby
 * If there is a timestamp column named "created_at", "CreatedAt" on the `struct`, it will be set with the current time when the record is created.
 * If there is a timestamp column named "updated_at", "UpdatedAt" on the `struct`, it will be set with the current time when the record is updated.
 * Default databases are lowercase, underscored versions of the `struct` name. Examples: User{} is "users", FooBar{} is "foo_bars", etc...
+
+CockroachDB is supported by the "cockroach" dialect, which speaks the PostgreSQL protocol and mostly runs the same SQL. It differs from the "postgres" dialect on these points:
+
+* The transactions failing with a serialization failure (SQLSTATE 40001) are retried by Connection.Transaction, and the statements run outside of a transaction by the RetryPolicy.
+* Connection.BlindWrites runs the writes with RETURNING NOTHING, when their results aren't needed.
+* Query.AsOf reads the rows at a past time with AS OF SYSTEM TIME, e.g. for follower reads.
+* The migrations are locked with a row of a lock table, CockroachDB having no advisory locks.
 */
 package pop
@@ -0,0 +1,318 @@
+package pop
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// ErrDryRun is returned by the selects of a dry run connection, when
+// DryRunOptions.RejectSelects is set.
+var ErrDryRun = errors.New("select not run in dry run mode")
+
+// DryRunOptions configures a dry run connection.
+type DryRunOptions struct {
+	// RejectSelects makes the selects fail with ErrDryRun, instead of
+	// running them against the database.
+	RejectSelects bool
+}
+
+// Statement is a statement recorded by a dry run connection.
+type Statement struct {
+	SQL  string
+	Args []interface{}
+	// Op is the SQL verb of the statement, e.g. "INSERT" or "COMMIT".
+	Op string
+	// Table is the table modified by the statement, if any.
+	Table string
+}
+
+// DryRun returns a connection recording the statements modifying the
+// database, instead of running them: see Connection.Statements. The
+// transactions record BEGIN and COMMIT/ROLLBACK statements. The selects
+// still run against the database, unless DryRunOptions.RejectSelects is set.
+//
+// The generated ids aren't known, models created in dry run get a zero id.
+//
+//	dr := c.DryRun()
+//	err := dr.Create(&user)
+//	for _, s := range dr.Statements() {
+//		fmt.Println(s.SQL, s.Args)
+//	}
+func (c *Connection) DryRun(opts ...DryRunOptions) *Connection {
+	ds := &dryRunStore{
+		reads: c.Store,
+		rec:   &statementRecorder{},
+	}
+	if len(opts) > 0 {
+		ds.opts = opts[0]
+	}
+	db := sql.OpenDB(dryRunConnector{ds.rec})
+	ds.Store = newDB(sqlx.NewDb(db, c.Dialect.Details().driverName()))
+
+	cn := c.copy()
+	cn.Store = withLogger(ds, c.logger, c.details())
+	cn.TX = nil
+	return cn
+}
+
+// Statements returns the statements recorded by a dry run connection.
+// It's empty for the other connections.
+func (c *Connection) Statements() []Statement {
+	ds, ok := unwrapLogger(c.Store).(*dryRunStore)
+	if !ok {
+		return nil
+	}
+	return ds.rec.statements()
+}
+
+// dryRunStore runs the selects against the reads store, and the other
+// statements against a store recording them.
+type dryRunStore struct {
+	Store
+	reads Store
+	opts  DryRunOptions
+	rec   *statementRecorder
+}
+
+// withWrites returns a copy of the store, recording with writes.
+func (s *dryRunStore) withWrites(writes Store) *dryRunStore {
+	ds := *s
+	ds.Store = writes
+	return &ds
+}
+
+func (s *dryRunStore) canRead() error {
+	if s.opts.RejectSelects {
+		return ErrDryRun
+	}
+	if s.reads == nil {
+		return errors.New("dry run selects need an open connection")
+	}
+	return nil
+}
+
+func (s *dryRunStore) Select(dest interface{}, query string, args ...interface{}) error {
+	if err := s.canRead(); err != nil {
+		return err
+	}
+	return s.reads.Select(dest, query, args...)
+}
+
+func (s *dryRunStore) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	if err := s.canRead(); err != nil {
+		return err
+	}
+	return s.reads.SelectContext(ctx, dest, query, args...)
+}
+
+func (s *dryRunStore) Get(dest interface{}, query string, args ...interface{}) error {
+	if err := s.canRead(); err != nil {
+		return err
+	}
+	return s.reads.Get(dest, query, args...)
+}
+
+func (s *dryRunStore) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	if err := s.canRead(); err != nil {
+		return nil, err
+	}
+	return s.reads.Queryx(query, args...)
+}
+
+func (s *dryRunStore) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if err := s.canRead(); err != nil {
+		return errRow(err)
+	}
+	return s.reads.QueryRowContext(ctx, query, args...)
+}
+
+// errRow returns a row whose Scan fails with err: sql.Row can't be built
+// outside of database/sql, so it's read from a database failing to connect.
+func errRow(err error) *sql.Row {
+	db := sql.OpenDB(errConnector{err})
+	defer db.Close()
+	return db.QueryRow("")
+}
+
+type errConnector struct {
+	err error
+}
+
+func (c errConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, c.err
+}
+
+func (c errConnector) Driver() driver.Driver {
+	return dryRunDriver{}
+}
+
+var dryRunTableX = regexp.MustCompile(`(?i)^\s*(?:INSERT\s+INTO|UPDATE|DELETE\s+FROM|TRUNCATE(?:\s+TABLE)?|(?:CREATE|DROP|ALTER)\s+TABLE(?:\s+IF(?:\s+NOT)?\s+EXISTS)?)\s+([^\s(]+)`)
+
+type statementRecorder struct {
+	mu    sync.Mutex
+	stmts []Statement
+}
+
+func (r *statementRecorder) record(query string, args []interface{}) {
+	s := Statement{SQL: query, Args: args}
+	if f := strings.Fields(query); len(f) > 0 {
+		s.Op = strings.ToUpper(f[0])
+	}
+	if m := dryRunTableX.FindStringSubmatch(query); m != nil {
+		s.Table = strings.Trim(m[1], "\"`")
+	}
+	r.mu.Lock()
+	r.stmts = append(r.stmts, s)
+	r.mu.Unlock()
+}
+
+func (r *statementRecorder) statements() []Statement {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]Statement(nil), r.stmts...)
+}
+
+// dryRunConnector is a database/sql connector which records the statements
+// instead of running them.
+type dryRunConnector struct {
+	rec *statementRecorder
+}
+
+func (c dryRunConnector) Connect(context.Context) (driver.Conn, error) {
+	return dryRunConn(c), nil
+}
+
+func (c dryRunConnector) Driver() driver.Driver {
+	return dryRunDriver{}
+}
+
+type dryRunDriver struct{}
+
+func (dryRunDriver) Open(string) (driver.Conn, error) {
+	return nil, errors.New("the dry run driver can't be opened by name")
+}
+
+type dryRunConn dryRunConnector
+
+func (c dryRunConn) Prepare(query string) (driver.Stmt, error) {
+	return dryRunStmt{rec: c.rec, query: query}, nil
+}
+
+func (c dryRunConn) Close() error {
+	return nil
+}
+
+func (c dryRunConn) Begin() (driver.Tx, error) {
+	c.rec.record("BEGIN", nil)
+	return dryRunTx(c), nil
+}
+
+// CheckNamedValue accepts any argument, so they're recorded as given.
+func (c dryRunConn) CheckNamedValue(nv *driver.NamedValue) error {
+	if v, ok := nv.Value.(driver.Valuer); ok {
+		var err error
+		nv.Value, err = v.Value()
+		return err
+	}
+	return nil
+}
+
+type dryRunTx dryRunConnector
+
+func (tx dryRunTx) Commit() error {
+	tx.rec.record("COMMIT", nil)
+	return nil
+}
+
+func (tx dryRunTx) Rollback() error {
+	tx.rec.record("ROLLBACK", nil)
+	return nil
+}
+
+type dryRunStmt struct {
+	rec   *statementRecorder
+	query string
+}
+
+func (s dryRunStmt) Close() error {
+	return nil
+}
+
+func (s dryRunStmt) NumInput() int {
+	return -1
+}
+
+func (s dryRunStmt) Exec(args []driver.Value) (driver.Result, error) {
+	s.rec.record(s.query, driverArgs(args))
+	return dryRunResult{}, nil
+}
+
+// dryRunResult reports no affected rows and a zero id.
+type dryRunResult struct{}
+
+func (dryRunResult) LastInsertId() (int64, error) {
+	return 0, nil
+}
+
+func (dryRunResult) RowsAffected() (int64, error) {
+	return 0, nil
+}
+
+var dryRunReturningX = regexp.MustCompile(`(?i)\bRETURNING\s+(.+)$`)
+
+// Query records the statement, and returns a row of zero values for
+// its RETURNING columns.
+func (s dryRunStmt) Query(args []driver.Value) (driver.Rows, error) {
+	s.rec.record(s.query, driverArgs(args))
+	rows := &dryRunRows{}
+	if m := dryRunReturningX.FindStringSubmatch(strings.TrimSpace(s.query)); m != nil {
+		for _, col := range strings.Split(m[1], ",") {
+			rows.columns = append(rows.columns, strings.Trim(strings.TrimSpace(col), "\"`"))
+		}
+		rows.left = 1
+	}
+	return rows, nil
+}
+
+func driverArgs(args []driver.Value) []interface{} {
+	if len(args) == 0 {
+		return nil
+	}
+	a := make([]interface{}, len(args))
+	for i, v := range args {
+		a[i] = v
+	}
+	return a
+}
+
+type dryRunRows struct {
+	columns []string
+	left    int
+}
+
+func (r *dryRunRows) Columns() []string {
+	return r.columns
+}
+
+func (r *dryRunRows) Close() error {
+	return nil
+}
+
+func (r *dryRunRows) Next(dest []driver.Value) error {
+	if r.left == 0 {
+		return io.EOF
+	}
+	r.left--
+	for i := range dest {
+		dest[i] = int64(0)
+	}
+	return nil
+}
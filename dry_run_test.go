@@ -0,0 +1,63 @@
+package pop
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_DryRun(t *testing.T) {
+	r := require.New(t)
+
+	count, err := PDB.Count(&User{})
+	r.NoError(err)
+
+	dr := PDB.DryRun()
+	r.NoError(dr.Create(&User{Name: nulls.NewString("Mark")}))
+	r.NoError(dr.RawQuery("DELETE FROM users WHERE name = ?", "Mark").Exec())
+
+	stmts := dr.Statements()
+	r.Len(stmts, 2)
+	r.Equal("INSERT", stmts[0].Op)
+	r.Equal("users", stmts[0].Table)
+	r.Contains(stmts[0].SQL, "INSERT INTO users")
+	r.Contains(stmts[0].Args, "Mark")
+	r.Equal("DELETE", stmts[1].Op)
+	r.Equal("users", stmts[1].Table)
+	r.Equal([]interface{}{"Mark"}, stmts[1].Args)
+
+	// the selects run against the database
+	n, err := dr.Count(&User{})
+	r.NoError(err)
+	r.Equal(count, n)
+	r.Nil(PDB.Statements())
+}
+
+func Test_DryRun_Transaction(t *testing.T) {
+	r := require.New(t)
+
+	dr := PDB.DryRun()
+	err := dr.Transaction(context.Background(), func(ctx context.Context, tx *Connection) error {
+		return tx.Update(&User{ID: 1, Name: nulls.NewString("Mark")})
+	})
+	r.NoError(err)
+
+	stmts := dr.Statements()
+	r.Len(stmts, 3)
+	r.Equal("BEGIN", stmts[0].Op)
+	r.Equal("UPDATE", stmts[1].Op)
+	r.Equal("users", stmts[1].Table)
+	r.Equal("COMMIT", stmts[2].Op)
+}
+
+func Test_DryRun_RejectSelects(t *testing.T) {
+	r := require.New(t)
+
+	dr := PDB.DryRun(DryRunOptions{RejectSelects: true})
+	err := dr.All(context.Background(), &Users{})
+	r.Equal(ErrDryRun, errors.Cause(err))
+	r.Len(dr.Statements(), 0)
+}
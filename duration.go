@@ -0,0 +1,87 @@
+package pop
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// DurationType is the fizz type of the BIGINT columns of the Duration
+// fields:
+//
+//	t.Column("session_length", pop.DurationType, {})
+const DurationType = "bigint"
+
+// Duration is a time.Duration written to an integer column as microseconds,
+// and marshaled to JSON as a time.Duration string, e.g. "1h30m0s". The
+// nanoseconds are truncated when written.
+type Duration time.Duration
+
+// Duration returns the time.Duration of d.
+func (d Duration) Duration() time.Duration {
+	return time.Duration(d)
+}
+
+// Add returns d+o.
+func (d Duration) Add(o Duration) Duration {
+	return Duration(d.Duration() + o.Duration())
+}
+
+// Sub returns d-o.
+func (d Duration) Sub(o Duration) Duration {
+	return Duration(d.Duration() - o.Duration())
+}
+
+// String returns the duration like time.Duration, e.g. "1h30m0s".
+func (d Duration) String() string {
+	return d.Duration().String()
+}
+
+// Value writes the duration as microseconds.
+func (d Duration) Value() (driver.Value, error) {
+	return int64(d.Duration() / time.Microsecond), nil
+}
+
+// Scan reads microseconds, returned as an int64, or as text by some
+// drivers, e.g. for a NUMERIC column.
+func (d *Duration) Scan(src interface{}) error {
+	var us int64
+	switch v := src.(type) {
+	case int64:
+		us = v
+	case []byte:
+		return d.Scan(string(v))
+	case string:
+		i, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		us = i
+	default:
+		return errors.Errorf("can't scan %T into a pop.Duration", src)
+	}
+	*d = Duration(time.Duration(us) * time.Microsecond)
+	return nil
+}
+
+// MarshalJSON marshals the duration as a time.Duration string.
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(d.String())
+}
+
+// UnmarshalJSON unmarshals a time.Duration string, e.g. "1h30m".
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	td, err := time.ParseDuration(s)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	*d = Duration(td)
+	return nil
+}
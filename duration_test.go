@@ -0,0 +1,37 @@
+package pop
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Duration(t *testing.T) {
+	r := require.New(t)
+
+	d := Duration(90*time.Minute + 1500*time.Nanosecond)
+	v, err := d.Value()
+	r.NoError(err)
+	r.Equal(int64(5400000001), v)
+
+	var s Duration
+	for _, src := range []interface{}{int64(5400000001), "5400000001", []byte("5400000001")} {
+		r.NoError(s.Scan(src))
+		r.Equal(Duration(90*time.Minute+time.Microsecond), s)
+	}
+	r.Error(s.Scan(nil))
+	r.Error(s.Scan("1h"))
+
+	b, err := json.Marshal(Duration(90 * time.Minute))
+	r.NoError(err)
+	r.Equal(`"1h30m0s"`, string(b))
+	r.NoError(json.Unmarshal([]byte(`"1h30m"`), &s))
+	r.Equal(Duration(90*time.Minute), s)
+	r.Error(json.Unmarshal([]byte(`5400`), &s))
+
+	r.Equal(Duration(2*time.Hour), s.Add(Duration(30*time.Minute)))
+	r.Equal(Duration(time.Hour), s.Sub(Duration(30*time.Minute)))
+	r.Equal(90*time.Minute, s.Duration())
+}
@@ -0,0 +1,57 @@
+package pop
+
+import (
+	"testing"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_EagerLoad(t *testing.T) {
+	r := require.New(t)
+	ctx := PDB.txContext()
+
+	u := &parallelUser{Name: nulls.NewString("EagerLoad")}
+	r.NoError(PDB.Create(u))
+	defer PDB.RawQuery("DELETE FROM users WHERE id = ?", u.ID).Exec()
+	defer PDB.RawQuery("DELETE FROM books WHERE user_id = ?", u.ID).Exec()
+	for _, title := range []string{"B", "A"} {
+		r.NoError(PDB.Create(&Book{Title: title, UserID: nulls.NewInt(u.ID)}))
+	}
+
+	found := &parallelUser{}
+	r.NoError(PDB.Eager().EagerLoad("Titles").Find(ctx, found, u.ID))
+	r.Len(found.Books, 0)
+	r.Len(found.Titles, 2)
+
+	users := []parallelUser{}
+	r.NoError(PDB.Eager().Where("id = ?", u.ID).EagerLoad("Books").All(ctx, &users))
+	r.Len(users, 1)
+	r.Len(users[0].Books, 2)
+	r.Len(users[0].Titles, 0)
+
+	// the associations of Eager not listed are skipped
+	found = &parallelUser{}
+	r.NoError(Q(PDB).Eager("Books", "Titles").EagerLoad("Books").Find(ctx, found, u.ID))
+	r.Len(found.Books, 2)
+	r.Len(found.Titles, 0)
+
+	found = &parallelUser{}
+	r.NoError(Q(PDB).Eager("Books").EagerLoad("Titles").Find(ctx, found, u.ID))
+	r.Len(found.Books, 0)
+	r.Len(found.Titles, 0)
+
+	found = &parallelUser{}
+	r.NoError(PDB.Eager().EagerLoad().Find(ctx, found, u.ID))
+	r.Len(found.Books, 0)
+	r.Len(found.Titles, 0)
+
+	// it doesn't enable the eager loading
+	found = &parallelUser{}
+	r.NoError(PDB.EagerLoad("Books").Find(ctx, found, u.ID))
+	r.Len(found.Books, 0)
+
+	err := PDB.Eager().EagerLoad("Houses").Find(ctx, &parallelUser{}, u.ID)
+	r.Error(err)
+	r.Contains(err.Error(), "field Houses does not exist in model parallelUser")
+}
@@ -0,0 +1,68 @@
+package pop
+
+import (
+	"context"
+
+	"github.com/gobuffalo/pop/associations"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// eagerParallelAssociations loads the associations of model concurrently,
+// see EagerParallel.
+func (q *Query) eagerParallelAssociations(ctx context.Context, model interface{}, assos associations.Associations, selects map[string][]string) error {
+	// the associations loaded into the same field are loaded in turn
+	var groups [][]associations.Association
+	fields := map[interface{}]int{}
+	for _, association := range assos {
+		if association.Skipped() {
+			continue
+		}
+		dest := association.Interface()
+		if i, ok := fields[dest]; ok {
+			groups[i] = append(groups[i], association)
+			continue
+		}
+		fields[dest] = len(groups)
+		groups = append(groups, []associations.Association{association})
+	}
+
+	g, gctx := errgroup.WithContext(ctx)
+	sem := make(chan struct{}, q.eagerParallel)
+	for _, group := range groups {
+		group := group
+		g.Go(func() error {
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-gctx.Done():
+				return errors.Wrapf(gctx.Err(), "eager loading of %T in %T canceled", group[0].Interface(), model)
+			}
+			// the loads disable the eager mode of their connection
+			c := q.Connection.copy()
+			for _, association := range group {
+				if err := gctx.Err(); err != nil {
+					return errors.Wrapf(err, "eager loading of %T in %T canceled", association.Interface(), model)
+				}
+				if err := loadParallelAssociation(gctx, c, model, association, selects); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	return g.Wait()
+}
+
+// loadParallelAssociation loads the association under its own span.
+func loadParallelAssociation(ctx context.Context, c *Connection, model interface{}, association associations.Association, selects map[string][]string) error {
+	span, ctx := tracer.StartSpanFromContext(ctx, "pop/eager")
+	defer span.Finish()
+	span.SetTag("pop.association", associationField(model, association.Interface()))
+	err := loadAssociation(ctx, c, model, association, selects)
+	if err != nil {
+		span.SetTag("error", err)
+	}
+	return err
+}
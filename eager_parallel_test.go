@@ -0,0 +1,71 @@
+package pop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+type titledBook struct {
+	ID     int       `db:"id"`
+	Title  string    `db:"title"`
+	UserID nulls.Int `db:"user_id"`
+}
+
+func (titledBook) TableName() string {
+	return "books"
+}
+
+type parallelUser struct {
+	ID        int          `db:"id"`
+	Name      nulls.String `db:"name"`
+	CreatedAt time.Time    `db:"created_at"`
+	UpdatedAt time.Time    `db:"updated_at"`
+	Books     Books        `has_many:"books" fk_id:"user_id" order_by:"title asc"`
+	Titles    []titledBook `has_many:"books" fk_id:"user_id" order_by:"title desc"`
+}
+
+func (parallelUser) TableName() string {
+	return "users"
+}
+
+func Test_EagerParallel(t *testing.T) {
+	r := require.New(t)
+	ctx := PDB.txContext()
+
+	u := &parallelUser{Name: nulls.NewString("Parallel")}
+	r.NoError(PDB.Create(u))
+	defer PDB.RawQuery("DELETE FROM users WHERE id = ?", u.ID).Exec()
+	defer PDB.RawQuery("DELETE FROM books WHERE user_id = ?", u.ID).Exec()
+	for _, title := range []string{"B", "A", "C"} {
+		r.NoError(PDB.Create(&Book{Title: title, UserID: nulls.NewInt(u.ID)}))
+	}
+
+	found := &parallelUser{}
+	r.NoError(Q(PDB).Eager("Books", "Titles").EagerParallel(2).Find(ctx, found, u.ID))
+	r.Len(found.Books, 3)
+	r.Equal("A", found.Books[0].Title)
+	r.Len(found.Titles, 3)
+	r.Equal("C", found.Titles[0].Title)
+
+	users := []parallelUser{}
+	r.NoError(PDB.Where("id = ?", u.ID).Eager().EagerParallel(4).All(ctx, &users))
+	r.Len(users, 1)
+	r.Len(users[0].Books, 3)
+	r.Len(users[0].Titles, 3)
+
+	// an error is returned, whatever the other loads
+	err := Q(PDB).EagerSelect("Books", "body").Eager("Titles").EagerParallel(2).Find(ctx, &parallelUser{}, u.ID)
+	r.Error(err)
+	r.Contains(err.Error(), "body is not a column of books")
+
+	// the associations are loaded in turn by a transaction
+	transaction(func(tx *Connection) {
+		found := &parallelUser{}
+		r.NoError(Q(tx).Eager().EagerParallel(2).Find(tx.txContext(), found, u.ID))
+		r.Len(found.Books, 3)
+		r.Len(found.Titles, 3)
+	})
+}
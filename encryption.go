@@ -0,0 +1,403 @@
+package pop
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+)
+
+// Cipher encrypts the columns of the fields with an `encrypted` tag, see
+// Connection.WithCipher. Its ciphertexts should start with the version of
+// their key, so the keys can be rotated, like the ones of AESCipher.
+type Cipher interface {
+	Encrypt(plaintext []byte) ([]byte, error)
+	Decrypt(ciphertext []byte) ([]byte, error)
+}
+
+// DeterministicCipher is a Cipher encrypting the columns of the fields
+// tagged `encrypted:"deterministic"`: EncryptDeterministic always returns
+// the same ciphertext for a plaintext, so the column can be compared by a
+// where clause, at the cost of revealing the equal values.
+type DeterministicCipher interface {
+	Cipher
+	EncryptDeterministic(plaintext []byte) ([]byte, error)
+}
+
+// WithCipher returns a copy of the connection encrypting the columns of
+// the string, *string and []byte fields with an `encrypted:"true"` tag
+// with ci. The transactions and copies of the returned connection encrypt
+// them too.
+//
+//	type Patient struct {
+//		ID    int    `db:"id"`
+//		SSN   string `db:"ssn" encrypted:"true"`
+//		Phone string `db:"phone" encrypted:"deterministic"`
+//	}
+//
+//	ci, err := pop.NewAESCipher(map[byte][]byte{1: key}, 1)
+//	ec := c.WithCipher(ci)
+//	err = ec.Create(&patient)
+//	err = ec.Where("phone = ?", phone).First(ctx, &patient)
+//
+// The fields are encrypted when they're written by Create, Update, Save and
+// Upsert, and decrypted when they're read by the finders; the columns hold
+// the ciphertexts in base64, in a text, bytea or blob column. Their NULLs,
+// the nil pointers and slices, aren't encrypted.
+//
+// The ciphertexts can't be queried: a where clause of an encrypted column
+// fails, unless its tag is `encrypted:"deterministic"` and the cipher a
+// DeterministicCipher. The args of its clause are then encrypted, for the
+// clauses `column = ?` and `column IN (?)` only. The models with encrypted
+// fields fail to be written or read by a connection without a cipher. The
+// encrypted columns are left out of the changes audited, see WithAuditor.
+func (c *Connection) WithCipher(ci Cipher) *Connection {
+	cn := c.copy()
+	cn.cipher = ci
+	return cn
+}
+
+// encryptedField is a field of a model with an `encrypted` tag.
+type encryptedField struct {
+	field         string
+	column        string
+	index         []int
+	deterministic bool
+}
+
+// encryptedFields returns the encrypted fields of the model, or of the
+// elements of a slice.
+func encryptedFields(model interface{}) ([]encryptedField, error) {
+	t := reflectx.Deref(reflect.TypeOf(model))
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = reflectx.Deref(t.Elem())
+	}
+	if t.Kind() != reflect.Struct {
+		return nil, nil
+	}
+	var fields []encryptedField
+	for _, fi := range strictMapper.TypeMap(t).Index {
+		if fi.Embedded || strings.Contains(fi.Path, ".") {
+			continue
+		}
+		tag := fi.Field.Tag.Get("encrypted")
+		switch tag {
+		case "", "false":
+			continue
+		case "true", "deterministic":
+		default:
+			return nil, errors.Errorf("invalid encrypted tag %q of %s", tag, fi.Field.Name)
+		}
+		switch ft := fi.Field.Type; {
+		case ft.Kind() == reflect.String, ft.Kind() == reflect.Ptr && ft.Elem().Kind() == reflect.String:
+		case ft.Kind() == reflect.Slice && ft.Elem().Kind() == reflect.Uint8:
+		default:
+			return nil, errors.Errorf("%s can't be encrypted: it's a %s, not a string or []byte", fi.Field.Name, ft)
+		}
+		fields = append(fields, encryptedField{field: fi.Field.Name, column: fi.Name, index: fi.Index, deterministic: tag == "deterministic"})
+	}
+	return fields, nil
+}
+
+// encrypt returns the column value of the plaintext of the field ef.
+func (c *Connection) encrypt(ef encryptedField, plaintext []byte) (string, error) {
+	var ciphertext []byte
+	var err error
+	if ef.deterministic {
+		dc, ok := c.cipher.(DeterministicCipher)
+		if !ok {
+			return "", errors.Errorf("%s is encrypted deterministically: %T is not a DeterministicCipher", ef.column, c.cipher)
+		}
+		ciphertext, err = dc.EncryptDeterministic(plaintext)
+	} else {
+		ciphertext, err = c.cipher.Encrypt(plaintext)
+	}
+	if err != nil {
+		return "", errors.Wrapf(err, "could not encrypt %s", ef.column)
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// checkCipher returns an error if the encrypted fields of the model can't
+// be encrypted by the connection.
+func (c *Connection) checkCipher(m *Model, fields []encryptedField) error {
+	if len(fields) > 0 && c.cipher == nil {
+		return errors.Errorf("%s of %s is encrypted: the connection needs a cipher, see WithCipher", fields[0].column, m.TableName())
+	}
+	return nil
+}
+
+// encryptFields replaces the encrypted fields of the model, a struct, by
+// their ciphertexts for its write, and returns the function restoring
+// them.
+func (c *Connection) encryptFields(m *Model) (func(), error) {
+	fields, err := encryptedFields(m.Value)
+	if err == nil {
+		err = c.checkCipher(m, fields)
+	}
+	if err != nil || len(fields) == 0 {
+		return func() {}, err
+	}
+	v := reflect.Indirect(reflect.ValueOf(m.Value))
+	var saved []reflect.Value
+	var restored []reflect.Value
+	restore := func() {
+		for i, f := range restored {
+			f.Set(saved[i])
+		}
+	}
+	for _, ef := range fields {
+		f, err := v.FieldByIndexErr(ef.index)
+		if err != nil {
+			continue
+		}
+		var plaintext []byte
+		switch {
+		case f.Kind() == reflect.String:
+			plaintext = []byte(f.String())
+		case f.IsNil():
+			continue
+		case f.Kind() == reflect.Ptr:
+			plaintext = []byte(f.Elem().String())
+		default:
+			plaintext = f.Bytes()
+		}
+		s, err := c.encrypt(ef, plaintext)
+		if err != nil {
+			restore()
+			return func() {}, err
+		}
+		old := reflect.New(f.Type()).Elem()
+		old.Set(f)
+		saved, restored = append(saved, old), append(restored, f)
+		setStringField(f, s)
+	}
+	return restore, nil
+}
+
+// setStringField sets the field f, a string, *string or []byte, to s.
+func setStringField(f reflect.Value, s string) {
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(s)
+	case reflect.Ptr:
+		p := reflect.New(f.Type().Elem())
+		p.Elem().SetString(s)
+		f.Set(p)
+	default:
+		f.SetBytes([]byte(s))
+	}
+}
+
+// decryptFields decrypts the encrypted fields of the models read.
+func (c *Connection) decryptFields(m *Model) error {
+	fields, err := encryptedFields(m.Value)
+	if err != nil || len(fields) == 0 {
+		return err
+	}
+	if err := c.checkCipher(m, fields); err != nil {
+		return err
+	}
+	return m.iterate(func(m *Model) error {
+		v := reflect.ValueOf(m.Value)
+		for v.Kind() == reflect.Ptr && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return nil
+		}
+		for _, ef := range fields {
+			f, err := v.FieldByIndexErr(ef.index)
+			if err != nil {
+				continue
+			}
+			var s string
+			switch {
+			case f.Kind() == reflect.String:
+				s = f.String()
+			case f.IsNil():
+				continue
+			case f.Kind() == reflect.Ptr:
+				s = f.Elem().String()
+			default:
+				s = string(f.Bytes())
+			}
+			ciphertext, err := base64.StdEncoding.DecodeString(s)
+			if err != nil {
+				return errors.Wrapf(err, "%s of %s is not encrypted", ef.column, m.TableName())
+			}
+			plaintext, err := c.cipher.Decrypt(ciphertext)
+			if err != nil {
+				return errors.Wrapf(err, "could not decrypt %s of %s", ef.column, m.TableName())
+			}
+			if f.Kind() == reflect.Slice {
+				f.SetBytes(plaintext)
+				continue
+			}
+			setStringField(f, string(plaintext))
+		}
+		return nil
+	})
+}
+
+// encryptedClauses returns the where clauses wc with the args of the
+// deterministic encrypted columns encrypted, or an error if a clause
+// compares an encrypted column otherwise.
+func (sq *sqlBuilder) encryptedClauses(wc clauses) (clauses, error) {
+	fields, err := encryptedFields(sq.Model.Value)
+	if err != nil || len(fields) == 0 {
+		return wc, err
+	}
+	alias := regexp.QuoteMeta(sq.Model.alias())
+	out := make(clauses, len(wc))
+	copy(out, wc)
+	for _, ef := range fields {
+		col := regexp.QuoteMeta(ef.column)
+		ref := regexp.MustCompile(`(?i)(^|[^\w.])(` + alias + `\.)?"?` + col + `"?\b`)
+		cmp := regexp.MustCompile(`(?i)^\s*(` + alias + `\.)?"?` + col + `"?\s*(=\s*\?|IN\s*\(\s*\?\s*\))\s*$`)
+		for i, cl := range out {
+			if !ref.MatchString(cl.Fragment) {
+				continue
+			}
+			if !ef.deterministic || !cmp.MatchString(cl.Fragment) {
+				return nil, errors.Errorf("the encrypted column %s can't be queried by %q: only the deterministic columns can, by `%s = ?` or `%s IN (?)`", ef.column, cl.Fragment, ef.column, ef.column)
+			}
+			if sq.Query.Connection.cipher == nil {
+				return nil, errors.Errorf("%s is encrypted: the connection needs a cipher, see WithCipher", ef.column)
+			}
+			args := make([]interface{}, len(cl.Arguments))
+			for j, arg := range cl.Arguments {
+				if args[j], err = sq.Query.Connection.encryptArg(ef, arg); err != nil {
+					return nil, err
+				}
+			}
+			out[i] = clause{Fragment: cl.Fragment, Arguments: args}
+		}
+	}
+	return out, nil
+}
+
+// encryptArg encrypts the arg of a where clause of the deterministic
+// encrypted column: a string, []byte, or a slice of them for IN.
+func (c *Connection) encryptArg(ef encryptedField, arg interface{}) (interface{}, error) {
+	switch a := arg.(type) {
+	case string:
+		return c.encrypt(ef, []byte(a))
+	case []byte:
+		return c.encrypt(ef, a)
+	case *string:
+		if a == nil {
+			return nil, nil
+		}
+		return c.encrypt(ef, []byte(*a))
+	}
+	v := reflect.ValueOf(arg)
+	if v.Kind() == reflect.Slice {
+		encrypted := make([]string, v.Len())
+		for i := range encrypted {
+			e, err := c.encryptArg(ef, v.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			s, ok := e.(string)
+			if !ok {
+				return nil, errors.Errorf("%v can't be compared to the encrypted column %s", v.Index(i).Interface(), ef.column)
+			}
+			encrypted[i] = s
+		}
+		return encrypted, nil
+	}
+	return nil, errors.Errorf("%T can't be compared to the encrypted column %s", arg, ef.column)
+}
+
+// AESCipher is a Cipher and DeterministicCipher encrypting with AES-GCM.
+// Its ciphertexts start with the version of their key, followed by their
+// nonce, so the keys can be rotated: the ciphertexts of the older keys are
+// still decrypted, and encrypted again with the current key when their
+// model is written. The deterministic ciphertexts have a nonce derived
+// from the plaintext: they're rewritten too, but can't be queried with
+// the older keys.
+type AESCipher struct {
+	current byte
+	keys    map[byte]cipher.AEAD
+	macKeys map[byte][]byte
+}
+
+// NewAESCipher returns an AESCipher decrypting with the keys, by version,
+// and encrypting with the key of the current version. The keys are 16, 24
+// or 32 bytes long, for AES-128, AES-192 or AES-256.
+//
+//	ci, err := pop.NewAESCipher(map[byte][]byte{1: oldKey, 2: newKey}, 2)
+func NewAESCipher(keys map[byte][]byte, current byte) (*AESCipher, error) {
+	if _, ok := keys[current]; !ok {
+		return nil, errors.Errorf("no key of the current version %d", current)
+	}
+	ac := &AESCipher{current: current, keys: map[byte]cipher.AEAD{}, macKeys: map[byte][]byte{}}
+	for version, key := range keys {
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid key of version %d", version)
+		}
+		gcm, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		ac.keys[version] = gcm
+		// the nonces of the deterministic ciphertexts are derived with a
+		// key of their own
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte("pop deterministic nonce"))
+		ac.macKeys[version] = mac.Sum(nil)
+	}
+	return ac, nil
+}
+
+// Encrypt encrypts the plaintext with the current key, and a random nonce.
+func (ac *AESCipher) Encrypt(plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, ac.keys[ac.current].NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return ac.seal(nonce, plaintext), nil
+}
+
+// EncryptDeterministic encrypts the plaintext with the current key, and a
+// nonce derived from the plaintext.
+func (ac *AESCipher) EncryptDeterministic(plaintext []byte) ([]byte, error) {
+	mac := hmac.New(sha256.New, ac.macKeys[ac.current])
+	mac.Write(plaintext)
+	return ac.seal(mac.Sum(nil)[:ac.keys[ac.current].NonceSize()], plaintext), nil
+}
+
+func (ac *AESCipher) seal(nonce []byte, plaintext []byte) []byte {
+	out := append([]byte{ac.current}, nonce...)
+	return ac.keys[ac.current].Seal(out, nonce, plaintext, nil)
+}
+
+// Decrypt decrypts the ciphertext with the key of its version.
+func (ac *AESCipher) Decrypt(ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) == 0 {
+		return nil, errors.New("empty ciphertext")
+	}
+	gcm, ok := ac.keys[ciphertext[0]]
+	if !ok {
+		return nil, errors.Errorf("no key of version %d", ciphertext[0])
+	}
+	ns := gcm.NonceSize()
+	if len(ciphertext) < 1+ns {
+		return nil, errors.New("truncated ciphertext")
+	}
+	plaintext, err := gcm.Open(nil, ciphertext[1:1+ns], ciphertext[1+ns:], nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return plaintext, nil
+}
@@ -0,0 +1,177 @@
+package pop
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type encryptedPatient struct {
+	ID        int       `db:"id"`
+	Name      string    `db:"name"`
+	SSN       string    `db:"ssn" encrypted:"true"`
+	Phone     *string   `db:"phone" encrypted:"deterministic"`
+	Notes     []byte    `db:"notes" encrypted:"true"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (encryptedPatient) TableName() string {
+	return "patients"
+}
+
+// plainPatient reads the ciphertexts of the patients.
+type plainPatient struct {
+	ID    int     `db:"id"`
+	SSN   string  `db:"ssn"`
+	Phone *string `db:"phone"`
+	Notes []byte  `db:"notes"`
+}
+
+func (plainPatient) TableName() string {
+	return "patients"
+}
+
+func Test_AESCipher(t *testing.T) {
+	r := require.New(t)
+
+	_, err := NewAESCipher(map[byte][]byte{1: make([]byte, 16)}, 2)
+	r.Error(err)
+	_, err = NewAESCipher(map[byte][]byte{1: []byte("short")}, 1)
+	r.Error(err)
+
+	old, err := NewAESCipher(map[byte][]byte{1: bytes.Repeat([]byte{1}, 16)}, 1)
+	r.NoError(err)
+	ac, err := NewAESCipher(map[byte][]byte{1: bytes.Repeat([]byte{1}, 16), 2: bytes.Repeat([]byte{2}, 32)}, 2)
+	r.NoError(err)
+
+	a, err := ac.Encrypt([]byte("secret"))
+	r.NoError(err)
+	b, err := ac.Encrypt([]byte("secret"))
+	r.NoError(err)
+	r.NotEqual(a, b)
+	r.Equal(byte(2), a[0])
+	p, err := ac.Decrypt(a)
+	r.NoError(err)
+	r.Equal("secret", string(p))
+
+	a, err = ac.EncryptDeterministic([]byte("secret"))
+	r.NoError(err)
+	b, err = ac.EncryptDeterministic([]byte("secret"))
+	r.NoError(err)
+	r.Equal(a, b)
+
+	// the ciphertexts of the old keys are still decrypted
+	o, err := old.Encrypt([]byte("rotated"))
+	r.NoError(err)
+	p, err = ac.Decrypt(o)
+	r.NoError(err)
+	r.Equal("rotated", string(p))
+	_, err = old.Decrypt(a)
+	r.Error(err)
+
+	a[len(a)-1] ^= 1
+	_, err = ac.Decrypt(a)
+	r.Error(err)
+}
+
+func Test_WithCipher(t *testing.T) {
+	transaction(func(c *Connection) {
+		r := require.New(t)
+		ctx := context.Background()
+
+		ci, err := NewAESCipher(map[byte][]byte{1: bytes.Repeat([]byte{7}, 32)}, 1)
+		r.NoError(err)
+		ec := c.WithCipher(ci)
+
+		phone := "555-0100"
+		p := &encryptedPatient{Name: "Mark", SSN: "123-45-6789", Phone: &phone, Notes: []byte("allergic")}
+		r.Error(c.Create(p))
+		r.NoError(ec.Create(p))
+		r.NotZero(p.ID)
+		r.Equal("123-45-6789", p.SSN)
+		r.Equal("555-0100", *p.Phone)
+		r.Equal("allergic", string(p.Notes))
+
+		// the columns hold the ciphertexts
+		pp := &plainPatient{}
+		r.NoError(c.Find(ctx, pp, p.ID))
+		r.NotEqual("123-45-6789", pp.SSN)
+		r.NotEqual("555-0100", *pp.Phone)
+		r.NotEqual("allergic", string(pp.Notes))
+
+		found := &encryptedPatient{}
+		r.Error(c.Find(ctx, found, p.ID))
+		r.NoError(ec.Find(ctx, found, p.ID))
+		r.Equal("123-45-6789", found.SSN)
+		r.Equal("555-0100", *found.Phone)
+		r.Equal("allergic", string(found.Notes))
+
+		found.SSN, found.Phone = "987-65-4321", nil
+		r.NoError(ec.Update(found))
+		r.Equal("987-65-4321", found.SSN)
+		patients := []encryptedPatient{}
+		r.NoError(ec.All(ctx, &patients))
+		r.Len(patients, 1)
+		r.Equal("987-65-4321", patients[0].SSN)
+		r.Nil(patients[0].Phone)
+
+		// only the deterministic columns can be queried
+		second := &encryptedPatient{Name: "Jane", SSN: "111-11-1111", Phone: &phone}
+		r.NoError(ec.Create(second))
+		found = &encryptedPatient{}
+		r.NoError(ec.Where("phone = ?", phone).First(ctx, found))
+		r.Equal(second.ID, found.ID)
+		n, err := ec.Where("patients.phone IN (?)", []string{phone, "other"}).Count(&encryptedPatient{})
+		r.NoError(err)
+		r.Equal(1, n)
+		_, err = ec.Where("ssn = ?", "111-11-1111").Count(&encryptedPatient{})
+		r.Error(err)
+		_, err = ec.Where("phone LIKE ?", "555%").Count(&encryptedPatient{})
+		r.Error(err)
+		n, err = ec.Where("name = ?", "Jane").Count(&encryptedPatient{})
+		r.NoError(err)
+		r.Equal(1, n)
+	})
+}
+
+func Test_WithCipher_Audited(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := context.Background()
+
+		ci, err := NewAESCipher(map[byte][]byte{1: bytes.Repeat([]byte{7}, 32)}, 1)
+		r.NoError(err)
+		ac := tx.WithCipher(ci).WithAuditor(TableAuditor{}, AuditOldValues())
+
+		phone := "555-0100"
+		p := &encryptedPatient{Name: "Mark", SSN: "123-45-6789", Phone: &phone, Notes: []byte("allergic")}
+		r.NoError(ac.Create(p))
+		p.Name, p.SSN = "Mark S.", "987-65-4321"
+		r.NoError(ac.Update(p))
+		r.NoError(ac.Destroy(p))
+
+		// the encrypted columns are left out of the audit trail
+		changes := []struct {
+			Changes string `db:"changes"`
+		}{}
+		r.NoError(tx.RawQuery("SELECT changes FROM audits WHERE table_name = ? AND record_id = ?", "patients", fmt.Sprint(p.ID)).All(ctx, &changes))
+		r.Len(changes, 3)
+		for _, ch := range changes {
+			values := map[string]AuditChange{}
+			r.NoError(json.Unmarshal([]byte(ch.Changes), &values))
+			r.Contains(values, "name")
+			r.NotContains(values, "ssn")
+			r.NotContains(values, "phone")
+			r.NotContains(values, "notes")
+			for _, secret := range []string{"123-45-6789", "987-65-4321", "555-0100", "allergic"} {
+				r.NotContains(ch.Changes, secret)
+			}
+		}
+	})
+}
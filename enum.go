@@ -0,0 +1,208 @@
+package pop
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/gobuffalo/flect"
+	"github.com/gobuffalo/pop/logging"
+	"github.com/gobuffalo/validate"
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+)
+
+// CodeEnum is the code of the FieldErrors of the enum fields set to a
+// value out of their enum, see EnumValues.
+const CodeEnum = "enum"
+
+// enumField is a field of a model with an `enum` tag.
+type enumField struct {
+	field    string
+	column   string
+	index    []int
+	values   []string
+	fallback *string
+}
+
+// EnumValues returns the allowed values of the enum columns of the model,
+// by column, e.g. for an API to list them. The columns of the fields with
+// an `enum` tag only accept the values of their tag:
+//
+//	type Order struct {
+//		ID     int    `db:"id"`
+//		Status string `db:"status" enum:"pending,active,closed" enum_fallback:"unknown"`
+//	}
+//
+//	pop.EnumValues(&Order{}) // map[status:[pending active closed]]
+//
+// The enums are checked by Create, Update and Save, which return
+// *ModelErrors with CodeEnum, and by the ValidateAnd* methods, which
+// return them as validation errors. The values are compared as written
+// to the database, e.g. the String of a valid nulls.String, or the
+// decimal form of an integer; NULL is always accepted.
+//
+// The finders fail when a column read has a value out of its enum, unless
+// its field has an `enum_fallback` tag: the field is then set to the
+// fallback, which may be out of the enum, and a warning is logged.
+func EnumValues(model interface{}) map[string][]string {
+	t := reflectx.Deref(reflect.TypeOf(model))
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = reflectx.Deref(t.Elem())
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	values := map[string][]string{}
+	for _, ef := range enumFields(t) {
+		values[ef.column] = append([]string(nil), ef.values...)
+	}
+	return values
+}
+
+// enumFields returns the enum fields of the struct type t.
+func enumFields(t reflect.Type) []enumField {
+	var fields []enumField
+	for _, fi := range strictMapper.TypeMap(t).Index {
+		if fi.Embedded || strings.Contains(fi.Path, ".") {
+			continue
+		}
+		tag, ok := fi.Field.Tag.Lookup("enum")
+		if !ok {
+			continue
+		}
+		ef := enumField{field: fi.Field.Name, column: fi.Name, index: fi.Index}
+		for _, v := range strings.Split(tag, ",") {
+			ef.values = append(ef.values, strings.TrimSpace(v))
+		}
+		if fb, ok := fi.Field.Tag.Lookup("enum_fallback"); ok {
+			ef.fallback = &fb
+		}
+		fields = append(fields, ef)
+	}
+	return fields
+}
+
+// allows tells if the enum field accepts the column value v.
+func (ef enumField) allows(v string) bool {
+	for _, ev := range ef.values {
+		if v == ev {
+			return true
+		}
+	}
+	return false
+}
+
+// enumValue returns the column value of the field f as a string, and
+// false if it's NULL.
+func enumValue(f reflect.Value) (string, bool) {
+	if f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return "", false
+		}
+		f = f.Elem()
+	}
+	switch v := dbValue(f.Interface()).(type) {
+	case nil:
+		return "", false
+	case []byte:
+		return string(v), true
+	case string:
+		return v, true
+	default:
+		return fmt.Sprint(v), true
+	}
+}
+
+// enumErrors returns the fields of the model, a struct, whose value is out
+// of their enum.
+func (m *Model) enumErrors() []FieldError {
+	v := reflect.ValueOf(m.Value)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	var errs []FieldError
+	for _, ef := range enumFields(v.Type()) {
+		f, err := v.FieldByIndexErr(ef.index)
+		if err != nil {
+			continue
+		}
+		if s, ok := enumValue(f); ok && !ef.allows(s) {
+			errs = append(errs, FieldError{
+				Field:   ef.field,
+				Column:  ef.column,
+				Rule:    RuleValidation,
+				Code:    CodeEnum,
+				Message: fmt.Sprintf("%s is not one of %s.", flect.Humanize(ef.column), strings.Join(ef.values, ", ")),
+			})
+		}
+	}
+	return errs
+}
+
+// validateEnums returns the enum fields of the model out of their enum as
+// *ModelErrors, or nil.
+func (m *Model) validateEnums() error {
+	errs := m.enumErrors()
+	if len(errs) == 0 {
+		return nil
+	}
+	return &ModelErrors{Model: m.typeName(reflect.TypeOf(m.Value)), Errors: errs}
+}
+
+// enumValidationErrors returns the enum fields of the model out of their
+// enum as validation errors.
+func (m *Model) enumValidationErrors() *validate.Errors {
+	verrs := validate.NewErrors()
+	for _, fe := range m.enumErrors() {
+		verrs.Add(fe.Column, fe.Message)
+	}
+	return verrs
+}
+
+// scanEnums checks the enum fields of the models read: the fields out of
+// their enum are set to their fallback, or fail the read.
+func (m *Model) scanEnums(c *Connection) error {
+	t := reflectx.Deref(reflect.TypeOf(m.Value))
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = reflectx.Deref(t.Elem())
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	// the fields are looked up once, rather than for each model
+	fields := enumFields(t)
+	if len(fields) == 0 {
+		return nil
+	}
+	return m.iterate(func(m *Model) error {
+		v := reflect.ValueOf(m.Value)
+		for v.Kind() == reflect.Ptr && !v.IsNil() {
+			v = v.Elem()
+		}
+		if v.Kind() != reflect.Struct {
+			return nil
+		}
+		for _, ef := range fields {
+			f, err := v.FieldByIndexErr(ef.index)
+			if err != nil {
+				continue
+			}
+			s, ok := enumValue(f)
+			if !ok || ef.allows(s) {
+				continue
+			}
+			if ef.fallback == nil {
+				return errors.Errorf("%s of %s is %q, not one of %s", ef.column, m.TableName(), s, strings.Join(ef.values, ", "))
+			}
+			c.log(logging.Warn, "%s of %s is %q, not one of %s: it's read as %q", ef.column, m.TableName(), s, strings.Join(ef.values, ", "), *ef.fallback)
+			if err := setDefault(f, *ef.fallback); err != nil {
+				return errors.Wrapf(err, "invalid enum fallback %q of %s", *ef.fallback, ef.field)
+			}
+		}
+		return nil
+	})
+}
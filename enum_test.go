@@ -0,0 +1,91 @@
+package pop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+type enumOrder struct {
+	ID        int          `db:"id"`
+	Status    string       `db:"status" enum:"pending, active,closed"`
+	Priority  int          `db:"priority" enum:"1,2,3"`
+	Channel   nulls.String `db:"channel" enum:"web,store"`
+	CreatedAt time.Time    `db:"created_at"`
+	UpdatedAt time.Time    `db:"updated_at"`
+}
+
+func (enumOrder) TableName() string {
+	return "enum_orders"
+}
+
+type fallbackEnumOrder struct {
+	ID        int       `db:"id"`
+	Status    string    `db:"status" enum:"pending,active,closed" enum_fallback:"unknown"`
+	Priority  int       `db:"priority" enum:"1,2,3" enum_fallback:"0"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (fallbackEnumOrder) TableName() string {
+	return "enum_orders"
+}
+
+func Test_Enum(t *testing.T) {
+	transaction(func(c *Connection) {
+		r := require.New(t)
+		ctx := context.Background()
+
+		r.Equal(map[string][]string{
+			"status":   {"pending", "active", "closed"},
+			"priority": {"1", "2", "3"},
+			"channel":  {"web", "store"},
+		}, EnumValues(&[]enumOrder{}))
+		r.Nil(EnumValues(1))
+
+		o := &enumOrder{Status: "pending", Priority: 1}
+		r.NoError(c.Create(o))
+
+		o.Status, o.Priority, o.Channel = "archived", 4, nulls.NewString("web")
+		err := c.Update(o)
+		merrs, ok := err.(*ModelErrors)
+		r.True(ok, "%v", err)
+		r.Equal("enumOrder", merrs.Model)
+		r.Equal([]FieldError{
+			{Field: "Status", Column: "status", Rule: RuleValidation, Code: CodeEnum, Message: "Status is not one of pending, active, closed."},
+			{Field: "Priority", Column: "priority", Rule: RuleValidation, Code: CodeEnum, Message: "Priority is not one of 1, 2, 3."},
+		}, merrs.Errors)
+
+		bad := &enumOrder{Status: "archived", Priority: 2}
+		r.Error(c.Create(bad))
+		r.Zero(bad.ID)
+		verrs, err := c.ValidateAndCreate(bad)
+		r.NoError(err)
+		r.Equal([]string{"Status is not one of pending, active, closed."}, verrs.Get("status"))
+		r.Equal([]FieldError{
+			{Field: "Status", Column: "status", Rule: RuleValidation, Code: CodeEnum, Message: "Status is not one of pending, active, closed."},
+		}, FieldErrors(bad, verrs, err).Errors)
+		r.Zero(bad.ID)
+
+		o.Status, o.Priority = "closed", 3
+		verrs, err = c.ValidateAndUpdate(o)
+		r.NoError(err)
+		r.False(verrs.HasAny())
+
+		// the values out of the enums fail the reads, or are read as the
+		// fallbacks
+		r.NoError(c.RawQuery("UPDATE enum_orders SET status = 'archived', priority = 7 WHERE id = ?", o.ID).Exec())
+		r.Error(c.Find(ctx, &enumOrder{}, o.ID))
+		fo := &fallbackEnumOrder{}
+		r.NoError(c.Find(ctx, fo, o.ID))
+		r.Equal("unknown", fo.Status)
+		r.Equal(0, fo.Priority)
+		fos := []fallbackEnumOrder{}
+		r.NoError(c.All(ctx, &fos))
+		r.Len(fos, 1)
+		r.Equal("unknown", fos[0].Status)
+	})
+}
@@ -2,29 +2,46 @@ package pop
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
 	"reflect"
+	"strings"
 
 	"github.com/gobuffalo/pop/associations"
 	"github.com/gobuffalo/pop/columns"
 	"github.com/gobuffalo/pop/logging"
 	"github.com/gobuffalo/validate"
 	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
 )
 
 // Reload fetch fresh data for a given model, using its ID.
 func (c *Connection) Reload(model interface{}) error {
-	sm := Model{Value: model}
+	if err := c.checkTableContext(model); err != nil {
+		return err
+	}
+	sm := c.model(model)
 	return sm.iterate(func(m *Model) error {
-		return c.Find(context.TODO(), m.Value, m.ID())
+		id, err := m.PrimaryKeyValue()
+		if err != nil {
+			return errors.Wrap(err, "could not reload the model")
+		}
+		return c.Find(c.txContext(), m.Value, id)
 	})
 }
 
 // Exec runs the given query.
 func (q *Query) Exec() error {
-	return q.Connection.timeFunc("Exec", func() error {
-		sql, args := q.ToSQL(nil)
-		log(logging.SQL, sql, args...)
-		_, err := q.Connection.Store.Exec(sql, args...)
+	if q.err != nil {
+		return q.err
+	}
+	return q.timeFunc(q.Connection.txContext(), "Exec", writeOp, nil, func(ctx context.Context) error {
+		sql, args, err := q.ToSQL(nil)
+		if err != nil {
+			return err
+		}
+		q.Connection.log(logging.SQL, sql, args...)
+		_, err = q.Connection.Store.Exec(sql, args...)
 		return err
 	})
 }
@@ -32,10 +49,16 @@ func (q *Query) Exec() error {
 // ExecWithCount runs the given query, and returns the amount of
 // affected rows.
 func (q *Query) ExecWithCount() (int, error) {
+	if q.err != nil {
+		return 0, q.err
+	}
 	count := int64(0)
-	return int(count), q.Connection.timeFunc("Exec", func() error {
-		sql, args := q.ToSQL(nil)
-		log(logging.SQL, sql, args...)
+	return int(count), q.timeFunc(q.Connection.txContext(), "Exec", writeOp, nil, func(ctx context.Context) error {
+		sql, args, err := q.ToSQL(nil)
+		if err != nil {
+			return err
+		}
+		q.Connection.log(logging.SQL, sql, args...)
 		result, err := q.Connection.Store.Exec(sql, args...)
 		if err != nil {
 			return err
@@ -46,10 +69,134 @@ func (q *Query) ExecWithCount() (int, error) {
 	})
 }
 
+// ExecRaw runs a statement returning no rows, e.g. SET, PRAGMA or a stored
+// procedure call, and returns its result. The args are bound the same way
+// as the RawQuery ones.
+//
+//	res, err := c.ExecRaw(ctx, "UPDATE users SET alive = ? WHERE id = ?", false, id)
+func (c *Connection) ExecRaw(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return c.exec(ctx, "ExecRaw", query, args)
+}
+
+// ErrUnsupported is returned by the features the dialect of a connection
+// doesn't support, e.g. the LastInsertId of an ExecResult on PostgreSQL.
+var ErrUnsupported = errors.New("unsupported by the dialect")
+
+// ExecResult is the result of a statement run by ExecContext.
+type ExecResult struct {
+	res     sql.Result
+	dialect string
+}
+
+// RowsAffected returns the number of rows affected by the statement.
+func (r ExecResult) RowsAffected() (int64, error) {
+	if r.res == nil {
+		return 0, errors.New("the statement wasn't run")
+	}
+	n, err := r.res.RowsAffected()
+	return n, errors.WithStack(err)
+}
+
+// LastInsertId returns the id of the last row inserted by the statement,
+// on the dialects reporting it. It returns ErrUnsupported on PostgreSQL
+// and CockroachDB, whose ids are read with RETURNING.
+func (r ExecResult) LastInsertId() (int64, error) {
+	switch r.dialect {
+	case namePostgreSQL, nameCockroach:
+		return 0, errors.Wrapf(ErrUnsupported, "%s has no LastInsertId", r.dialect)
+	}
+	if r.res == nil {
+		return 0, errors.New("the statement wasn't run")
+	}
+	id, err := r.res.LastInsertId()
+	return id, errors.WithStack(err)
+}
+
+// ExecContext runs a statement returning no rows, like ExecRaw, and
+// returns its result, to check the rows it affected:
+//
+//	res, err := c.ExecContext(ctx, "UPDATE users SET alive = ? WHERE id IN (?, ?)", false, 1, 2)
+//	n, err := res.RowsAffected()
+func (c *Connection) ExecContext(ctx context.Context, query string, args ...interface{}) (ExecResult, error) {
+	res, err := c.exec(ctx, "ExecContext", query, args)
+	if err != nil {
+		return ExecResult{}, err
+	}
+	return ExecResult{res: res, dialect: c.Dialect.Name()}, nil
+}
+
+// exec runs the statement of the operation name.
+func (c *Connection) exec(ctx context.Context, name string, query string, args []interface{}) (sql.Result, error) {
+	var res sql.Result
+	err := c.timeFunc(ctx, name, writeOp, "", func(ctx context.Context, c *Connection) error {
+		query := c.Dialect.TranslateSQL(query)
+		c.log(logging.SQL, query, args...)
+		var err error
+		res, err = c.Store.ExecContext(ctx, query, customArgs(args)...)
+		return err
+	})
+	return res, err
+}
+
+// execWrite runs the write statement stmt, not run by the dialect, e.g. to
+// touch a row, holding the write lock of the dialect if it has one, see
+// writeLocker.
+func (c *Connection) execWrite(ctx context.Context, stmt string, args ...interface{}) (sql.Result, error) {
+	exec := func() (sql.Result, error) {
+		c.log(logging.SQL, stmt, args...)
+		res, err := c.Store.ExecContext(ctx, stmt, args...)
+		return res, errors.WithStack(err)
+	}
+	l, ok := c.Dialect.(writeLocker)
+	if !ok {
+		return exec()
+	}
+	var res sql.Result
+	err := l.lockWrite(func() error {
+		var err error
+		res, err = exec()
+		return err
+	})
+	return res, err
+}
+
+// needsTransaction tells if the write of model runs several statements,
+// to touch the parents of the model or to audit it, outside of a
+// transaction: they're run in a new one, so they're committed together.
+func (c *Connection) needsTransaction(model interface{}) bool {
+	return c.TX == nil && (c.auditing != nil || touchesParents(model))
+}
+
+// QueryRow runs a query expected to return at most one row, e.g. an
+// aggregate or a sequence value, and returns the row to scan. The args are
+// bound the same way as the RawQuery ones. The errors are deferred until
+// Scan, which returns sql.ErrNoRows if the query selects no row.
+//
+//	var max int
+//	err := c.QueryRow(ctx, "SELECT MAX(price) FROM products WHERE category = ?", cat).Scan(&max)
+func (c *Connection) QueryRow(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	var row *sql.Row
+	err := c.timeFunc(ctx, "QueryRow", readOp, "", func(ctx context.Context, c *Connection) error {
+		query := c.Dialect.TranslateSQL(query)
+		c.log(logging.SQL, query, args...)
+		row = c.Store.QueryRowContext(ctx, query, customArgs(args)...)
+		return row.Err()
+	})
+	if err != nil {
+		if row != nil {
+			// a middleware failed after the query: release its connection
+			row.Scan()
+		}
+		return errRow(err)
+	}
+	return row
+}
+
 // ValidateAndSave applies validation rules on the given entry, then save it
-// if the validation succeed, excluding the given columns.
+// if the validation succeed, excluding the given columns. A constraint
+// violation of the write is returned as *ModelErrors, see FieldErrors.
 func (c *Connection) ValidateAndSave(model interface{}, excludeColumns ...string) (*validate.Errors, error) {
-	sm := &Model{Value: model}
+	sm := c.model(model)
 	verrs, err := sm.validateSave(c)
 	if err != nil {
 		return verrs, err
@@ -57,7 +204,7 @@ func (c *Connection) ValidateAndSave(model interface{}, excludeColumns ...string
 	if verrs.HasAny() {
 		return verrs, nil
 	}
-	return verrs, c.Save(model, excludeColumns...)
+	return verrs, modelErrors(model, c.Save(model, excludeColumns...))
 }
 
 var emptyUUID = uuid.Nil.String()
@@ -70,7 +217,7 @@ func IsZeroOfUnderlyingType(x interface{}) bool {
 // Save wraps the Create and Update methods. It executes a Create if no ID is provided with the entry;
 // or issues an Update otherwise.
 func (c *Connection) Save(model interface{}, excludeColumns ...string) error {
-	sm := &Model{Value: model}
+	sm := c.model(model)
 	return sm.iterate(func(m *Model) error {
 		id, err := m.fieldByName("ID")
 		if err != nil {
@@ -84,9 +231,10 @@ func (c *Connection) Save(model interface{}, excludeColumns ...string) error {
 }
 
 // ValidateAndCreate applies validation rules on the given entry, then creates it
-// if the validation succeed, excluding the given columns.
+// if the validation succeed, excluding the given columns. A constraint
+// violation of the write is returned as *ModelErrors, see FieldErrors.
 func (c *Connection) ValidateAndCreate(model interface{}, excludeColumns ...string) (*validate.Errors, error) {
-	sm := &Model{Value: model}
+	sm := c.model(model)
 	verrs, err := sm.validateCreate(c)
 	if err != nil {
 		return verrs, err
@@ -104,7 +252,7 @@ func (c *Connection) ValidateAndCreate(model interface{}, excludeColumns ...stri
 
 		if len(asos) == 0 {
 			c.disableEager()
-			return verrs, c.Create(model, excludeColumns...)
+			return verrs, modelErrors(model, c.Create(model, excludeColumns...))
 		}
 
 		before := asos.AssociationsBeforeCreatable()
@@ -114,7 +262,7 @@ func (c *Connection) ValidateAndCreate(model interface{}, excludeColumns ...stri
 				continue
 			}
 
-			sm := &Model{Value: i}
+			sm := c.model(i)
 			verrs, err := sm.validateAndOnlyCreate(c)
 			if err != nil || verrs.HasAny() {
 				return verrs, err
@@ -128,38 +276,114 @@ func (c *Connection) ValidateAndCreate(model interface{}, excludeColumns ...stri
 				continue
 			}
 
-			sm := &Model{Value: i}
+			sm := c.model(i)
 			verrs, err := sm.validateAndOnlyCreate(c)
 			if err != nil || verrs.HasAny() {
 				return verrs, err
 			}
 		}
 
-		sm := &Model{Value: model}
+		sm := c.model(model)
 		verrs, err = sm.validateCreate(c)
 		if err != nil || verrs.HasAny() {
 			return verrs, err
 		}
 	}
 
-	return verrs, c.Create(model, excludeColumns...)
+	return verrs, modelErrors(model, c.Create(model, excludeColumns...))
+}
+
+// BlindWrites returns a copy of the connection whose Create, Update and
+// Destroy don't read anything back, for the writes of CockroachDB whose
+// results aren't needed: the statements end with RETURNING NOTHING, and
+// Create sets neither the int ID of the model nor its Returning columns.
+// The other dialects run the writes as usual.
+//
+//	err := c.BlindWrites().Create(&event)
+func (c *Connection) BlindWrites() *Connection {
+	cn := c.copy()
+	cn.blind = true
+	return cn
 }
 
 // Create add a new given entry to the database, excluding the given columns.
-// It updates `created_at` and `updated_at` columns automatically.
+// It updates `created_at` and `updated_at` columns automatically, or reads
+// them back when they're set by the database, see DatabaseTimestamps.
+//
+// The unset fields with a `default` tag are set to their default: the nil
+// pointers and the invalid nulls types. The zero values of the other
+// fields, e.g. 0, "" or false, are values: they're only unset if the field
+// has a `default_zero:"true"` tag too. A default which is a SQL
+// expression, such as now(), is left to the database: the column isn't
+// inserted, and is read back like the Returning columns.
+//
+//	type Order struct {
+//		ID       int          `db:"id"`
+//		Status   string       `db:"status" default:"pending" default_zero:"true"`
+//		Note     nulls.String `db:"note" default:"none"`
+//		PlacedAt time.Time    `db:"placed_at" default:"now()" default_zero:"true"`
+//	}
+//
+// The defaults are set first, so they're checked by the validations of
+// the model, and seen by its callbacks: the defaults, the validations,
+// BeforeSave, BeforeCreate, the insert, AfterCreate and AfterSave. The
+// fields set by BeforeSave or BeforeCreate are kept.
 //
 // Create support two modes:
 // * Flat (default): Associate existing nested objects only. NO creation or update of nested objects.
 // * Eager: Associate existing nested objects and create non-existent objects. NO change to existing objects.
 func (c *Connection) Create(model interface{}, excludeColumns ...string) error {
+	return c.create(model, nil, excludeColumns...)
+}
+
+// Create adds a new given entry to the database, excluding the given columns,
+// and reads back the Returning columns.
+//
+//	q.Returning("created_at", "sequence_num").Create(&order)
+func (q *Query) Create(model interface{}, excludeColumns ...string) error {
+	return q.Connection.create(model, q.returning, excludeColumns...)
+}
+
+func (c *Connection) create(model interface{}, returning []string, excludeColumns ...string) error {
+	if err := c.checkTableContext(model); err != nil {
+		return err
+	}
+	if c.needsTransaction(model) {
+		defer c.disableEager()
+		return c.Transaction(c.txContext(), func(_ context.Context, tx *Connection) error {
+			return tx.create(model, returning, excludeColumns...)
+		})
+	}
+
 	var isEager = c.eager
 
 	c.disableEager()
 
-	sm := &Model{Value: model}
+	sm := c.model(model)
+	ctx := c.txContext()
+	// validate all the models before running any query
+	if err := sm.iterate(func(m *Model) error {
+		if err := c.checkShard(m, true); err != nil {
+			return err
+		}
+		if _, err := m.applyDefaults(); err != nil {
+			return err
+		}
+		if err := m.validateEnums(); err != nil {
+			return err
+		}
+		return m.validateContext(ctx)
+	}); err != nil {
+		return err
+	}
 	return sm.iterate(func(m *Model) error {
-		return c.timeFunc("Create", func() error {
+		return c.timeFunc(ctx, "Create", writeOp, m.TableName(), func(ctx context.Context, c *Connection) error {
 			var localIsEager = isEager
+			if localIsEager {
+				if err := checkAssociations(m.Value); err != nil {
+					return err
+				}
+			}
 			asos, err := associations.ForStruct(m.Value, c.eagerFields...)
 			if err != nil {
 				return err
@@ -174,7 +398,7 @@ func (c *Connection) Create(model interface{}, excludeColumns ...string) error {
 				return err
 			}
 
-			if err = m.beforeCreate(c); err != nil {
+			if err = m.beforeCreate(ctx, c); err != nil {
 				return err
 			}
 
@@ -189,7 +413,7 @@ func (c *Connection) Create(model interface{}, excludeColumns ...string) error {
 					}
 
 					if localIsEager {
-						sm := &Model{Value: i}
+						sm := c.model(i)
 						err = sm.iterate(func(m *Model) error {
 							id, err := m.fieldByName("ID")
 							if err != nil {
@@ -220,12 +444,54 @@ func (c *Connection) Create(model interface{}, excludeColumns ...string) error {
 				cols.Remove(excludeColumns...)
 			}
 
-			m.touchCreatedAt()
-			m.touchUpdatedAt()
+			// the defaults of the columns set by the database apply, and
+			// are read back
+			dbDefaults, err := m.applyDefaults()
+			if err != nil {
+				return err
+			}
+			cols.Remove(dbDefaults...)
+			m.returning = returning
+			if len(dbDefaults) > 0 {
+				m.returning = append(append([]string(nil), returning...), dbDefaults...)
+			}
+			if c.databaseTimestamps(m) {
+				ts := timestampColumns(cols, "created_at", "updated_at")
+				cols.Remove(ts...)
+				m.returning = append(append([]string(nil), m.returning...), ts...)
+			} else {
+				now := c.dbTime(c.now())
+				m.touchCreatedAt(now)
+				m.touchUpdatedAt(now)
+			}
+			defer c.timesToUTC(m)()
 
-			if err = c.Dialect.Create(c.Store, m, cols); err != nil {
+			m.blind = c.blind
+			restore, err := c.encryptFields(m)
+			if err != nil {
+				return err
+			}
+			err = c.Dialect.Create(c.Store, m, cols)
+			restore()
+			if err != nil {
 				return err
 			}
+			if len(m.returning) > 0 {
+				if err = c.selectReturning(m); err != nil {
+					return err
+				}
+			}
+			if err = c.touchParents(m); err != nil {
+				return err
+			}
+			audited, err := c.auditedColumns(m, cols, true)
+			if err != nil {
+				return err
+			}
+			if err = c.audit(ctx, "Create", m, append(audited, "id"), nil); err != nil {
+				return err
+			}
+			m.snapshot()
 
 			if processAssoc {
 				after := asos.AssociationsAfterCreatable()
@@ -241,7 +507,7 @@ func (c *Connection) Create(model interface{}, excludeColumns ...string) error {
 							continue
 						}
 
-						sm := &Model{Value: i}
+						sm := c.model(i)
 						err = sm.iterate(func(m *Model) error {
 							fbn, err := m.fieldByName("ID")
 							if err != nil {
@@ -264,7 +530,7 @@ func (c *Connection) Create(model interface{}, excludeColumns ...string) error {
 					}
 					stm := after[index].AfterProcess()
 					if c.TX != nil && !stm.Empty() {
-						_, err := c.TX.Exec(c.Dialect.TranslateSQL(stm.Statement), stm.Args...)
+						_, err := c.Store.Exec(c.Dialect.TranslateSQL(stm.Statement), stm.Args...)
 						if err != nil {
 							return err
 						}
@@ -275,13 +541,6 @@ func (c *Connection) Create(model interface{}, excludeColumns ...string) error {
 				for index := range stms {
 					statements := stms[index].Statements()
 					for _, stm := range statements {
-						if c.TX != nil {
-							_, err := c.TX.Exec(c.Dialect.TranslateSQL(stm.Statement), stm.Args...)
-							if err != nil {
-								return err
-							}
-							continue
-						}
 						_, err = c.Store.Exec(c.Dialect.TranslateSQL(stm.Statement), stm.Args...)
 						if err != nil {
 							return err
@@ -290,7 +549,7 @@ func (c *Connection) Create(model interface{}, excludeColumns ...string) error {
 				}
 			}
 
-			if err = m.afterCreate(c); err != nil {
+			if err = m.afterCreate(ctx, c); err != nil {
 				return err
 			}
 
@@ -299,10 +558,20 @@ func (c *Connection) Create(model interface{}, excludeColumns ...string) error {
 	})
 }
 
+// selectReturning reads the returning columns of a created model, for
+// the dialects not supporting INSERT ... RETURNING, or the updated_at
+// column of an updated model set by the database.
+func (c *Connection) selectReturning(m *Model) error {
+	query := c.Dialect.TranslateSQL(fmt.Sprintf("SELECT %s FROM %s WHERE %s", strings.Join(m.returning, ", "), m.TableName(), m.whereID()))
+	c.log(logging.SQL, query, m.ID())
+	return errors.Wrap(c.Store.Get(m.Value, query, m.ID()), "could not read the returning columns")
+}
+
 // ValidateAndUpdate applies validation rules on the given entry, then update it
-// if the validation succeed, excluding the given columns.
+// if the validation succeed, excluding the given columns. A constraint
+// violation of the write is returned as *ModelErrors, see FieldErrors.
 func (c *Connection) ValidateAndUpdate(model interface{}, excludeColumns ...string) (*validate.Errors, error) {
-	sm := &Model{Value: model}
+	sm := c.model(model)
 	verrs, err := sm.validateUpdate(c)
 	if err != nil {
 		return verrs, err
@@ -310,21 +579,50 @@ func (c *Connection) ValidateAndUpdate(model interface{}, excludeColumns ...stri
 	if verrs.HasAny() {
 		return verrs, nil
 	}
-	return verrs, c.Update(model, excludeColumns...)
+	return verrs, modelErrors(model, c.Update(model, excludeColumns...))
 }
 
 // Update writes changes from an entry to the database, excluding the given columns.
-// It updates the `updated_at` column automatically.
+// It updates the `updated_at` column automatically, or reads it back when
+// it's set by the database, see DatabaseTimestamps.
 func (c *Connection) Update(model interface{}, excludeColumns ...string) error {
-	sm := &Model{Value: model}
+	if err := c.checkTableContext(model); err != nil {
+		return err
+	}
+	return c.update(c.txContext(), model, false, excludeColumns...)
+}
+
+// update updates the models, excluding the given columns. Only the changed
+// columns of the tracked models are updated if changed is true, see
+// UpdateChanged.
+func (c *Connection) update(ctx context.Context, model interface{}, changed bool, excludeColumns ...string) error {
+	if c.needsTransaction(model) {
+		return c.Transaction(ctx, func(ctx context.Context, tx *Connection) error {
+			return tx.update(ctx, model, changed, excludeColumns...)
+		})
+	}
+
+	sm := c.modelContext(ctx, model)
+	// validate all the models before running any query
+	if err := sm.iterate(func(m *Model) error {
+		if err := c.checkShard(m, false); err != nil {
+			return err
+		}
+		if err := m.validateEnums(); err != nil {
+			return err
+		}
+		return m.validateContext(ctx)
+	}); err != nil {
+		return err
+	}
 	return sm.iterate(func(m *Model) error {
-		return c.timeFunc("Update", func() error {
+		return c.timeFunc(ctx, "Update", writeOp, m.TableName(), func(ctx context.Context, c *Connection) error {
 			var err error
 
 			if err = m.beforeSave(c); err != nil {
 				return err
 			}
-			if err = m.beforeUpdate(c); err != nil {
+			if err = m.beforeUpdate(ctx, c); err != nil {
 				return err
 			}
 
@@ -335,13 +633,62 @@ func (c *Connection) Update(model interface{}, excludeColumns ...string) error {
 			if tn == sm.TableName() {
 				cols.Remove(excludeColumns...)
 			}
+			if changed {
+				if changes, ok := m.changes(); ok {
+					if len(changes) == 0 {
+						return nil
+					}
+					for _, col := range columnNames(cols, false) {
+						if _, ok := changes[col]; !ok && col != "updated_at" {
+							cols.Remove(col)
+						}
+					}
+				}
+			}
 
-			m.touchUpdatedAt()
+			dbUpdatedAt := c.databaseTimestamps(m) && len(timestampColumns(cols, "updated_at")) > 0
+			if dbUpdatedAt {
+				// set by a trigger, and read back
+				cols.Remove("updated_at")
+			} else {
+				m.touchUpdatedAt(c.dbTime(c.now()))
+			}
+			defer c.timesToUTC(m)()
+			m.blind = c.blind
 
-			if err = c.Dialect.Update(c.Store, m, cols); err != nil {
+			audited, err := c.auditedColumns(m, cols, true)
+			if err != nil {
+				return err
+			}
+			old, err := c.auditedValues(m, audited)
+			if err != nil {
 				return err
 			}
-			if err = m.afterUpdate(c); err != nil {
+			restore, err := c.encryptFields(m)
+			if err != nil {
+				return err
+			}
+			err = c.Dialect.Update(c.Store, m, cols)
+			restore()
+			if err != nil {
+				return err
+			}
+			if dbUpdatedAt && !c.blind {
+				m.returning = []string{"updated_at"}
+				err = c.selectReturning(m)
+				m.returning = nil
+				if err != nil {
+					return err
+				}
+			}
+			if err = c.touchParents(m); err != nil {
+				return err
+			}
+			if err = c.audit(ctx, "Update", m, audited, old); err != nil {
+				return err
+			}
+			m.snapshot()
+			if err = m.afterUpdate(ctx, c); err != nil {
 				return err
 			}
 
@@ -350,21 +697,102 @@ func (c *Connection) Update(model interface{}, excludeColumns ...string) error {
 	})
 }
 
-// Destroy deletes a given entry from the database
+// Destroy deletes a given entry from the database. The soft-deletable
+// models get their deleted_at column set instead, see Query.Unscoped.
 func (c *Connection) Destroy(model interface{}) error {
-	sm := &Model{Value: model}
+	return c.destroy(model, false)
+}
+
+// destroy deletes the model, or soft-deletes it unless hard is true.
+func (c *Connection) destroy(model interface{}, hard bool) error {
+	if err := c.checkTableContext(model); err != nil {
+		return err
+	}
+	if c.needsTransaction(model) {
+		return c.Transaction(c.txContext(), func(_ context.Context, tx *Connection) error {
+			return tx.destroy(model, hard)
+		})
+	}
+
+	sm := c.model(model)
 	return sm.iterate(func(m *Model) error {
-		return c.timeFunc("Destroy", func() error {
+		ctx := c.txContext()
+		return c.timeFunc(ctx, "Destroy", writeOp, m.TableName(), func(ctx context.Context, c *Connection) error {
 			var err error
 
-			if err = m.beforeDestroy(c); err != nil {
+			if err = c.checkShard(m, false); err != nil {
 				return err
 			}
-			if err = c.Dialect.Destroy(c.Store, m); err != nil {
+			if err = m.beforeDestroy(ctx, c); err != nil {
+				return err
+			}
+			m.blind = c.blind
+			audited, err := c.auditedColumns(m, columns.ForStruct(m.Value, m.TableName()), false)
+			if err != nil {
+				return err
+			}
+			old, err := c.auditedValues(m, audited)
+			if err != nil {
+				return err
+			}
+			if !hard && m.softDeletable() {
+				err = c.softDestroy(ctx, m)
+			} else {
+				err = c.Dialect.Destroy(c.Store, m)
+			}
+			if err != nil {
+				return err
+			}
+			if err = c.touchParents(m); err != nil {
+				return err
+			}
+			if err = c.audit(ctx, "Destroy", m, audited, old); err != nil {
 				return err
 			}
 
-			return m.afterDestroy(c)
+			return m.afterDestroy(ctx, c)
 		})
 	})
 }
+
+// BulkDelete deletes the rows of the table of model whose ids are in ids, a
+// slice of primary keys, without loading their models, and returns the
+// number of rows deleted. The rows of a soft-deletable model get their
+// deleted_at column set instead, unless they're already deleted, see
+// Query.Unscoped. The callbacks of the model aren't run, nor is its
+// auditor.
+//
+//	n, err := c.BulkDelete(ctx, &User{}, []int{1, 2, 3})
+func (c *Connection) BulkDelete(ctx context.Context, model interface{}, ids interface{}) (int64, error) {
+	v := reflect.ValueOf(ids)
+	if v.Kind() != reflect.Slice && v.Kind() != reflect.Array {
+		return 0, errors.Errorf("BulkDelete needs a slice of ids, not %T", ids)
+	}
+	if v.Len() == 0 {
+		return 0, nil
+	}
+	args := make([]interface{}, v.Len())
+	for i := range args {
+		args[i] = v.Index(i).Interface()
+	}
+	in := "id IN (?" + strings.Repeat(", ?", len(args)-1) + ")"
+
+	m := c.modelContext(ctx, model)
+	var n int64
+	err := c.timeFunc(ctx, "BulkDelete", writeOp, m.TableName(), func(ctx context.Context, c *Connection) error {
+		stmt := fmt.Sprintf("DELETE FROM %s WHERE %s", m.TableName(), in)
+		stmtArgs := args
+		if m.softDeletable() {
+			now := c.dbTime(c.now())
+			stmt = fmt.Sprintf("UPDATE %s SET %s = ? WHERE %s AND %s IS NULL", m.TableName(), softDeleteColumn, in, softDeleteColumn)
+			stmtArgs = append([]interface{}{now}, args...)
+		}
+		res, err := c.execWrite(ctx, c.Dialect.TranslateSQL(stmt), stmtArgs...)
+		if err != nil {
+			return err
+		}
+		n, err = res.RowsAffected()
+		return errors.WithStack(err)
+	})
+	return n, err
+}
@@ -1,10 +1,14 @@
 package pop
 
 import (
+	"context"
+	"database/sql"
 	"testing"
+	"time"
 
 	"github.com/gobuffalo/nulls"
 	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -377,6 +381,25 @@ func Test_Create(t *testing.T) {
 	})
 }
 
+func Test_Create_Returning(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+
+		user := User{Name: nulls.NewString("Mark")}
+		err := tx.Returning("upper(name) as full_name").Create(&user)
+		r.NoError(err)
+		r.NotEqual(0, user.ID)
+		r.Equal("MARK", user.FullName.String)
+
+		users := Users{{Name: nulls.NewString("Ann")}, {Name: nulls.NewString("Bob")}}
+		err = tx.Returning("upper(name) as full_name").Create(&users)
+		r.NoError(err)
+		r.Equal("ANN", users[0].FullName.String)
+		r.Equal("BOB", users[1].FullName.String)
+		r.NotEqual(users[0].ID, users[1].ID)
+	})
+}
+
 func Test_Create_stringID(t *testing.T) {
 	transaction(func(tx *Connection) {
 		r := require.New(t)
@@ -1227,6 +1250,53 @@ func Test_Destroy_With_Slice(t *testing.T) {
 	})
 }
 
+func Test_BulkDelete(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		users := Users{
+			{Name: nulls.NewString("Mark")},
+			{Name: nulls.NewString("Larry")},
+			{Name: nulls.NewString("Kent")},
+		}
+		r.NoError(tx.Create(&users))
+
+		n, err := tx.BulkDelete(ctx, &User{}, []int{users[0].ID, users[1].ID, -1})
+		r.NoError(err)
+		r.Equal(int64(2), n)
+		ok, err := tx.Where("id = ?", users[2].ID).Exists(&User{})
+		r.NoError(err)
+		r.True(ok)
+		count, err := tx.Where("id in (?)", users[0].ID, users[1].ID).Count(&User{})
+		r.NoError(err)
+		r.Equal(0, count)
+
+		n, err = tx.BulkDelete(ctx, &User{}, []int{})
+		r.NoError(err)
+		r.Equal(int64(0), n)
+		_, err = tx.BulkDelete(ctx, &User{}, users[2].ID)
+		r.Error(err)
+
+		// the soft-deletable rows are soft-deleted, once
+		notes := []Note{{Title: "a"}, {Title: "b"}}
+		r.NoError(tx.Create(&notes))
+		ids := [2]int{notes[0].ID, notes[1].ID}
+		n, err = tx.BulkDelete(ctx, &Note{}, ids)
+		r.NoError(err)
+		r.Equal(int64(2), n)
+		n, err = tx.BulkDelete(ctx, &Note{}, ids)
+		r.NoError(err)
+		r.Equal(int64(0), n)
+		count, err = tx.Count(&Note{})
+		r.NoError(err)
+		r.Equal(0, count)
+		count, err = tx.Unscoped().Count(&Note{})
+		r.NoError(err)
+		r.Equal(2, count)
+	})
+}
+
 func Test_Destroy_UUID(t *testing.T) {
 	transaction(func(tx *Connection) {
 		r := require.New(t)
@@ -1267,10 +1337,193 @@ func Test_TruncateAll(t *testing.T) {
 		r.NoError(err)
 		r.Equal(count+1, ctx)
 
-		err = tx.TruncateAll()
+		err = tx.TruncateAll(context.Background())
 		r.NoError(err)
 
 		ctx, _ = tx.Count("users")
 		r.Equal(count, ctx)
 	})
 }
+
+func Test_Truncate(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+
+		user := User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(&user))
+		song := Song{Title: "Truncated"}
+		r.NoError(tx.Create(&song))
+
+		r.NoError(tx.Truncate(context.Background(), &User{}))
+
+		count, err := tx.Count("users")
+		r.NoError(err)
+		r.Equal(0, count)
+
+		count, err = tx.Count("songs")
+		r.NoError(err)
+		r.NotEqual(0, count)
+	})
+}
+
+func Test_TruncateWithOptions(t *testing.T) {
+	if PDB.Dialect.Name() == nameCockroach {
+		t.Skip("cockroach ids aren't sequences")
+	}
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := context.Background()
+
+		user := User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(&user))
+		r.NoError(tx.TruncateWithOptions(ctx, TruncateOptions{}, &User{}))
+		count, err := tx.Count("users")
+		r.NoError(err)
+		r.Equal(0, count)
+
+		// the ids keep increasing
+		next := User{Name: nulls.NewString("Larry")}
+		r.NoError(tx.Create(&next))
+		r.True(next.ID > user.ID)
+
+		r.NoError(tx.TruncateWithOptions(ctx, TruncateOptions{ResetSequence: true}, &User{}))
+		first := User{Name: nulls.NewString("Kent")}
+		r.NoError(tx.Create(&first))
+		r.Equal(1, first.ID)
+	})
+}
+
+func Test_Create_Update_Validatable(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *Connection) {
+		count, err := tx.Count(&User{})
+		r.NoError(err)
+
+		users := []SelfValidatingUser{{Name: nulls.NewString("Mark")}, {}}
+		r.Equal(errNameRequired, tx.Create(&users))
+		r.Equal(errNameRequired, tx.Save(&SelfValidatingUser{}))
+		// no user is created when one is invalid
+		c, err := tx.Count(&User{})
+		r.NoError(err)
+		r.Equal(count, c)
+
+		user := &SelfValidatingUser{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(user))
+		r.NotZero(user.ID)
+
+		user.Name = nulls.String{}
+		r.Equal(errNameRequired, tx.Update(user))
+		c, err = tx.Where("id = ? AND name = ?", user.ID, "Mark").Count(&User{})
+		r.NoError(err)
+		r.Equal(1, c)
+	})
+}
+
+func Test_ExecRaw(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *Connection) {
+		ctx := context.Background()
+		user := &User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(user))
+
+		var ops []string
+		tx.Use(func(ctx context.Context, op string, next func(ctx context.Context) error) error {
+			ops = append(ops, op)
+			return next(ctx)
+		})
+
+		res, err := tx.ExecRaw(ctx, "UPDATE users SET name = ? WHERE id = ?", "Ringo", user.ID)
+		r.NoError(err)
+		n, err := res.RowsAffected()
+		r.NoError(err)
+		r.Equal(int64(1), n)
+		r.Equal([]string{"ExecRaw"}, ops)
+
+		c, err := tx.Where("name = ?", "Ringo").Count(&User{})
+		r.NoError(err)
+		r.Equal(1, c)
+
+		_, err = tx.ExecRaw(ctx, "UPDATE unknown_table SET name = ?", "Ringo")
+		r.Error(err)
+	})
+}
+
+func Test_ExecContext(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *Connection) {
+		ctx := context.Background()
+		var ops []string
+		tx.Use(func(ctx context.Context, op string, next func(ctx context.Context) error) error {
+			ops = append(ops, op)
+			return next(ctx)
+		})
+
+		res, err := tx.ExecContext(ctx, "INSERT INTO users (name, alive, created_at, updated_at) VALUES (?, ?, ?, ?)", "Mark", true, time.Now(), time.Now())
+		r.NoError(err)
+		n, err := res.RowsAffected()
+		r.NoError(err)
+		r.Equal(int64(1), n)
+		r.Equal([]string{"ExecContext"}, ops)
+
+		id, err := res.LastInsertId()
+		switch tx.Dialect.Name() {
+		case namePostgreSQL, nameCockroach:
+			r.True(errors.Is(err, ErrUnsupported))
+		default:
+			r.NoError(err)
+			ok, err := tx.Where("id = ? AND name = ?", id, "Mark").Exists(&User{})
+			r.NoError(err)
+			r.True(ok)
+		}
+
+		res, err = tx.ExecContext(ctx, "UPDATE users SET name = ? WHERE name = ?", "Ringo", "Mark")
+		r.NoError(err)
+		n, err = res.RowsAffected()
+		r.NoError(err)
+		r.Equal(int64(1), n)
+
+		res, err = tx.ExecContext(ctx, "UPDATE unknown_table SET name = ?", "Ringo")
+		r.Error(err)
+		_, err = res.RowsAffected()
+		r.Error(err)
+	})
+}
+
+func Test_QueryRow(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *Connection) {
+		ctx := context.Background()
+		r.NoError(tx.Create(&User{Name: nulls.NewString("Mark")}))
+		r.NoError(tx.Create(&User{Name: nulls.NewString("Ringo")}))
+
+		var ops []string
+		tx.Use(func(ctx context.Context, op string, next func(ctx context.Context) error) error {
+			ops = append(ops, op)
+			return next(ctx)
+		})
+
+		var name string
+		r.NoError(tx.QueryRow(ctx, "SELECT MAX(name) FROM users WHERE name <> ?", "Ringo").Scan(&name))
+		r.Equal("Mark", name)
+		r.Equal([]string{"QueryRow"}, ops)
+
+		err := tx.QueryRow(ctx, "SELECT name FROM users WHERE name = ?", "Unknown").Scan(&name)
+		r.Equal(sql.ErrNoRows, err)
+
+		err = tx.QueryRow(ctx, "SELECT name FROM unknown_table").Scan(&name)
+		r.Error(err)
+
+		fail := errors.New("middleware failed")
+		tx.Use(func(ctx context.Context, op string, next func(ctx context.Context) error) error {
+			if err := next(ctx); err != nil {
+				return err
+			}
+			return fail
+		})
+		err = tx.QueryRow(ctx, "SELECT name FROM users").Scan(&name)
+		r.Equal(fail, errors.Cause(err))
+	})
+
+	err := PDB.DryRun(DryRunOptions{RejectSelects: true}).QueryRow(context.Background(), "SELECT 1").Scan(new(int))
+	r.Equal(ErrDryRun, errors.Cause(err))
+}
@@ -68,25 +68,14 @@ func (fm *FileMigrator) findMigrations() error {
 				DBType:    dbType,
 				Direction: m[4],
 				Type:      m[5],
-				Runner: func(mf Migration, tx *Connection) error {
+				Runner:    runContent,
+				Content: func(mf Migration, c *Connection) (string, error) {
 					f, err := os.Open(p)
 					if err != nil {
-						return errors.WithStack(err)
+						return "", errors.WithStack(err)
 					}
-					content, err := migrationContent(mf, tx, f)
-					if err != nil {
-						return errors.Wrapf(err, "error processing %s", mf.Path)
-					}
-
-					if content == "" {
-						return nil
-					}
-
-					err = tx.RawQuery(content).Exec()
-					if err != nil {
-						return errors.Wrapf(err, "error executing %s, sql: %s", mf.Path, content)
-					}
-					return nil
+					defer f.Close()
+					return migrationContent(mf, c, f)
 				},
 			}
 			fm.Migrations[mf.Direction] = append(fm.Migrations[mf.Direction], mf)
@@ -10,15 +10,64 @@ import (
 	"strings"
 
 	"github.com/gobuffalo/pop/associations"
+	"github.com/gobuffalo/pop/columns"
 	"github.com/gobuffalo/pop/logging"
 	"github.com/gofrs/uuid"
 	"github.com/pkg/errors"
- 	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
 )
 
 var rLimitOffset = regexp.MustCompile("(?i)(limit [0-9]+ offset [0-9]+)$")
 var rLimit = regexp.MustCompile("(?i)(limit [0-9]+)$")
 
+// rQualifiedColumn matches the qualified columns, e.g. "users.id" or
+// "billing.invoices.id", of the field counted by CountByField.
+var rQualifiedColumn = regexp.MustCompile(`(?:[A-Za-z_][A-Za-z0-9_]*\.)+([A-Za-z_][A-Za-z0-9_]*|\*)`)
+
+// ErrRecordNotFound is returned by Find, First and Last when no record
+// matches the query. The error still has sql.ErrNoRows as its cause.
+//
+//	err := c.Find(ctx, &user, id)
+//	if errors.Is(err, pop.ErrRecordNotFound) {
+//		return nil, ErrUnknownUser
+//	}
+var ErrRecordNotFound = errors.New("record not found")
+
+// IsNotFound tells if err, or an error it wraps, is ErrRecordNotFound or
+// sql.ErrNoRows.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrRecordNotFound) || errors.Is(err, sql.ErrNoRows)
+}
+
+// notFoundError marks a sql.ErrNoRows error as ErrRecordNotFound, keeping
+// sql.ErrNoRows as its cause.
+type notFoundError struct {
+	err error
+}
+
+func (e notFoundError) Error() string {
+	return ErrRecordNotFound.Error() + ": " + e.err.Error()
+}
+
+func (e notFoundError) Is(target error) bool {
+	return target == ErrRecordNotFound
+}
+
+func (e notFoundError) Cause() error {
+	return e.err
+}
+
+func (e notFoundError) Unwrap() error {
+	return e.err
+}
+
+// notFound marks err as ErrRecordNotFound if it's caused by sql.ErrNoRows.
+func notFound(err error) error {
+	if errors.Cause(err) != sql.ErrNoRows {
+		return err
+	}
+	return notFoundError{err}
+}
+
 // Find the first record of the model in the database with a particular id.
 //
 //	c.Find(&User{}, 1)
@@ -27,14 +76,14 @@ func (c *Connection) Find(ctx context.Context, model interface{}, id interface{}
 }
 
 // Find the first record of the model in the database with a particular id.
+// It returns ErrRecordNotFound if there's no such record.
 //
 //	q.Find(&User{}, 1)
 func (q *Query) Find(ctx context.Context, model interface{}, id interface{}) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "pop/finders/Find")
-	defer span.Finish()
-
-	m := &Model{Value: model}
-	idq := m.whereID()
+	m := q.Connection.modelContext(ctx, model)
+	// the table is aliased by the select, so the id is qualified by the
+	// alias, not by the schema-qualified table name
+	idq := fmt.Sprintf("%s.id = ?", m.alias())
 	switch t := id.(type) {
 	case uuid.UUID:
 		return q.Where(idq, t.String()).First(ctx, model)
@@ -64,15 +113,16 @@ func (c *Connection) First(ctx context.Context, model interface{}) error {
 }
 
 // First record of the model in the database that matches the query.
+// It returns ErrRecordNotFound if no record matches.
 //
 //	q.Where("name = ?", "mark").First(&User{})
 func (q *Query) First(ctx context.Context, model interface{}) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "pop/finders/First")
-	defer span.Finish()
-
-	err := q.Connection.timeFunc("First", func() error {
+	if q.err != nil {
+		return q.err
+	}
+	err := q.timeFunc(ctx, "First", readOp, model, func(ctx context.Context) error {
 		q.Limit(1)
-		m := &Model{Value: model}
+		m := q.Connection.modelContext(ctx, model)
 		if err := q.Connection.Dialect.SelectOne(q.Connection.Store, m, *q); err != nil {
 			return err
 		}
@@ -80,7 +130,7 @@ func (q *Query) First(ctx context.Context, model interface{}) error {
 	})
 
 	if err != nil {
-		return err
+		return notFound(err)
 	}
 
 	if q.eager {
@@ -99,16 +149,21 @@ func (c *Connection) Last(ctx context.Context, model interface{}) error {
 }
 
 // Last record of the model in the database that matches the query.
+// It returns ErrRecordNotFound if no record matches. The records are
+// ordered by created_at and id, except for a RawQuery, which keeps its
+// own order.
 //
 //	q.Where("name = ?", "mark").Last(&User{})
 func (q *Query) Last(ctx context.Context, model interface{}) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "pop/finders/Last")
-	defer span.Finish()
-
-	err := q.Connection.timeFunc("Last", func() error {
+	if q.err != nil {
+		return q.err
+	}
+	err := q.timeFunc(ctx, "Last", readOp, model, func(ctx context.Context) error {
 		q.Limit(1)
-		q.Order("created_at DESC, id DESC")
-		m := &Model{Value: model}
+		if q.RawSQL.Fragment == "" {
+			q.Order("created_at DESC, id DESC")
+		}
+		m := q.Connection.modelContext(ctx, model)
 		if err := q.Connection.Dialect.SelectOne(q.Connection.Store, m, *q); err != nil {
 			return err
 		}
@@ -116,7 +171,7 @@ func (q *Query) Last(ctx context.Context, model interface{}) error {
 	})
 
 	if err != nil {
-		return err
+		return notFound(err)
 	}
 
 	if q.eager {
@@ -139,12 +194,14 @@ func (c *Connection) All(ctx context.Context, models interface{}) error {
 //
 //	q.Where("name = ?", "mark").All(&[]User{})
 func (q *Query) All(ctx context.Context, models interface{}) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "pop/finders/All")
-	defer span.Finish()
-	span.SetTag("models", reflect.TypeOf(models).String())
-	err := q.Connection.timeFunc("All", func() error {
-		m := &Model{Value: models}
+	if q.err != nil {
+		return q.err
+	}
+	err := q.timeFunc(ctx, "All", readOp, models, func(ctx context.Context) error {
+		m := q.Connection.modelContext(ctx, models)
+		release := q.preallocate(models)
 		err := q.Connection.Dialect.SelectMany(q.Connection.Store, m, *q)
+		release()
 		if err != nil {
 			return err
 		}
@@ -168,16 +225,46 @@ func (q *Query) All(ctx context.Context, models interface{}) error {
 	return nil
 }
 
-func (q *Query) paginateModel(ctx context.Context, models interface{}) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "pop/finders/paginateModel")
-	defer span.Finish()
-	span.SetTag("models", reflect.TypeOf(models).String())
+// maxPreallocatedRows caps the rows preallocated by All, a large limit
+// being more often a guard than the expected count.
+const maxPreallocatedRows = 10000
+
+// preallocate grows the capacity of the slice models to the count of rows
+// expected by the query: the rows of a page, or its limit. The returned
+// function restores a nil slice when no row was appended.
+func (q *Query) preallocate(models interface{}) func() {
+	n := int(q.limitResults)
+	if q.Paginator != nil {
+		n = q.Paginator.PerPage
+	}
+	v := reflect.ValueOf(models)
+	if n <= 0 || v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return func() {}
+	}
+	if n > maxPreallocatedRows {
+		n = maxPreallocatedRows
+	}
+	v = v.Elem()
+	if v.Cap()-v.Len() >= n {
+		return func() {}
+	}
+	wasNil := v.IsNil()
+	grown := reflect.MakeSlice(v.Type(), v.Len(), v.Len()+n)
+	reflect.Copy(grown, v)
+	v.Set(grown)
+	return func() {
+		if wasNil && v.Len() == 0 {
+			v.Set(reflect.Zero(v.Type()))
+		}
+	}
+}
 
+func (q *Query) paginateModel(ctx context.Context, models interface{}) error {
 	if q.Paginator == nil {
 		return nil
 	}
 
-	ct, err := q.Count(models)
+	ct, err := q.countByField(ctx, models, "*")
 	if err != nil {
 		return err
 	}
@@ -198,8 +285,6 @@ func (q *Query) paginateModel(ctx context.Context, models interface{}) error {
 // tx.First(&u)
 // tx.Load(&u)
 func (c *Connection) Load(ctx context.Context, model interface{}, fields ...string) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "pop/finders/Load")
-	defer span.Finish()
 	q := Q(c)
 	q.eagerFields = fields
 	err := q.eagerAssociations(ctx, model)
@@ -208,9 +293,10 @@ func (c *Connection) Load(ctx context.Context, model interface{}, fields ...stri
 }
 
 func (q *Query) eagerAssociations(ctx context.Context, model interface{}) error {
-	span, ctx := tracer.StartSpanFromContext(ctx, "pop/finders/eagerAssociations")
-	defer span.Finish()
-	span.SetTag("model", reflect.TypeOf(model).String())
+	if _, ok := contextTableNameAble(model); ok {
+		// the tables of its associations can't be told from its own
+		return errors.Errorf("the associations of %T can't be loaded: its table name depends on the context", model)
+	}
 
 	var err error
 
@@ -220,6 +306,10 @@ func (q *Query) eagerAssociations(ctx context.Context, model interface{}) error
 		reflect.Indirect(v).Kind() == reflect.Array {
 		v = v.Elem()
 		for i := 0; i < v.Len(); i++ {
+			// stop the fan-out as soon as the caller gives up
+			if err := ctx.Err(); err != nil {
+				return errors.Wrapf(err, "eager loading of %T canceled at element %d", model, i)
+			}
 			err = q.eagerAssociations(ctx, v.Index(i).Addr().Interface())
 			if err != nil {
 				return err
@@ -228,7 +318,23 @@ func (q *Query) eagerAssociations(ctx context.Context, model interface{}) error
 		return err
 	}
 
-	assos, err := associations.ForStruct(model, q.eagerFields...)
+	if err := checkAssociations(model); err != nil {
+		return err
+	}
+
+	fields, selects, err := eagerSelections(q.eagerFields, q.eagerSelects)
+	if err != nil {
+		return err
+	}
+	fields, load, err := eagerLoadFields(model, fields, q.eagerLoad)
+	if err != nil {
+		return err
+	}
+	if !load {
+		return nil
+	}
+
+	assos, err := associations.ForStruct(model, fields...)
 	if err != nil {
 		return err
 	}
@@ -237,56 +343,239 @@ func (q *Query) eagerAssociations(ctx context.Context, model interface{}) error
 	q.eager = false
 	q.Connection.eager = false
 
+	if q.eagerParallel > 1 && q.Connection.TX == nil {
+		return q.eagerParallelAssociations(ctx, model, assos, selects)
+	}
+
 	for _, association := range assos {
 		if association.Skipped() {
 			continue
 		}
+		if err := ctx.Err(); err != nil {
+			return errors.Wrapf(err, "eager loading of %T in %T canceled", association.Interface(), model)
+		}
+		if err := loadAssociation(ctx, q.Connection, model, association, selects); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		query := Q(q.Connection)
+// loadAssociation loads the association of model with the connection c,
+// then its inner associations.
+func loadAssociation(ctx context.Context, c *Connection, model interface{}, association associations.Association, selects map[string][]string) error {
+	var err error
+	query := Q(c)
 
-		whereCondition, args := association.Constraint()
-		query = query.Where(whereCondition, args...)
+	whereCondition, args := association.Constraint()
+	query = query.Where(whereCondition, args...)
 
-		// validates if association is Sortable
-		sortable := (*associations.AssociationSortable)(nil)
-		t := reflect.TypeOf(association)
-		if t.Implements(reflect.TypeOf(sortable).Elem()) {
-			m := reflect.ValueOf(association).MethodByName("OrderBy")
-			out := m.Call([]reflect.Value{})
-			orderClause := out[0].String()
-			if orderClause != "" {
-				query = query.Order(orderClause)
-			}
+	if orderClause := associationOrder(association); orderClause != "" {
+		query = query.Order(orderClause)
+	}
+
+	dest := association.Interface()
+	if cols := selects[associationField(model, dest)]; len(cols) > 0 {
+		if query, err = selectAssociation(query, dest, association, cols); err != nil {
+			return err
 		}
+	}
 
-		sqlSentence, args := query.ToSQL(&Model{Value: association.Interface()})
-		query = query.RawQuery(sqlSentence, args...)
+	qctx := ctx
+	if c.debugEager {
+		qctx = debugAssociation(ctx, model, dest, association, query)
+	}
 
-		if association.Kind() == reflect.Slice || association.Kind() == reflect.Array {
-			err = query.All(ctx, association.Interface())
-		}
+	if association.Kind() == reflect.Slice || association.Kind() == reflect.Array {
+		err = query.All(qctx, dest)
+	}
 
-		if association.Kind() == reflect.Struct {
-			err = query.First(ctx, association.Interface())
-		}
+	if association.Kind() == reflect.Struct {
+		err = query.First(qctx, dest)
+	}
 
-		if err != nil && errors.Cause(err) != sql.ErrNoRows {
+	if err != nil && !IsNotFound(err) {
+		return err
+	}
+
+	// load all inner associations.
+	innerAssociations := association.InnerAssociations()
+	for _, inner := range innerAssociations {
+		v := reflect.Indirect(reflect.ValueOf(model)).FieldByName(inner.Name)
+		innerQuery := Q(query.Connection)
+		innerQuery.eagerFields = []string{inner.Fields}
+		innerQuery.eagerSelects = innerSelections(selects, inner.Name)
+		err = innerQuery.eagerAssociations(ctx, v.Addr().Interface())
+		if err != nil {
 			return err
 		}
+	}
+	return nil
+}
 
-		// load all inner associations.
-		innerAssociations := association.InnerAssociations()
-		for _, inner := range innerAssociations {
-			v = reflect.Indirect(reflect.ValueOf(model)).FieldByName(inner.Name)
-			innerQuery := Q(query.Connection)
-			innerQuery.eagerFields = []string{inner.Fields}
-			err = innerQuery.eagerAssociations(ctx, v.Addr().Interface())
-			if err != nil {
-				return err
+// eagerDebug describes an association loaded by a connection returned by
+// DebugEager.
+type eagerDebug struct {
+	name       string
+	constraint string
+	args       []interface{}
+	sql        string
+	sqlArgs    []interface{}
+}
+
+type eagerDebugKey struct{}
+
+// debugAssociation logs the association of model loaded into dest with
+// query, and returns a context carrying the same for TracingMiddleware, the
+// args redacted.
+func debugAssociation(ctx context.Context, model interface{}, dest interface{}, association associations.Association, query *Query) context.Context {
+	d := eagerDebug{name: associationField(model, dest)}
+	if d.name == "" {
+		d.name = fmt.Sprintf("%T", dest)
+	}
+	d.constraint, d.args = association.Constraint()
+	if association.Kind() == reflect.Struct {
+		query.Limit(1)
+	}
+	var err error
+	d.sql, d.sqlArgs, err = query.ToSQL(query.Connection.model(dest))
+	if err != nil {
+		d.sql = err.Error()
+	}
+	deets := query.Connection.details()
+	d.args = redactArgs(deets, d.constraint, d.args)
+	d.sqlArgs = redactArgs(deets, d.sql, d.sqlArgs)
+
+	query.Connection.log(logging.Eager, "%T.%s: constraint %q %v, query %q %v", model, d.name, d.constraint, d.args, d.sql, d.sqlArgs)
+	return context.WithValue(ctx, eagerDebugKey{}, d)
+}
+
+// associationOrder returns the order clause of the associated models, if
+// the association is sortable. An OrderBy method of another signature
+// doesn't make the association sortable.
+func associationOrder(association associations.Association) string {
+	if s, ok := association.(associations.AssociationSortable); ok {
+		return s.OrderBy()
+	}
+	return ""
+}
+
+// associationField returns the name of the field of model the associated
+// models are loaded into, dest being returned by the Interface method of
+// the association.
+func associationField(model interface{}, dest interface{}) string {
+	v := reflect.Indirect(reflect.ValueOf(model))
+	for i := 0; i < v.NumField(); i++ {
+		if v.Type().Field(i).PkgPath != "" {
+			continue
+		}
+		// dest is the field, or the pointer stored in the field
+		f := v.Field(i)
+		if (f.Kind() == reflect.Ptr && f.Interface() == dest) || (f.CanAddr() && f.Addr().Interface() == dest) {
+			return v.Type().Field(i).Name
+		}
+	}
+	return ""
+}
+
+var rEagerSelect = regexp.MustCompile(`^([^\[\]]*)(?:\[([^\[\]]*)\])?$`)
+
+// eagerSelections strips the columns listed after the associations of the
+// eager fields, e.g. "Books[id,title].Writers[id]", and adds them to the
+// columns selected with EagerSelect, by association path.
+func eagerSelections(fields []string, selected map[string][]string) ([]string, map[string][]string, error) {
+	selects := map[string][]string{}
+	for path, cols := range selected {
+		selects[path] = append(selects[path], cols...)
+	}
+
+	stripped := make([]string, 0, len(fields))
+	for _, field := range fields {
+		names := strings.Split(field, ".")
+		for i, n := range names {
+			m := rEagerSelect.FindStringSubmatch(n)
+			if m == nil {
+				return nil, nil, errors.Errorf("association %q does not match the format '<field>[<column>,...]'", field)
+			}
+			names[i] = strings.TrimSpace(m[1])
+			path := strings.Join(names[:i+1], ".")
+			for _, c := range strings.Split(m[2], ",") {
+				if c = strings.TrimSpace(c); c != "" {
+					selects[path] = append(selects[path], c)
+				}
 			}
 		}
+		stripped = append(stripped, strings.Join(names, "."))
 	}
-	return nil
+	return stripped, selects, nil
+}
+
+// eagerLoadFields restricts the eager fields of model to the associations
+// listed by EagerLoad, if any: all the listed associations when fields is
+// empty. It returns false when no association is left to load.
+func eagerLoadFields(model interface{}, fields []string, listed []string) ([]string, bool, error) {
+	if listed == nil {
+		return fields, true, nil
+	}
+	t := reflect.Indirect(reflect.ValueOf(model)).Type()
+	allowed := map[string]bool{}
+	var names []string
+	for _, name := range listed {
+		name = strings.TrimSpace(name)
+		if _, ok := t.FieldByName(name); !ok {
+			return nil, false, errors.Errorf("field %s does not exist in model %s", name, t.Name())
+		}
+		allowed[name] = true
+		names = append(names, name)
+	}
+	if len(fields) == 0 {
+		return names, len(names) > 0, nil
+	}
+	var restricted []string
+	for _, f := range fields {
+		if allowed[strings.TrimSpace(strings.SplitN(f, ".", 2)[0])] {
+			restricted = append(restricted, f)
+		}
+	}
+	return restricted, len(restricted) > 0, nil
+}
+
+// innerSelections returns the columns selected in the associations nested
+// in the association name.
+func innerSelections(selects map[string][]string, name string) map[string][]string {
+	inner := map[string][]string{}
+	for path, cols := range selects {
+		if strings.HasPrefix(path, name+".") {
+			inner[strings.TrimPrefix(path, name+".")] = cols
+		}
+	}
+	return inner
+}
+
+// selectAssociation restricts the query loading the association to the
+// given columns of dest, adding the id and key columns of the association.
+func selectAssociation(query *Query, dest interface{}, association associations.Association, cols []string) (*Query, error) {
+	m := query.Connection.model(dest)
+	known := columns.ForStruct(dest, m.TableName()).Cols
+	for _, c := range cols {
+		if _, ok := known[c]; !ok {
+			return query, errors.Errorf("%s is not a column of %s", c, m.TableName())
+		}
+	}
+
+	keys := []string{"id"}
+	if k, ok := association.(associations.AssociationKeyed); ok {
+		keys = append(keys, k.KeyColumns()...)
+	}
+	seen := map[string]bool{}
+	var selected []string
+	for _, c := range append(append([]string(nil), cols...), keys...) {
+		if _, ok := known[c]; ok && !seen[c] {
+			seen[c] = true
+			selected = append(selected, c)
+		}
+	}
+	return query.Select(selected...), nil
 }
 
 // Exists returns true/false if a record exists in the database that matches
@@ -294,16 +583,27 @@ func (q *Query) eagerAssociations(ctx context.Context, model interface{}) error
 //
 // 	q.Where("name = ?", "mark").Exists(&User{})
 func (q *Query) Exists(model interface{}) (bool, error) {
+	if q.err != nil {
+		return false, q.err
+	}
+	if err := q.Connection.checkTableContext(model); err != nil {
+		return false, err
+	}
 	tmpQuery := Q(q.Connection)
-	q.Clone(tmpQuery) //avoid meddling with original query
+	q.Clone(tmpQuery) // the clone can be modified without meddling with the original query
 
 	var res bool
 
-	err := tmpQuery.Connection.timeFunc("Exists", func() error {
+	err := tmpQuery.timeFunc(tmpQuery.Connection.txContext(), "Exists", readOp, model, func(ctx context.Context) error {
 		tmpQuery.Paginator = nil
 		tmpQuery.orderClauses = clauses{}
 		tmpQuery.limitResults = 0
-		query, args := tmpQuery.ToSQL(&Model{Value: model})
+		tmpQuery.offsetResults = 0
+		sb := tmpQuery.toSQLBuilder(tmpQuery.Connection.model(model))
+		query, args, err := sb.toSQL()
+		if err != nil {
+			return err
+		}
 
 		// when query contains custom selected fields / executed using RawQuery,
 		// sql may already contains limit and offset
@@ -315,9 +615,17 @@ func (q *Query) Exists(model interface{}) (bool, error) {
 			query = query[0 : len(query)-len(foundLimit)]
 		}
 
-		existsQuery := fmt.Sprintf("SELECT EXISTS (%s)", query)
-		log(logging.SQL, existsQuery, args...)
-		return q.Connection.Store.Get(&res, existsQuery, args...)
+		hint, query := leadingHint(query)
+		comment, query := sb.leadingComment(query)
+		with, query := sb.leadingWith(query)
+		asOf, query := sb.hoistAsOf(query)
+		existsQuery := fmt.Sprintf("%s%s%sSELECT EXISTS (%s)", hint, comment, with, query)
+		if asOf != "" {
+			// AS OF SYSTEM TIME follows a FROM clause
+			existsQuery += " FROM (VALUES (1)) AS pop_as_of" + asOf
+		}
+		q.Connection.log(logging.SQL, existsQuery, args...)
+		return tmpQuery.Connection.Store.Get(&res, existsQuery, args...)
 	})
 	return res, err
 }
@@ -340,16 +648,33 @@ func (q Query) Count(model interface{}) (int, error) {
 //
 //	q.Where("sex = ?", "f").Count(&User{}, "name")
 func (q Query) CountByField(model interface{}, field string) (int, error) {
+	if err := q.Connection.checkTableContext(model); err != nil {
+		return 0, err
+	}
+	return q.countByField(q.Connection.txContext(), model, field)
+}
+
+// countByField counts the records like CountByField, the table name of the
+// model being resolved in ctx.
+func (q Query) countByField(ctx context.Context, model interface{}, field string) (int, error) {
+	if q.err != nil {
+		return 0, q.err
+	}
 	tmpQuery := Q(q.Connection)
-	q.Clone(tmpQuery) //avoid meddling with original query
+	q.Clone(tmpQuery) // the clone can be modified without meddling with the original query
 
 	res := &rowCount{}
 
-	err := tmpQuery.Connection.timeFunc("CountByField", func() error {
+	err := tmpQuery.timeFunc(ctx, "CountByField", readOp, model, func(ctx context.Context) error {
 		tmpQuery.Paginator = nil
 		tmpQuery.orderClauses = clauses{}
 		tmpQuery.limitResults = 0
-		query, args := tmpQuery.ToSQL(&Model{Value: model})
+		tmpQuery.offsetResults = 0
+		sb := tmpQuery.toSQLBuilder(tmpQuery.Connection.modelContext(ctx, model))
+		query, args, err := sb.toSQL()
+		if err != nil {
+			return err
+		}
 		//when query contains custom selected fields / executed using RawQuery,
 		//	sql may already contains limit and offset
 
@@ -361,9 +686,15 @@ func (q Query) CountByField(model interface{}, field string) (int, error) {
 			query = query[0 : len(query)-len(foundLimit)]
 		}
 
-		countQuery := fmt.Sprintf("SELECT COUNT(%s) AS row_count FROM (%s) a", field, query)
-		log(logging.SQL, countQuery, args...)
-		return q.Connection.Store.Get(res, countQuery, args...)
+		hint, query := leadingHint(query)
+		comment, query := sb.leadingComment(query)
+		with, query := sb.leadingWith(query)
+		asOf, query := sb.hoistAsOf(query)
+		// the columns of the wrapped query are the columns of a
+		field := rQualifiedColumn.ReplaceAllString(field, "a.$1")
+		countQuery := fmt.Sprintf("%s%s%sSELECT COUNT(%s) AS row_count FROM (%s) a%s", hint, comment, with, field, query, asOf)
+		q.Connection.log(logging.SQL, countQuery, args...)
+		return tmpQuery.Connection.Store.Get(res, countQuery, args...)
 	})
 	return res.Count, err
 }
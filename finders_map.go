@@ -0,0 +1,125 @@
+package pop
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+)
+
+// AllIndexedBy retrieves all of the records matching the query, like All,
+// into a map keyed by their column, see Query.AllIndexedBy.
+//
+//	c.AllIndexedBy(ctx, &map[uuid.UUID]User{}, "id")
+func (c *Connection) AllIndexedBy(ctx context.Context, models interface{}, column string) error {
+	return Q(c).AllIndexedBy(ctx, models, column)
+}
+
+// AllIndexedBy retrieves all of the records matching the query, like All,
+// into the map pointed to by models, keyed by the value of their column.
+// The values of the map are the models, or pointers to them. It fails if
+// two records have the same key, e.g. for a column which isn't unique.
+//
+//	users := map[uuid.UUID]User{}
+//	err := q.Where("active = ?", true).AllIndexedBy(ctx, &users, "id")
+//
+// The field of the column must be assignable to the type of the keys, or
+// both must be numbers, e.g. an int64 column keying a map[int]. The map is
+// replaced, rather than added to. The records are read by All into a
+// slice, then put in the map, so the callbacks, eager loading and
+// pagination of the query apply.
+func (q *Query) AllIndexedBy(ctx context.Context, models interface{}, column string) error {
+	return q.allMapped(ctx, models, column, false)
+}
+
+// AllGroupedBy retrieves all of the records matching the query, like All,
+// into a map of slices keyed by their column, see Query.AllGroupedBy.
+//
+//	c.AllGroupedBy(ctx, &map[uuid.UUID][]Order{}, "user_id")
+func (c *Connection) AllGroupedBy(ctx context.Context, models interface{}, column string) error {
+	return Q(c).AllGroupedBy(ctx, models, column)
+}
+
+// AllGroupedBy retrieves all of the records matching the query, like All,
+// into the map of slices pointed to by models, grouped by the value of
+// their column. The records of a group are in the order of the query.
+//
+//	orders := map[uuid.UUID][]Order{}
+//	err := q.Where("user_id IN (?)", ids...).Order("created_at").AllGroupedBy(ctx, &orders, "user_id")
+//
+// The keys are typed like the ones of AllIndexedBy.
+func (q *Query) AllGroupedBy(ctx context.Context, models interface{}, column string) error {
+	return q.allMapped(ctx, models, column, true)
+}
+
+// allMapped retrieves the records of the query into the map models, keyed
+// by column: their slices if grouped, else the records.
+func (q *Query) allMapped(ctx context.Context, models interface{}, column string, grouped bool) error {
+	if q.err != nil {
+		return q.err
+	}
+	v := reflect.ValueOf(models)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Map {
+		return errors.Errorf("%T is not a pointer to a map", models)
+	}
+	mt := v.Elem().Type()
+	elem := mt.Elem()
+	if grouped {
+		if elem.Kind() != reflect.Slice {
+			return errors.Errorf("the values of %s are not slices", mt)
+		}
+		elem = elem.Elem()
+	}
+	t := reflectx.Deref(elem)
+	if t.Kind() != reflect.Struct {
+		return errors.Errorf("%s is not a model", elem)
+	}
+	fi, ok := strictMapper.TypeMap(t).Names[column]
+	if !ok {
+		return errors.Errorf("%s is not a column of %s", column, t)
+	}
+	kt := mt.Key()
+	if !fi.Field.Type.AssignableTo(kt) && !(numericKind(fi.Field.Type) && numericKind(kt)) {
+		return errors.Errorf("a %s column can't key a %s", fi.Field.Type, mt)
+	}
+
+	rows := reflect.New(reflect.SliceOf(elem))
+	if err := q.All(ctx, rows.Interface()); err != nil {
+		return err
+	}
+	rows = rows.Elem()
+	m := reflect.MakeMapWithSize(mt, rows.Len())
+	for i := 0; i < rows.Len(); i++ {
+		row := rows.Index(i)
+		key := reflectx.FieldByIndexesReadOnly(reflect.Indirect(row), fi.Index)
+		if !key.Type().AssignableTo(kt) {
+			key = key.Convert(kt)
+		}
+		if grouped {
+			group := m.MapIndex(key)
+			if !group.IsValid() {
+				group = reflect.MakeSlice(mt.Elem(), 0, 1)
+			}
+			m.SetMapIndex(key, reflect.Append(group, row))
+			continue
+		}
+		if m.MapIndex(key).IsValid() {
+			return errors.Errorf("duplicate %s %v of %s", column, key.Interface(), t)
+		}
+		m.SetMapIndex(key, row)
+	}
+	v.Elem().Set(m)
+	return nil
+}
+
+// numericKind tells if t is an integer or a floating-point number.
+func numericKind(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package pop
+
+import (
+	"testing"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_AllIndexedBy_AllGroupedBy(t *testing.T) {
+	r := require.New(t)
+	ctx := PDB.txContext()
+
+	var ids []int
+	for _, name := range []string{"Indexed", "Grouped"} {
+		u := &parallelUser{Name: nulls.NewString(name)}
+		r.NoError(PDB.Create(u))
+		defer PDB.RawQuery("DELETE FROM users WHERE id = ?", u.ID).Exec()
+		defer PDB.RawQuery("DELETE FROM books WHERE user_id = ?", u.ID).Exec()
+		ids = append(ids, u.ID)
+	}
+	for i, title := range []string{"B", "A", "C"} {
+		r.NoError(PDB.Create(&Book{Title: title, UserID: nulls.NewInt(ids[i%2])}))
+	}
+
+	users := map[int]parallelUser{}
+	r.NoError(PDB.Where("id IN (?)", ids[0], ids[1]).Eager("Titles").AllIndexedBy(ctx, &users, "id"))
+	r.Len(users, 2)
+	r.Equal("Indexed", users[ids[0]].Name.String)
+	r.Len(users[ids[0]].Titles, 2)
+	r.Len(users[ids[1]].Titles, 1)
+
+	ptrs := map[int64]*parallelUser{}
+	r.NoError(PDB.Where("id = ?", ids[1]).AllIndexedBy(ctx, &ptrs, "id"))
+	r.Len(ptrs, 1)
+	r.Equal("Grouped", ptrs[int64(ids[1])].Name.String)
+
+	books := map[nulls.Int][]titledBook{}
+	r.NoError(PDB.Where("user_id IN (?)", ids[0], ids[1]).Order("title").AllGroupedBy(ctx, &books, "user_id"))
+	r.Len(books, 2)
+	first := books[nulls.NewInt(ids[0])]
+	r.Len(first, 2)
+	r.Equal("B", first[0].Title)
+	r.Equal("C", first[1].Title)
+	r.Len(books[nulls.NewInt(ids[1])], 1)
+
+	// the map is replaced
+	r.NoError(PDB.Where("user_id = ?", -1).AllGroupedBy(ctx, &books, "user_id"))
+	r.Len(books, 0)
+
+	err := PDB.Where("user_id = ?", ids[0]).AllIndexedBy(ctx, &map[nulls.Int]titledBook{}, "user_id")
+	r.Error(err)
+	r.Contains(err.Error(), "duplicate user_id")
+
+	r.Error(PDB.AllIndexedBy(ctx, &map[int]titledBook{}, "user_id"))
+	r.Error(PDB.AllIndexedBy(ctx, &map[int]titledBook{}, "author"))
+	// an int id doesn't key a map of strings, as a rune
+	r.Error(PDB.AllIndexedBy(ctx, &map[string]User{}, "id"))
+	int64s := map[int64]User{}
+	r.NoError(PDB.Where("id = ?", ids[1]).AllIndexedBy(ctx, &int64s, "id"))
+	r.Contains(int64s, int64(ids[1]))
+	r.Error(PDB.AllIndexedBy(ctx, &[]titledBook{}, "id"))
+	r.Error(PDB.AllGroupedBy(ctx, &map[int]titledBook{}, "id"))
+}
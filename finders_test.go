@@ -2,10 +2,17 @@ package pop
 
 import (
 	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
 	"testing"
+	"time"
 
 	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/pop/associations"
+	"github.com/gobuffalo/pop/logging"
 	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -79,7 +86,7 @@ func Test_Select(t *testing.T) {
 		q := tx.Select("name", "email", "\n", "\t\n", "")
 
 		sm := &Model{Value: &User{}}
-		sql, _ := q.ToSQL(sm)
+		sql := q.ToSQLString(sm)
 		r.Equal(tx.Dialect.TranslateSQL("SELECT email, name FROM users AS users"), sql)
 
 		u := User{}
@@ -448,6 +455,12 @@ func Test_Last(t *testing.T) {
 		r.NoError(err)
 
 		r.Equal(last.ID, u.ID)
+
+		// a raw query keeps its own order
+		u = User{}
+		err = tx.RawQuery("select * from users where name = ? order by id asc", "Mark").Last(context.TODO(), &u)
+		r.NoError(err)
+		r.Equal(first.ID, u.ID)
 	})
 }
 
@@ -781,3 +794,274 @@ func Test_FindManyToMany(t *testing.T) {
 		r.NoError(err)
 	})
 }
+
+func Test_Load_Canceled(t *testing.T) {
+	r := require.New(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// cancel the context as soon as the books of the first user are loaded
+	queries := 0
+	c := PDB.copy()
+	c.Use(func(ctx context.Context, op string, next func(ctx context.Context) error) error {
+		queries++
+		err := next(ctx)
+		cancel()
+		return err
+	})
+
+	users := Users{{ID: 1}, {ID: 2}, {ID: 3}}
+	err := c.Load(ctx, &users, "Books")
+	r.Equal(context.Canceled, errors.Cause(err))
+	r.Contains(err.Error(), "pop.Users")
+	r.Equal(1, queries)
+}
+
+func Test_Eager_Has_Many_Order_By_Args(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		user := User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(&user))
+		other := User{Name: nulls.NewString("Other")}
+		r.NoError(tx.Create(&other))
+
+		for _, b := range []Book{
+			{Title: "C Book", Isbn: "PB3", UserID: nulls.NewInt(user.ID)},
+			{Title: "A Book", Isbn: "PB1", UserID: nulls.NewInt(user.ID)},
+			{Title: "B Book", Isbn: "PB2", UserID: nulls.NewInt(other.ID)},
+		} {
+			r.NoError(tx.Create(&b))
+		}
+
+		// the user_id arg of the association constraint is bound to its
+		// own placeholder, whatever the order clause of the association
+		u := User{}
+		r.NoError(tx.Eager("Books").Find(ctx, &u, user.ID))
+		r.Len(u.Books, 2)
+		r.Equal("A Book", u.Books[0].Title)
+		r.Equal("C Book", u.Books[1].Title)
+
+		users := Users{}
+		r.NoError(tx.Where("name in (?)", "Mark", "Other").Order("name asc").Eager("Books").All(ctx, &users))
+		r.Len(users, 2)
+		r.Len(users[0].Books, 2)
+		r.Equal("A Book", users[0].Books[0].Title)
+		r.Len(users[1].Books, 1)
+		r.Equal("B Book", users[1].Books[0].Title)
+	})
+}
+
+func Test_Find_NotFound(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		u := User{}
+		err := tx.Find(ctx, &u, -1)
+		r.True(errors.Is(err, ErrRecordNotFound))
+		r.True(IsNotFound(errors.Wrap(errors.Wrap(err, "find user"), "handler")))
+		// the callers checking the cause still see sql.ErrNoRows
+		r.Equal(sql.ErrNoRows, errors.Cause(err))
+
+		r.True(errors.Is(tx.Where("id = ?", -1).First(ctx, &u), ErrRecordNotFound))
+		r.True(errors.Is(tx.Where("id = ?", -1).Last(ctx, &u), ErrRecordNotFound))
+
+		r.NoError(tx.Create(&User{Name: nulls.NewString("Mark")}))
+		err = tx.Where("name = ?", "Mark").RawQuery("select * from unknown_table").First(ctx, &u)
+		r.Error(err)
+		r.False(IsNotFound(err))
+		r.False(IsNotFound(nil))
+	})
+}
+
+func Test_Eager_Has_Many_Through(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		user := User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(&user))
+
+		home := Address{Street: "Pop Avenue", HouseNumber: 1}
+		r.NoError(tx.Create(&home))
+		office := Address{Street: "Buffalo Street", HouseNumber: 2}
+		r.NoError(tx.Create(&office))
+		other := Address{Street: "Other Street", HouseNumber: 3}
+		r.NoError(tx.Create(&other))
+
+		// the home address is reached twice
+		for _, a := range []Address{home, office, home} {
+			r.NoError(tx.Create(&UsersAddress{UserID: user.ID, AddressID: a.ID}))
+		}
+
+		res := Resident{}
+		r.NoError(tx.Eager("Addresses").Find(ctx, &res, user.ID))
+		r.Len(res.Addresses, 2)
+		r.Equal("Buffalo Street", res.Addresses[0].Street)
+		r.Equal("Pop Avenue", res.Addresses[1].Street)
+
+		res = Resident{ID: user.ID}
+		r.NoError(tx.Load(ctx, &res, "Addresses"))
+		r.Len(res.Addresses, 2)
+	})
+}
+
+func Test_DebugEager(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		user := User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(&user))
+		r.NoError(tx.Create(&Book{Title: "Pop Book", Isbn: "PB1", UserID: nulls.NewInt(user.ID)}))
+
+		var logs []string
+		oldLog := log
+		defer func() { log = oldLog }()
+		SetLogger(func(lvl logging.Level, s string, args ...interface{}) {
+			if lvl == logging.Eager {
+				logs = append(logs, fmt.Sprintf(s, args...))
+			}
+		})
+
+		var debugged []eagerDebug
+		c := tx.DebugEager()
+		c.Use(func(ctx context.Context, op string, next func(ctx context.Context) error) error {
+			if d, ok := ctx.Value(eagerDebugKey{}).(eagerDebug); ok {
+				debugged = append(debugged, d)
+			}
+			return next(ctx)
+		})
+
+		u := User{}
+		r.NoError(c.Eager("Books").Find(ctx, &u, user.ID))
+		r.Len(u.Books, 1)
+		r.Len(logs, 1)
+		r.Contains(logs[0], "pop.User.Books: constraint \"user_id = ?\"")
+		r.Contains(logs[0], "ORDER BY title asc")
+		r.Len(debugged, 1)
+		r.Equal("Books", debugged[0].name)
+		r.Equal([]interface{}{user.ID}, debugged[0].args)
+
+		// the other connections don't log the associations
+		logs = nil
+		r.NoError(tx.Eager("Books").Find(ctx, &u, user.ID))
+		r.Empty(logs)
+	})
+}
+
+func Test_Eager_Select(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		user := User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(&user))
+		book := Book{Title: "Pop Book", Isbn: "PB1", Description: "a long text", UserID: nulls.NewInt(user.ID)}
+		r.NoError(tx.Create(&book))
+		writer := Writer{Name: "Larry", BookID: book.ID}
+		r.NoError(tx.Create(&writer))
+
+		u := User{}
+		r.NoError(tx.Eager("Books[title].Writers[ name ]").Find(ctx, &u, user.ID))
+		r.Len(u.Books, 1)
+		r.Equal("Pop Book", u.Books[0].Title)
+		r.Empty(u.Books[0].Description)
+		// the id and the foreign key are always loaded
+		r.Equal(book.ID, u.Books[0].ID)
+		r.Equal(user.ID, u.Books[0].UserID.Int)
+		r.Len(u.Books[0].Writers, 1)
+		r.Equal("Larry", u.Books[0].Writers[0].Name)
+		r.Equal(book.ID, u.Books[0].Writers[0].BookID)
+
+		u = User{}
+		r.NoError(tx.Q().EagerSelect("Books", "description").EagerSelect("Books.Writers", "id").Find(ctx, &u, user.ID))
+		r.Len(u.Books, 1)
+		r.Empty(u.Books[0].Title)
+		r.Equal("a long text", u.Books[0].Description)
+		r.Len(u.Books[0].Writers, 1)
+		r.Empty(u.Books[0].Writers[0].Name)
+
+		err := tx.Eager("Books[body]").Find(ctx, &u, user.ID)
+		r.Error(err)
+		r.Contains(err.Error(), "body is not a column of books")
+
+		r.Error(tx.Eager("Books[title").Find(ctx, &u, user.ID))
+	})
+}
+
+// oddOrderAssociation has an OrderBy method not matching
+// associations.AssociationSortable.
+type oddOrderAssociation struct {
+	sortedAssociation
+}
+
+func (oddOrderAssociation) OrderBy() int {
+	return 1
+}
+
+type sortedAssociation struct {
+	order string
+}
+
+func (sortedAssociation) Kind() reflect.Kind                                { return reflect.Slice }
+func (sortedAssociation) Interface() interface{}                            { return &Books{} }
+func (sortedAssociation) Constraint() (string, []interface{})               { return "user_id = ?", []interface{}{1} }
+func (sortedAssociation) InnerAssociations() associations.InnerAssociations { return nil }
+func (sortedAssociation) Skipped() bool                                     { return false }
+func (a sortedAssociation) OrderBy() string                                 { return a.order }
+
+func Test_associationOrder(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal("title asc", associationOrder(sortedAssociation{order: "title asc"}))
+	r.NotPanics(func() {
+		r.Equal("", associationOrder(oddOrderAssociation{}))
+	})
+}
+
+type foundBook struct {
+	ID        int       `db:"id"`
+	Title     string    `db:"title"`
+	Found     bool      `db:"-"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (foundBook) TableName() string {
+	return "books"
+}
+
+func (b *foundBook) AfterFind(tx *Connection) error {
+	b.Found = true
+	return nil
+}
+
+func Test_All_Preallocated(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		r.NoError(tx.Create(&Book{Title: "Preallocated 1"}))
+		r.NoError(tx.Create(&Book{Title: "Preallocated 2"}))
+
+		var none []Book
+		r.NoError(tx.Where("title = ?", "none").Limit(100).All(ctx, &none))
+		r.Nil(none)
+
+		books := []foundBook{{Title: "kept"}}
+		r.NoError(tx.Where("title LIKE ?", "Preallocated%").Order("title").Limit(100).All(ctx, &books))
+		r.Len(books, 3)
+		r.Equal("kept", books[0].Title)
+		r.Equal("Preallocated 1", books[1].Title)
+		r.True(books[1].Found)
+		r.True(books[2].Found)
+
+		pbooks := []*foundBook{}
+		r.NoError(tx.Where("title LIKE ?", "Preallocated%").Paginate(1, 1).All(ctx, &pbooks))
+		r.Len(pbooks, 1)
+	})
+}
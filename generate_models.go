@@ -0,0 +1,304 @@
+package pop
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"go/format"
+	"path"
+	"sort"
+	"strings"
+	"text/template"
+
+	"github.com/gobuffalo/flect"
+	nflect "github.com/gobuffalo/flect/name"
+	"github.com/markbates/going/defaults"
+	"github.com/pkg/errors"
+)
+
+// GenerateOptions configures GenerateModels.
+type GenerateOptions struct {
+	// Package is the package name of the generated files. Defaults to "models".
+	Package string
+	// Include lists the patterns (see path.Match) of the tables to
+	// generate models for. Defaults to all the tables.
+	Include []string
+	// Exclude lists the patterns of the tables to skip.
+	Exclude []string
+	// ModelNames overrides the model name of the given tables, when
+	// singularizing the table name doesn't give the right name.
+	// Example: {"people": "Person"}
+	ModelNames map[string]string
+}
+
+// File is a generated Go source file.
+type File struct {
+	Name    string
+	Content []byte
+}
+
+// GenerateModels introspects the database tables, and generates the
+// model structs mapping them: one file per table. Nullable columns use the
+// nulls types, and belongs_to/has_many associations are inferred from
+// the foreign keys.
+//
+//	files, err := pop.GenerateModels(ctx, c, pop.GenerateOptions{Package: "models"})
+//	for _, f := range files {
+//		ioutil.WriteFile(filepath.Join("models", f.Name), f.Content, 0644)
+//	}
+func GenerateModels(ctx context.Context, c *Connection, opts GenerateOptions) ([]File, error) {
+	names, err := c.TableNames(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tables := map[string]*TableInfo{}
+	for _, name := range names {
+		ok, err := opts.selected(name)
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		ti, err := c.TableInfo(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		tables[name] = ti
+	}
+	return generateModels(opts, tables)
+}
+
+// selected tells if the table matches the include and exclude patterns.
+func (opts GenerateOptions) selected(table string) (bool, error) {
+	for _, p := range opts.Exclude {
+		ok, err := path.Match(p, table)
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid exclude pattern %s", p)
+		}
+		if ok {
+			return false, nil
+		}
+	}
+	if len(opts.Include) == 0 {
+		return true, nil
+	}
+	for _, p := range opts.Include {
+		ok, err := path.Match(p, table)
+		if err != nil {
+			return false, errors.Wrapf(err, "invalid include pattern %s", p)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (opts GenerateOptions) modelName(table string) string {
+	if n, ok := opts.ModelNames[table]; ok {
+		return n
+	}
+	return flect.New(table).Singularize().Pascalize().String()
+}
+
+type genModel struct {
+	Package   string
+	Name      string
+	Plural    string
+	Table     string
+	TableName bool
+	Std       []string
+	Imports   []string
+	Fields    []genField
+}
+
+type genField struct {
+	Name string
+	Type string
+	Tags string
+}
+
+func generateModels(opts GenerateOptions, tables map[string]*TableInfo) ([]File, error) {
+	names := make([]string, 0, len(tables))
+	for n := range tables {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	var files []File
+	for _, n := range names {
+		m := newGenModel(opts, tables[n], tables, names)
+		bb := &bytes.Buffer{}
+		if err := genModelTemplate.Execute(bb, m); err != nil {
+			return nil, errors.Wrapf(err, "could not generate model for table %s", n)
+		}
+		b, err := format.Source(bb.Bytes())
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not format model for table %s", n)
+		}
+		files = append(files, File{Name: flect.Underscore(m.Name) + ".go", Content: b})
+	}
+	return files, nil
+}
+
+func newGenModel(opts GenerateOptions, ti *TableInfo, tables map[string]*TableInfo, names []string) genModel {
+	m := genModel{
+		Package: defaults.String(opts.Package, "models"),
+		Name:    opts.modelName(ti.Name),
+		Table:   ti.Name,
+	}
+	if p := flect.Pascalize(flect.Pluralize(m.Name)); p != m.Name {
+		m.Plural = p
+	}
+	m.TableName = nflect.Tableize(m.Name) != ti.Name
+
+	imports := map[string]bool{}
+	used := map[string]bool{}
+	for _, col := range ti.Columns {
+		typ, imp := genColumnType(col)
+		if imp != "" {
+			imports[imp] = true
+		}
+		f := genField{
+			Name: flect.Pascalize(col.Name),
+			Type: typ,
+			Tags: fmt.Sprintf(`json:"%s" db:"%s"`, col.Name, col.Name),
+		}
+		used[f.Name] = true
+		m.Fields = append(m.Fields, f)
+	}
+
+	// belongs_to associations, from the foreign keys of the table.
+	for _, fk := range ti.ForeignKeys {
+		if _, ok := tables[fk.ReferencedTable]; !ok || !strings.HasSuffix(fk.Column, "_id") {
+			continue
+		}
+		base := strings.TrimSuffix(fk.Column, "_id")
+		f := genField{Name: flect.Pascalize(base), Type: "*" + opts.modelName(fk.ReferencedTable)}
+		if used[f.Name] {
+			continue
+		}
+		f.Tags = fmt.Sprintf(`json:"%s,omitempty" belongs_to:"%s"`, base, flect.Underscore(opts.modelName(fk.ReferencedTable)))
+		if fkField := flect.Pascalize(fk.Column); fkField != f.Name+"ID" {
+			f.Tags += fmt.Sprintf(` fk_id:"%s"`, fkField)
+		}
+		if fk.ReferencedCol != "id" {
+			f.Tags += fmt.Sprintf(` primary_id:"%s"`, flect.Pascalize(fk.ReferencedCol))
+		}
+		used[f.Name] = true
+		m.Fields = append(m.Fields, f)
+	}
+
+	// has_many associations, from the foreign keys referencing the table.
+	for _, n := range names {
+		for _, fk := range tables[n].ForeignKeys {
+			if fk.ReferencedTable != ti.Name || fk.ReferencedCol != "id" {
+				continue
+			}
+			owned := opts.modelName(n)
+			f := genField{Name: flect.Pascalize(flect.Pluralize(owned)), Type: "[]" + owned}
+			if used[f.Name] {
+				f.Name += "By" + flect.Pascalize(strings.TrimSuffix(fk.Column, "_id"))
+			}
+			if used[f.Name] {
+				continue
+			}
+			f.Tags = fmt.Sprintf(`json:"%s,omitempty" has_many:"%s"`, flect.Underscore(f.Name), n)
+			if fk.Column != flect.Underscore(m.Name)+"_id" {
+				f.Tags += fmt.Sprintf(` fk_id:"%s"`, fk.Column)
+			}
+			used[f.Name] = true
+			m.Fields = append(m.Fields, f)
+		}
+	}
+
+	for imp := range imports {
+		if strings.Contains(imp, ".") {
+			m.Imports = append(m.Imports, imp)
+		} else {
+			m.Std = append(m.Std, imp)
+		}
+	}
+	sort.Strings(m.Std)
+	sort.Strings(m.Imports)
+	return m
+}
+
+var genIntTypes = map[string]bool{
+	"int": true, "integer": true, "int2": true, "int4": true, "smallint": true,
+	"mediumint": true, "tinyint": true, "serial": true, "smallserial": true,
+}
+
+// genColumnType returns the Go type for the column, and the
+// package it needs to import.
+func genColumnType(col ColumnInfo) (string, string) {
+	if col.Name == "created_at" || col.Name == "updated_at" {
+		return "time.Time", "time"
+	}
+
+	t := strings.ToLower(col.Type)
+	if strings.HasPrefix(t, "tinyint(1)") {
+		t = "boolean"
+	}
+	if i := strings.Index(t, "("); i >= 0 {
+		t = t[:i]
+	}
+	t = strings.TrimSpace(strings.TrimSuffix(t, " unsigned"))
+
+	typ, nullType, imp := "string", "nulls.String", ""
+	switch {
+	case t == "uuid":
+		typ, nullType, imp = "uuid.UUID", "nulls.UUID", "github.com/gofrs/uuid"
+	case t == "bigint" || t == "int8" || t == "bigserial":
+		typ, nullType = "int64", "nulls.Int64"
+	case genIntTypes[t]:
+		typ, nullType = "int", "nulls.Int"
+	case t == "bool" || t == "boolean":
+		typ, nullType = "bool", "nulls.Bool"
+	case t == "numeric" || t == "decimal" || t == "real" || strings.HasPrefix(t, "float") || strings.HasPrefix(t, "double"):
+		typ, nullType = "float64", "nulls.Float64"
+	case strings.HasPrefix(t, "timestamp") || strings.HasPrefix(t, "date") || strings.HasPrefix(t, "time"):
+		typ, nullType, imp = "time.Time", "nulls.Time", "time"
+	case t == "json" || t == "jsonb":
+		return "slices.Map", "github.com/gobuffalo/pop/slices"
+	case t == "array" || strings.HasSuffix(t, "[]"):
+		return "slices.String", "github.com/gobuffalo/pop/slices"
+	case t == "bytea" || strings.HasSuffix(t, "blob") || strings.HasSuffix(t, "binary"):
+		return "[]byte", ""
+	}
+
+	if col.Nullable {
+		return nullType, "github.com/gobuffalo/nulls"
+	}
+	return typ, imp
+}
+
+var genModelTemplate = template.Must(template.New("model").Parse(`// Code generated by pop.GenerateModels from the {{.Table}} table.
+
+package {{.Package}}
+{{if or .Std .Imports}}
+import (
+	{{range .Std -}}
+	"{{.}}"
+	{{end}}
+	{{range .Imports -}}
+	"{{.}}"
+	{{end -}}
+)
+{{end}}
+type {{.Name}} struct {
+	{{range .Fields -}}
+	{{.Name}} {{.Type}} ` + "`{{.Tags}}`" + `
+	{{end -}}
+}
+{{if .TableName}}
+// TableName overrides the table name used by pop.
+func ({{.Name}}) TableName() string {
+	return "{{.Table}}"
+}
+{{end}}{{if .Plural}}
+// {{.Plural}} is not required by pop and may be deleted
+type {{.Plural}} []{{.Name}}
+{{end}}`))
@@ -0,0 +1,124 @@
+package pop
+
+import (
+	"context"
+	"database/sql"
+	"go/parser"
+	"go/token"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GenerateModels(t *testing.T) {
+	r := require.New(t)
+
+	files, err := GenerateModels(context.Background(), PDB, GenerateOptions{
+		Package: "models",
+		Include: []string{"users", "books"},
+	})
+	r.NoError(err)
+	r.Len(files, 2)
+	r.Equal("book.go", files[0].Name)
+	r.Equal("user.go", files[1].Name)
+
+	for _, f := range files {
+		_, err := parser.ParseFile(token.NewFileSet(), f.Name, f.Content, 0)
+		r.NoError(err)
+	}
+	r.Contains(string(files[1].Content), "type User struct {")
+	r.Regexp(`Bio\s+nulls.String`, string(files[1].Content))
+}
+
+func Test_generateModels(t *testing.T) {
+	r := require.New(t)
+
+	tables := map[string]*TableInfo{
+		"people": {
+			Name: "people",
+			Columns: []ColumnInfo{
+				{Name: "id", Type: "uuid"},
+				{Name: "name", Type: "character varying"},
+				{Name: "age", Type: "integer", Nullable: true},
+				{Name: "manager_id", Type: "uuid", Nullable: true},
+				{Name: "created_at", Type: "timestamp without time zone"},
+				{Name: "updated_at", Type: "timestamp without time zone"},
+			},
+			ForeignKeys: []ForeignKeyInfo{
+				{Column: "manager_id", ReferencedTable: "people", ReferencedCol: "id"},
+			},
+		},
+		"pets": {
+			Name: "pets",
+			Columns: []ColumnInfo{
+				{Name: "id", Type: "int(11)"},
+				{Name: "owner_id", Type: "uuid"},
+				{Name: "vaccinated", Type: "tinyint(1)"},
+				{Name: "weight", Type: "decimal(10,2)", Nullable: true},
+				{Name: "born_at", Type: "datetime", Nullable: true, Default: sql.NullString{String: "NOW()", Valid: true}},
+			},
+			ForeignKeys: []ForeignKeyInfo{
+				{Column: "owner_id", ReferencedTable: "people", ReferencedCol: "id"},
+			},
+		},
+	}
+
+	files, err := generateModels(GenerateOptions{ModelNames: map[string]string{"people": "Person"}}, tables)
+	r.NoError(err)
+	r.Len(files, 2)
+
+	r.Equal("person.go", files[0].Name)
+	r.Equal(`// Code generated by pop.GenerateModels from the people table.
+
+package models
+
+import (
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+)
+
+type Person struct {
+	ID        uuid.UUID  `+"`"+`json:"id" db:"id"`+"`"+`
+	Name      string     `+"`"+`json:"name" db:"name"`+"`"+`
+	Age       nulls.Int  `+"`"+`json:"age" db:"age"`+"`"+`
+	ManagerID nulls.UUID `+"`"+`json:"manager_id" db:"manager_id"`+"`"+`
+	CreatedAt time.Time  `+"`"+`json:"created_at" db:"created_at"`+"`"+`
+	UpdatedAt time.Time  `+"`"+`json:"updated_at" db:"updated_at"`+"`"+`
+	Manager   *Person    `+"`"+`json:"manager,omitempty" belongs_to:"person"`+"`"+`
+	People    []Person   `+"`"+`json:"people,omitempty" has_many:"people" fk_id:"manager_id"`+"`"+`
+	Pets      []Pet      `+"`"+`json:"pets,omitempty" has_many:"pets" fk_id:"owner_id"`+"`"+`
+}
+
+// People is not required by pop and may be deleted
+type People []Person
+`, string(files[0].Content))
+
+	r.Equal("pet.go", files[1].Name)
+	pet := string(files[1].Content)
+	r.Contains(pet, "\tID         int           `json:\"id\" db:\"id\"`\n")
+	r.Contains(pet, "\tVaccinated bool ")
+	r.Contains(pet, "\tWeight     nulls.Float64 ")
+	r.Contains(pet, "\tBornAt     nulls.Time ")
+	r.Contains(pet, "\tOwner      *Person       `json:\"owner,omitempty\" belongs_to:\"person\"`\n")
+	r.Contains(pet, "type Pets []Pet")
+}
+
+func Test_GenerateOptions_selected(t *testing.T) {
+	r := require.New(t)
+
+	opts := GenerateOptions{Include: []string{"user*"}, Exclude: []string{"users_*"}}
+	ok, err := opts.selected("users")
+	r.NoError(err)
+	r.True(ok)
+	ok, err = opts.selected("users_addresses")
+	r.NoError(err)
+	r.False(ok)
+	ok, err = opts.selected("books")
+	r.NoError(err)
+	r.False(ok)
+
+	_, err = GenerateOptions{Include: []string{"["}}.selected("users")
+	r.Error(err)
+}
@@ -0,0 +1,51 @@
+package pop
+
+import "context"
+
+// Find returns the record of the model T with the given id, like
+// Connection.Find, its associations loaded if c is eager.
+//
+//	user, err := pop.Find[User](ctx, c, id)
+func Find[T any](ctx context.Context, c *Connection, id interface{}) (*T, error) {
+	m := new(T)
+	if err := c.Find(ctx, m, id); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// First returns the first record of the model T matching the query, like
+// Query.First.
+//
+//	user, err := pop.First[User](ctx, c.Where("name = ?", "mark"))
+func First[T any](ctx context.Context, q *Query) (*T, error) {
+	m := new(T)
+	if err := q.First(ctx, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// All returns the records of the model T matching the query, like
+// Query.All, their associations loaded if q is eager, and the page of q
+// if it's paginated, q.Paginator being set.
+//
+//	users, err := pop.All[User](ctx, c.Eager().Paginate(2, 20))
+func All[T any](ctx context.Context, q *Query) ([]T, error) {
+	var models []T
+	if err := q.All(ctx, &models); err != nil {
+		return nil, err
+	}
+	if models == nil {
+		models = []T{}
+	}
+	return models, nil
+}
+
+// Count returns the number of records of the model T matching the query,
+// like Query.Count, the table name of T being resolved in ctx.
+//
+//	n, err := pop.Count[User](ctx, c.Where("name = ?", "mark"))
+func Count[T any](ctx context.Context, q *Query) (int, error) {
+	return q.countByField(ctx, new(T), "*")
+}
@@ -0,0 +1,55 @@
+package pop
+
+import (
+	"testing"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_GenericFinders(t *testing.T) {
+	r := require.New(t)
+	ctx := PDB.txContext()
+
+	u := &parallelUser{Name: nulls.NewString("Generic")}
+	r.NoError(PDB.Create(u))
+	defer PDB.RawQuery("DELETE FROM users WHERE id = ?", u.ID).Exec()
+	defer PDB.RawQuery("DELETE FROM books WHERE user_id = ?", u.ID).Exec()
+	for _, title := range []string{"B", "A", "C"} {
+		r.NoError(PDB.Create(&Book{Title: title, UserID: nulls.NewInt(u.ID)}))
+	}
+
+	found, err := Find[parallelUser](ctx, PDB.Eager("Books"), u.ID)
+	r.NoError(err)
+	r.Equal("Generic", found.Name.String)
+	r.Len(found.Books, 3)
+	r.Len(found.Titles, 0)
+
+	_, err = Find[parallelUser](ctx, PDB, -1)
+	r.Error(err)
+
+	first, err := First[titledBook](ctx, PDB.Where("user_id = ?", u.ID).Order("title desc"))
+	r.NoError(err)
+	r.Equal("C", first.Title)
+
+	q := PDB.Where("user_id = ?", u.ID).Order("title asc").Paginate(1, 2)
+	books, err := All[titledBook](ctx, q)
+	r.NoError(err)
+	r.Len(books, 2)
+	r.Equal("A", books[0].Title)
+	r.Equal(3, q.Paginator.TotalEntriesSize)
+
+	users, err := All[parallelUser](ctx, PDB.Eager().Where("id = ?", u.ID))
+	r.NoError(err)
+	r.Len(users, 1)
+	r.Len(users[0].Titles, 3)
+
+	none, err := All[titledBook](ctx, PDB.Where("user_id = ?", -1))
+	r.NoError(err)
+	r.NotNil(none)
+	r.Len(none, 0)
+
+	n, err := Count[titledBook](ctx, PDB.Where("user_id = ?", u.ID).Paginate(1, 2))
+	r.NoError(err)
+	r.Equal(3, n)
+}
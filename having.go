@@ -27,3 +27,15 @@ func (c havingClauses) String() string {
 	}
 	return strings.Join(cs, " AND ")
 }
+
+func (c havingClauses) clone() havingClauses {
+	if c == nil {
+		return nil
+	}
+	out := make(havingClauses, len(c))
+	for i := range c {
+		out[i] = c[i]
+		out[i].Arguments = cloneArgs(c[i].Arguments)
+	}
+	return out
+}
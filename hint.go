@@ -0,0 +1,80 @@
+package pop
+
+import (
+	"fmt"
+	"strings"
+)
+
+// queryHints are the optimizer and index hints of a query.
+type queryHints struct {
+	hints        []string
+	useIndexes   []string
+	forceIndexes []string
+}
+
+func (h queryHints) empty() bool {
+	return len(h.hints) == 0 && len(h.useIndexes) == 0 && len(h.forceIndexes) == 0
+}
+
+func (h queryHints) clone() queryHints {
+	return queryHints{
+		hints:        append([]string(nil), h.hints...),
+		useIndexes:   append([]string(nil), h.useIndexes...),
+		forceIndexes: append([]string(nil), h.forceIndexes...),
+	}
+}
+
+// renderedHints are the hints placed in a select statement:
+//
+//	<leading>SELECT <afterSelect>... FROM table AS alias <afterTable>
+type renderedHints struct {
+	leading     string
+	afterSelect string
+	afterTable  string
+}
+
+// queryHinter is implemented by the dialects supporting the query hints.
+type queryHinter interface {
+	// renderHints renders the hints of a select of the table aliased as
+	// alias.
+	renderHints(h queryHints, alias string) renderedHints
+}
+
+func (m *mysql) renderHints(h queryHints, alias string) renderedHints {
+	var r renderedHints
+	if len(h.hints) > 0 {
+		r.afterSelect = "/*+ " + strings.Join(h.hints, " ") + " */ "
+	}
+	var idx []string
+	if len(h.useIndexes) > 0 {
+		idx = append(idx, fmt.Sprintf("USE INDEX (%s)", strings.Join(h.useIndexes, ", ")))
+	}
+	if len(h.forceIndexes) > 0 {
+		idx = append(idx, fmt.Sprintf("FORCE INDEX (%s)", strings.Join(h.forceIndexes, ", ")))
+	}
+	r.afterTable = strings.Join(idx, " ")
+	return r
+}
+
+func (p *postgresql) renderHints(h queryHints, alias string) renderedHints {
+	hints := append([]string(nil), h.hints...)
+	if indexes := append(append([]string(nil), h.useIndexes...), h.forceIndexes...); len(indexes) > 0 {
+		hints = append(hints, fmt.Sprintf("IndexScan(%s %s)", alias, strings.Join(indexes, " ")))
+	}
+	return renderedHints{leading: "/*+ " + strings.Join(hints, " ") + " */ "}
+}
+
+// leadingHint splits the leading hint comment of query, if any, from the
+// statement. It's moved before the statements wrapping query, as
+// pg_hint_plan only reads the comment starting the statement.
+func leadingHint(query string) (string, string) {
+	if !strings.HasPrefix(query, "/*+") {
+		return "", query
+	}
+	end := strings.Index(query, "*/")
+	if end < 0 {
+		return "", query
+	}
+	end += len("*/")
+	return query[:end] + " ", strings.TrimLeft(query[end:], " ")
+}
@@ -0,0 +1,287 @@
+package pop
+
+import (
+	"context"
+	"database/sql"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// QueryInfo is the record of an operation run by a connection, e.g.
+// "First", "All", "Create" or "Exec", given to the observers once it's done.
+type QueryInfo struct {
+	// ID identifies the operation, and ParentID the operation running it,
+	// e.g. the "All" of a paginated query for its "CountByField". ParentID
+	// is zero for a top-level operation.
+	ID       uint64
+	ParentID uint64
+	// Operation is the name of the operation, and Table the table of its
+	// model, if any.
+	Operation string
+	Table     string
+	// SQL and Args are the first statement run by the operation, as
+	// rewritten, not including the statements of its nested operations.
+	// The args are redacted, see Redact.
+	SQL  string
+	Args []interface{}
+	// StartedAt is the time the operation started, and Duration the time
+	// it took, its nested operations included.
+	StartedAt time.Time
+	Duration  time.Duration
+	// RowsAffected is the number of rows affected by the statements of the
+	// operation, or read by its selects, when the driver reports it.
+	RowsAffected int64
+	Err          error
+}
+
+// QueryObserver is given the record of each operation run by a
+// connection, after its execution. It's run synchronously: a slow
+// observer slows down the queries.
+type QueryObserver func(ctx context.Context, info QueryInfo)
+
+// Observe appends observers to the pipeline given the records of the
+// operations run by the connection. Observers are run in the order they
+// are added, after the built-in timing of the Elapsed field. Connections
+// created from c (transactions, copies) inherit its observers.
+//
+//	c.Observe(func(ctx context.Context, info pop.QueryInfo) {
+//		metrics.Observe(info.Operation, info.Table, info.Duration)
+//	})
+func (c *Connection) Observe(o ...QueryObserver) {
+	// copy the pipeline, so connections sharing it aren't modified.
+	obs := make([]QueryObserver, 0, len(c.observers)+len(o))
+	obs = append(obs, c.observers...)
+	c.observers = append(obs, o...)
+}
+
+// queryIDs generates the ids of the operations records.
+var queryIDs uint64
+
+// newQueryInfo starts the record of an operation run by c.
+func (c *Connection) newQueryInfo(op string, table string) *QueryInfo {
+	info := &QueryInfo{
+		ID:        atomic.AddUint64(&queryIDs, 1),
+		Operation: op,
+		Table:     table,
+		StartedAt: time.Now(),
+	}
+	if c.op != nil {
+		info.ParentID = c.op.ID
+	}
+	return info
+}
+
+// operation returns the connection running the operation recorded by
+// info: a copy of c rewriting and recording the statements of its store,
+// and parenting the operations run with it. It's c when there's neither
+// observer nor rewriter.
+func (c *Connection) operation(ctx context.Context, info *QueryInfo) *Connection {
+	if len(c.observers) == 0 && len(c.rewriters) == 0 {
+		return c
+	}
+	cn := c.copy()
+	cn.ID = c.ID
+	cn.eager = c.eager
+	cn.eagerFields = c.eagerFields
+	cn.Store = &operationStore{
+		Store:     unwrapOperation(c.Store),
+		ctx:       ctx,
+		info:      info,
+		rewriters: c.rewriters,
+	}
+	cn.op = info
+	return cn
+}
+
+// timeFunc runs the operation fn of the query, on the table of model if
+// it's not nil, with its connection being the one running the operation
+// meanwhile, see Connection.timeFunc.
+func (q *Query) timeFunc(ctx context.Context, name string, kind opKind, model interface{}, fn func(ctx context.Context) error) error {
+	var table string
+	if model != nil {
+		table = q.Connection.modelContext(ctx, model).TableName()
+	}
+	// the rows of a slice are appended to it: each attempt starts from
+	// its length before the first one, so a retry doesn't read them twice
+	reset := func() {}
+	if v := reflect.ValueOf(model); v.Kind() == reflect.Ptr && !v.IsNil() && v.Elem().Kind() == reflect.Slice {
+		v = v.Elem()
+		n := v.Len()
+		reset = func() { v.SetLen(n) }
+	}
+	c := q.Connection
+	defer func() { q.Connection = c }()
+	return c.timeFunc(ctx, name, kind, table, func(ctx context.Context, oc *Connection) error {
+		reset()
+		q.Connection = oc
+		return fn(ctx)
+	})
+}
+
+// observe runs the observers pipeline with the record of an operation.
+func (c *Connection) observe(ctx context.Context, info *QueryInfo) {
+	atomic.AddInt64(&c.Elapsed, int64(info.Duration))
+	if len(c.observers) == 0 {
+		return
+	}
+	i := *info
+	i.Args = redactArgs(c.details(), i.SQL, i.Args)
+	for _, o := range c.observers {
+		o(ctx, i)
+	}
+}
+
+// operationStore rewrites the statements of an operation with the
+// rewriters of its connection, and records them into its record.
+type operationStore struct {
+	Store
+	ctx       context.Context
+	rewriters []QueryRewriter
+	mu        sync.Mutex
+	info      *QueryInfo
+}
+
+// unwrapOperation returns the store wrapped by an operationStore, if any.
+func unwrapOperation(s Store) Store {
+	if os, ok := s.(*operationStore); ok {
+		return os.Store
+	}
+	return s
+}
+
+// reset clears the statements recorded by a previous attempt of the
+// operation.
+func (s *operationStore) reset() {
+	s.mu.Lock()
+	s.info.SQL, s.info.Args, s.info.RowsAffected = "", nil, 0
+	s.mu.Unlock()
+}
+
+func (s *operationStore) record(query string, args []interface{}, rows int64) {
+	s.mu.Lock()
+	if s.info.SQL == "" {
+		s.info.SQL, s.info.Args = query, args
+	}
+	s.info.RowsAffected += rows
+	s.mu.Unlock()
+}
+
+func (s *operationStore) Select(dest interface{}, query string, args ...interface{}) error {
+	query, args, err := s.rewrite(query, args)
+	if err != nil {
+		return err
+	}
+	err = s.Store.Select(dest, query, args...)
+	var rows int64
+	if v := reflect.Indirect(reflect.ValueOf(dest)); err == nil && v.Kind() == reflect.Slice {
+		rows = int64(v.Len())
+	}
+	s.record(query, args, rows)
+	return err
+}
+
+func (s *operationStore) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	query, args, err := s.rewrite(query, args)
+	if err != nil {
+		return err
+	}
+	err = s.Store.SelectContext(ctx, dest, query, args...)
+	var rows int64
+	if v := reflect.Indirect(reflect.ValueOf(dest)); err == nil && v.Kind() == reflect.Slice {
+		rows = int64(v.Len())
+	}
+	s.record(query, args, rows)
+	return err
+}
+
+func (s *operationStore) Get(dest interface{}, query string, args ...interface{}) error {
+	query, args, err := s.rewrite(query, args)
+	if err != nil {
+		return err
+	}
+	err = s.Store.Get(dest, query, args...)
+	var rows int64
+	if err == nil {
+		rows = 1
+	}
+	s.record(query, args, rows)
+	return err
+}
+
+func (s *operationStore) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	query, args, err := s.rewrite(query, args)
+	if err != nil {
+		return nil, err
+	}
+	// the rows are read by the caller, see observeRows
+	s.record(query, args, 0)
+	return s.Store.Queryx(query, args...)
+}
+
+func (s *operationStore) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	query, args, err := s.rewrite(query, args)
+	if err != nil {
+		return errRow(err)
+	}
+	// the row is read by the caller, it can't be counted here
+	s.record(query, args, 0)
+	return s.Store.QueryRowContext(ctx, query, args...)
+}
+
+func (s *operationStore) NamedExec(query string, arg interface{}) (sql.Result, error) {
+	query, arg, err := s.rewriteNamed(query, arg)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.Store.NamedExec(query, arg)
+	s.record(query, []interface{}{arg}, affectedRows(res, err))
+	return res, err
+}
+
+func (s *operationStore) Exec(query string, args ...interface{}) (sql.Result, error) {
+	query, args, err := s.rewrite(query, args)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.Store.Exec(query, args...)
+	s.record(query, args, affectedRows(res, err))
+	return res, err
+}
+
+func (s *operationStore) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	query, args, err := s.rewrite(query, args)
+	if err != nil {
+		return nil, err
+	}
+	res, err := s.Store.ExecContext(ctx, query, args...)
+	s.record(query, args, affectedRows(res, err))
+	return res, err
+}
+
+func (s *operationStore) PrepareNamed(query string) (*sqlx.NamedStmt, error) {
+	query, _, err := s.rewriteNamed(query, nil)
+	if err != nil {
+		return nil, err
+	}
+	// the statement is run by the caller, its rows can't be counted here
+	s.record(query, nil, 0)
+	return s.Store.PrepareNamed(query)
+}
+
+// observeRows records the rows read from the Queryx of s into dest, a
+// struct or a slice of structs if many is true.
+func observeRows(s Store, dest interface{}, many bool) {
+	os, ok := s.(*operationStore)
+	if !ok {
+		return
+	}
+	var rows int64 = 1
+	if many {
+		rows = int64(reflect.Indirect(reflect.ValueOf(dest)).Len())
+	}
+	os.record("", nil, rows)
+}
@@ -0,0 +1,73 @@
+package pop
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Connection_Observe(t *testing.T) {
+	r := require.New(t)
+
+	var infos []QueryInfo
+	var order []int
+	c := PDB.copy()
+	c.Observe(func(ctx context.Context, info QueryInfo) {
+		infos = append(infos, info)
+		order = append(order, 1)
+	}, func(ctx context.Context, info QueryInfo) {
+		order = append(order, 2)
+	})
+
+	r.NoError(c.Rollback(func(tx *Connection) {
+		ctx := tx.txContext()
+		for _, name := range []string{"Mark", "Ringo"} {
+			r.NoError(tx.Create(&User{Name: nulls.NewString(name)}))
+		}
+		r.Len(infos, 2)
+		create := infos[0]
+		r.Equal("Create", create.Operation)
+		r.Equal("users", create.Table)
+		r.Contains(create.SQL, "INSERT INTO users")
+		r.NotZero(create.ID)
+		r.Zero(create.ParentID)
+		r.False(create.StartedAt.IsZero())
+		r.NotZero(create.Duration)
+		r.NoError(create.Err)
+		r.Equal([]int{1, 2, 1, 2}, order)
+
+		// the count of the pagination is nested in All
+		infos = nil
+		users := Users{}
+		r.NoError(tx.Paginate(1, 1).All(ctx, &users))
+		r.Len(infos, 2)
+		count, all := infos[0], infos[1]
+		r.Equal("CountByField", count.Operation)
+		r.Equal("All", all.Operation)
+		r.Equal(all.ID, count.ParentID)
+		r.Zero(all.ParentID)
+		r.Contains(all.SQL, "FROM users")
+		r.Contains(count.SQL, "COUNT")
+		r.Equal(int64(1), all.RowsAffected)
+		r.True(all.Duration >= count.Duration)
+
+		infos = nil
+		_, err := tx.ExecRaw(ctx, "UPDATE users SET alive = ? WHERE name = ?", true, "Ringo")
+		r.NoError(err)
+		r.Len(infos, 1)
+		r.Equal("ExecRaw", infos[0].Operation)
+		r.Equal([]interface{}{true, "Ringo"}, infos[0].Args)
+		r.Equal(int64(1), infos[0].RowsAffected)
+
+		infos = nil
+		r.Error(tx.Find(ctx, &User{}, -1))
+		r.Len(infos, 1)
+		r.Error(infos[0].Err)
+		r.NotZero(tx.Elapsed)
+	}))
+
+	// the original connection is left untouched
+	r.Len(PDB.observers, len(c.observers)-2)
+}
@@ -0,0 +1,175 @@
+// Package testutil provides test aids for the code using pop.
+package testutil
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"sync"
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/jmoiron/sqlx"
+)
+
+// FaultStore is a pop.Store injecting latency and errors into the
+// statements run by the wrapped Store, to test how the code using a
+// connection copes with a slow or failing database:
+//
+//	fs := &testutil.FaultStore{
+//		Store:   c.Store,
+//		Latency: 50 * time.Millisecond,
+//		Fault: func(query string) error {
+//			if strings.HasPrefix(query, "INSERT") {
+//				return errors.New("disk full")
+//			}
+//			return nil
+//		},
+//	}
+//	fc, err := c.WithStore(fs)
+//	err = fc.Create(&user) // "disk full"
+//
+// The statements of the transactions started from its connection are
+// slowed down and failed too.
+type FaultStore struct {
+	pop.Store
+	// Latency is slept before running each statement.
+	Latency time.Duration
+	// Fault returns the error of a statement, which isn't run, or nil to
+	// run it. It's called with the statement, with the placeholders of the
+	// dialect, and may be nil.
+	Fault func(query string) error
+
+	mu      sync.Mutex
+	queries []string
+	// root is the store recording the statements of a transaction.
+	root *FaultStore
+}
+
+// Queries returns the statements run, or failed, by the store.
+func (s *FaultStore) Queries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.queries...)
+}
+
+// inject records a statement, sleeps the latency, and returns its fault.
+func (s *FaultStore) inject(query string) error {
+	r := s
+	if s.root != nil {
+		r = s.root
+	}
+	r.mu.Lock()
+	r.queries = append(r.queries, query)
+	r.mu.Unlock()
+	if s.Latency > 0 {
+		time.Sleep(s.Latency)
+	}
+	if s.Fault == nil {
+		return nil
+	}
+	return s.Fault(query)
+}
+
+// WrapTx implements pop.TxWrapper: the statements of the transactions are
+// recorded by s, and slowed down and failed the same way.
+func (s *FaultStore) WrapTx(tx *pop.Tx) pop.Store {
+	return &FaultStore{Store: tx, Latency: s.Latency, Fault: s.Fault, root: s}
+}
+
+// Unwrap implements pop.StoreUnwrapper: the operations on the pool, e.g.
+// Connection.PoolStats, use the wrapped Store.
+func (s *FaultStore) Unwrap() pop.Store {
+	return s.Store
+}
+
+func (s *FaultStore) Select(dest interface{}, query string, args ...interface{}) error {
+	if err := s.inject(query); err != nil {
+		return err
+	}
+	return s.Store.Select(dest, query, args...)
+}
+
+func (s *FaultStore) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	if err := s.inject(query); err != nil {
+		return err
+	}
+	return s.Store.SelectContext(ctx, dest, query, args...)
+}
+
+func (s *FaultStore) Get(dest interface{}, query string, args ...interface{}) error {
+	if err := s.inject(query); err != nil {
+		return err
+	}
+	return s.Store.Get(dest, query, args...)
+}
+
+func (s *FaultStore) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	if err := s.inject(query); err != nil {
+		return nil, err
+	}
+	return s.Store.Queryx(query, args...)
+}
+
+func (s *FaultStore) NamedExec(query string, arg interface{}) (sql.Result, error) {
+	if err := s.inject(query); err != nil {
+		return nil, err
+	}
+	return s.Store.NamedExec(query, arg)
+}
+
+func (s *FaultStore) Exec(query string, args ...interface{}) (sql.Result, error) {
+	if err := s.inject(query); err != nil {
+		return nil, err
+	}
+	return s.Store.Exec(query, args...)
+}
+
+func (s *FaultStore) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	if err := s.inject(query); err != nil {
+		return nil, err
+	}
+	return s.Store.ExecContext(ctx, query, args...)
+}
+
+func (s *FaultStore) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	if err := s.inject(query); err != nil {
+		return errRow(err)
+	}
+	return s.Store.QueryRowContext(ctx, query, args...)
+}
+
+func (s *FaultStore) PrepareNamed(query string) (*sqlx.NamedStmt, error) {
+	if err := s.inject(query); err != nil {
+		return nil, err
+	}
+	return s.Store.PrepareNamed(query)
+}
+
+// errRow returns a row whose Scan returns err: the error of a row can't
+// be set otherwise, so it's the one of a connector failing to connect.
+func errRow(err error) *sql.Row {
+	db := sql.OpenDB(errConnector{err})
+	defer db.Close()
+	return db.QueryRow("")
+}
+
+type errConnector struct {
+	err error
+}
+
+func (c errConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, c.err
+}
+
+func (c errConnector) Driver() driver.Driver {
+	return errDriver{c.err}
+}
+
+type errDriver struct {
+	err error
+}
+
+func (d errDriver) Open(string) (driver.Conn, error) {
+	return nil, d.err
+}
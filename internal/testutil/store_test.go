@@ -0,0 +1,75 @@
+package testutil
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/pop"
+	"github.com/stretchr/testify/require"
+)
+
+// execStore is a pop.Store counting the statements it runs.
+type execStore struct {
+	pop.Store
+	execs int
+}
+
+func (s *execStore) Exec(query string, args ...interface{}) (sql.Result, error) {
+	s.execs++
+	return nil, nil
+}
+
+func (s *execStore) Select(dest interface{}, query string, args ...interface{}) error {
+	s.execs++
+	return nil
+}
+
+func Test_FaultStore(t *testing.T) {
+	r := require.New(t)
+
+	inner := &execStore{}
+	fs := &FaultStore{
+		Store:   inner,
+		Latency: 10 * time.Millisecond,
+		Fault: func(query string) error {
+			if strings.HasPrefix(query, "DELETE") {
+				return errors.New("disk full")
+			}
+			return nil
+		},
+	}
+
+	start := time.Now()
+	_, err := fs.Exec("UPDATE users SET name = ?", "Mark")
+	r.NoError(err)
+	r.True(time.Since(start) >= fs.Latency)
+	r.NoError(fs.Select(&[]string{}, "SELECT name FROM users"))
+	r.Equal(2, inner.execs)
+
+	// the faulty statements aren't run
+	_, err = fs.Exec("DELETE FROM users")
+	r.EqualError(err, "disk full")
+	r.Equal(2, inner.execs)
+
+	// the error of a row is returned by its Scan
+	var name string
+	err = fs.QueryRowContext(context.Background(), "DELETE FROM users RETURNING name").Scan(&name)
+	r.EqualError(err, "disk full")
+
+	r.Equal([]string{
+		"UPDATE users SET name = ?",
+		"SELECT name FROM users",
+		"DELETE FROM users",
+		"DELETE FROM users RETURNING name",
+	}, fs.Queries())
+
+	// the statements of the transactions are recorded by the store
+	ts := fs.WrapTx(&pop.Tx{})
+	_, err = ts.Exec("DELETE FROM books")
+	r.EqualError(err, "disk full")
+	r.Len(fs.Queries(), 5)
+}
@@ -31,3 +31,15 @@ func (c joinClauses) String() string {
 	}
 	return strings.Join(cs, " ")
 }
+
+func (c joinClauses) clone() joinClauses {
+	if c == nil {
+		return nil
+	}
+	out := make(joinClauses, len(c))
+	for i := range c {
+		out[i] = c[i]
+		out[i].Arguments = cloneArgs(c[i].Arguments)
+	}
+	return out
+}
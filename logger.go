@@ -4,13 +4,18 @@ import (
 	"fmt"
 	stdlog "log"
 	"os"
+	"reflect"
 
 	"github.com/fatih/color"
 	"github.com/gobuffalo/pop/logging"
 	"github.com/markbates/oncer"
 )
 
-type logger func(lvl logging.Level, s string, args ...interface{})
+// Logger logs the messages of pop: the SQL statements at the logging.SQL
+// level, and the events of the connections at the other levels. See
+// SetLogger and Connection.WithLogger.
+type Logger func(lvl logging.Level, s string, args ...interface{})
+
 type legacyLogger func(s string, args ...interface{})
 
 // Debug mode, to toggle verbose log traces
@@ -19,7 +24,7 @@ var Debug = false
 // Color mode, to toggle colored logs
 var Color = true
 
-var log logger
+var log Logger
 
 var defaultStdLogger = stdlog.New(os.Stdout, "[POP] ", stdlog.LstdFlags)
 var defaultLogger = func(lvl logging.Level, s string, args ...interface{}) {
@@ -60,11 +65,91 @@ var defaultLogger = func(lvl logging.Level, s string, args ...interface{}) {
 // SetLogger overrides the default logger.
 //
 // The logger must implement the following interface:
-// type logger func(lvl logging.Level, s string, args ...interface{})
-func SetLogger(l logger) {
+// type Logger func(lvl logging.Level, s string, args ...interface{})
+func SetLogger(l Logger) {
 	log = l
 }
 
+// WithLogger returns a copy of the connection logging with l, instead of
+// the logger set by SetLogger. The transactions and copies of the returned
+// connection use l too; the other connections aren't affected. The events
+// not related to a connection, e.g. the loading of the config, are still
+// logged by the SetLogger logger.
+//
+//	tc := c.WithLogger(func(lvl logging.Level, s string, args ...interface{}) {
+//		t.Logf(s, args...)
+//	})
+func (c *Connection) WithLogger(l Logger) *Connection {
+	cn := c.copy()
+	cn.logger = l
+	if c.Store != nil {
+		cn.Store = withLogger(withSlowQueryLogger(unwrapLogger(c.Store), l), l, c.details())
+	}
+	return cn
+}
+
+// log logs with the logger of the connection, the args of the statements
+// redacted, only when they're emitted.
+func (c *Connection) log(lvl logging.Level, s string, args ...interface{}) {
+	l := log
+	if c != nil && c.logger != nil {
+		l = c.logger
+	}
+	if !emits(l, lvl) {
+		return
+	}
+	if lvl == logging.SQL {
+		args = redactArgs(c.details(), s, args)
+	}
+	l(lvl, s, args...)
+}
+
+// emits tells if the logger l emits the messages of level lvl: the default
+// logger drops the SQL and debug messages unless Debug is set, the other
+// loggers are given them all.
+func emits(l Logger, lvl logging.Level) bool {
+	if Debug || lvl > logging.Debug || Log != nil || l == nil {
+		return true
+	}
+	return reflect.ValueOf(l).Pointer() != reflect.ValueOf(defaultLogger).Pointer()
+}
+
+// loggerStore carries the logger of a connection returned by WithLogger,
+// and the args it redacts, for the statements logged by the dialects.
+type loggerStore struct {
+	Store
+	log   Logger
+	deets *ConnectionDetails
+}
+
+// withLogger returns s logging with l, nil for the default logger, and
+// redacting the args of deets, or s if there's neither.
+func withLogger(s Store, l Logger, deets *ConnectionDetails) Store {
+	if l == nil && !deets.redacts() {
+		return s
+	}
+	return &loggerStore{Store: s, log: l, deets: deets}
+}
+
+// unwrapLogger returns the store wrapped by withLogger, if any, the store
+// of an operation being unwrapped first.
+func unwrapLogger(s Store) Store {
+	s = unwrapOperation(s)
+	if ls, ok := s.(*loggerStore); ok {
+		return ls.Store
+	}
+	return s
+}
+
+// storeLog returns the logger of the connection owning s, for the
+// functions given a store rather than a connection.
+func storeLog(s Store) Logger {
+	if ls, ok := unwrapOperation(s).(*loggerStore); ok {
+		return redactingLogger(ls.log, ls.deets)
+	}
+	return redactingLogger(nil, nil)
+}
+
 // Log defines the pop logger. Override it to customize pop logs handling.
 // Deprecated: use SetLogger instead
 var Log legacyLogger
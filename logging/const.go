@@ -14,6 +14,9 @@ const (
 	Warn
 	// Error level dumps logs only errors.
 	Error
+	// Eager level dumps the associations loaded by the connections
+	// returned by Connection.DebugEager, whatever the other levels.
+	Eager
 )
 
 func (l Level) String() string {
@@ -28,6 +31,8 @@ func (l Level) String() string {
 		return "warn"
 	case Error:
 		return "error"
+	case Eager:
+		return "eager"
 	}
 	return "unknown"
 }
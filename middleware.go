@@ -0,0 +1,72 @@
+package pop
+
+import (
+	"context"
+	"fmt"
+
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// QueryMiddleware wraps the execution of a query. op is the name
+// of the operation, e.g. "First", "All", "Create" or "Exec", and next runs
+// the query in the given context, e.g. ctx or a context derived from it.
+// A middleware must call next to let the query hit the database, and can
+// stop the execution by returning an error instead.
+type QueryMiddleware func(ctx context.Context, op string, next func(ctx context.Context) error) error
+
+// Use appends middlewares to the chain wrapping the queries run by the
+// connection. Middlewares are run in the order they are added: the first
+// one is the outermost. Connections created from c (transactions, copies)
+// inherit its middlewares.
+//
+//	c.Use(func(ctx context.Context, op string, next func(ctx context.Context) error) error {
+//		if breaker.Open() {
+//			return ErrCircuitOpen
+//		}
+//		return next(ctx)
+//	})
+func (c *Connection) Use(m ...QueryMiddleware) {
+	// copy the chain, so connections sharing it aren't modified.
+	mws := make([]QueryMiddleware, 0, len(c.middlewares)+len(m))
+	mws = append(mws, c.middlewares...)
+	c.middlewares = append(mws, m...)
+}
+
+// runMiddlewares runs fn through the middlewares chain, with the context
+// given by the innermost middleware.
+func (c *Connection) runMiddlewares(ctx context.Context, op string, fn func(ctx context.Context) error) error {
+	if c.name != "" {
+		ctx = context.WithValue(ctx, connectionNameKey{}, c.name)
+	}
+	next := fn
+	for i := len(c.middlewares) - 1; i >= 0; i-- {
+		m, n := c.middlewares[i], next
+		next = func(ctx context.Context) error {
+			return m(ctx, op, n)
+		}
+	}
+	return next(ctx)
+}
+
+// TracingMiddleware reports each query as a DataDog span, named after
+// the operation and tagged with the connection name. It's installed by
+// default on new connections. The query runs in the context of the span,
+// so the spans of the queries it runs, e.g. by its callbacks, are its
+// children.
+func TracingMiddleware(ctx context.Context, op string, next func(ctx context.Context) error) error {
+	span, ctx := tracer.StartSpanFromContext(ctx, "pop/"+op)
+	defer span.Finish()
+	if name := connectionName(ctx); name != "" {
+		span.SetTag("pop.connection", name)
+	}
+	if d, ok := ctx.Value(eagerDebugKey{}).(eagerDebug); ok {
+		span.SetTag("pop.association", d.name)
+		span.SetTag("pop.association.constraint", fmt.Sprintf("%s %v", d.constraint, d.args))
+		span.SetTag("pop.association.sql", fmt.Sprintf("%s %v", d.sql, d.sqlArgs))
+	}
+	err := next(ctx)
+	if err != nil {
+		span.SetTag("error", err)
+	}
+	return err
+}
@@ -0,0 +1,70 @@
+package pop
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Connection_Use(t *testing.T) {
+	r := require.New(t)
+
+	var ops []string
+	c := PDB.copy()
+	c.Use(func(ctx context.Context, op string, next func(ctx context.Context) error) error {
+		ops = append(ops, op)
+		return next(ctx)
+	})
+
+	r.NoError(c.Rollback(func(tx *Connection) {
+		r.NoError(tx.Create(&User{Name: nulls.NewString("Mark")}))
+		r.NoError(tx.First(context.Background(), &User{}))
+		r.NoError(tx.All(context.Background(), &Users{}))
+	}))
+	r.Equal([]string{"Create", "First", "All"}, ops)
+
+	// the original connection is left untouched
+	r.Len(PDB.middlewares, len(c.middlewares)-1)
+}
+
+func Test_Connection_Use_Stop(t *testing.T) {
+	r := require.New(t)
+
+	stop := errors.New("stop")
+	c := PDB.copy()
+	c.Use(func(ctx context.Context, op string, next func(ctx context.Context) error) error {
+		return stop
+	})
+	err := c.First(context.Background(), &User{})
+	r.Equal(stop, errors.Cause(err))
+}
+
+func Test_Connection_runMiddlewares(t *testing.T) {
+	r := require.New(t)
+
+	var calls []string
+	mw := func(name string) QueryMiddleware {
+		return func(ctx context.Context, op string, next func(ctx context.Context) error) error {
+			calls = append(calls, name+" before "+op)
+			err := next(ctx)
+			calls = append(calls, name+" after "+op)
+			return err
+		}
+	}
+
+	type key struct{}
+	c := &Connection{}
+	c.Use(mw("a"), mw("b"), func(ctx context.Context, op string, next func(ctx context.Context) error) error {
+		return next(context.WithValue(ctx, key{}, "c"))
+	})
+	err := c.runMiddlewares(context.Background(), "Exec", func(ctx context.Context) error {
+		// the operation runs in the context given by the middlewares
+		calls = append(calls, "exec "+ctx.Value(key{}).(string))
+		return nil
+	})
+	r.NoError(err)
+	r.Equal([]string{"a before Exec", "b before Exec", "exec c", "b after Exec", "a after Exec"}, calls)
+}
@@ -2,6 +2,7 @@ package pop
 
 import (
 	"fmt"
+	"os"
 	"path/filepath"
 	"time"
 
@@ -55,7 +56,7 @@ func (c *Connection) MigrateStatus(path string) error {
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	return mig.Status()
+	return mig.PrintStatus(os.Stdout)
 }
 
 // MigrateReset is deprecated, and will be removed in a future version. Use FileMigrator#Reset instead.
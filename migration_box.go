@@ -2,6 +2,7 @@ package pop
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/gobuffalo/packd"
 	"github.com/pkg/errors"
@@ -57,21 +58,9 @@ func (fm *MigrationBox) findMigrations() error {
 			DBType:    dbType,
 			Direction: m[4],
 			Type:      m[5],
-			Runner: func(mf Migration, tx *Connection) error {
-				content, err := migrationContent(mf, tx, f)
-				if err != nil {
-					return errors.Wrapf(err, "error processing %s", mf.Path)
-				}
-
-				if content == "" {
-					return nil
-				}
-
-				err = tx.RawQuery(content).Exec()
-				if err != nil {
-					return errors.Wrapf(err, "error executing %s, sql: %s", mf.Path, content)
-				}
-				return nil
+			Runner:    runContent,
+			Content: func(mf Migration, c *Connection) (string, error) {
+				return migrationContent(mf, c, strings.NewReader(f.String()))
 			},
 		}
 		fm.Migrations[mf.Direction] = append(fm.Migrations[mf.Direction], mf)
@@ -0,0 +1,81 @@
+package pop
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/pkg/errors"
+)
+
+// FSMigrator is a migrator for SQL and Fizz files
+// in a fs.FS, such as an embed.FS. This will allow you
+// to run migrations embedded inside of a compiled binary.
+//
+//	//go:embed migrations/*
+//	var migrations embed.FS
+//
+//	fsys, _ := fs.Sub(migrations, "migrations")
+//	m, err := pop.NewMigratorFS(fsys, c)
+type FSMigrator struct {
+	Migrator
+	FS fs.FS
+}
+
+// NewMigratorFS from a fs.FS and a Connection.
+func NewMigratorFS(fsys fs.FS, c *Connection) (FSMigrator, error) {
+	fm := FSMigrator{
+		Migrator: NewMigrator(c),
+		FS:       fsys,
+	}
+
+	err := fm.findMigrations()
+	if err != nil {
+		return fm, errors.WithStack(err)
+	}
+
+	return fm, nil
+}
+
+func (fm *FSMigrator) findMigrations() error {
+	return fs.WalkDir(fm.FS, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if d.IsDir() {
+			return nil
+		}
+		matches := mrx.FindAllStringSubmatch(d.Name(), -1)
+		if len(matches) == 0 {
+			return nil
+		}
+		m := matches[0]
+		var dbType string
+		if m[3] == "" {
+			dbType = "all"
+		} else {
+			dbType = normalizeSynonyms(m[3][1:])
+			if !DialectSupported(dbType) {
+				return fmt.Errorf("unsupported dialect %s", dbType)
+			}
+		}
+		mf := Migration{
+			Path:      p,
+			Version:   m[1],
+			Name:      m[2],
+			DBType:    dbType,
+			Direction: m[4],
+			Type:      m[5],
+			Runner:    runContent,
+			Content: func(mf Migration, c *Connection) (string, error) {
+				f, err := fm.FS.Open(p)
+				if err != nil {
+					return "", errors.WithStack(err)
+				}
+				defer f.Close()
+				return migrationContent(mf, c, f)
+			},
+		}
+		fm.Migrations[mf.Direction] = append(fm.Migrations[mf.Direction], mf)
+		return nil
+	})
+}
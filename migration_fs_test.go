@@ -0,0 +1,96 @@
+package pop
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+)
+
+var fsMigrations = fstest.MapFS{
+	"20990101000001_fs_widgets.up.sql":   {Data: []byte("create table fs_widgets (id integer primary key, name varchar(255));")},
+	"20990101000001_fs_widgets.down.sql": {Data: []byte("drop table fs_widgets;")},
+	"20990101000002_fs_gadgets.up.sql":   {Data: []byte("create table fs_gadgets (id integer primary key, name varchar(255));")},
+	"20990101000002_fs_gadgets.down.sql": {Data: []byte("drop table fs_gadgets;")},
+	"README.md":                          {Data: []byte("not a migration")},
+}
+
+func Test_MigratorFS(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	m, err := NewMigratorFS(fsMigrations, PDB)
+	r.NoError(err)
+	r.Len(m.Migrations["up"], 2)
+	r.Len(m.Migrations["down"], 2)
+	r.Equal("all", m.Migrations["up"][0].DBType)
+
+	r.Error(m.UpTo(ctx, "20990101000003"))
+
+	sqls, err := m.DryRunUpTo(ctx, "20990101000001")
+	r.NoError(err)
+	r.Len(sqls, 1)
+	r.Equal("20990101000001", sqls[0].Version)
+	r.Equal("up", sqls[0].Direction)
+	r.Contains(sqls[0].SQL, "fs_widgets")
+	has, err := PDB.HasTable(ctx, "fs_widgets")
+	r.NoError(err)
+	r.False(has)
+
+	r.NoError(m.UpTo(ctx, "20990101000001"))
+	defer m.DownTo(ctx, "")
+
+	st, err := m.Status(ctx)
+	r.NoError(err)
+	r.Equal([]MigrationStatus{
+		{Version: "20990101000001", Name: "fs_widgets", Applied: true},
+		{Version: "20990101000002", Name: "fs_gadgets", Applied: false},
+	}, st)
+	has, err = PDB.HasTable(ctx, "fs_widgets")
+	r.NoError(err)
+	r.True(has)
+
+	r.NoError(m.UpTo(ctx, ""))
+	has, err = PDB.HasTable(ctx, "fs_gadgets")
+	r.NoError(err)
+	r.True(has)
+
+	sqls, err = m.DryRunDownTo(ctx, "20990101000001")
+	r.NoError(err)
+	r.Len(sqls, 1)
+	r.Equal("down", sqls[0].Direction)
+	r.Contains(sqls[0].SQL, "fs_gadgets")
+
+	r.NoError(m.DownTo(ctx, "20990101000001"))
+	st, err = m.Status(ctx)
+	r.NoError(err)
+	r.True(st[0].Applied)
+	r.False(st[1].Applied)
+	has, err = PDB.HasTable(ctx, "fs_gadgets")
+	r.NoError(err)
+	r.False(has)
+
+	r.NoError(m.DownTo(ctx, ""))
+	st, err = m.Status(ctx)
+	r.NoError(err)
+	r.False(st[0].Applied)
+}
+
+func Test_MigratorFS_Middlewares(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	c := PDB.copy()
+	var ops []string
+	c.Use(func(ctx context.Context, op string, next func(ctx context.Context) error) error {
+		ops = append(ops, op)
+		return next(ctx)
+	})
+
+	m, err := NewMigratorFS(fsMigrations, c)
+	r.NoError(err)
+	r.NoError(m.UpTo(ctx, "20990101000001"))
+	r.NoError(m.DownTo(ctx, ""))
+	r.Contains(ops, "Migrate")
+}
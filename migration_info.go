@@ -18,6 +18,9 @@ type Migration struct {
 	DBType string
 	// Runner function to run/execute the migration
 	Runner func(Migration, *Connection) error
+	// Content function returning the SQL run by the migration, used by
+	// dry runs. It's optional.
+	Content func(Migration, *Connection) (string, error)
 }
 
 // Run the migration. Returns an error if there is
@@ -29,6 +32,34 @@ func (mf Migration) Run(c *Connection) error {
 	return mf.Runner(mf, c)
 }
 
+// SQL returns the SQL run by the migration. Returns an error if
+// there is no mf.Content defined.
+func (mf Migration) SQL(c *Connection) (string, error) {
+	if mf.Content == nil {
+		return "", errors.Errorf("no content defined for %s", mf.Path)
+	}
+	return mf.Content(mf, c)
+}
+
+// runContent is the Runner of the migrations defined by their
+// Content: it executes the SQL.
+func runContent(mf Migration, tx *Connection) error {
+	content, err := mf.SQL(tx)
+	if err != nil {
+		return errors.Wrapf(err, "error processing %s", mf.Path)
+	}
+
+	if content == "" {
+		return nil
+	}
+
+	err = tx.RawQuery(content).Exec()
+	if err != nil {
+		return errors.Wrapf(err, "error executing %s, sql: %s", mf.Path, content)
+	}
+	return nil
+}
+
 // Migrations is a collection of Migration
 type Migrations []Migration
 
@@ -43,3 +74,12 @@ func (mfs Migrations) Less(i, j int) bool {
 func (mfs Migrations) Swap(i, j int) {
 	mfs[i], mfs[j] = mfs[j], mfs[i]
 }
+
+func (mfs Migrations) has(version string) bool {
+	for _, mf := range mfs {
+		if mf.Version == version {
+			return true
+		}
+	}
+	return false
+}
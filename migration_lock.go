@@ -0,0 +1,171 @@
+package pop
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/gobuffalo/pop/logging"
+	"github.com/pkg/errors"
+)
+
+// ErrMigrationInProgress is returned by the Migrator when the migrations
+// lock couldn't be taken before Migrator.LockTimeout: another process is
+// running the migrations.
+var ErrMigrationInProgress = errors.New("another migration is in progress")
+
+// DefaultMigrationLockTimeout is how long the Migrator waits for the
+// migrations lock, when Migrator.LockTimeout isn't set.
+var DefaultMigrationLockTimeout = time.Minute
+
+// migrationLocker is implemented by the dialects able to lock the migrations,
+// so concurrent processes don't apply them twice. The lock is held by conn,
+// and must be released when it is closed. Dialects not implementing it,
+// like SQLite whose writers are serialized by the database file, run the
+// migrations without locking.
+type migrationLocker interface {
+	lockMigrations(ctx context.Context, c *Connection, conn *sql.Conn, key string, timeout time.Duration) (unlock func() error, err error)
+}
+
+// lock takes the migrations lock, if the dialect supports it. The returned
+// function releases the lock.
+func (m Migrator) lock(ctx context.Context) (func(), error) {
+	c := m.Connection
+	l, ok := c.Dialect.(migrationLocker)
+	if !ok || c.TX != nil {
+		// a transaction can't hold a session lock
+		return func() {}, nil
+	}
+	err := c.Open()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open connection")
+	}
+	conn, err := sessionConn(ctx, c.Store)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get a connection for the migrations lock")
+	}
+
+	key := m.LockKey
+	if key == "" {
+		key = fmt.Sprintf("%s.%s", c.Dialect.Details().Database, c.MigrationTableName())
+	}
+	timeout := m.LockTimeout
+	if timeout <= 0 {
+		timeout = DefaultMigrationLockTimeout
+	}
+	unlock, err := l.lockMigrations(ctx, c, conn, key, timeout)
+	if err != nil {
+		conn.Close()
+		return nil, errors.WithStack(err)
+	}
+	return func() {
+		if err := unlock(); err != nil {
+			m.Connection.log(logging.Warn, "Migrator: unable to release the migrations lock: %v", err)
+			// drop the connection, so the database releases the lock
+			conn.Raw(func(interface{}) error {
+				return driver.ErrBadConn
+			})
+		}
+		conn.Close()
+	}, nil
+}
+
+// sessionConn returns a connection of the store pool, dedicated
+// to the caller until it is closed.
+func sessionConn(ctx context.Context, s Store) (*sql.Conn, error) {
+	db, ok := rawDB(s)
+	if !ok {
+		return nil, errors.Errorf("unable to get a connection from a %T", s)
+	}
+	return db.Conn(ctx)
+}
+
+// rawDB returns the database of the store, unwrapping the instrumentation
+// and the stores of Connection.WithStore. It's false for the transactions.
+func rawDB(s Store) (*dB, bool) {
+	for {
+		switch w := unwrapLogger(s).(type) {
+		case *dB:
+			return w, true
+		case *statsStore:
+			s = w.Store
+		case *slowQueryStore:
+			s = w.Store
+		case StoreUnwrapper:
+			s = w.Unwrap()
+		default:
+			return nil, false
+		}
+	}
+}
+
+// lockID hashes the lock key into a numeric lock identifier.
+func lockID(key string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	return int64(h.Sum64())
+}
+
+// pollLock calls try until it takes the lock, or timeout is reached.
+func pollLock(ctx context.Context, timeout time.Duration, try func() (bool, error)) error {
+	deadline := time.Now().Add(timeout)
+	for {
+		ok, err := try()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if ok {
+			return nil
+		}
+		wait := time.Until(deadline)
+		if wait <= 0 {
+			return ErrMigrationInProgress
+		}
+		if wait > 250*time.Millisecond {
+			wait = 250 * time.Millisecond
+		}
+		select {
+		case <-ctx.Done():
+			return errors.WithStack(ctx.Err())
+		case <-time.After(wait):
+		}
+	}
+}
+
+// rowLockMigrations locks the row of key in a lock table next to the
+// migrations table, with a SELECT FOR UPDATE. The lock is held by an open
+// transaction, rolled back when unlocking.
+func rowLockMigrations(ctx context.Context, c *Connection, conn *sql.Conn, key string, timeout time.Duration) (func() error, error) {
+	table := c.MigrationTableName() + "_lock"
+	create := fmt.Sprintf("CREATE TABLE IF NOT EXISTS %s (id VARCHAR(255) PRIMARY KEY)", table)
+	c.log(logging.SQL, create)
+	if _, err := conn.ExecContext(ctx, create); err != nil {
+		return nil, errors.Wrapf(err, "could not create the migrations lock table %s", table)
+	}
+	insert := c.Dialect.TranslateSQL(fmt.Sprintf("INSERT INTO %s (id) VALUES (?) ON CONFLICT (id) DO NOTHING", table))
+	c.log(logging.SQL, insert, key)
+	if _, err := conn.ExecContext(ctx, insert, key); err != nil {
+		return nil, errors.Wrapf(err, "could not insert the migrations lock row in %s", table)
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	lctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	query := c.Dialect.TranslateSQL(fmt.Sprintf("SELECT id FROM %s WHERE id = ? FOR UPDATE", table))
+	c.log(logging.SQL, query, key)
+	_, err = tx.ExecContext(lctx, query, key)
+	if err != nil {
+		tx.Rollback()
+		if lctx.Err() == context.DeadlineExceeded {
+			return nil, ErrMigrationInProgress
+		}
+		return nil, errors.WithStack(err)
+	}
+	return tx.Rollback, nil
+}
@@ -0,0 +1,57 @@
+package pop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Migrator_Lock(t *testing.T) {
+	if _, ok := PDB.Dialect.(migrationLocker); !ok {
+		t.Skipf("%s doesn't lock migrations", PDB.Dialect.Name())
+	}
+	r := require.New(t)
+	ctx := context.Background()
+
+	m := NewMigrator(PDB)
+	m.LockKey = "pop_test_lock"
+	m.LockTimeout = 500 * time.Millisecond
+
+	unlock, err := m.lock(ctx)
+	r.NoError(err)
+
+	_, err = m.lock(ctx)
+	r.Equal(ErrMigrationInProgress, errors.Cause(err))
+	r.Equal(ErrMigrationInProgress, errors.Cause(m.Up()))
+
+	unlock()
+	unlock, err = m.lock(ctx)
+	r.NoError(err)
+	unlock()
+}
+
+func Test_pollLock(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	tries := 0
+	err := pollLock(ctx, time.Second, func() (bool, error) {
+		tries++
+		return tries == 3, nil
+	})
+	r.NoError(err)
+	r.Equal(3, tries)
+
+	err = pollLock(ctx, 100*time.Millisecond, func() (bool, error) {
+		return false, nil
+	})
+	r.Equal(ErrMigrationInProgress, err)
+
+	err = pollLock(ctx, time.Second, func() (bool, error) {
+		return false, errors.New("boom")
+	})
+	r.EqualError(err, "boom")
+}
@@ -0,0 +1,6 @@
+CREATE TABLE stamped_orders (
+  id SERIAL PRIMARY KEY,
+  total integer NOT NULL,
+  created_at timestamp NOT NULL DEFAULT clock_timestamp(),
+  updated_at timestamp NOT NULL DEFAULT clock_timestamp() ON UPDATE clock_timestamp()
+);
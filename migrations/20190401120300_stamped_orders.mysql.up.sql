@@ -0,0 +1,6 @@
+CREATE TABLE stamped_orders (
+  id int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+  total int NOT NULL,
+  created_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6),
+  updated_at DATETIME(6) NOT NULL DEFAULT CURRENT_TIMESTAMP(6) ON UPDATE CURRENT_TIMESTAMP(6)
+);
@@ -0,0 +1,2 @@
+DROP TABLE stamped_orders;
+DROP FUNCTION stamped_orders_updated_at();
@@ -0,0 +1,16 @@
+CREATE TABLE stamped_orders (
+  id SERIAL PRIMARY KEY,
+  total integer NOT NULL,
+  created_at timestamp NOT NULL DEFAULT clock_timestamp(),
+  updated_at timestamp NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE FUNCTION stamped_orders_updated_at() RETURNS trigger AS $$
+BEGIN
+  NEW.updated_at = clock_timestamp();
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER stamped_orders_updated_at BEFORE UPDATE ON stamped_orders
+FOR EACH ROW EXECUTE PROCEDURE stamped_orders_updated_at();
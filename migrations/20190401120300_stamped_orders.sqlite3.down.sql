@@ -0,0 +1 @@
+DROP TABLE stamped_orders;
@@ -0,0 +1,10 @@
+CREATE TABLE stamped_orders (
+  id integer PRIMARY KEY AUTOINCREMENT,
+  total integer NOT NULL,
+  created_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+  updated_at DATETIME NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now'))
+);
+
+CREATE TRIGGER stamped_orders_updated_at AFTER UPDATE ON stamped_orders BEGIN
+  UPDATE stamped_orders SET updated_at = strftime('%Y-%m-%d %H:%M:%f', 'now') WHERE id = NEW.id;
+END;
@@ -1,7 +1,9 @@
 package pop
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"regexp"
@@ -29,6 +31,21 @@ func NewMigrator(c *Connection) Migrator {
 	}
 }
 
+// MigrationStatus is the status of a migration in the database.
+type MigrationStatus struct {
+	Version string
+	Name    string
+	Applied bool
+}
+
+// MigrationSQL is the SQL run by a migration, as returned by dry runs.
+type MigrationSQL struct {
+	Version   string
+	Name      string
+	Direction string
+	SQL       string
+}
+
 // Migrator forms the basis of all migrations systems.
 // It does the actual heavy lifting of running migrations.
 // When building a new migration system, you should embed this
@@ -37,17 +54,25 @@ type Migrator struct {
 	Connection *Connection
 	SchemaPath string
 	Migrations map[string]Migrations
+	// LockKey identifies the lock taken while migrating, so concurrent
+	// processes don't run the migrations together. It defaults to the
+	// database and migrations table names.
+	LockKey string
+	// LockTimeout is how long to wait for the lock, before failing with
+	// ErrMigrationInProgress. It defaults to DefaultMigrationLockTimeout.
+	LockTimeout time.Duration
 }
 
 // UpLogOnly insert pending "up" migrations logs only, without applying the patch.
 // It's used when loading the schema dump, instead of the migrations.
 func (m Migrator) UpLogOnly() error {
 	c := m.Connection
-	return m.exec(func() error {
+	ctx := context.TODO()
+	return m.exec(ctx, func() error {
 		mtn := c.MigrationTableName()
 		mfs := m.Migrations["up"]
 		sort.Sort(mfs)
-		return c.Transaction(func(tx *Connection) error {
+		return c.Transaction(ctx, func(ctx context.Context, tx *Connection) error {
 			for _, mi := range mfs {
 				if mi.DBType != "all" && mi.DBType != c.Dialect.Name() {
 					// Skip migration for non-matching dialect
@@ -60,7 +85,7 @@ func (m Migrator) UpLogOnly() error {
 				if exists {
 					continue
 				}
-				_, err = tx.Store.Exec(fmt.Sprintf("insert into %s (version) values ('%s')", mtn, mi.Version))
+				err = tx.RawQuery(fmt.Sprintf("insert into %s (version) values (?)", mtn), mi.Version).Exec()
 				if err != nil {
 					return errors.Wrapf(err, "problem inserting migration version %s", mi.Version)
 				}
@@ -72,40 +97,27 @@ func (m Migrator) UpLogOnly() error {
 
 // Up runs pending "up" migrations and applies them to the database.
 func (m Migrator) Up() error {
-	c := m.Connection
-	return m.exec(func() error {
-		mtn := c.MigrationTableName()
-		mfs := m.Migrations["up"]
-		sort.Sort(mfs)
-		applied := 0
+	return m.UpTo(context.TODO(), "")
+}
+
+// UpTo runs pending "up" migrations until version, included. An empty
+// version applies all the pending migrations.
+func (m Migrator) UpTo(ctx context.Context, version string) error {
+	return m.exec(ctx, func() error {
+		mfs, err := m.pendingUp(ctx, version)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		if len(mfs) == 0 {
+			m.Connection.log(logging.Info, "Migrations already up to date, nothing to apply")
+			return nil
+		}
 		for _, mi := range mfs {
-			if mi.DBType != "all" && mi.DBType != c.Dialect.Name() {
-				// Skip migration for non-matching dialect
-				continue
-			}
-			exists, err := c.Where("version = ?", mi.Version).Exists(mtn)
-			if err != nil {
-				return errors.Wrapf(err, "problem checking for migration version %s", mi.Version)
-			}
-			if exists {
-				continue
-			}
-			err = c.Transaction(func(tx *Connection) error {
-				err := mi.Run(tx)
-				if err != nil {
-					return err
-				}
-				_, err = tx.Store.Exec(fmt.Sprintf("insert into %s (version) values ('%s')", mtn, mi.Version))
-				return errors.Wrapf(err, "problem inserting migration version %s", mi.Version)
-			})
+			err = m.run(ctx, mi)
 			if err != nil {
 				return errors.WithStack(err)
 			}
-			log(logging.Info, "> %s", mi.Name)
-			applied++
-		}
-		if applied == 0 {
-			log(logging.Info, "Migrations already up to date, nothing to apply")
+			m.Connection.log(logging.Info, "> %s", mi.Name)
 		}
 		return nil
 	})
@@ -115,7 +127,7 @@ func (m Migrator) Up() error {
 // database by the specified number of steps.
 func (m Migrator) Down(step int) error {
 	c := m.Connection
-	return m.exec(func() error {
+	return m.exec(context.TODO(), func() error {
 		mtn := c.MigrationTableName()
 		count, err := c.Count(mtn)
 		if err != nil {
@@ -136,24 +148,57 @@ func (m Migrator) Down(step int) error {
 			if err != nil || !exists {
 				return errors.Wrapf(err, "problem checking for migration version %s", mi.Version)
 			}
-			err = c.Transaction(func(tx *Connection) error {
-				err := mi.Run(tx)
-				if err != nil {
-					return err
-				}
-				err = tx.RawQuery(fmt.Sprintf("delete from %s where version = ?", mtn), mi.Version).Exec()
-				return errors.Wrapf(err, "problem deleting migration version %s", mi.Version)
-			})
+			err = m.run(context.TODO(), mi)
 			if err != nil {
 				return err
 			}
 
-			log(logging.Info, "< %s", mi.Name)
+			m.Connection.log(logging.Info, "< %s", mi.Name)
 		}
 		return nil
 	})
 }
 
+// DownTo rolls back the applied migrations newer than version, the
+// migration with this version staying applied. An empty version rolls
+// back all the migrations.
+func (m Migrator) DownTo(ctx context.Context, version string) error {
+	return m.exec(ctx, func() error {
+		mfs, err := m.pendingDown(ctx, version)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		for _, mi := range mfs {
+			err = m.run(ctx, mi)
+			if err != nil {
+				return errors.WithStack(err)
+			}
+			m.Connection.log(logging.Info, "< %s", mi.Name)
+		}
+		return nil
+	})
+}
+
+// DryRunUpTo returns the SQL UpTo would run, without applying
+// the migrations.
+func (m Migrator) DryRunUpTo(ctx context.Context, version string) ([]MigrationSQL, error) {
+	mfs, err := m.pendingUp(ctx, version)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return m.dryRun(mfs)
+}
+
+// DryRunDownTo returns the SQL DownTo would run, without rolling
+// back the migrations.
+func (m Migrator) DryRunDownTo(ctx context.Context, version string) ([]MigrationSQL, error) {
+	mfs, err := m.pendingDown(ctx, version)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return m.dryRun(mfs)
+}
+
 // Reset the database by running the down migrations followed by the up migrations.
 func (m Migrator) Reset() error {
 	err := m.Down(-1)
@@ -172,12 +217,11 @@ func (m Migrator) CreateSchemaMigrations() error {
 	if err != nil {
 		return errors.Wrap(err, "could not open connection")
 	}
-	_, err = c.Store.Exec(fmt.Sprintf("select * from %s", mtn))
-	if err == nil {
+	if m.hasSchemaMigrations() {
 		return nil
 	}
 
-	return c.Transaction(func(tx *Connection) error {
+	return c.Transaction(context.TODO(), func(ctx context.Context, tx *Connection) error {
 		schemaMigrations := newSchemaMigrations(mtn)
 		smSQL, err := c.Dialect.FizzTranslator().CreateTable(schemaMigrations)
 		if err != nil {
@@ -191,24 +235,40 @@ func (m Migrator) CreateSchemaMigrations() error {
 	})
 }
 
-// Status prints out the status of applied/pending migrations.
-func (m Migrator) Status() error {
-	err := m.CreateSchemaMigrations()
+// Status returns the status of the "up" migrations for the
+// connection dialect, ordered by version.
+func (m Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	mfs := m.forDialect("up")
+	sort.Sort(mfs)
+	st := make([]MigrationStatus, 0, len(mfs))
+	for _, mi := range mfs {
+		st = append(st, MigrationStatus{
+			Version: mi.Version,
+			Name:    mi.Name,
+			Applied: applied[mi.Version],
+		})
+	}
+	return st, nil
+}
+
+// PrintStatus writes the status of applied/pending migrations to out.
+func (m Migrator) PrintStatus(out io.Writer) error {
+	st, err := m.Status(context.TODO())
 	if err != nil {
 		return errors.WithStack(err)
 	}
-	w := tabwriter.NewWriter(os.Stdout, 0, 0, 3, ' ', tabwriter.TabIndent)
+	w := tabwriter.NewWriter(out, 0, 0, 3, ' ', tabwriter.TabIndent)
 	fmt.Fprintln(w, "Version\tName\tStatus\t")
-	for _, mf := range m.Migrations["up"] {
-		exists, err := m.Connection.Where("version = ?", mf.Version).Exists(m.Connection.MigrationTableName())
-		if err != nil {
-			return errors.Wrapf(err, "problem with migration")
-		}
+	for _, s := range st {
 		state := "Pending"
-		if exists {
+		if s.Applied {
 			state = "Applied"
 		}
-		fmt.Fprintf(w, "%s\t%s\t%s\t\n", mf.Version, mf.Name, state)
+		fmt.Fprintf(w, "%s\t%s\t%s\t\n", s.Version, s.Name, state)
 	}
 	return w.Flush()
 }
@@ -233,23 +293,181 @@ func (m Migrator) DumpMigrationSchema() error {
 	return nil
 }
 
-func (m Migrator) exec(fn func() error) error {
+func (m Migrator) exec(ctx context.Context, fn func() error) error {
 	now := time.Now()
 	defer func() {
 		err := m.DumpMigrationSchema()
 		if err != nil {
-			log(logging.Warn, "Migrator: unable to dump schema: %v", err)
+			m.Connection.log(logging.Warn, "Migrator: unable to dump schema: %v", err)
 		}
 	}()
 	defer printTimer(now)
 
-	err := m.CreateSchemaMigrations()
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return errors.Wrap(err, "Migrator: problem locking migrations")
+	}
+	defer unlock()
+
+	err = m.CreateSchemaMigrations()
 	if err != nil {
 		return errors.Wrap(err, "Migrator: problem creating schema migrations")
 	}
 	return fn()
 }
 
+// run runs the migration and records it in the migrations table. Both
+// happen in a single transaction, on dialects supporting transactional DDL.
+func (m Migrator) run(ctx context.Context, mi Migration) error {
+	c := m.Connection
+	mtn := c.MigrationTableName()
+	apply := func(tx *Connection) error {
+		err := mi.Run(tx)
+		if err != nil {
+			return err
+		}
+		if mi.Direction == "down" {
+			err = tx.RawQuery(fmt.Sprintf("delete from %s where version = ?", mtn), mi.Version).Exec()
+			return errors.Wrapf(err, "problem deleting migration version %s", mi.Version)
+		}
+		err = tx.RawQuery(fmt.Sprintf("insert into %s (version) values (?)", mtn), mi.Version).Exec()
+		return errors.Wrapf(err, "problem inserting migration version %s", mi.Version)
+	}
+	defer c.InvalidateSchemaCache()
+	return c.runMiddlewares(ctx, "Migrate", func(ctx context.Context) error {
+		if !transactionalDDL(c.Dialect) {
+			return apply(c)
+		}
+		return c.Transaction(ctx, func(ctx context.Context, tx *Connection) error {
+			return apply(tx)
+		})
+	})
+}
+
+// transactionalDDL tells if schema changes can be rolled back on the
+// dialect. MySQL commits them implicitly.
+func transactionalDDL(d dialect) bool {
+	return d.Name() != nameMySQL
+}
+
+// dryRun returns the SQL of the migrations.
+func (m Migrator) dryRun(mfs Migrations) ([]MigrationSQL, error) {
+	sqls := make([]MigrationSQL, 0, len(mfs))
+	for _, mi := range mfs {
+		content, err := mi.SQL(m.Connection)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		sqls = append(sqls, MigrationSQL{
+			Version:   mi.Version,
+			Name:      mi.Name,
+			Direction: mi.Direction,
+			SQL:       content,
+		})
+	}
+	return sqls, nil
+}
+
+// pendingUp returns the "up" migrations to run to reach version.
+func (m Migrator) pendingUp(ctx context.Context, version string) (Migrations, error) {
+	mfs := m.forDialect("up")
+	sort.Sort(mfs)
+	if version != "" && !mfs.has(version) {
+		return nil, errors.Errorf("unknown migration version %s", version)
+	}
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	var pending Migrations
+	for _, mi := range mfs {
+		if version != "" && mi.Version > version {
+			break
+		}
+		if !applied[mi.Version] {
+			pending = append(pending, mi)
+		}
+	}
+	return pending, nil
+}
+
+// pendingDown returns the "down" migrations to run to roll back to version,
+// newest first.
+func (m Migrator) pendingDown(ctx context.Context, version string) (Migrations, error) {
+	ups := m.forDialect("up")
+	if version != "" && !ups.has(version) {
+		return nil, errors.Errorf("unknown migration version %s", version)
+	}
+	applied, err := m.appliedVersions(ctx)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+	mfs := m.forDialect("down")
+	sort.Sort(sort.Reverse(mfs))
+	var pending Migrations
+	for _, mi := range mfs {
+		if mi.Version <= version {
+			break
+		}
+		if applied[mi.Version] {
+			pending = append(pending, mi)
+			delete(applied, mi.Version)
+		}
+	}
+	for v := range applied {
+		if v > version && ups.has(v) {
+			return nil, errors.Errorf("no down migration found for version %s", v)
+		}
+	}
+	return pending, nil
+}
+
+// forDialect returns the migrations in direction which apply to
+// the connection dialect.
+func (m Migrator) forDialect(direction string) Migrations {
+	var mfs Migrations
+	for _, mi := range m.Migrations[direction] {
+		if mi.DBType == "all" || mi.DBType == m.Connection.Dialect.Name() {
+			mfs = append(mfs, mi)
+		}
+	}
+	return mfs
+}
+
+// appliedVersions returns the versions recorded in the migrations table.
+// It's empty if the table doesn't exist yet.
+func (m Migrator) appliedVersions(ctx context.Context) (map[string]bool, error) {
+	c := m.Connection
+	err := c.Open()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not open connection")
+	}
+	applied := map[string]bool{}
+	if !m.hasSchemaMigrations() {
+		return applied, nil
+	}
+	var versions []string
+	query := fmt.Sprintf("select version from %s", c.MigrationTableName())
+	err = c.timeFunc(ctx, "Status", readOp, c.MigrationTableName(), func(ctx context.Context, c *Connection) error {
+		c.log(logging.SQL, query)
+		return c.Store.Select(&versions, query)
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "problem listing applied migrations")
+	}
+	for _, v := range versions {
+		applied[v] = true
+	}
+	return applied, nil
+}
+
+// hasSchemaMigrations tells if the migrations table exists.
+func (m Migrator) hasSchemaMigrations() bool {
+	c := m.Connection
+	_, err := c.Store.Exec(fmt.Sprintf("select * from %s", c.MigrationTableName()))
+	return err == nil
+}
+
 func printTimer(timerStart time.Time) {
 	diff := time.Since(timerStart).Seconds()
 	if diff > 60 {
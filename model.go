@@ -1,8 +1,11 @@
 package pop
 
 import (
+	"context"
+	"database/sql/driver"
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
 	"time"
 
@@ -26,17 +29,74 @@ type Model struct {
 	Value
 	tableName string
 	As        string
+	// schema is the default schema of the connection of the model,
+	// qualifying its table name if it's not qualified.
+	schema string
+	// returning columns to read back after the model creation. The dialects
+	// reading them with INSERT ... RETURNING reset it.
+	returning []string
+	// blind writes don't read anything back, see Connection.BlindWrites.
+	blind bool
+	// ctx is the context the table name is resolved in, see
+	// ContextTableNameAble.
+	ctx context.Context
 }
 
 // ID returns the ID of the Model. All models must have an `ID` field this is
 // of type `int`,`int64` or of type `uuid.UUID`.
 func (m *Model) ID() interface{} {
-	fbn, err := m.fieldByName("ID")
+	fbn, err := m.idField()
 	if err != nil {
 		return 0
 	}
-	if m.PrimaryKeyType() == "UUID" {
-		return fbn.Interface().(uuid.UUID).String()
+	return idValue(fbn)
+}
+
+// PrimaryKeyValue returns the value of the ID field of the Model, its
+// primary key, to query the model by ID. The ID is an integer, a string, a
+// `uuid.UUID`, returned as a string like ID does, or another value of the
+// database like a ULID. It returns an error when the model has no ID, e.g.
+// before it's created.
+func (m *Model) PrimaryKeyValue() (interface{}, error) {
+	fbn, err := m.idField()
+	if err != nil {
+		return nil, err
+	}
+	if fbn.Kind() == reflect.Ptr {
+		if fbn.IsNil() {
+			return nil, errors.Errorf("%T has no ID", m.Value)
+		}
+		fbn = fbn.Elem()
+	}
+	if fbn.IsZero() {
+		return nil, errors.Errorf("%T has no ID", m.Value)
+	}
+	switch fbn.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.String:
+		return idValue(fbn), nil
+	}
+	if _, ok := fbn.Interface().(driver.Valuer); ok {
+		return idValue(fbn), nil
+	}
+	return nil, errors.Errorf("unsupported primary key type %s of %T", fbn.Type(), m.Value)
+}
+
+// idField returns the ID field of the model.
+func (m *Model) idField() (reflect.Value, error) {
+	v := reflect.ValueOf(m.Value)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, errors.Errorf("%T is not a pointer to a struct", m.Value)
+	}
+	return m.fieldByName("ID")
+}
+
+// idValue returns the value of the ID field fbn, the UUIDs being returned
+// as strings.
+func idValue(fbn reflect.Value) interface{} {
+	if u, ok := fbn.Interface().(uuid.UUID); ok {
+		return u.String()
 	}
 	return fbn.Interface()
 }
@@ -58,12 +118,69 @@ type TableNameAble interface {
 	TableName() string
 }
 
+// ContextTableNameAble is implemented by the models whose table is chosen
+// per query, such as partitions, from the context of the query. It takes
+// precedence over TableNameAble.
+//
+//	func (Event) TableNameWithContext(ctx context.Context) string {
+//		return "events_" + ctx.Value(monthKey{}).(string)
+//	}
+//
+// The context is the one given to the finders. The operations without a
+// context, such as Create, Update, Destroy, Count and Exists, use the
+// context of the transaction of their connection, see
+// Connection.Transaction, or the one of Connection.WithContext, and
+// return an error without one:
+//
+//	err := c.Transaction(ctx, func(ctx context.Context, tx *pop.Connection) error {
+//		return tx.Create(&event)
+//	})
+//
+// The associations of such models can't be loaded: Load and the eager
+// finders return an error.
+type ContextTableNameAble interface {
+	TableNameWithContext(ctx context.Context) string
+}
+
 // TableName returns the corresponding name of the underlying database table
 // for a given `Model`. See also `TableNameAble` to change the default name of the table.
+//
+// The table name may be qualified by its schema, e.g. "billing.invoices",
+// or by its database for MySQL. The unqualified names are qualified by the
+// Schema of the ConnectionDetails of the connection, if it's set.
 func (m *Model) TableName() string {
+	name := m.unqualifiedTableName()
+	if m.schema != "" && !strings.Contains(name, ".") {
+		return m.schema + "." + name
+	}
+	return name
+}
+
+// alias returns the alias of the table of the model in the queries: its
+// As, or its table name without the default schema, the dots of a
+// qualified name being replaced by underscores, e.g. "billing_invoices".
+func (m *Model) alias() string {
+	if m.As != "" {
+		return m.As
+	}
+	return strings.Replace(m.unqualifiedTableName(), ".", "_", -1)
+}
+
+// unqualifiedTableName returns the table name of the model, without the
+// default schema of its connection.
+func (m *Model) unqualifiedTableName() string {
 	if s, ok := m.Value.(string); ok {
 		return s
 	}
+	if n, ok := contextTableNameAble(m.Value); ok {
+		// not cached, the name depends on the context. The operations
+		// without one refuse these models, see checkTableContext.
+		ctx := m.ctx
+		if ctx == nil {
+			ctx = context.TODO()
+		}
+		return n.TableNameWithContext(ctx)
+	}
 	if n, ok := m.Value.(TableNameAble); ok {
 		return n.TableName()
 	}
@@ -85,6 +202,30 @@ func (m *Model) TableName() string {
 	return tableMap[name]
 }
 
+// contextTableNameAble returns the ContextTableNameAble of a model, or of
+// the elements of a slice.
+func contextTableNameAble(v interface{}) (ContextTableNameAble, bool) {
+	if n, ok := v.(ContextTableNameAble); ok {
+		return n, true
+	}
+	t := reflect.TypeOf(v)
+	if t == nil {
+		return nil, false
+	}
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Slice && t.Kind() != reflect.Array {
+		return nil, false
+	}
+	el := t.Elem()
+	if el.Kind() == reflect.Ptr {
+		el = el.Elem()
+	}
+	n, ok := reflect.New(el).Interface().(ContextTableNameAble)
+	return n, ok
+}
+
 func (m *Model) typeName(t reflect.Type) string {
 	if t.Kind() == reflect.Ptr {
 		t = t.Elem()
@@ -97,14 +238,13 @@ func (m *Model) typeName(t reflect.Type) string {
 		}
 
 		// validates if the elem of slice or array implements TableNameAble interface.
-		tableNameAble := (*TableNameAble)(nil)
-		if el.Implements(reflect.TypeOf(tableNameAble).Elem()) {
-			v := reflect.New(el)
-			out := v.MethodByName("TableName").Call([]reflect.Value{})
-			name := out[0].String()
+		if tn, ok := reflect.Zero(el).Interface().(TableNameAble); ok {
+			name := tn.TableName()
+			tableMapMu.Lock()
 			if tableMap[el.Name()] == "" {
 				tableMap[el.Name()] = name
 			}
+			tableMapMu.Unlock()
 		}
 
 		return el.Name()
@@ -123,7 +263,8 @@ func (m *Model) fieldByName(s string) (reflect.Value, error) {
 }
 
 func (m *Model) associationName() string {
-	tn := flect.Singularize(m.TableName())
+	tn := m.TableName()
+	tn = flect.Singularize(tn[strings.LastIndex(tn, ".")+1:])
 	return fmt.Sprintf("%s_id", tn)
 }
 
@@ -140,28 +281,38 @@ func (m *Model) setID(i interface{}) {
 	}
 }
 
-func (m *Model) touchCreatedAt() {
+// dbTime returns t in the time zone the connection writes the times in:
+// UTC with ConnectionDetails.UTC, the zone of t otherwise. It's applied to
+// the timestamps of the models, and to the time bounds of the range
+// helpers, so the columns without a time zone compare them in the same
+// zone.
+func (c *Connection) dbTime(t time.Time) time.Time {
+	if d := c.details(); d != nil && d.UTC {
+		return t.UTC()
+	}
+	return t
+}
+
+func (m *Model) touchCreatedAt(t time.Time) {
 	fbn, err := m.fieldByName("CreatedAt")
 	if err == nil {
-		now := time.Now()
 		switch fbn.Kind() {
 		case reflect.Int, reflect.Int64:
-			fbn.SetInt(now.Unix())
+			fbn.SetInt(t.Unix())
 		default:
-			fbn.Set(reflect.ValueOf(now))
+			fbn.Set(reflect.ValueOf(t))
 		}
 	}
 }
 
-func (m *Model) touchUpdatedAt() {
+func (m *Model) touchUpdatedAt(t time.Time) {
 	fbn, err := m.fieldByName("UpdatedAt")
 	if err == nil {
-		now := time.Now()
 		switch fbn.Kind() {
 		case reflect.Int, reflect.Int64:
-			fbn.SetInt(now.Unix())
+			fbn.SetInt(t.Unix())
 		default:
-			fbn.Set(reflect.ValueOf(now))
+			fbn.Set(reflect.ValueOf(t))
 		}
 	}
 }
@@ -184,7 +335,7 @@ func (m *Model) iterate(fn modelIterable) error {
 		v := reflect.Indirect(reflect.ValueOf(m.Value))
 		for i := 0; i < v.Len(); i++ {
 			val := v.Index(i)
-			newModel := &Model{Value: val.Addr().Interface()}
+			newModel := &Model{Value: val.Addr().Interface(), schema: m.schema, ctx: m.ctx}
 			err := fn(newModel)
 
 			if err != nil {
@@ -0,0 +1,50 @@
+package pop
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/gobuffalo/pop/associations"
+)
+
+// checkedAssociations caches the association checks, by model type.
+var checkedAssociations = sync.Map{}
+
+// ValidateModels checks the association tags (has_many, belongs_to, has_one,
+// many_to_many) of the given models, without a database connection. It
+// returns all the problems found, with their struct and field names.
+// It's meant to be called from a unit test, to catch misspelled tags
+// before they fail at runtime:
+//
+//	func Test_Models(t *testing.T) {
+//		if err := pop.ValidateModels(&User{}, &Book{}); err != nil {
+//			t.Fatal(err)
+//		}
+//	}
+func ValidateModels(models ...interface{}) error {
+	var errs associations.TagErrors
+	for _, m := range models {
+		if err := checkAssociations(m); err != nil {
+			errs = append(errs, err.(associations.TagErrors)...)
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	return errs
+}
+
+// checkAssociations checks the association tags of the model type,
+// once per type.
+func checkAssociations(model interface{}) error {
+	t := reflect.TypeOf(model)
+	if err, ok := checkedAssociations.Load(t); ok {
+		if err == nil {
+			return nil
+		}
+		return err.(error)
+	}
+	err := associations.ValidateStruct(model)
+	checkedAssociations.Store(t, err)
+	return err
+}
@@ -0,0 +1,33 @@
+package pop
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gobuffalo/pop/associations"
+	"github.com/stretchr/testify/require"
+)
+
+type badAssociationsModel struct {
+	ID    int    `db:"id"`
+	Owner Writer `belongs_to:"writer"`
+}
+
+func Test_ValidateModels(t *testing.T) {
+	r := require.New(t)
+
+	r.NoError(ValidateModels(&User{}, &Book{}, &Song{}, &Taxi{}, &Student{}, &Parent{}))
+
+	err := ValidateModels(&User{}, &badAssociationsModel{})
+	r.Error(err)
+	errs, ok := err.(associations.TagErrors)
+	r.True(ok)
+	r.Len(errs, 1)
+	r.Equal("badAssociationsModel", errs[0].Model)
+	r.Equal("Owner", errs[0].Field)
+
+	// the checks are cached by model type
+	cached, ok := checkedAssociations.Load(reflect.TypeOf(&badAssociationsModel{}))
+	r.True(ok)
+	r.Equal(errs, cached)
+}
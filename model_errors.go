@@ -0,0 +1,231 @@
+package pop
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gobuffalo/flect"
+	"github.com/gobuffalo/validate"
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+// The rule and code of the FieldErrors of the validation errors: the
+// validate.Errors only carry the messages of the failures, not the
+// validators reporting them.
+const (
+	RuleValidation = "validation"
+	CodeInvalid    = "invalid"
+)
+
+// FieldError is a failure of a field of a model: a validation error, or a
+// constraint violation of its column.
+type FieldError struct {
+	// Field is the name of the struct field, empty if the failure isn't
+	// mapped to a field.
+	Field string `json:"field,omitempty"`
+	// Column is the column of the field.
+	Column string `json:"column,omitempty"`
+	// Rule is RuleValidation for the validation errors, or the kind of the
+	// violated constraint, e.g. ConstraintUnique.
+	Rule string `json:"rule"`
+	// Code is the machine readable code of the failure: CodeEnum for the
+	// enum fields, CodeInvalid for the other validation errors, or the
+	// code of the ConstraintError, e.g. "unique_violation".
+	Code string `json:"code"`
+	// Message is the human readable message of the failure.
+	Message string `json:"message"`
+}
+
+// ModelErrors are all the failures of the fields of a model. It's returned
+// as the error of ValidateAndCreate, ValidateAndUpdate and ValidateAndSave
+// when the write violates a constraint, their validation errors being
+// returned as *validate.Errors: FieldErrors merges both.
+type ModelErrors struct {
+	// Model is the name of the type of the model.
+	Model  string       `json:"model"`
+	Errors []FieldError `json:"errors"`
+
+	err error
+}
+
+func (e *ModelErrors) Error() string {
+	msgs := make([]string, len(e.Errors))
+	for i, fe := range e.Errors {
+		msgs[i] = fe.Message
+	}
+	return fmt.Sprintf("invalid %s: %s", e.Model, strings.Join(msgs, "; "))
+}
+
+// Cause returns the ConstraintError of the failed write, if any.
+func (e *ModelErrors) Cause() error {
+	return e.err
+}
+
+// HasAny tells if there are any failures.
+func (e *ModelErrors) HasAny() bool {
+	return e != nil && len(e.Errors) > 0
+}
+
+// FieldErrors returns the failures of the model: the validation errors
+// verrs, whose keys are matched against the columns and fields of the
+// model, and the constraint violation causing err, if any. It returns nil
+// when there are no failures. The validation errors have RuleValidation
+// and CodeInvalid, except the enum ones, which have CodeEnum.
+//
+//	verrs, err := c.ValidateAndCreate(&user)
+//	if merrs := pop.FieldErrors(&user, verrs, err); merrs != nil {
+//		return c.Render(422, r.JSON(merrs.JSONAPI()))
+//	}
+func FieldErrors(model interface{}, verrs *validate.Errors, err error) *ModelErrors {
+	m := &Model{Value: model}
+	me := &ModelErrors{Model: m.typeName(reflect.TypeOf(model))}
+	if prev, ok := err.(*ModelErrors); ok {
+		me.Errors = append(me.Errors, prev.Errors...)
+		me.err = prev.err
+	} else if ce, ok := AsConstraintError(err); ok {
+		me.add(m, ce)
+	}
+	if verrs != nil {
+		enums := map[[2]string]FieldError{}
+		for _, fe := range m.enumErrors() {
+			enums[[2]string{fe.Column, fe.Message}] = fe
+		}
+		fields := modelFields(model)
+		keys := make([]string, 0, len(verrs.Errors))
+		for k := range verrs.Errors {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fe := fields.find(k)
+			for _, msg := range verrs.Errors[k] {
+				if ef, ok := enums[[2]string{k, msg}]; ok {
+					me.Errors = append(me.Errors, ef)
+					continue
+				}
+				fe.Rule, fe.Code, fe.Message = RuleValidation, CodeInvalid, msg
+				me.Errors = append(me.Errors, fe)
+			}
+		}
+	}
+	if !me.HasAny() {
+		return nil
+	}
+	return me
+}
+
+// modelErrors returns the failed write err of the model as ModelErrors, if
+// it violated a constraint.
+func modelErrors(model interface{}, err error) error {
+	if _, ok := AsConstraintError(err); !ok {
+		return err
+	}
+	return FieldErrors(model, nil, err)
+}
+
+// add adds the failures of the columns of the constraint violation.
+func (e *ModelErrors) add(m *Model, ce *ConstraintError) {
+	e.err = ce
+	if ce.Table != "" && ce.Table != m.TableName() {
+		// the columns of the other table of a foreign key aren't fields
+		ce = &ConstraintError{Kind: ce.Kind, Constraint: ce.Constraint}
+	}
+	fields := modelFields(m.Value)
+	cols := ce.Columns
+	if len(cols) == 0 {
+		cols = []string{""}
+	}
+	for _, col := range cols {
+		fe := FieldError{Column: col}
+		if col != "" {
+			fe = fields.find(col)
+		}
+		fe.Rule, fe.Code = ce.Kind, ce.Code()
+		fe.Message = constraintMessage(fe.Column, ce.Kind)
+		e.Errors = append(e.Errors, fe)
+	}
+}
+
+var constraintMessages = map[string]string{
+	ConstraintUnique:     "is already taken",
+	ConstraintForeignKey: "references a missing record",
+	ConstraintNotNull:    "can not be blank",
+	ConstraintCheck:      "is invalid",
+}
+
+func constraintMessage(col, kind string) string {
+	if col == "" {
+		return fmt.Sprintf("%s constraint violated.", flect.Humanize(kind))
+	}
+	return fmt.Sprintf("%s %s.", flect.Humanize(col), constraintMessages[kind])
+}
+
+type modelFieldList []FieldError
+
+// modelFields returns the fields of the columns of the model.
+func modelFields(model interface{}) modelFieldList {
+	t := reflectx.Deref(reflect.TypeOf(model))
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	var fields modelFieldList
+	for _, fi := range strictMapper.TypeMap(t).Index {
+		if fi.Embedded || fi.Name == "" || strings.Contains(fi.Path, ".") {
+			continue
+		}
+		fields = append(fields, FieldError{Field: fi.Field.Name, Column: fi.Name})
+	}
+	return fields
+}
+
+// find returns the field whose column or name matches the key, e.g. the key
+// of a validation error, or a FieldError with the key as column.
+func (l modelFieldList) find(key string) FieldError {
+	for _, f := range l {
+		if key == f.Column || key == f.Field || key == flect.Underscore(f.Field) {
+			return f
+		}
+	}
+	return FieldError{Column: key}
+}
+
+// JSONAPIError is an error object of the JSON:API specification.
+type JSONAPIError struct {
+	Status string         `json:"status"`
+	Code   string         `json:"code"`
+	Title  string         `json:"title"`
+	Detail string         `json:"detail"`
+	Source *JSONAPISource `json:"source,omitempty"`
+}
+
+// JSONAPISource is the source of a JSONAPIError.
+type JSONAPISource struct {
+	Pointer string `json:"pointer"`
+}
+
+// JSONAPI returns the failures as the errors member of a JSON:API
+// document, pointing at the attributes of their columns: the status of
+// the unique violations is 409, the others 422.
+//
+//	json.NewEncoder(w).Encode(map[string]interface{}{"errors": merrs.JSONAPI()})
+func (e *ModelErrors) JSONAPI() []JSONAPIError {
+	errs := make([]JSONAPIError, len(e.Errors))
+	for i, fe := range e.Errors {
+		je := JSONAPIError{
+			Status: "422",
+			Code:   fe.Code,
+			Title:  flect.Humanize(fe.Code),
+			Detail: fe.Message,
+		}
+		if fe.Rule == ConstraintUnique {
+			je.Status = "409"
+		}
+		if fe.Column != "" {
+			je.Source = &JSONAPISource{Pointer: "/data/attributes/" + fe.Column}
+		}
+		errs[i] = je
+	}
+	return errs
+}
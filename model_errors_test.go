@@ -0,0 +1,111 @@
+package pop
+
+import (
+	"testing"
+
+	_mysql "github.com/go-sql-driver/mysql"
+	"github.com/gobuffalo/validate"
+	pg "github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_ConstraintError_Translation(t *testing.T) {
+	r := require.New(t)
+
+	err := withConstraintError(&postgresql{}, errors.Wrap(&pg.Error{
+		Code:       "23505",
+		Table:      "books",
+		Constraint: "books_isbn_key",
+		Detail:     "Key (isbn)=(X1) already exists.",
+	}, "postgres create"))
+	ce, ok := AsConstraintError(err)
+	r.True(ok)
+	r.Equal(ConstraintUnique, ce.Kind)
+	r.Equal("books_isbn_key", ce.Constraint)
+	r.Equal([]string{"isbn"}, ce.Columns)
+	r.Equal("unique_violation", ce.Code())
+	r.IsType(&pg.Error{}, errors.Cause(err))
+
+	ce, ok = AsConstraintError(withConstraintError(&mysql{}, &_mysql.MySQLError{
+		Number:  1452,
+		Message: "Cannot add or update a child row: a foreign key constraint fails (`pop_test`.`books`, CONSTRAINT `books_user_fk` FOREIGN KEY (`user_id`) REFERENCES `users` (`id`))",
+	}))
+	r.True(ok)
+	r.Equal(ConstraintForeignKey, ce.Kind)
+	r.Equal("books", ce.Table)
+	r.Equal("books_user_fk", ce.Constraint)
+	r.Equal([]string{"user_id"}, ce.Columns)
+
+	ce, ok = AsConstraintError(withConstraintError(&mysql{}, &_mysql.MySQLError{
+		Number:  1062,
+		Message: "Duplicate entry 'X1' for key 'books.books_isbn_idx'",
+	}))
+	r.True(ok)
+	r.Equal("books_isbn_idx", ce.Constraint)
+	r.Empty(ce.Columns)
+
+	_, ok = AsConstraintError(withConstraintError(&mysql{}, &_mysql.MySQLError{Number: 1213}))
+	r.False(ok)
+}
+
+func Test_FieldErrors(t *testing.T) {
+	r := require.New(t)
+
+	verrs := validate.NewErrors()
+	verrs.Add("title", "Title can not be blank.")
+	verrs.Add("UserID", "UserID is invalid.")
+	verrs.Add("other", "Other is invalid.")
+	err := &ConstraintError{Kind: ConstraintUnique, Table: "books", Columns: []string{"isbn"}, Err: errors.New("duplicate")}
+
+	merrs := FieldErrors(&Book{}, verrs, err)
+	r.Equal("Book", merrs.Model)
+	r.Equal([]FieldError{
+		{Field: "Isbn", Column: "isbn", Rule: ConstraintUnique, Code: "unique_violation", Message: "Isbn is already taken."},
+		{Field: "UserID", Column: "user_id", Rule: RuleValidation, Code: CodeInvalid, Message: "UserID is invalid."},
+		{Column: "other", Rule: RuleValidation, Code: CodeInvalid, Message: "Other is invalid."},
+		{Field: "Title", Column: "title", Rule: RuleValidation, Code: CodeInvalid, Message: "Title can not be blank."},
+	}, merrs.Errors)
+	r.Equal(err, merrs.Cause())
+	r.Equal(err.Err, errors.Cause(merrs))
+
+	jerrs := merrs.JSONAPI()
+	r.Len(jerrs, 4)
+	r.Equal(JSONAPIError{
+		Status: "409",
+		Code:   "unique_violation",
+		Title:  "Unique violation",
+		Detail: "Isbn is already taken.",
+		Source: &JSONAPISource{Pointer: "/data/attributes/isbn"},
+	}, jerrs[0])
+	r.Equal("422", jerrs[3].Status)
+
+	r.Nil(FieldErrors(&Book{}, validate.NewErrors(), nil))
+}
+
+func Test_ValidateAndCreate_ConstraintError(t *testing.T) {
+	if n := PDB.Dialect.Name(); n == nameMySQL || n == nameCockroach {
+		t.Skipf("%s commits the transaction of the index", n)
+	}
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		_, err := tx.ExecRaw(ctx, "CREATE UNIQUE INDEX books_isbn_uniq ON books (isbn)")
+		r.NoError(err)
+		r.NoError(tx.Create(&Book{Title: "Unique", Isbn: "U1"}))
+
+		verrs, err := tx.ValidateAndCreate(&Book{Title: "Unique, again", Isbn: "U1"})
+		r.False(verrs.HasAny())
+		merrs, ok := err.(*ModelErrors)
+		r.True(ok)
+		r.Len(merrs.Errors, 1)
+		r.Equal("Isbn", merrs.Errors[0].Field)
+		r.Equal("isbn", merrs.Errors[0].Column)
+		r.Equal("unique_violation", merrs.Errors[0].Code)
+
+		ce, ok := AsConstraintError(err)
+		r.True(ok)
+		r.Equal(ConstraintUnique, ce.Kind)
+	})
+}
@@ -1,9 +1,17 @@
 package pop
 
 import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -50,6 +58,146 @@ func Test_TableName_With_Array(t *testing.T) {
 	r.Equal("this is my table name", m.TableName())
 }
 
+type payment struct {
+	ID int `db:"id"`
+}
+
+func (payment) TableName() string {
+	return "billing.payments"
+}
+
+func Test_Model_TableName_Schema(t *testing.T) {
+	r := require.New(t)
+
+	// the unqualified names are qualified by the default schema, which
+	// doesn't change their alias
+	m := &Model{Value: &User{}, schema: "auth"}
+	r.Equal("auth.users", m.TableName())
+	r.Equal("users", m.alias())
+	r.Equal("user_id", m.associationName())
+
+	m = &Model{Value: &payment{}, schema: "auth"}
+	r.Equal("billing.payments", m.TableName())
+	r.Equal("billing_payments", m.alias())
+	r.Equal("payment_id", m.associationName())
+
+	m = &Model{Value: &[]payment{}, As: "i"}
+	r.Equal("billing.payments", m.TableName())
+	r.Equal("i", m.alias())
+
+	// the models of a slice keep the schema
+	r.NoError((&Model{Value: &Users{{}}, schema: "auth"}).iterate(func(m *Model) error {
+		r.Equal("auth.users", m.TableName())
+		return nil
+	}))
+}
+
+type monthKey struct{}
+
+type partitionedEvent struct {
+	ID        int       `db:"id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (partitionedEvent) TableNameWithContext(ctx context.Context) string {
+	if month, ok := ctx.Value(monthKey{}).(string); ok {
+		return "events_" + month
+	}
+	return "events"
+}
+
+func Test_Model_TableNameWithContext(t *testing.T) {
+	r := require.New(t)
+	d, err := ioutil.TempDir("", "pop-partitions")
+	r.NoError(err)
+	defer os.RemoveAll(d)
+
+	c, err := NewConnection(&ConnectionDetails{
+		Dialect:  "sqlite3",
+		Database: filepath.Join(d, "events.sqlite"),
+	})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+	for _, table := range []string{"events", "events_2024_01", "events_2024_02"} {
+		r.NoError(c.RawQuery(fmt.Sprintf(`CREATE TABLE %s (id integer primary key autoincrement, name text NOT NULL, created_at DATETIME NOT NULL, updated_at DATETIME NOT NULL)`, table)).Exec())
+	}
+
+	jan := context.WithValue(context.Background(), monthKey{}, "2024_01")
+	feb := context.WithValue(context.Background(), monthKey{}, "2024_02")
+	r.Equal("events_2024_01", c.modelContext(jan, &[]partitionedEvent{}).TableName())
+
+	// the writes use the context of the transaction
+	e := &partitionedEvent{Name: "signup"}
+	r.NoError(c.Transaction(jan, func(ctx context.Context, tx *Connection) error {
+		if err := tx.Create(e); err != nil {
+			return err
+		}
+		if err := tx.Create(&partitionedEvent{Name: "login"}); err != nil {
+			return err
+		}
+		e.Name = "register"
+		return tx.Update(e)
+	}))
+	r.NoError(c.Transaction(feb, func(ctx context.Context, tx *Connection) error {
+		return tx.Create(&[]partitionedEvent{{Name: "logout"}})
+	}))
+
+	events := []partitionedEvent{}
+	r.NoError(c.Order("id").All(jan, &events))
+	r.Len(events, 2)
+	r.Equal("register", events[0].Name)
+	events = []partitionedEvent{}
+	r.NoError(c.All(feb, &events))
+	r.Len(events, 1)
+	events = []partitionedEvent{}
+	r.NoError(c.All(context.Background(), &events))
+	r.Len(events, 0)
+	q := c.Paginate(1, 1)
+	r.NoError(q.All(jan, &events))
+	r.Len(events, 1)
+	r.Equal(2, q.Paginator.TotalEntriesSize)
+
+	found := &partitionedEvent{}
+	r.NoError(c.Find(jan, found, e.ID))
+	r.Equal("register", found.Name)
+	r.NoError(c.Find(feb, found, e.ID))
+	r.Equal("logout", found.Name)
+	r.True(errors.Is(c.Find(feb, found, e.ID+1), ErrRecordNotFound))
+
+	r.NoError(c.Transaction(jan, func(ctx context.Context, tx *Connection) error {
+		n, err := tx.Count(&partitionedEvent{})
+		r.Equal(2, n)
+		if err != nil {
+			return err
+		}
+		ok, err := tx.Where("name = ?", "login").Exists(&partitionedEvent{})
+		r.True(ok)
+		if err != nil {
+			return err
+		}
+		return tx.Destroy(e)
+	}))
+	// the operations without a context need one to resolve the table
+	_, err = c.Count(&partitionedEvent{})
+	r.Error(err)
+	r.Error(c.Create(&partitionedEvent{Name: "lost"}))
+	n, err := c.WithContext(context.Background()).Count(&partitionedEvent{})
+	r.NoError(err)
+	r.Equal(0, n)
+	events = []partitionedEvent{}
+	r.NoError(c.All(jan, &events))
+	r.Len(events, 1)
+
+	// the tables of the associations can't be told
+	err = c.Eager().All(jan, &events)
+	r.Error(err)
+	r.Contains(err.Error(), "its table name depends on the context")
+	r.Error(c.Load(jan, &events[0]))
+}
+
 type TimeTimestamp struct {
 	ID        int       `db:"id"`
 	CreatedAt time.Time `db:"created_at"`
@@ -66,8 +214,8 @@ func Test_Touch_Time_Timestamp(t *testing.T) {
 	r := require.New(t)
 
 	m := Model{Value: &TimeTimestamp{}}
-	m.touchCreatedAt()
-	m.touchUpdatedAt()
+	m.touchCreatedAt(time.Now())
+	m.touchUpdatedAt(time.Now())
 	v := m.Value.(*TimeTimestamp)
 	r.NotZero(v.CreatedAt)
 	r.NotZero(v.UpdatedAt)
@@ -77,9 +225,100 @@ func Test_Touch_Unix_Timestamp(t *testing.T) {
 	r := require.New(t)
 
 	m := Model{Value: &UnixTimestamp{}}
-	m.touchCreatedAt()
-	m.touchUpdatedAt()
+	m.touchCreatedAt(time.Now())
+	m.touchUpdatedAt(time.Now())
 	v := m.Value.(*UnixTimestamp)
 	r.NotZero(v.CreatedAt)
 	r.NotZero(v.UpdatedAt)
 }
+
+// testULID is a ULID-like primary key type.
+type testULID [16]byte
+
+func (u testULID) Value() (driver.Value, error) {
+	return u[:], nil
+}
+
+func Test_Model_PrimaryKeyValue(t *testing.T) {
+	r := require.New(t)
+
+	id, err := (&Model{Value: &User{ID: 42}}).PrimaryKeyValue()
+	r.NoError(err)
+	r.Equal(42, id)
+
+	u := uuid.Must(uuid.NewV4())
+	id, err = (&Model{Value: &Song{ID: u}}).PrimaryKeyValue()
+	r.NoError(err)
+	r.Equal(u.String(), id)
+
+	id, err = (&Model{Value: &struct{ ID uint }{ID: 7}}).PrimaryKeyValue()
+	r.NoError(err)
+	r.Equal(uint(7), id)
+
+	id, err = (&Model{Value: &struct{ ID string }{ID: "sku-1"}}).PrimaryKeyValue()
+	r.NoError(err)
+	r.Equal("sku-1", id)
+
+	ulid := testULID{1, 2, 3}
+	id, err = (&Model{Value: &struct{ ID testULID }{ID: ulid}}).PrimaryKeyValue()
+	r.NoError(err)
+	r.Equal(ulid, id)
+
+	for _, v := range []interface{}{
+		&User{},
+		&Song{},
+		&struct{ ID testULID }{},
+		&struct{ ID *int }{},
+		&struct{ ID float64 }{ID: 1},
+		&struct{ Name string }{},
+		User{ID: 1},
+	} {
+		_, err = (&Model{Value: v}).PrimaryKeyValue()
+		r.Error(err, "%T", v)
+	}
+}
+
+type BookDetails struct {
+	Title string `db:"title"`
+	Isbn  string `db:"isbn"`
+}
+
+type Timestamps struct {
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+type embeddedBook struct {
+	ID          int `db:"id"`
+	BookDetails `pop:",embed"`
+	*Timestamps `pop:",embed"`
+}
+
+func (embeddedBook) TableName() string {
+	return "books"
+}
+
+func Test_Model_EmbeddedFields(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		b := &embeddedBook{BookDetails: BookDetails{Title: "Pop", Isbn: "978"}, Timestamps: &Timestamps{}}
+		r.NoError(tx.Create(b))
+		r.NotZero(b.ID)
+		r.False(b.CreatedAt.IsZero())
+
+		found := &embeddedBook{}
+		r.NoError(tx.Find(ctx, found, b.ID))
+		r.Equal("Pop", found.Title)
+		r.Equal("978", found.Isbn)
+		r.True(b.CreatedAt.Equal(found.CreatedAt))
+
+		found.Title = "Soda"
+		r.NoError(tx.Update(found))
+		books := []embeddedBook{}
+		r.NoError(tx.Where("id = ?", b.ID).All(ctx, &books))
+		r.Len(books, 1)
+		r.Equal("Soda", books[0].Title)
+	})
+}
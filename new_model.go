@@ -0,0 +1,199 @@
+package pop
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding"
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
+)
+
+// NewModel returns a new T, with the defaults of its `pop:"default:…"`
+// tags, and its ID generated if it's a UUID or a ULID, a [16]byte array
+// type, so the model has an ID before it's created. It panics if a default
+// can't be parsed into its field, like regexp.MustCompile does.
+//
+//	type User struct {
+//		ID     uuid.UUID    `db:"id"`
+//		Status string       `db:"status" pop:"default:pending"`
+//		Quota  int          `db:"quota" pop:"default:10"`
+//		Admin  bool         `db:"admin" pop:"default:false"`
+//		Bio    nulls.String `db:"bio" pop:"default:n/a"`
+//	}
+//
+//	u := pop.NewModel[User]()
+//	err := c.Create(u)
+//
+// The defaults are parsed like the literals of Go for the numbers and
+// booleans; the other types are set by their UnmarshalText or Scan method,
+// given the default as a string. The fields of the embedded structs are
+// set too. The defaults may also be given by a `default:"…"` tag, and the
+// ones which are SQL expressions are left to the database, see
+// Connection.Create.
+func NewModel[T any]() *T {
+	m := new(T)
+	v := reflect.ValueOf(m).Elem()
+	if v.Kind() != reflect.Struct {
+		return m
+	}
+	if err := setDefaults(v); err != nil {
+		panic(fmt.Sprintf("pop: NewModel[%T]: %s", *m, err))
+	}
+	if err := generateID(&Model{Value: m}); err != nil {
+		panic(fmt.Sprintf("pop: NewModel[%T]: %s", *m, err))
+	}
+	return m
+}
+
+// setDefaults sets the fields of the struct v to the defaults of their
+// `pop:"default:…"` tags.
+func setDefaults(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.PkgPath != "" && !field.Anonymous {
+			continue
+		}
+		def, ok := defaultTag(field)
+		if ok && sqlDefault(def) {
+			// set by the database
+			continue
+		}
+		if !ok {
+			if field.Anonymous && field.Type.Kind() == reflect.Struct {
+				if err := setDefaults(v.Field(i)); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+		if err := setDefault(v.Field(i), def); err != nil {
+			return errors.Wrapf(err, "invalid default %q of %s", def, field.Name)
+		}
+	}
+	return nil
+}
+
+// defaultTag returns the default of a field, of its `default:"…"` tag or
+// its `pop:"default:…"` tag.
+func defaultTag(field reflect.StructField) (string, bool) {
+	if def, ok := field.Tag.Lookup("default"); ok {
+		return def, true
+	}
+	for _, opt := range strings.Split(field.Tag.Get("pop"), ",") {
+		if strings.HasPrefix(opt, "default:") {
+			return strings.TrimPrefix(opt, "default:"), true
+		}
+	}
+	return "", false
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// setDefault parses def into the field f.
+func setDefault(f reflect.Value, def string) error {
+	if f.Kind() == reflect.Ptr {
+		p := reflect.New(f.Type().Elem())
+		if err := setDefault(p.Elem(), def); err != nil {
+			return err
+		}
+		f.Set(p)
+		return nil
+	}
+	if f.CanAddr() {
+		switch u := f.Addr().Interface().(type) {
+		case encoding.TextUnmarshaler:
+			return u.UnmarshalText([]byte(def))
+		case sql.Scanner:
+			return u.Scan(def)
+		}
+	}
+	if f.Type() == durationType {
+		d, err := time.ParseDuration(def)
+		if err != nil {
+			return err
+		}
+		f.SetInt(int64(d))
+		return nil
+	}
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(def)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(def)
+		if err != nil {
+			return err
+		}
+		f.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(def, 0, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(def, 0, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(def, f.Type().Bits())
+		if err != nil {
+			return err
+		}
+		f.SetFloat(n)
+	default:
+		return errors.Errorf("%s can't have a default", f.Type())
+	}
+	return nil
+}
+
+var uuidType = reflect.TypeOf(uuid.UUID{})
+
+// generateID sets the ID of the model to a new UUID, or a new ULID for
+// the other [16]byte array types, if it has such an ID.
+func generateID(m *Model) error {
+	f, err := m.fieldByName("ID")
+	if err != nil {
+		return nil
+	}
+	if f.Type() == uuidType {
+		u, err := uuid.NewV4()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		f.Set(reflect.ValueOf(u))
+		return nil
+	}
+	if f.Kind() != reflect.Array || f.Len() != 16 || f.Type().Elem().Kind() != reflect.Uint8 {
+		return nil
+	}
+	id, err := newULID(time.Now())
+	if err != nil {
+		return err
+	}
+	reflect.Copy(f, reflect.ValueOf(id[:]))
+	return nil
+}
+
+// newULID returns a ULID of the time t: its first 48 bits are the
+// milliseconds of t since the Unix epoch, so the ULIDs sort by time, and
+// its 80 other bits are random.
+func newULID(t time.Time) ([16]byte, error) {
+	var id [16]byte
+	var ms [8]byte
+	binary.BigEndian.PutUint64(ms[:], uint64(t.UnixNano()/int64(time.Millisecond)))
+	copy(id[:6], ms[2:])
+	if _, err := rand.Read(id[6:]); err != nil {
+		return id, errors.WithStack(err)
+	}
+	return id, nil
+}
@@ -0,0 +1,68 @@
+package pop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+type defaultsBase struct {
+	Status string `db:"status" pop:"default:pending"`
+}
+
+type defaultedUser struct {
+	ID uuid.UUID `db:"id"`
+	defaultsBase
+	Quota   int           `db:"quota" pop:"default:10"`
+	Ratio   float64       `db:"ratio" pop:"default:0.5"`
+	Admin   bool          `db:"admin" pop:"default:true"`
+	Level   *uint8        `db:"level" pop:"default:3"`
+	TTL     time.Duration `db:"ttl" pop:"default:1h30m"`
+	Bio     nulls.String  `db:"bio" pop:"default:n/a"`
+	Name    string        `db:"name"`
+	private int           `pop:"default:1"`
+}
+
+type ulidModel struct {
+	ID   testULID `db:"id"`
+	Name string   `db:"name" pop:"default:anonymous"`
+}
+
+type badDefault struct {
+	Quota int `db:"quota" pop:"default:many"`
+}
+
+func Test_NewModel(t *testing.T) {
+	r := require.New(t)
+
+	u := NewModel[defaultedUser]()
+	r.NotEqual(uuid.Nil, u.ID)
+	r.Equal("pending", u.Status)
+	r.Equal(10, u.Quota)
+	r.Equal(0.5, u.Ratio)
+	r.True(u.Admin)
+	r.NotNil(u.Level)
+	r.Equal(uint8(3), *u.Level)
+	r.Equal(90*time.Minute, u.TTL)
+	r.Equal(nulls.NewString("n/a"), u.Bio)
+	r.Empty(u.Name)
+	r.Zero(u.private)
+
+	// each model gets its own ID
+	r.NotEqual(u.ID, NewModel[defaultedUser]().ID)
+
+	before := time.Now().UnixNano() / int64(time.Millisecond)
+	m := NewModel[ulidModel]()
+	r.Equal("anonymous", m.Name)
+	r.NotEqual(testULID{}, m.ID)
+	// the ULIDs start with their time in milliseconds
+	ms := int64(m.ID[0])<<40 | int64(m.ID[1])<<32 | int64(m.ID[2])<<24 | int64(m.ID[3])<<16 | int64(m.ID[4])<<8 | int64(m.ID[5])
+	r.True(ms >= before)
+
+	r.PanicsWithValue(`pop: NewModel[pop.badDefault]: invalid default "many" of Quota: strconv.ParseInt: parsing "many": invalid syntax`, func() {
+		NewModel[badDefault]()
+	})
+}
@@ -0,0 +1,142 @@
+package pop
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/json"
+)
+
+var jsonNull = []byte("null")
+
+// NullableString is a sql.NullString marshaled to JSON as its string, or
+// null when it isn't Valid. It's a drop-in replacement in the models:
+//
+//	type User struct {
+//		ID  int                 `db:"id"`
+//		Bio pop.NullableString `db:"bio"`
+//	}
+type NullableString struct {
+	sql.NullString
+}
+
+// NewNullableString returns a valid NullableString of s.
+func NewNullableString(s string) NullableString {
+	return NullableString{sql.NullString{String: s, Valid: true}}
+}
+
+// MarshalJSON marshals the string, or null.
+func (n NullableString) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return jsonNull, nil
+	}
+	return json.Marshal(n.String)
+}
+
+// UnmarshalJSON unmarshals a string, or null.
+func (n *NullableString) UnmarshalJSON(b []byte) error {
+	n.String, n.Valid = "", false
+	if bytes.Equal(b, jsonNull) {
+		return nil
+	}
+	if err := json.Unmarshal(b, &n.String); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// NullableInt is a sql.NullInt64 marshaled to JSON as its number, or null
+// when it isn't Valid.
+type NullableInt struct {
+	sql.NullInt64
+}
+
+// NewNullableInt returns a valid NullableInt of i.
+func NewNullableInt(i int64) NullableInt {
+	return NullableInt{sql.NullInt64{Int64: i, Valid: true}}
+}
+
+// MarshalJSON marshals the number, or null.
+func (n NullableInt) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return jsonNull, nil
+	}
+	return json.Marshal(n.Int64)
+}
+
+// UnmarshalJSON unmarshals a number, or null.
+func (n *NullableInt) UnmarshalJSON(b []byte) error {
+	n.Int64, n.Valid = 0, false
+	if bytes.Equal(b, jsonNull) {
+		return nil
+	}
+	if err := json.Unmarshal(b, &n.Int64); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// NullableFloat is a sql.NullFloat64 marshaled to JSON as its number, or
+// null when it isn't Valid.
+type NullableFloat struct {
+	sql.NullFloat64
+}
+
+// NewNullableFloat returns a valid NullableFloat of f.
+func NewNullableFloat(f float64) NullableFloat {
+	return NullableFloat{sql.NullFloat64{Float64: f, Valid: true}}
+}
+
+// MarshalJSON marshals the number, or null.
+func (n NullableFloat) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return jsonNull, nil
+	}
+	return json.Marshal(n.Float64)
+}
+
+// UnmarshalJSON unmarshals a number, or null.
+func (n *NullableFloat) UnmarshalJSON(b []byte) error {
+	n.Float64, n.Valid = 0, false
+	if bytes.Equal(b, jsonNull) {
+		return nil
+	}
+	if err := json.Unmarshal(b, &n.Float64); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
+
+// NullableBool is a sql.NullBool marshaled to JSON as its boolean, or null
+// when it isn't Valid.
+type NullableBool struct {
+	sql.NullBool
+}
+
+// NewNullableBool returns a valid NullableBool of b.
+func NewNullableBool(b bool) NullableBool {
+	return NullableBool{sql.NullBool{Bool: b, Valid: true}}
+}
+
+// MarshalJSON marshals the boolean, or null.
+func (n NullableBool) MarshalJSON() ([]byte, error) {
+	if !n.Valid {
+		return jsonNull, nil
+	}
+	return json.Marshal(n.Bool)
+}
+
+// UnmarshalJSON unmarshals a boolean, or null.
+func (n *NullableBool) UnmarshalJSON(b []byte) error {
+	n.Bool, n.Valid = false, false
+	if bytes.Equal(b, jsonNull) {
+		return nil
+	}
+	if err := json.Unmarshal(b, &n.Bool); err != nil {
+		return err
+	}
+	n.Valid = true
+	return nil
+}
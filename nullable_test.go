@@ -0,0 +1,79 @@
+package pop
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type nullableBook struct {
+	ID        int            `db:"id" json:"id"`
+	Title     string         `db:"title" json:"title"`
+	Isbn      NullableString `db:"isbn" json:"isbn"`
+	UserID    NullableInt    `db:"user_id" json:"user_id"`
+	CreatedAt time.Time      `db:"created_at" json:"-"`
+	UpdatedAt time.Time      `db:"updated_at" json:"-"`
+}
+
+func (nullableBook) TableName() string {
+	return "books"
+}
+
+func Test_Nullable_JSON(t *testing.T) {
+	r := require.New(t)
+
+	table := []struct {
+		value interface{}
+		json  string
+	}{
+		{NewNullableString("x"), `"x"`},
+		{NullableString{}, `null`},
+		{NewNullableInt(42), `42`},
+		{NullableInt{}, `null`},
+		{NewNullableFloat(1.5), `1.5`},
+		{NullableFloat{}, `null`},
+		{NewNullableBool(false), `false`},
+		{NullableBool{}, `null`},
+	}
+	for _, tt := range table {
+		b, err := json.Marshal(tt.value)
+		r.NoError(err)
+		r.Equal(tt.json, string(b))
+	}
+
+	s := NewNullableString("x")
+	r.NoError(json.Unmarshal([]byte(`null`), &s))
+	r.Equal(NullableString{}, s)
+	var i NullableInt
+	r.NoError(json.Unmarshal([]byte(`42`), &i))
+	r.Equal(NewNullableInt(42), i)
+	var f NullableFloat
+	r.NoError(json.Unmarshal([]byte(`1.5`), &f))
+	r.Equal(NewNullableFloat(1.5), f)
+	var b NullableBool
+	r.NoError(json.Unmarshal([]byte(`true`), &b))
+	r.Equal(NewNullableBool(true), b)
+	r.Error(json.Unmarshal([]byte(`"42"`), &i))
+}
+
+func Test_Nullable_Columns(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		b := &nullableBook{Title: "Nullable", Isbn: NewNullableString("N1")}
+		r.NoError(tx.Create(b))
+
+		found := &nullableBook{}
+		r.NoError(tx.Find(ctx, found, b.ID))
+		r.Equal(NewNullableString("N1"), found.Isbn)
+		r.False(found.UserID.Valid)
+
+		j, err := json.Marshal(found)
+		r.NoError(err)
+		r.JSONEq(fmt.Sprintf(`{"id":%d,"title":"Nullable","isbn":"N1","user_id":null}`, b.ID), string(j))
+	})
+}
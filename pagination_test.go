@@ -1,6 +1,7 @@
 package pop
 
 import (
+	"context"
 	"net/url"
 	"reflect"
 	"testing"
@@ -42,6 +43,76 @@ func Test_NewPaginatorFromParams(t *testing.T) {
 	a.Equal(p.PerPage, 30)
 }
 
+func Test_Paginator_Links(t *testing.T) {
+	r := require.New(t)
+
+	p := Paginator{Page: 3, PerPage: 20, TotalPages: 10}
+	r.Equal(map[string]string{
+		"self":  "https://api.example.com/users?page=3&per_page=20",
+		"first": "https://api.example.com/users?page=1&per_page=20",
+		"last":  "https://api.example.com/users?page=10&per_page=20",
+		"next":  "https://api.example.com/users?page=4&per_page=20",
+		"prev":  "https://api.example.com/users?page=2&per_page=20",
+	}, p.Links("https://api.example.com/users"))
+
+	p = Paginator{Page: 1, PerPage: 5, TotalPages: 1}
+	links := p.Links("/users?q=a%26b+c&page=7")
+	r.Equal(map[string]string{
+		"self":  "/users?page=1&per_page=5&q=a%26b+c",
+		"first": "/users?page=1&per_page=5&q=a%26b+c",
+		"last":  "/users?page=1&per_page=5&q=a%26b+c",
+	}, links)
+
+	r.Nil(p.Links("http://[::1"))
+}
+
+func Test_Query_Page_PerPage(t *testing.T) {
+	a := require.New(t)
+
+	q := PDB.Q().Page(3).PerPage(50)
+	a.Equal(NewPaginator(3, 50), q.Paginator)
+	a.Equal(q.Paginator, PDB.Paginate(3, 50).Paginator)
+
+	perPage := func(q *Query) *Query {
+		return q.PerPage(10)
+	}
+	q = PDB.Scope(perPage).Page(2)
+	a.Equal(10, q.Paginator.PerPage)
+	a.Equal(10, q.Paginator.Offset)
+
+	q = PDB.Page(2)
+	a.Equal(PaginatorPerPageDefault, q.Paginator.PerPage)
+	q = PDB.PerPage(5)
+	a.Equal(1, q.Paginator.Page)
+	a.Equal(0, q.Paginator.Offset)
+}
+
+func Test_PageRequest(t *testing.T) {
+	a := require.New(t)
+
+	a.NoError(PageRequest{Page: 1, PerPage: 100}.Validate())
+	a.Equal(ValidationError{Field: "page", Message: "must be at least 1, not 0"}, PageRequest{PerPage: 20}.Validate())
+	a.Equal(ValidationError{Field: "per_page", Message: "must be between 1 and 100, not 101"}, PageRequest{Page: 1, PerPage: 101}.Validate())
+	a.Error(PageRequest{Page: 1}.Validate())
+
+	q := PDB.PaginateRequest(PageRequest{Page: 2, PerPage: 15})
+	a.NoError(q.err)
+	a.Equal(NewPaginator(2, 15), q.Paginator)
+
+	// the query fails before any SQL is run
+	q = PDB.PaginateRequest(PageRequest{Page: -1, PerPage: 15})
+	a.Nil(q.Paginator)
+	a.Equal(ValidationError{Field: "page", Message: "must be at least 1, not -1"}, q.All(context.Background(), &Users{}))
+
+	c := PDB.WithMaxPerPage(500)
+	a.NoError(c.PaginateRequest(PageRequest{Page: 1, PerPage: 500}).err)
+	a.Error(c.PaginateRequest(PageRequest{Page: 1, PerPage: 501}).err)
+	tx, err := c.NewTransaction()
+	a.NoError(err)
+	defer tx.TX.Rollback()
+	a.NoError(tx.PaginateRequest(PageRequest{Page: 1, PerPage: 500}).err)
+}
+
 func Test_Pagination(t *testing.T) {
 	transaction(func(tx *Connection) {
 		a := require.New(t)
@@ -2,6 +2,8 @@ package pop
 
 import (
 	"encoding/json"
+	"fmt"
+	"net/url"
 	"strconv"
 
 	"github.com/markbates/going/defaults"
@@ -10,6 +12,11 @@ import (
 // PaginatorPerPageDefault is the amount of results per page
 var PaginatorPerPageDefault = 20
 
+// PaginatorMaxPerPageDefault is the maximum amount of results per page of
+// a PageRequest, unless the connection sets its own, see
+// Connection.WithMaxPerPage.
+var PaginatorMaxPerPageDefault = 100
+
 // PaginatorPageKey is the query parameter holding the current page index
 var PaginatorPageKey = "page"
 
@@ -50,6 +57,48 @@ func (p Paginator) String() string {
 	return p.Paginate()
 }
 
+// Links returns the links of the pagination, for the JSON:API or HAL
+// responses: the "self", "first" and "last" pages, and the "next" and
+// "prev" pages unless p is at the boundaries. The links are baseURL with
+// the PaginatorPageKey and PaginatorPerPageKey query parameters set; the
+// other parameters of baseURL are kept.
+//
+//	p.Links("https://api.example.com/users?sort=name")
+//	// "next": "https://api.example.com/users?page=4&per_page=20&sort=name"
+//
+// Links returns nil if baseURL can't be parsed.
+func (p Paginator) Links(baseURL string) map[string]string {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil
+	}
+	link := func(page int) string {
+		q := u.Query()
+		q.Set(PaginatorPageKey, strconv.Itoa(page))
+		q.Set(PaginatorPerPageKey, strconv.Itoa(p.PerPage))
+		l := *u
+		l.RawQuery = q.Encode()
+		return l.String()
+	}
+
+	last := p.TotalPages
+	if last < 1 {
+		last = 1
+	}
+	links := map[string]string{
+		"self":  link(p.Page),
+		"first": link(1),
+		"last":  link(last),
+	}
+	if p.Page < last {
+		links["next"] = link(p.Page + 1)
+	}
+	if p.Page > 1 {
+		links["prev"] = link(p.Page - 1)
+	}
+	return links
+}
+
 // NewPaginator returns a new `Paginator` value with the appropriate
 // defaults set.
 func NewPaginator(page int, perPage int) *Paginator {
@@ -110,6 +159,116 @@ func (q *Query) Paginate(page int, perPage int) *Query {
 	return q
 }
 
+// Page paginates records returned from the database, like Paginate, and
+// returns the given page. The number of records per page is set by
+// PerPage, and defaults to PaginatorPerPageDefault.
+//
+//	q := c.Page(2).PerPage(15)
+func (c *Connection) Page(page int) *Query {
+	return Q(c).Page(page)
+}
+
+// Page paginates records returned from the database, like Paginate, and
+// returns the given page. The number of records per page set by PerPage
+// is kept, e.g. by a scope:
+//
+//	q = q.Scope(defaultPagination).Page(page)
+func (q *Query) Page(page int) *Query {
+	perPage := PaginatorPerPageDefault
+	if q.Paginator != nil {
+		perPage = q.Paginator.PerPage
+	}
+	q.Paginator = NewPaginator(page, perPage)
+	return q
+}
+
+// PerPage paginates records returned from the database, like Paginate,
+// with perPage records per page. The page is set by Page, and defaults
+// to the first one.
+//
+//	q := c.PerPage(50).Page(3)
+func (c *Connection) PerPage(perPage int) *Query {
+	return Q(c).PerPage(perPage)
+}
+
+// PerPage paginates records returned from the database, like Paginate,
+// with perPage records per page. The page set by Page is kept.
+//
+//	q = q.Page(3).PerPage(50)
+func (q *Query) PerPage(perPage int) *Query {
+	page := 1
+	if q.Paginator != nil {
+		page = q.Paginator.Page
+	}
+	q.Paginator = NewPaginator(page, perPage)
+	return q
+}
+
+// PageRequest is a page requested by a client, e.g. decoded from the body
+// of a request, which is validated before it paginates a query, see
+// Query.PaginateRequest.
+type PageRequest struct {
+	Page    int `json:"page"`
+	PerPage int `json:"per_page"`
+}
+
+// Validate checks that the page is at least 1, and the results per page
+// between 1 and PaginatorMaxPerPageDefault. The problem is returned as a
+// ValidationError, whose field is PaginatorPageKey or PaginatorPerPageKey.
+func (r PageRequest) Validate() error {
+	return r.validate(PaginatorMaxPerPageDefault)
+}
+
+func (r PageRequest) validate(maxPerPage int) error {
+	if r.Page < 1 {
+		return ValidationError{Field: PaginatorPageKey, Message: fmt.Sprintf("must be at least 1, not %d", r.Page)}
+	}
+	if r.PerPage < 1 || r.PerPage > maxPerPage {
+		return ValidationError{Field: PaginatorPerPageKey, Message: fmt.Sprintf("must be between 1 and %d, not %d", maxPerPage, r.PerPage)}
+	}
+	return nil
+}
+
+// WithMaxPerPage returns a copy of the connection whose PageRequests have
+// at most max results per page, rather than PaginatorMaxPerPageDefault.
+// The transactions and copies of the returned connection keep it.
+func (c *Connection) WithMaxPerPage(max int) *Connection {
+	cn := c.copy()
+	cn.maxPerPage = max
+	return cn
+}
+
+// PaginateRequest paginates records returned from the database, like
+// Paginate, with the page of r once it's validated, see
+// Query.PaginateRequest.
+//
+//	q := c.PaginateRequest(req)
+func (c *Connection) PaginateRequest(r PageRequest) *Query {
+	return Q(c).PaginateRequest(r)
+}
+
+// PaginateRequest paginates records returned from the database, like
+// Paginate, with the page of r. Unlike Paginate, which corrects the
+// invalid pages, an invalid r fails the query with its ValidationError
+// when it's run, before any SQL is issued: the page must be at least 1,
+// and the results per page between 1 and the max of the connection.
+//
+//	err := c.PaginateRequest(pop.PageRequest{Page: 2, PerPage: 500}).All(ctx, &users)
+//	// per_page: must be between 1 and 100, not 500
+func (q *Query) PaginateRequest(r PageRequest) *Query {
+	max := PaginatorMaxPerPageDefault
+	if q.Connection != nil && q.Connection.maxPerPage > 0 {
+		max = q.Connection.maxPerPage
+	}
+	if err := r.validate(max); err != nil {
+		if q.err == nil {
+			q.err = err
+		}
+		return q
+	}
+	return q.Paginate(r.Page, r.PerPage)
+}
+
 // PaginateFromParams paginates records returned from the database.
 //
 //	q := c.PaginateFromParams(req.URL.Query())
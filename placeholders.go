@@ -0,0 +1,125 @@
+package pop
+
+import (
+	"strconv"
+	"strings"
+)
+
+// NormalizePlaceholders rewrites the placeholders of query in the style
+// of dialect: positional "?" markers become "$1", "$2", ... for PostgreSQL
+// and CockroachDB, and "$N" markers become "?" for the other dialects.
+// Markers in string literals, quoted identifiers and comments are left
+// untouched. "$N" markers are expected in the order of their arguments.
+//
+//	pop.NormalizePlaceholders("name = ? AND note <> '?'", "postgres")
+//	// name = $1 AND note <> '?'
+func NormalizePlaceholders(query string, dialect string) string {
+	d := normalizeSynonyms(dialect)
+	dollar := d == namePostgreSQL || d == nameCockroach
+
+	return replacePlaceholders(query, dialect, func(n int, marker string) string {
+		switch {
+		case !dollar:
+			return "?"
+		case marker == "?":
+			return "$" + strconv.Itoa(n+1)
+		}
+		return marker
+	})
+}
+
+// replacePlaceholders replaces the placeholders of query with the result
+// of replace, given the index of their argument and the marker found in
+// query. The string literals are parsed in the style of dialect.
+func replacePlaceholders(query string, dialect string, replace func(n int, marker string) string) string {
+	d := normalizeSynonyms(dialect)
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	for i := 0; i < len(query); {
+		ch := query[i]
+		j := i + 1
+		switch {
+		case ch == '\'':
+			// MySQL escapes quotes with backslashes, PostgreSQL only in E'' strings
+			backslash := d == nameMySQL || (i > 0 && (query[i-1] == 'E' || query[i-1] == 'e') && (i == 1 || !isIdentByte(query[i-2])))
+			j = skipQuoted(query, i, ch, backslash)
+		case ch == '"' || ch == '`':
+			j = skipQuoted(query, i, ch, false)
+		case strings.HasPrefix(query[i:], "--"):
+			j = strings.IndexByte(query[i:], '\n')
+			if j < 0 {
+				j = len(query)
+			} else {
+				j += i
+			}
+		case strings.HasPrefix(query[i:], "/*"):
+			j = strings.Index(query[i+2:], "*/")
+			if j < 0 {
+				j = len(query)
+			} else {
+				j += i + 4
+			}
+		case ch == '?':
+			b.WriteString(replace(n, "?"))
+			n++
+			i = j
+			continue
+		case ch == '$' && (i == 0 || !isIdentByte(query[i-1])):
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			if j > i+1 {
+				// $N placeholder
+				num, _ := strconv.Atoi(query[i+1 : j])
+				b.WriteString(replace(num-1, query[i:j]))
+				i = j
+				continue
+			}
+			// $tag$ dollar quoted string
+			for j < len(query) && query[j] != '$' && isIdentByte(query[j]) {
+				j++
+			}
+			if j < len(query) && query[j] == '$' {
+				tag := query[i : j+1]
+				end := strings.Index(query[j+1:], tag)
+				if end < 0 {
+					j = len(query)
+				} else {
+					j += 1 + end + len(tag)
+				}
+			} else {
+				j = i + 1
+			}
+		}
+		b.WriteString(query[i:j])
+		i = j
+	}
+	return b.String()
+}
+
+// skipQuoted returns the index following the quoted string starting at i.
+// The quote is escaped by doubling it, or with a backslash if backslash is true.
+func skipQuoted(s string, i int, quote byte, backslash bool) int {
+	for j := i + 1; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			if backslash {
+				j++
+			}
+		case quote:
+			if j+1 < len(s) && s[j+1] == quote {
+				j++
+				continue
+			}
+			return j + 1
+		}
+	}
+	return len(s)
+}
+
+func isIdentByte(c byte) bool {
+	return c == '_' || c == '$' || c >= 0x80 ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
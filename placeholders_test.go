@@ -0,0 +1,39 @@
+package pop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_NormalizePlaceholders(t *testing.T) {
+	table := []struct {
+		dialect string
+		in      string
+		out     string
+	}{
+		{"postgres", "select * from users where id = ? and name = ?", "select * from users where id = $1 and name = $2"},
+		{"cockroach", "id = ?", "id = $1"},
+		{"postgresql", "id in (?, ?)", "id in ($1, $2)"},
+		{"postgres", "note = 'what?' and id = ?", "note = 'what?' and id = $1"},
+		{"postgres", "note = 'it''s ?' and id = ?", "note = 'it''s ?' and id = $1"},
+		{"postgres", `note = E'it\'s ?' and id = ?`, `note = E'it\'s ?' and id = $1`},
+		{"postgres", `note = 'C:\' and id = ?`, `note = 'C:\' and id = $1`},
+		{"postgres", `"weird?" = ? -- really?` + "\nand b = ? /* ? */", `"weird?" = $1 -- really?` + "\nand b = $2 /* ? */"},
+		{"postgres", "body = $$ what? $$ and id = ?", "body = $$ what? $$ and id = $1"},
+		{"postgres", "body = $fn$ what? $fn$ and id = ?", "body = $fn$ what? $fn$ and id = $1"},
+		{"postgres", "id = $1 and name = $2", "id = $1 and name = $2"},
+		{"mysql", "id = $1 and name = $2", "id = ? and name = ?"},
+		{"mysql", `note = 'it\'s $1' and a$1 = $1`, `note = 'it\'s $1' and a$1 = ?`},
+		{"mysql", "`col$1` = $1", "`col$1` = ?"},
+		{"sqlite3", "id = $1 and note = '$2'", "id = ? and note = '$2'"},
+		{"sqlite3", "id = ?", "id = ?"},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.dialect+"/"+tt.in, func(st *testing.T) {
+			r := require.New(st)
+			r.Equal(tt.out, NormalizePlaceholders(tt.in, tt.dialect))
+		})
+	}
+}
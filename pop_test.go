@@ -1,6 +1,8 @@
 package pop
 
 import (
+	"context"
+	"fmt"
 	stdlog "log"
 	"os"
 	"testing"
@@ -11,6 +13,7 @@ import (
 	"github.com/gobuffalo/validate"
 	"github.com/gobuffalo/validate/validators"
 	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -161,6 +164,17 @@ type UsersAddress struct {
 	UpdatedAt time.Time `db:"updated_at"`
 }
 
+// Resident reads the addresses of a user through the UsersAddress model.
+type Resident struct {
+	ID        int          `db:"id"`
+	Name      nulls.String `db:"name"`
+	Addresses Addresses    `has_many:"addresses" through:"users_addresses" fk_id:"user_id" order_by:"street asc"`
+}
+
+func (Resident) TableName() string {
+	return "users"
+}
+
 type UsersAddressQuery struct {
 	ID        int       `db:"id"`
 	UserID    int       `db:"user_id"`
@@ -336,6 +350,76 @@ func (u *CallbacksUser) AfterFind(tx *Connection) error {
 	return nil
 }
 
+// ContextCallbacksUser records the context value of its callbacks.
+type ContextCallbacksUser struct {
+	ID        int       `db:"id"`
+	BeforeC   string    `db:"before_c"`
+	BeforeU   string    `db:"before_u"`
+	BeforeD   string    `db:"before_d"`
+	AfterC    string    `db:"after_c"`
+	AfterU    string    `db:"after_u"`
+	AfterD    string    `db:"after_d"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type callbackKey struct{}
+
+func (ContextCallbacksUser) TableName() string {
+	return "callbacks_users"
+}
+
+func (u *ContextCallbacksUser) BeforeCreate(ctx context.Context, tx *Connection) error {
+	u.BeforeC, _ = ctx.Value(callbackKey{}).(string)
+	return nil
+}
+
+func (u *ContextCallbacksUser) AfterCreate(ctx context.Context, tx *Connection) error {
+	u.AfterC = fmt.Sprintf("%v %d", ctx.Value(callbackKey{}), u.ID)
+	return nil
+}
+
+func (u *ContextCallbacksUser) BeforeUpdate(ctx context.Context, tx *Connection) error {
+	u.BeforeU, _ = ctx.Value(callbackKey{}).(string)
+	return nil
+}
+
+func (u *ContextCallbacksUser) AfterUpdate(ctx context.Context, tx *Connection) error {
+	u.AfterU, _ = ctx.Value(callbackKey{}).(string)
+	return nil
+}
+
+func (u *ContextCallbacksUser) BeforeDelete(ctx context.Context, tx *Connection) error {
+	u.BeforeD, _ = ctx.Value(callbackKey{}).(string)
+	return nil
+}
+
+func (u *ContextCallbacksUser) AfterDelete(ctx context.Context, tx *Connection) error {
+	u.AfterD, _ = ctx.Value(callbackKey{}).(string)
+	return nil
+}
+
+// SelfValidatingUser fails its validation without a name.
+type SelfValidatingUser struct {
+	ID        int          `db:"id"`
+	Name      nulls.String `db:"name"`
+	CreatedAt time.Time    `db:"created_at"`
+	UpdatedAt time.Time    `db:"updated_at"`
+}
+
+var errNameRequired = errors.New("name is required")
+
+func (SelfValidatingUser) TableName() string {
+	return "users"
+}
+
+func (u *SelfValidatingUser) Validate(ctx context.Context) error {
+	if u.Name.String == "" {
+		return errNameRequired
+	}
+	return nil
+}
+
 type Label struct {
 	ID string `db:"id"`
 }
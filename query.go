@@ -3,18 +3,26 @@ package pop
 import (
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/gobuffalo/pop/logging"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
 )
 
 // Query is the main value that is used to build up a query
 // to be executed against the `Connection`.
 type Query struct {
 	RawSQL                  *clause
-	limitResults            int
+	limitResults            int64
+	offsetResults           int64
 	addColumns              []string
+	returning               []string
 	eager                   bool
 	eagerFields             []string
+	eagerSelects            map[string][]string
+	eagerParallel           int
+	eagerLoad               []string
 	whereClauses            clauses
 	orderClauses            clauses
 	fromClauses             fromClauses
@@ -22,25 +30,68 @@ type Query struct {
 	joinClauses             joinClauses
 	groupClauses            groupClauses
 	havingClauses           havingClauses
+	hints                   queryHints
+	windowColumns           []windowColumn
+	latestPerGroup          *latestPerGroup
+	unions                  []union
+	ctes                    []cte
+	asOf                    time.Time
+	unscoped                bool
+	shards                  *ShardedConnection
+	comment                 string
 	Paginator               *Paginator
 	Connection              *Connection
+	// err is returned when the query is run
+	err error
+}
+
+// ErrRawQueryClause is returned when running a raw query whose clauses
+// were set with Where, Order, Join... after RawQuery: the raw SQL wins,
+// and the clauses can't be merged into it.
+var ErrRawQueryClause = errors.New("clauses can't be added to a raw query")
+
+// rawClause records the addition of a clause to a raw query, which fails
+// the query when it's run.
+func (q *Query) rawClause(name string) *Query {
+	q.Connection.log(logging.Warn, "Query is setup to use raw SQL, %s is ignored", name)
+	if q.err == nil {
+		q.err = errors.Wrap(ErrRawQueryClause, name)
+	}
+	return q
 }
 
 // Clone will fill targetQ query with the connection used in q, if
 // targetQ is not empty, Clone will override all the fields.
+//
+// The clauses are deep copied: modifying targetQ doesn't change q.
 func (q *Query) Clone(targetQ *Query) {
-	rawSQL := *q.RawSQL
-	targetQ.RawSQL = &rawSQL
+	if q.RawSQL != nil {
+		rawSQL := q.RawSQL.clone()
+		targetQ.RawSQL = &rawSQL
+	}
 
 	targetQ.limitResults = q.limitResults
-	targetQ.whereClauses = q.whereClauses
-	targetQ.orderClauses = q.orderClauses
-	targetQ.fromClauses = q.fromClauses
-	targetQ.belongsToThroughClauses = q.belongsToThroughClauses
-	targetQ.joinClauses = q.joinClauses
-	targetQ.groupClauses = q.groupClauses
-	targetQ.havingClauses = q.havingClauses
-	targetQ.addColumns = q.addColumns
+	targetQ.offsetResults = q.offsetResults
+	targetQ.whereClauses = q.whereClauses.clone()
+	targetQ.orderClauses = q.orderClauses.clone()
+	targetQ.fromClauses = append(fromClauses(nil), q.fromClauses...)
+	targetQ.belongsToThroughClauses = append(belongsToThroughClauses(nil), q.belongsToThroughClauses...)
+	targetQ.joinClauses = q.joinClauses.clone()
+	targetQ.groupClauses = append(groupClauses(nil), q.groupClauses...)
+	targetQ.havingClauses = q.havingClauses.clone()
+	targetQ.hints = q.hints.clone()
+	targetQ.windowColumns = append([]windowColumn(nil), q.windowColumns...)
+	targetQ.latestPerGroup = q.latestPerGroup
+	// the queries of the unions and ctes are never modified, they're shared
+	targetQ.unions = append([]union(nil), q.unions...)
+	targetQ.ctes = append([]cte(nil), q.ctes...)
+	targetQ.addColumns = append([]string(nil), q.addColumns...)
+	targetQ.returning = append([]string(nil), q.returning...)
+	targetQ.asOf = q.asOf
+	targetQ.unscoped = q.unscoped
+	targetQ.shards = q.shards
+	targetQ.comment = q.comment
+	targetQ.err = q.err
 
 	if q.Paginator != nil {
 		paginator := *q.Paginator
@@ -67,11 +118,31 @@ func (c *Connection) RawQuery(stmt string, args ...interface{}) *Query {
 // to use the `?` argument syntax.
 //
 //	q.RawQuery("select * from foo where id = ?", 1)
+//
+// The raw SQL takes precedence over the clauses already set on the query,
+// which are ignored. Setting clauses after RawQuery fails the query with
+// ErrRawQueryClause.
 func (q *Query) RawQuery(stmt string, args ...interface{}) *Query {
+	if len(q.whereClauses)+len(q.orderClauses)+len(q.joinClauses)+len(q.groupClauses)+len(q.havingClauses) > 0 {
+		q.Connection.log(logging.Warn, "RawQuery overrides the clauses of the query")
+	}
 	q.RawSQL = &clause{stmt, args}
 	return q
 }
 
+// In expands the slice arguments of query into a list of "?" bind vars,
+// for use in an IN clause. The returned query and args can be passed to
+// RawQuery or Where.
+//
+//	query, args, err := pop.In("select * from orders where id in (?)", ids)
+//	if err != nil {
+//		return err
+//	}
+//	err = c.RawQuery(query, args...).All(ctx, &orders)
+func In(query string, args ...interface{}) (string, []interface{}, error) {
+	return sqlx.In(query, args...)
+}
+
 // Eager will enable associations loading of the model.
 // by defaults loads all the associations on the model,
 // but can take a variadic list of associations to load.
@@ -91,22 +162,93 @@ func (c *Connection) Eager(fields ...string) *Connection {
 	return con
 }
 
+// DebugEager returns a copy of the connection logging each association
+// loaded by Eager or Load, at the logging.Eager level: its constraint and
+// the SQL run to load it. The spans of these queries are tagged with the
+// same. It's meant to find out why an association is loaded empty.
+//
+//	err := c.DebugEager().Eager("Books").Find(ctx, &user, id)
+func (c *Connection) DebugEager() *Connection {
+	cn := c.copy()
+	cn.debugEager = true
+	return cn
+}
+
 // Eager will enable load associations of the model.
 // by defaults loads all the associations on the model,
 // but can take a variadic list of associations to load.
 //
 // 	q.Eager().Find(model, 1) // will load all associations for model.
 // 	q.Eager("Books").Find(model, 1) // will load only Book association for model.
+//
+// The columns loaded for an association can be listed after its name, as
+// with EagerSelect:
+//
+// 	q.Eager("Books[id,title].Writers[id,name]").Find(model, 1)
 func (q *Query) Eager(fields ...string) *Query {
 	q.eager = true
 	q.eagerFields = append(q.eagerFields, fields...)
 	return q
 }
 
+// EagerSelect eager loads the association like Eager, loading only the
+// given columns of the associated models. A nested association is named
+// with its path, e.g. "Books.Writers". The id column, and the columns
+// matching the associated models with their owner, such as the user_id
+// column of the books of a user, are always loaded.
+//
+// The columns which aren't loaded keep their zero value, including in the
+// AfterFind callback of the associated models.
+//
+// 	q.EagerSelect("Books", "id", "title").Find(ctx, &user, id)
+func (q *Query) EagerSelect(association string, columns ...string) *Query {
+	q.Eager(association)
+	if q.eagerSelects == nil {
+		q.eagerSelects = map[string][]string{}
+	}
+	q.eagerSelects[association] = append(q.eagerSelects[association], columns...)
+	return q
+}
+
+// EagerParallel loads the top-level associations of Eager concurrently, at
+// most n at a time, each with the nested associations it names, in turn.
+// Each association is loaded into its own field, on its own connection of
+// the pool, under a "pop/eager" span. The first error cancels the loads
+// which didn't start. It has no effect on a transaction, whose connection
+// can't run concurrent queries, nor when n is lower than 2.
+//
+// 	q.Eager("Books", "Houses", "FavoriteSong").EagerParallel(3).Find(ctx, &user, id)
+func (q *Query) EagerParallel(n int) *Query {
+	q.eagerParallel = n
+	return q
+}
+
+// EagerLoad restricts the associations loaded by Eager to the given
+// fields: the other associations are skipped, even when Eager loads all
+// the associations, e.g. for an eager connection given to a function
+// which only needs some of them. It doesn't enable the eager loading by
+// itself. The nested associations of a field are loaded as listed by
+// Eager.
+//
+// 	c.Eager().EagerLoad("Books").Find(ctx, model, 1) // will load only the Book association for model.
+// 	q.Eager("Books.Writers", "Houses").EagerLoad("Books").All(ctx, &models) // will load Books and their Writers.
+func (q *Query) EagerLoad(fields ...string) *Query {
+	q.eagerLoad = append(append([]string{}, q.eagerLoad...), fields...)
+	return q
+}
+
+// EagerLoad restricts the associations loaded by Eager to the given fields,
+// see Query.EagerLoad.
+func (c *Connection) EagerLoad(fields ...string) *Query {
+	return Q(c).EagerLoad(fields...)
+}
+
 // disableEager disables eager mode for current query and Connection.
 func (q *Query) disableEager() {
 	q.Connection.eager, q.eager = false, false
 	q.Connection.eagerFields, q.eagerFields = []string{}, []string{}
+	q.eagerSelects = nil
+	q.eagerLoad = nil
 }
 
 // Where will append a where clause to the query. You may use `?` in place of
@@ -126,8 +268,7 @@ func (c *Connection) Where(stmt string, args ...interface{}) *Query {
 // 	q.Where("id in (?)", 1, 2, 3)
 func (q *Query) Where(stmt string, args ...interface{}) *Query {
 	if q.RawSQL.Fragment != "" {
-		log(logging.Warn, "Query is setup to use raw SQL")
-		return q
+		return q.rawClause("Where")
 	}
 	if inRegex.MatchString(stmt) {
 		var inq []string
@@ -153,8 +294,7 @@ func (c *Connection) Order(stmt string) *Query {
 // 	q.Order("name desc")
 func (q *Query) Order(stmt string) *Query {
 	if q.RawSQL.Fragment != "" {
-		log(logging.Warn, "Query is setup to use raw SQL")
-		return q
+		return q.rawClause("Order")
 	}
 	q.orderClauses = append(q.orderClauses, clause{stmt, []interface{}{}})
 	return q
@@ -167,14 +307,78 @@ func (c *Connection) Limit(limit int) *Query {
 	return Q(c).Limit(limit)
 }
 
+// LimitInt64 will create a query and add a limit clause to it.
+//
+//	c.LimitInt64(10)
+func (c *Connection) LimitInt64(limit int64) *Query {
+	return Q(c).LimitInt64(limit)
+}
+
 // Limit will add a limit clause to the query.
 //
 // 	q.Limit(10)
 func (q *Query) Limit(limit int) *Query {
+	return q.LimitInt64(int64(limit))
+}
+
+// LimitInt64 will add a limit clause to the query.
+//
+//	q.LimitInt64(10)
+func (q *Query) LimitInt64(limit int64) *Query {
 	q.limitResults = limit
 	return q
 }
 
+// Offset will create a query and add an offset clause to it.
+//
+//	c.Offset(20)
+func (c *Connection) Offset(offset int) *Query {
+	return Q(c).Offset(offset)
+}
+
+// OffsetInt64 will create a query and add an offset clause to it.
+//
+//	c.OffsetInt64(20)
+func (c *Connection) OffsetInt64(offset int64) *Query {
+	return Q(c).OffsetInt64(offset)
+}
+
+// Offset will add an offset clause to the query. It's ignored
+// when the query is paginated.
+//
+//	q.Limit(10).Offset(20)
+func (q *Query) Offset(offset int) *Query {
+	return q.OffsetInt64(int64(offset))
+}
+
+// OffsetInt64 will add an offset clause to the query. It's ignored
+// when the query is paginated.
+//
+//	q.LimitInt64(10).OffsetInt64(20)
+func (q *Query) OffsetInt64(offset int64) *Query {
+	q.offsetResults = offset
+	return q
+}
+
+// Returning will create a query reading back the given columns
+// after a Create.
+//
+//	c.Returning("created_at", "sequence_num").Create(&order)
+func (c *Connection) Returning(columns ...string) *Query {
+	return Q(c).Returning(columns...)
+}
+
+// Returning sets the columns read back into the model after a Create,
+// such as the columns computed by the database on insert. They're
+// returned by the INSERT on PostgreSQL and CockroachDB, and read with
+// a follow-up SELECT on the other dialects.
+//
+//	q.Returning("created_at", "sequence_num").Create(&order)
+func (q *Query) Returning(columns ...string) *Query {
+	q.returning = append(q.returning, columns...)
+	return q
+}
+
 // Q will create a new "empty" query from the current connection.
 func Q(c *Connection) *Query {
 	return &Query{
@@ -186,10 +390,23 @@ func Q(c *Connection) *Query {
 }
 
 // ToSQL will generate SQL and the appropriate arguments for that SQL
-// from the `Model` passed in.
-func (q Query) ToSQL(model *Model, addColumns ...string) (string, []interface{}) {
-	sb := q.toSQLBuilder(model, addColumns...)
-	return sb.String(), sb.Args()
+// from the `Model` passed in. It returns the error of the query, or the
+// error building the SQL, e.g. when the model isn't a struct.
+func (q Query) ToSQL(model *Model, addColumns ...string) (string, []interface{}, error) {
+	if q.err != nil {
+		return "", nil, q.err
+	}
+	return q.toSQLBuilder(model, addColumns...).toSQL()
+}
+
+// ToSQLString returns the SQL of ToSQL, without its args. It panics when
+// the SQL can't be built, for the callers asserting the query is valid.
+func (q Query) ToSQLString(model *Model, addColumns ...string) string {
+	sql, _, err := q.ToSQL(model, addColumns...)
+	if err != nil {
+		panic(err)
+	}
+	return sql
 }
 
 // ToSQLBuilder returns a new `SQLBuilder` that can be used to generate SQL,
@@ -198,5 +415,13 @@ func (q Query) toSQLBuilder(model *Model, addColumns ...string) *sqlBuilder {
 	if len(q.addColumns) != 0 {
 		addColumns = q.addColumns
 	}
+	if model != nil && q.Connection != nil {
+		if model.schema == "" {
+			model.schema = q.Connection.schema()
+		}
+		if model.ctx == nil {
+			model.ctx = q.Connection.txContext()
+		}
+	}
 	return newSQLBuilder(q, model, addColumns...)
 }
@@ -0,0 +1,57 @@
+package pop
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// AsOf reads the rows as they were at the time t, with the AS OF SYSTEM
+// TIME clause of CockroachDB. The historical reads are served by the
+// nearest replica, without conflicting with the writes, when t is old
+// enough for the follower reads:
+//
+//	q.AsOf(time.Now().Add(-5 * time.Second)).All(ctx, &products)
+//
+// The query fails on the other dialects. The clause is moved to the
+// statements of Count and Exists, CockroachDB only accepting it on the
+// top-level select. It can't be used in a transaction.
+func (q *Query) AsOf(t time.Time) *Query {
+	if q.RawSQL.Fragment != "" {
+		return q.rawClause("AsOf")
+	}
+	if name := q.Connection.Dialect.Name(); name != nameCockroach {
+		if q.err == nil {
+			q.err = errors.Errorf("%s doesn't support AS OF SYSTEM TIME", name)
+		}
+		return q
+	}
+	q.asOf = t
+	return q
+}
+
+// asOfClause returns the AS OF SYSTEM TIME clause of the query, if any.
+func (q *Query) asOfClause() string {
+	if q.asOf.IsZero() {
+		return ""
+	}
+	return fmt.Sprintf(" AS OF SYSTEM TIME '%s'", q.asOf.UTC().Format("2006-01-02 15:04:05.999999"))
+}
+
+// buildAsOfClause adds the AS OF SYSTEM TIME clause after the from
+// clauses of sql.
+func (sq *sqlBuilder) buildAsOfClause(sql string) string {
+	return sql + sq.Query.asOfClause()
+}
+
+// hoistAsOf returns the AS OF SYSTEM TIME clause of the query, and the
+// query without it, to be set on the statement wrapping the query.
+func (sq *sqlBuilder) hoistAsOf(query string) (string, string) {
+	clause := sq.Query.asOfClause()
+	if clause == "" {
+		return "", query
+	}
+	return clause, strings.Replace(query, clause, "", 1)
+}
@@ -0,0 +1,49 @@
+package pop
+
+import "strings"
+
+// WithComment prepends the SQL comment /* comment */ to the statements of
+// the query, for the DBAs to find where they come from, e.g. in the
+// pg_stat_activity view of PostgreSQL or the PROCESSLIST of MySQL:
+//
+//	q.WithComment("billing_service:charge_user").Where("id = ?", id).First(ctx, &user)
+//	// /* billing_service:charge_user */ SELECT ... FROM users AS users WHERE id = $1 LIMIT 1
+//
+// The "/*" and "*/" sequences of comment are stripped, so it can't end the
+// comment. A leading /*+ */ hint still starts the statement, as
+// pg_hint_plan only reads the first comment. The comment is kept in the
+// queries of Count and Exists, and the last one set wins.
+func (q *Query) WithComment(comment string) *Query {
+	q.comment = sanitizeComment(comment)
+	return q
+}
+
+// sanitizeComment strips the sequences opening and closing a comment from
+// comment, the stripping being repeated for the sequences it forms, e.g.
+// "*/" for "**//".
+func sanitizeComment(comment string) string {
+	for strings.Contains(comment, "*/") || strings.Contains(comment, "/*") {
+		comment = strings.Replace(comment, "*/", "", -1)
+		comment = strings.Replace(comment, "/*", "", -1)
+	}
+	return strings.TrimSpace(comment)
+}
+
+// renderedComment returns the comment of the query, as prepended to its
+// statements, or "" if it has none.
+func (q Query) renderedComment() string {
+	if q.comment == "" {
+		return ""
+	}
+	return "/* " + q.comment + " */ "
+}
+
+// leadingComment splits the comment of the built query, if any, from the
+// statement. It's moved before the statements wrapping query.
+func (sq *sqlBuilder) leadingComment(query string) (string, string) {
+	c := sq.Query.renderedComment()
+	if c == "" || !strings.HasPrefix(query, c) {
+		return "", query
+	}
+	return c, query[len(c):]
+}
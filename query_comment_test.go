@@ -0,0 +1,45 @@
+package pop
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Query_WithComment(t *testing.T) {
+	r := require.New(t)
+
+	postgres, err := NewConnection(&ConnectionDetails{Dialect: "postgres", Host: "db.local", Database: "pop_test"})
+	r.NoError(err)
+	m := &Model{Value: &Book{}}
+
+	q := postgres.Where("title = ?", "Pop?").WithComment("billing_service:charge_user?")
+	r.Regexp(`^/\* billing_service:charge_user\? \*/ SELECT books\.created_at, .* FROM books AS books WHERE title = \$1$`, q.ToSQLString(m))
+
+	// the hint still starts the statement
+	q = postgres.Q().Hint("SeqScan(books)").WithComment("reports")
+	r.Regexp(`^/\*\+ SeqScan\(books\) \*/ /\* reports \*/ SELECT `, q.ToSQLString(m))
+
+	q = postgres.RawQuery("SELECT * FROM books").WithComment("*/ DROP TABLE books; /*")
+	r.Equal("/* DROP TABLE books; */ SELECT * FROM books", q.ToSQLString(m))
+	r.Equal("x", sanitizeComment(" x**// "))
+	r.Equal("SELECT * FROM books", postgres.RawQuery("SELECT * FROM books").WithComment("*/").ToSQLString(m))
+
+	// the comment starts the statements of Count and Exists too
+	var sqls []string
+	c := PDB.copy()
+	c.Observe(func(ctx context.Context, info QueryInfo) {
+		sqls = append(sqls, info.SQL)
+	})
+	q = c.Where("title = ?", "Pop").WithComment("counter")
+	_, err = q.Count(&Book{})
+	r.NoError(err)
+	_, err = q.Exists(&Book{})
+	r.NoError(err)
+	r.NoError(q.All(context.Background(), &Books{}))
+	r.Len(sqls, 3)
+	for _, sql := range sqls {
+		r.Regexp(`^/\* counter \*/ SELECT `, sql)
+	}
+}
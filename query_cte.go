@@ -0,0 +1,41 @@
+package pop
+
+// cte is a common table expression of a query, see Query.With.
+type cte struct {
+	name      string
+	query     *Query
+	recursive bool
+}
+
+// With adds the common table expression name, selecting the rows of sub,
+// to the WITH clause of the query. The query refers to it by name, in its
+// Where, Join or RawQuery fragments:
+//
+//	recent := c.RawQuery("SELECT customer_id FROM orders WHERE created_at > ?", since)
+//	err := c.Q().With("recent_orders", recent).Join("recent_orders", "recent_orders.customer_id = customers.id").All(ctx, &customers)
+//	// => WITH recent_orders AS (SELECT customer_id FROM orders WHERE created_at > $1) SELECT ... FROM customers AS customers JOIN recent_orders ON ...
+//
+// sub is a copy of the query: it can be modified without changing the
+// query. Unless it's a RawQuery, it selects the columns of the model the
+// query is run with. The expressions are added in the order of the With
+// calls, and their args come before the args of the query.
+func (q *Query) With(name string, sub *Query) *Query {
+	return q.with(name, sub, false)
+}
+
+// WithRecursive adds the recursive common table expression name, selecting
+// the rows of sub, like With. sub refers to name, e.g. in a RawQuery:
+//
+//	tree := c.RawQuery("SELECT id, parent_id FROM categories WHERE id = ? UNION ALL SELECT c.id, c.parent_id FROM categories c JOIN tree ON c.parent_id = tree.id", rootID)
+//	err := c.Q().WithRecursive("tree", tree).Where("id IN (SELECT id FROM tree)").All(ctx, &categories)
+func (q *Query) WithRecursive(name string, sub *Query) *Query {
+	return q.with(name, sub, true)
+}
+
+func (q *Query) with(name string, sub *Query, recursive bool) *Query {
+	q.ctes = append(q.ctes, cte{name: name, query: cloneQuery(sub), recursive: recursive})
+	if q.err == nil {
+		q.err = sub.err
+	}
+	return q
+}
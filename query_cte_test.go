@@ -0,0 +1,76 @@
+package pop
+
+import (
+	"testing"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Query_With_SQL(t *testing.T) {
+	r := require.New(t)
+	m := &Model{Value: &latestBook{}}
+
+	postgres, err := NewConnection(&ConnectionDetails{Dialect: "postgres", Host: "db.local", Database: "pop_test"})
+	r.NoError(err)
+	recent := postgres.Where("id > ?", 10)
+	authors := postgres.RawQuery("SELECT id FROM users WHERE name = ?", "Mark")
+	q := postgres.Q().With("recent_books", recent).With("authors", authors).
+		Where("books.id IN (SELECT id FROM recent_books)").
+		Where("user_id IN (SELECT id FROM authors) AND title <> ?", "Draft")
+	sql, args, err := q.ToSQL(m)
+	r.NoError(err)
+	r.Equal("WITH recent_books AS (SELECT books.id, books.title, books.user_id FROM books AS books WHERE id > $1), authors AS (SELECT id FROM users WHERE name = $2) SELECT books.id, books.title, books.user_id FROM books AS books WHERE books.id IN (SELECT id FROM recent_books) AND user_id IN (SELECT id FROM authors) AND title <> $3", sql)
+	r.Equal([]interface{}{10, "Mark", "Draft"}, args)
+
+	// the sub-queries are copies
+	recent.Where("title = ?", "Other")
+	sql = q.ToSQLString(m)
+	r.NotContains(sql, "Other")
+
+	tree := postgres.RawQuery("SELECT id FROM users WHERE id = ?", 1)
+	sql, args, err = postgres.RawQuery("SELECT * FROM books WHERE user_id IN (SELECT id FROM tree) AND title = ?", "A").WithRecursive("tree", tree).ToSQL(m)
+	r.NoError(err)
+	r.Equal("WITH RECURSIVE tree AS (SELECT id FROM users WHERE id = $1) SELECT * FROM books WHERE user_id IN (SELECT id FROM tree) AND title = $2", sql)
+	r.Equal([]interface{}{1, "A"}, args)
+
+	// the leading hint starts the statement
+	sql = postgres.Q().With("authors", authors).Hint("SeqScan(books)").ToSQLString(m)
+	r.Regexp(`^/\*\+ SeqScan\(books\) \*/ WITH authors AS \(`, sql)
+}
+
+func Test_Query_With(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		user := User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(&user))
+		for _, b := range []Book{
+			{Title: "Mine", Isbn: "W1", UserID: nulls.NewInt(user.ID)},
+			{Title: "Also mine", Isbn: "W2", UserID: nulls.NewInt(user.ID)},
+			{Title: "Other", Isbn: "W3"},
+		} {
+			r.NoError(tx.Create(&b))
+		}
+
+		authors := tx.RawQuery("SELECT id FROM users WHERE name = ?", "Mark")
+		q := tx.Q().With("authors", authors).Where("user_id IN (SELECT id FROM authors) AND title <> ?", "Also mine")
+		books := []latestBook{}
+		r.NoError(q.All(ctx, &books))
+		r.Len(books, 1)
+		r.Equal("Mine", books[0].Title)
+
+		count, err := q.Count(&latestBook{})
+		r.NoError(err)
+		r.Equal(1, count)
+
+		exists, err := q.Exists(&latestBook{})
+		r.NoError(err)
+		r.True(exists)
+
+		books = []latestBook{}
+		r.NoError(q.Paginate(1, 10).All(ctx, &books))
+		r.Len(books, 1)
+	})
+}
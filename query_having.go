@@ -1,12 +1,9 @@
 package pop
 
-import "github.com/gobuffalo/pop/logging"
-
 // Having will append a HAVING clause to the query
 func (q *Query) Having(condition string, args ...interface{}) *Query {
 	if q.RawSQL.Fragment != "" {
-		log(logging.Warn, "Query is setup to use raw SQL")
-		return q
+		return q.rawClause("Having")
 	}
 	q.havingClauses = append(q.havingClauses, HavingClause{condition, args})
 
@@ -0,0 +1,42 @@
+package pop
+
+// Hint adds an optimizer hint to the selects of the query, in the syntax
+// of the dialect. It's rendered in a /*+ */ comment right after SELECT on
+// MySQL, and in a /*+ */ comment before the query on PostgreSQL, read by
+// the pg_hint_plan extension. The hints are ignored, with a debug log, on
+// the other dialects.
+//
+//	q.Hint("MAX_EXECUTION_TIME(1000)").All(ctx, &orders) // MySQL
+//	q.Hint("SeqScan(orders)").All(ctx, &orders)          // PostgreSQL
+//
+// The hints are kept in the queries of Count and Exists.
+func (q *Query) Hint(hint string) *Query {
+	if q.RawSQL.Fragment != "" {
+		return q.rawClause("Hint")
+	}
+	q.hints.hints = append(q.hints.hints, hint)
+	return q
+}
+
+// UseIndex hints the database to use one of the given indexes to select
+// the rows of the model table. It's rendered as USE INDEX after the table
+// on MySQL, and as an IndexScan hint of pg_hint_plan on PostgreSQL.
+//
+//	q.UseIndex("idx_orders_user_created").Where("user_id = ?", id).All(ctx, &orders)
+func (q *Query) UseIndex(indexes ...string) *Query {
+	if q.RawSQL.Fragment != "" {
+		return q.rawClause("UseIndex")
+	}
+	q.hints.useIndexes = append(q.hints.useIndexes, indexes...)
+	return q
+}
+
+// ForceIndex is like UseIndex, but rendered as FORCE INDEX on MySQL: a
+// table scan is only used if none of the indexes can be used.
+func (q *Query) ForceIndex(indexes ...string) *Query {
+	if q.RawSQL.Fragment != "" {
+		return q.rawClause("ForceIndex")
+	}
+	q.hints.forceIndexes = append(q.hints.forceIndexes, indexes...)
+	return q
+}
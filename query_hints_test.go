@@ -0,0 +1,46 @@
+package pop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Query_Hints(t *testing.T) {
+	r := require.New(t)
+
+	mysql, err := NewConnection(&ConnectionDetails{Dialect: "mysql", Host: "db.local", Database: "pop_test"})
+	r.NoError(err)
+	postgres, err := NewConnection(&ConnectionDetails{Dialect: "postgres", Host: "db.local", Database: "pop_test"})
+	r.NoError(err)
+	m := &Model{Value: &Book{}}
+
+	q := mysql.Where("user_id = ?", 1).UseIndex("idx_books_user").ForceIndex("idx_books_isbn").Hint("MAX_EXECUTION_TIME(1000)")
+	sql := q.ToSQLString(m)
+	r.Regexp(`^SELECT /\*\+ MAX_EXECUTION_TIME\(1000\) \*/ books\.created_at, .* FROM books AS books USE INDEX \(idx_books_user\) FORCE INDEX \(idx_books_isbn\) WHERE user_id = \?$`, sql)
+
+	q = postgres.Where("user_id = ?", 1).UseIndex("idx_books_user", "idx_books_isbn").Hint("Leading(books)")
+	sql = q.ToSQLString(m)
+	r.Regexp(`^/\*\+ Leading\(books\) IndexScan\(books idx_books_user idx_books_isbn\) \*/ SELECT books\.created_at, .* FROM books AS books WHERE user_id = \$1$`, sql)
+
+	// the hints are kept by the clones used by Count and Exists
+	c := Q(postgres)
+	q.Clone(c)
+	sql = c.ToSQLString(&Model{Value: &Book{}, As: "b"})
+	r.Contains(sql, "IndexScan(b idx_books_user idx_books_isbn)")
+
+	q = PDB.RawQuery("select * from books").UseIndex("idx_books_user")
+	r.Error(q.err)
+}
+
+func Test_leadingHint(t *testing.T) {
+	r := require.New(t)
+
+	hint, query := leadingHint("/*+ SeqScan(books) */ SELECT * FROM books")
+	r.Equal("/*+ SeqScan(books) */ ", hint)
+	r.Equal("SELECT * FROM books", query)
+
+	hint, query = leadingHint("SELECT /*+ BKA(books) */ * FROM books")
+	r.Empty(hint)
+	r.Equal("SELECT /*+ BKA(books) */ * FROM books", query)
+}
@@ -1,15 +1,11 @@
 package pop
 
-import (
-	"github.com/gobuffalo/pop/logging"
-	"github.com/markbates/oncer"
-)
+import "github.com/markbates/oncer"
 
 // Join will append a JOIN clause to the query
 func (q *Query) Join(table string, on string, args ...interface{}) *Query {
 	if q.RawSQL.Fragment != "" {
-		log(logging.Warn, "Query is setup to use raw SQL")
-		return q
+		return q.rawClause("Join")
 	}
 	q.joinClauses = append(q.joinClauses, joinClause{"JOIN", table, on, args})
 	return q
@@ -18,8 +14,7 @@ func (q *Query) Join(table string, on string, args ...interface{}) *Query {
 // LeftJoin will append a LEFT JOIN clause to the query
 func (q *Query) LeftJoin(table string, on string, args ...interface{}) *Query {
 	if q.RawSQL.Fragment != "" {
-		log(logging.Warn, "Query is setup to use raw SQL")
-		return q
+		return q.rawClause("LeftJoin")
 	}
 	q.joinClauses = append(q.joinClauses, joinClause{"LEFT JOIN", table, on, args})
 	return q
@@ -28,8 +23,7 @@ func (q *Query) LeftJoin(table string, on string, args ...interface{}) *Query {
 // RightJoin will append a RIGHT JOIN clause to the query
 func (q *Query) RightJoin(table string, on string, args ...interface{}) *Query {
 	if q.RawSQL.Fragment != "" {
-		log(logging.Warn, "Query is setup to use raw SQL")
-		return q
+		return q.rawClause("RightJoin")
 	}
 	q.joinClauses = append(q.joinClauses, joinClause{"RIGHT JOIN", table, on, args})
 	return q
@@ -38,8 +32,7 @@ func (q *Query) RightJoin(table string, on string, args ...interface{}) *Query {
 // LeftOuterJoin will append a LEFT OUTER JOIN clause to the query
 func (q *Query) LeftOuterJoin(table string, on string, args ...interface{}) *Query {
 	if q.RawSQL.Fragment != "" {
-		log(logging.Warn, "Query is setup to use raw SQL")
-		return q
+		return q.rawClause("LeftOuterJoin")
 	}
 	q.joinClauses = append(q.joinClauses, joinClause{"LEFT OUTER JOIN", table, on, args})
 	return q
@@ -48,8 +41,7 @@ func (q *Query) LeftOuterJoin(table string, on string, args ...interface{}) *Que
 // RightOuterJoin will append a RIGHT OUTER JOIN clause to the query
 func (q *Query) RightOuterJoin(table string, on string, args ...interface{}) *Query {
 	if q.RawSQL.Fragment != "" {
-		log(logging.Warn, "Query is setup to use raw SQL")
-		return q
+		return q.rawClause("RightOuterJoin")
 	}
 	q.joinClauses = append(q.joinClauses, joinClause{"RIGHT OUTER JOIN", table, on, args})
 	return q
@@ -74,8 +66,7 @@ func (q *Query) RightInnerJoin(table string, on string, args ...interface{}) *Qu
 // InnerJoin will append an INNER JOIN clause to the query
 func (q *Query) InnerJoin(table string, on string, args ...interface{}) *Query {
 	if q.RawSQL.Fragment != "" {
-		log(logging.Warn, "Query is setup to use raw SQL")
-		return q
+		return q.rawClause("InnerJoin")
 	}
 	q.joinClauses = append(q.joinClauses, joinClause{"INNER JOIN", table, on, args})
 	return q
@@ -0,0 +1,103 @@
+package pop
+
+import (
+	"fmt"
+	"strings"
+)
+
+// WhereILike keeps the rows whose column matches the LIKE pattern, ignoring
+// the case: with ILIKE on PostgreSQL and CockroachDB, and by comparing
+// the lower case column and pattern on the other databases.
+//
+//	q.WhereILike("email", "%@example.com")
+func (q *Query) WhereILike(column string, pattern string) *Query {
+	switch q.Connection.Dialect.Name() {
+	case namePostgreSQL, nameCockroach:
+		return q.Where(fmt.Sprintf("%s ILIKE ?", column), pattern)
+	}
+	return q.Where(fmt.Sprintf("LOWER(%s) LIKE LOWER(?)", column), pattern)
+}
+
+// WhereStartsWith keeps the rows whose column starts with prefix. The %
+// and _ wildcards of prefix are escaped: it's matched as is. The case is
+// ignored or not depending on the database, e.g. LIKE ignores the case on
+// MySQL, with the default collations, and SQLite.
+//
+//	q.WhereStartsWith("name", params.Get("q"))
+func (q *Query) WhereStartsWith(column string, prefix string) *Query {
+	return q.whereLike(column, escapeLike(prefix)+"%")
+}
+
+// WhereEndsWith keeps the rows whose column ends with suffix, escaped like
+// the prefix of WhereStartsWith.
+func (q *Query) WhereEndsWith(column string, suffix string) *Query {
+	return q.whereLike(column, "%"+escapeLike(suffix))
+}
+
+// WhereContains keeps the rows whose column contains s, escaped like the
+// prefix of WhereStartsWith.
+func (q *Query) WhereContains(column string, s string) *Query {
+	return q.whereLike(column, "%"+escapeLike(s)+"%")
+}
+
+func (q *Query) whereLike(column string, pattern string) *Query {
+	return q.Where(fmt.Sprintf("%s LIKE ?%s", column, likeEscape(q.Connection.Dialect)), pattern)
+}
+
+// likeEscape returns the ESCAPE clause of the LIKE patterns escaped by
+// escapeLike, for the databases having no default escape character.
+func likeEscape(d dialect) string {
+	if d.Name() == nameSQLite3 {
+		return ` ESCAPE '\'`
+	}
+	// the backslash is the default escape character
+	return ""
+}
+
+// escapeLike escapes the wildcards of a LIKE pattern, with a backslash.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
+
+// WhereEqualFold keeps the rows whose column equals value, ignoring the
+// case, in the form the indexes of the database can serve:
+//
+//   - PostgreSQL and CockroachDB: LOWER(column) = LOWER(value), for an
+//     index on LOWER(column). A citext column is compared as is, when it's
+//     qualified with its table and the table was read with TableInfo.
+//   - MySQL: column = value, the default collations ignoring the case.
+//   - SQLite: column = value COLLATE NOCASE.
+//
+// For example:
+//
+//	q.WhereEqualFold("users.email", email)
+func (q *Query) WhereEqualFold(column string, value string) *Query {
+	switch q.Connection.Dialect.Name() {
+	case nameMySQL:
+		return q.Where(fmt.Sprintf("%s = ?", column), value)
+	case nameSQLite3:
+		return q.Where(fmt.Sprintf("%s = ? COLLATE NOCASE", column), value)
+	}
+	if q.columnType(column) == "citext" {
+		return q.Where(fmt.Sprintf("%s = ?", column), value)
+	}
+	return q.Where(fmt.Sprintf("LOWER(%s) = LOWER(?)", column), value)
+}
+
+// columnType returns the type of the table qualified column, when its
+// table is in the schema cache of the connection.
+func (q *Query) columnType(column string) string {
+	i := strings.LastIndex(column, ".")
+	sc := q.Connection.schemaCache
+	if i < 0 || sc == nil {
+		return ""
+	}
+	sc.mu.RLock()
+	ti, ok := sc.tables[column[:i]]
+	sc.mu.RUnlock()
+	if !ok {
+		return ""
+	}
+	c, _ := ti.Column(column[i+1:])
+	return c.Type
+}
@@ -0,0 +1,65 @@
+package pop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Query_Like_SQL(t *testing.T) {
+	r := require.New(t)
+	m := &Model{Value: &latestBook{}}
+	sel := "SELECT books.id, books.title, books.user_id FROM books AS books WHERE "
+
+	postgres, err := NewConnection(&ConnectionDetails{Dialect: "postgres", Host: "db.local", Database: "pop_test"})
+	r.NoError(err)
+	sql, args, err := postgres.Q().WhereILike("title", "go%").WhereStartsWith("title", "100%_").WhereEqualFold("books.title", "Go").ToSQL(m)
+	r.NoError(err)
+	r.Equal(sel+"title ILIKE $1 AND title LIKE $2 AND LOWER(books.title) = LOWER($3)", sql)
+	r.Equal([]interface{}{"go%", `100\%\_%`, "Go"}, args)
+
+	// a citext column of a table read with TableInfo is compared as is
+	postgres.schemaCache.tables["books"] = &TableInfo{Name: "books", Columns: []ColumnInfo{{Name: "title", Type: "citext"}}}
+	sql = postgres.Q().WhereEqualFold("books.title", "Go").WhereEqualFold("title", "Go").ToSQLString(m)
+	r.Equal(sel+"books.title = $1 AND LOWER(title) = LOWER($2)", sql)
+
+	mysql, err := NewConnection(&ConnectionDetails{Dialect: "mysql", Host: "db.local", Database: "pop_test"})
+	r.NoError(err)
+	sql, args, err = mysql.Q().WhereILike("title", "go%").WhereEndsWith("title", `\o/`).WhereEqualFold("title", "Go").ToSQL(m)
+	r.NoError(err)
+	r.Equal(sel+"LOWER(title) LIKE LOWER(?) AND title LIKE ? AND title = ?", sql)
+	r.Equal([]interface{}{"go%", `%\\o/`, "Go"}, args)
+
+	sqlite, err := NewConnection(&ConnectionDetails{Dialect: "sqlite3", Database: "pop_test.sqlite"})
+	r.NoError(err)
+	sql = sqlite.Q().WhereContains("title", "a").WhereEqualFold("title", "Go").ToSQLString(m)
+	r.Equal(sel+`title LIKE ? ESCAPE '\' AND title = ? COLLATE NOCASE`, sql)
+
+	r.Equal(`50\% off\_sale \\o/`, escapeLike(`50% off_sale \o/`))
+}
+
+func Test_Query_Like(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		for _, b := range []Book{
+			{Title: "Go_Lang", Isbn: "L1"},
+			{Title: "GoXLang", Isbn: "L2"},
+			{Title: "100% Go", Isbn: "L3"},
+		} {
+			r.NoError(tx.Create(&b))
+		}
+
+		count := func(q *Query) int {
+			books := []latestBook{}
+			r.NoError(q.Where("isbn LIKE ?", "L%").All(ctx, &books))
+			return len(books)
+		}
+		r.Equal(2, count(tx.Q().WhereILike("title", "go%")))
+		r.Equal(1, count(tx.Q().WhereStartsWith("title", "Go_")))
+		r.Equal(1, count(tx.Q().WhereEndsWith("title", "% go")))
+		r.Equal(1, count(tx.Q().WhereContains("title", "0%")))
+		r.Equal(1, count(tx.Q().WhereEqualFold("title", "goxlang")))
+	})
+}
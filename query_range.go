@@ -0,0 +1,82 @@
+package pop
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"time"
+)
+
+// WhereBetween keeps the rows whose column is between from and to,
+// included. A nil or zero time bound is dropped:
+//
+//	q.WhereBetween("price", 10, 20)          // (price BETWEEN ? AND ?)
+//	q.WhereBetween("created_at", since, nil) // (created_at >= ?)
+//
+// The time bounds are converted to the time zone of the timestamps of the
+// models: UTC with ConnectionDetails.UTC.
+func (q *Query) WhereBetween(column string, from interface{}, to interface{}) *Query {
+	from, hasFrom := q.rangeBound(from)
+	to, hasTo := q.rangeBound(to)
+	switch {
+	case hasFrom && hasTo:
+		return q.Where(fmt.Sprintf("(%s BETWEEN ? AND ?)", column), from, to)
+	case hasFrom:
+		return q.Where(fmt.Sprintf("(%s >= ?)", column), from)
+	case hasTo:
+		return q.Where(fmt.Sprintf("(%s <= ?)", column), to)
+	}
+	return q
+}
+
+// WhereInRange keeps the rows whose column is in the half-open range
+// [from, to): from is included, to is excluded. It's the range of the
+// consecutive periods, e.g. the rows created in a day:
+//
+//	q.WhereInRange("created_at", day, day.AddDate(0, 0, 1)) // (created_at >= ? AND created_at < ?)
+//
+// A nil or zero time bound is dropped, and the time bounds are converted
+// like with WhereBetween.
+func (q *Query) WhereInRange(column string, from interface{}, to interface{}) *Query {
+	from, hasFrom := q.rangeBound(from)
+	to, hasTo := q.rangeBound(to)
+	switch {
+	case hasFrom && hasTo:
+		return q.Where(fmt.Sprintf("(%s >= ? AND %s < ?)", column, column), from, to)
+	case hasFrom:
+		return q.Where(fmt.Sprintf("(%s >= ?)", column), from)
+	case hasTo:
+		return q.Where(fmt.Sprintf("(%s < ?)", column), to)
+	}
+	return q
+}
+
+// rangeBound returns the arg of the range bound b, and false if b is
+// unbounded: nil, a nil pointer, a zero time or a null value.
+func (q *Query) rangeBound(b interface{}) (interface{}, bool) {
+	if b == nil {
+		return nil, false
+	}
+	if v := reflect.ValueOf(b); v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil, false
+		}
+		b = v.Elem().Interface()
+	}
+	if v, ok := b.(driver.Valuer); ok {
+		dv, err := v.Value()
+		if err == nil && dv == nil {
+			return nil, false
+		}
+		if t, ok := dv.(time.Time); ok {
+			b = t
+		}
+	}
+	if t, ok := b.(time.Time); ok {
+		if t.IsZero() {
+			return nil, false
+		}
+		return q.Connection.dbTime(t), true
+	}
+	return b, true
+}
@@ -0,0 +1,66 @@
+package pop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Query_Range_SQL(t *testing.T) {
+	r := require.New(t)
+	m := &Model{Value: &latestBook{}}
+	sel := "SELECT books.id, books.title, books.user_id FROM books AS books WHERE "
+
+	sqlite, err := NewConnection(&ConnectionDetails{Dialect: "sqlite3", Database: "pop_test.sqlite"})
+	r.NoError(err)
+	sql, args, err := sqlite.Q().WhereBetween("user_id", 0, 10).WhereInRange("id", 5, nulls.Int{}).ToSQL(m)
+	r.NoError(err)
+	r.Equal(sel+"(user_id BETWEEN ? AND ?) AND (id >= ?)", sql)
+	r.Equal([]interface{}{0, 10, 5}, args)
+
+	paris, err := time.LoadLocation("Europe/Paris")
+	r.NoError(err)
+	day := time.Date(2020, 3, 1, 0, 0, 0, 0, paris)
+	var none *time.Time
+	sql, args, err = sqlite.Q().WhereInRange("created_at", day, day.AddDate(0, 0, 1)).WhereBetween("updated_at", time.Time{}, &day).WhereInRange("id", nil, none).ToSQL(m)
+	r.NoError(err)
+	r.Equal(sel+"(created_at >= ? AND created_at < ?) AND (updated_at <= ?)", sql)
+	r.Equal([]interface{}{day, day.AddDate(0, 0, 1), day}, args)
+
+	// converted to UTC like the timestamps of the models
+	utc, err := NewConnection(&ConnectionDetails{Dialect: "sqlite3", Database: "pop_test.sqlite", UTC: true})
+	r.NoError(err)
+	_, args, err = utc.Q().WhereInRange("created_at", day, nil).ToSQL(m)
+	r.NoError(err)
+	r.Equal([]interface{}{day.UTC()}, args)
+	r.Equal(time.UTC, args[0].(time.Time).Location())
+}
+
+func Test_Query_Range(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		for _, b := range []Book{
+			{Title: "one", Isbn: "R1"},
+			{Title: "two", Isbn: "R2"},
+		} {
+			r.NoError(tx.Create(&b))
+		}
+		b1, b2 := Book{}, Book{}
+		r.NoError(tx.Where("isbn = ?", "R1").First(ctx, &b1))
+		r.NoError(tx.Where("isbn = ?", "R2").First(ctx, &b2))
+
+		count := func(q *Query) int {
+			books := []latestBook{}
+			r.NoError(q.Where("isbn LIKE ?", "R%").All(ctx, &books))
+			return len(books)
+		}
+		r.Equal(2, count(tx.Q().WhereBetween("id", b1.ID, b2.ID)))
+		r.Equal(1, count(tx.Q().WhereInRange("id", b1.ID, b2.ID)))
+		r.Equal(2, count(tx.Q().WhereInRange("created_at", b1.CreatedAt, nil)))
+		r.Equal(0, count(tx.Q().WhereInRange("created_at", nil, b1.CreatedAt)))
+	})
+}
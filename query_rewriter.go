@@ -0,0 +1,69 @@
+package pop
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+)
+
+// QueryRewriter rewrites a statement of the operation op, e.g. "First",
+// "All", "Create" or "Exec", right before it's run: sql is the statement
+// with the placeholders of the dialect, and args its arguments. A
+// rewriter returning an error aborts the operation.
+//
+// The named statements, e.g. the INSERT of Create, are given their bound
+// struct or map as their only argument, or none when they're prepared:
+// it must be returned as is.
+type QueryRewriter func(ctx context.Context, op string, sql string, args []interface{}) (string, []interface{}, error)
+
+// AddQueryRewriter appends a rewriter to the ones applied to the
+// statements of the operations run by the connection, eager loading and
+// the Count and Exists wrappers included. Rewriters are applied in the
+// order they are added, each one to the statement returned by the
+// previous one; the statement prepared is the rewritten one. Connections
+// created from c (transactions, copies) inherit its rewriters.
+//
+//	c.AddQueryRewriter(func(ctx context.Context, op string, sql string, args []interface{}) (string, []interface{}, error) {
+//		if strings.HasPrefix(sql, "SELECT") {
+//			sql = "SELECT /*+ MAX_EXECUTION_TIME(2000) */" + sql[len("SELECT"):]
+//		}
+//		return sql, args, nil
+//	})
+func (c *Connection) AddQueryRewriter(r QueryRewriter) {
+	// copy the rewriters, so connections sharing them aren't modified.
+	rs := make([]QueryRewriter, 0, len(c.rewriters)+1)
+	rs = append(rs, c.rewriters...)
+	c.rewriters = append(rs, r)
+}
+
+// rewrite applies the rewriters of the operation to a statement.
+func (s *operationStore) rewrite(query string, args []interface{}) (string, []interface{}, error) {
+	for _, r := range s.rewriters {
+		var err error
+		query, args, err = r(s.ctx, s.info.Operation, query, args)
+		if err != nil {
+			return "", nil, errors.Wrapf(err, "could not rewrite a statement of %s", s.info.Operation)
+		}
+	}
+	return query, args, nil
+}
+
+// rewriteNamed applies the rewriters of the operation to a named
+// statement, arg being its bound struct or map, nil when it's prepared.
+func (s *operationStore) rewriteNamed(query string, arg interface{}) (string, interface{}, error) {
+	var args []interface{}
+	if arg != nil {
+		args = []interface{}{arg}
+	}
+	query, args, err := s.rewrite(query, args)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(args) > 1 || (len(args) == 1) != (arg != nil) {
+		return "", nil, errors.Errorf("the arguments of the named statement of %s can't be rewritten", s.info.Operation)
+	}
+	if arg != nil {
+		arg = args[0]
+	}
+	return query, arg, nil
+}
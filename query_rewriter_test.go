@@ -0,0 +1,81 @@
+package pop
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Connection_AddQueryRewriter(t *testing.T) {
+	r := require.New(t)
+
+	var ops []string
+	var statements []string
+	c := PDB.copy()
+	c.AddQueryRewriter(func(ctx context.Context, op string, sql string, args []interface{}) (string, []interface{}, error) {
+		ops = append(ops, op)
+		return "/* a */ " + sql, args, nil
+	})
+	c.AddQueryRewriter(func(ctx context.Context, op string, sql string, args []interface{}) (string, []interface{}, error) {
+		return "/* b */ " + sql, args, nil
+	})
+	c.Observe(func(ctx context.Context, info QueryInfo) {
+		statements = append(statements, info.SQL)
+	})
+
+	r.NoError(c.Rollback(func(tx *Connection) {
+		ctx := tx.txContext()
+		u := &User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(u))
+		r.NoError(tx.Create(&Book{Title: "Pop", UserID: nulls.NewInt(u.ID)}))
+
+		found := &User{}
+		r.NoError(tx.Eager("Books").Find(ctx, found, u.ID))
+		r.Len(found.Books, 1)
+		n, err := tx.Where("id = ?", u.ID).Count(&User{})
+		r.NoError(err)
+		r.Equal(1, n)
+		ok, err := tx.Where("id = ?", u.ID).Exists(&User{})
+		r.NoError(err)
+		r.True(ok)
+
+		r.Contains(ops, "Create")
+		r.Contains(ops, "First")
+		r.Contains(ops, "All")
+		r.Contains(ops, "CountByField")
+		r.Contains(ops, "Exists")
+		for _, s := range statements {
+			if s != "" {
+				r.True(strings.HasPrefix(s, "/* b */ /* a */ "), s)
+			}
+		}
+
+		// the args can be rewritten too
+		c := tx.copy()
+		c.AddQueryRewriter(func(ctx context.Context, op string, sql string, args []interface{}) (string, []interface{}, error) {
+			if op == "First" {
+				args = []interface{}{-1}
+			}
+			return sql, args, nil
+		})
+		r.True(IsNotFound(c.Find(ctx, &User{}, u.ID)))
+
+		// an error aborts the operation
+		refused := errors.New("refused")
+		c.AddQueryRewriter(func(ctx context.Context, op string, sql string, args []interface{}) (string, []interface{}, error) {
+			if op == "Destroy" {
+				return "", nil, refused
+			}
+			return sql, args, nil
+		})
+		r.Equal(refused, errors.Cause(c.Destroy(u)))
+		r.NoError(tx.Find(ctx, &User{}, u.ID))
+	}))
+
+	// the original connection is left untouched
+	r.Empty(PDB.rewriters)
+}
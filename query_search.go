@@ -0,0 +1,123 @@
+package pop
+
+import (
+	"fmt"
+	"regexp"
+
+	"github.com/gobuffalo/pop/logging"
+	"github.com/pkg/errors"
+)
+
+// SearchOption configures a full-text Search.
+type SearchOption func(*searchOptions)
+
+type searchOptions struct {
+	rank     string
+	language string
+}
+
+// SearchRank selects the relevance of the rows to the phrase as alias, and
+// orders the rows by decreasing relevance. The model needs a field for
+// alias, e.g. a read-only `db:"rank" rw:"r"` field.
+func SearchRank(alias string) SearchOption {
+	return func(o *searchOptions) {
+		o.rank = alias
+	}
+}
+
+// SearchLanguage sets the text search configuration of PostgreSQL, e.g.
+// "english". It's ignored by the other databases.
+func SearchLanguage(config string) SearchOption {
+	return func(o *searchOptions) {
+		o.language = config
+	}
+}
+
+// fullTextSearcher is implemented by the dialects supporting full-text
+// search. The other dialects search the phrase with LIKE.
+type fullTextSearcher interface {
+	// searchMatch returns the condition of the rows of column matching
+	// the phrase placeholder.
+	searchMatch(column string, o searchOptions) string
+	// searchRank returns the relevance of column to the phrase
+	// placeholder.
+	searchRank(column string, o searchOptions) string
+}
+
+var rSearchLanguage = regexp.MustCompile(`^[a-zA-Z_]+$`)
+
+// Search keeps the rows whose column matches the phrase, with the
+// full-text search of the database:
+//
+//	q.Search("body", "pop query builder", pop.SearchRank("rank")).Paginate(1, 20).All(ctx, &posts)
+//	// PostgreSQL: WHERE to_tsvector(body) @@ plainto_tsquery($1) ORDER BY rank DESC
+//	// MySQL:      WHERE MATCH(body) AGAINST (? IN NATURAL LANGUAGE MODE) ORDER BY rank DESC
+//
+// The phrase is bound as an arg, and its words are searched as plain text.
+// SQLite, which has no full-text search without an FTS table, searches
+// the phrase with LIKE, and ranks all the matching rows equally. MySQL
+// needs a FULLTEXT index of column.
+func (q *Query) Search(column string, phrase string, opts ...SearchOption) *Query {
+	if q.RawSQL.Fragment != "" {
+		return q.rawClause("Search")
+	}
+	var o searchOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if o.language != "" && !rSearchLanguage.MatchString(o.language) {
+		if q.err == nil {
+			q.err = errors.Errorf("invalid text search configuration %q", o.language)
+		}
+		return q
+	}
+
+	fs, ok := q.Connection.Dialect.(fullTextSearcher)
+	if !ok {
+		q.Connection.log(logging.Debug, "%s doesn't support full-text search, the phrase is searched with LIKE", q.Connection.Dialect.Name())
+		q.WhereContains(column, phrase)
+		if o.rank != "" {
+			q.windowColumns = append(q.windowColumns, windowColumn{expr: "1", alias: o.rank})
+		}
+		return q
+	}
+	q.Where(fs.searchMatch(column, o), phrase)
+	if o.rank != "" {
+		q.windowColumns = append(q.windowColumns, windowColumn{expr: fs.searchRank(column, o), alias: o.rank, args: []interface{}{phrase}})
+		q.Order(o.rank + " DESC")
+	}
+	return q
+}
+
+// tsConfig returns the text search configuration arg of the PostgreSQL
+// text search functions, if any.
+func tsConfig(o searchOptions) string {
+	if o.language == "" {
+		return ""
+	}
+	return fmt.Sprintf("'%s', ", o.language)
+}
+
+func (p *postgresql) searchMatch(column string, o searchOptions) string {
+	return fmt.Sprintf("to_tsvector(%s%s) @@ plainto_tsquery(%s?)", tsConfig(o), column, tsConfig(o))
+}
+
+func (p *postgresql) searchRank(column string, o searchOptions) string {
+	return fmt.Sprintf("ts_rank(to_tsvector(%s%s), plainto_tsquery(%s?))", tsConfig(o), column, tsConfig(o))
+}
+
+func (p *cockroach) searchMatch(column string, o searchOptions) string {
+	return fmt.Sprintf("to_tsvector(%s%s) @@ plainto_tsquery(%s?)", tsConfig(o), column, tsConfig(o))
+}
+
+func (p *cockroach) searchRank(column string, o searchOptions) string {
+	return fmt.Sprintf("ts_rank(to_tsvector(%s%s), plainto_tsquery(%s?))", tsConfig(o), column, tsConfig(o))
+}
+
+func (m *mysql) searchMatch(column string, o searchOptions) string {
+	return fmt.Sprintf("MATCH(%s) AGAINST (? IN NATURAL LANGUAGE MODE)", column)
+}
+
+func (m *mysql) searchRank(column string, o searchOptions) string {
+	return m.searchMatch(column, o)
+}
@@ -0,0 +1,69 @@
+package pop
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type rankedSearchBook struct {
+	ID    int     `db:"id"`
+	Title string  `db:"title"`
+	Rank  float64 `db:"rank" rw:"r"`
+}
+
+func (rankedSearchBook) TableName() string {
+	return "books"
+}
+
+func Test_Query_Search_SQL(t *testing.T) {
+	r := require.New(t)
+	m := &Model{Value: &rankedSearchBook{}}
+
+	postgres, err := NewConnection(&ConnectionDetails{Dialect: "postgres", Host: "db.local", Database: "pop_test"})
+	r.NoError(err)
+	q := postgres.Where("user_id = ?", 1).Search("title", "pop's query", SearchRank("rank"), SearchLanguage("english")).Paginate(2, 10)
+	sql, args, err := q.ToSQL(m)
+	r.NoError(err)
+	r.Equal("SELECT books.id, books.title, ts_rank(to_tsvector('english', title), plainto_tsquery('english', $1)) AS rank FROM books AS books WHERE user_id = $2 AND to_tsvector('english', title) @@ plainto_tsquery('english', $3) ORDER BY rank DESC LIMIT 10 OFFSET 10", sql)
+	r.Equal([]interface{}{"pop's query", 1, "pop's query"}, args)
+
+	_, _, err = postgres.Q().Search("title", "pop", SearchLanguage("english'); drop table books; --")).ToSQL(m)
+	r.Error(err)
+
+	mysql, err := NewConnection(&ConnectionDetails{Dialect: "mysql", Host: "db.local", Database: "pop_test"})
+	r.NoError(err)
+	sql, args, err = mysql.Q().Search("title", "pop").ToSQL(&Model{Value: &latestBook{}})
+	r.NoError(err)
+	r.Equal("SELECT books.id, books.title, books.user_id FROM books AS books WHERE MATCH(title) AGAINST (? IN NATURAL LANGUAGE MODE)", sql)
+	r.Equal([]interface{}{"pop"}, args)
+}
+
+func Test_Query_Search(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		for _, b := range []Book{
+			{Title: "Learning Go", Isbn: "S1"},
+			{Title: "100% Go", Isbn: "S2"},
+			{Title: "Learning Rust", Isbn: "S3"},
+		} {
+			r.NoError(tx.Create(&b))
+		}
+
+		books := []rankedSearchBook{}
+		r.NoError(tx.Where("isbn LIKE ?", "S%").Search("title", "go", SearchRank("rank")).Order("title").All(ctx, &books))
+		r.Len(books, 2)
+		r.Equal("100% Go", books[0].Title)
+		r.Equal(float64(1), books[0].Rank)
+
+		matches := []latestBook{}
+		r.NoError(tx.Q().Search("title", "100%").All(ctx, &matches))
+		r.Len(matches, 1)
+
+		count, err := tx.Q().Search("title", "learning").Count(&latestBook{})
+		r.NoError(err)
+		r.Equal(2, count)
+	})
+}
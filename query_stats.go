@@ -0,0 +1,321 @@
+package pop
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+// queryStatsSamples is the number of durations kept per statement,
+// to compute the p95.
+const queryStatsSamples = 128
+
+// defaultQueryStatsLimit is the number of statements kept when
+// ConnectionDetails.QueryStatsLimit isn't set.
+const defaultQueryStatsLimit = 100
+
+// QueryStat is the statistics of a statement.
+type QueryStat struct {
+	// Query is the normalized SQL, with its literals replaced.
+	Query string
+	Calls int64
+	Total time.Duration
+	Mean  time.Duration
+	// P95 is the 95th percentile of the last 128 calls durations.
+	P95 time.Duration
+	// Rows returned by the selects, or affected by the other statements.
+	Rows int64
+}
+
+// MarshalJSON renders the durations in a human readable form.
+func (s QueryStat) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Query string `json:"query"`
+		Calls int64  `json:"calls"`
+		Total string `json:"total"`
+		Mean  string `json:"mean"`
+		P95   string `json:"p95"`
+		Rows  int64  `json:"rows"`
+	}{s.Query, s.Calls, s.Total.String(), s.Mean.String(), s.P95.String(), s.Rows})
+}
+
+// QueryStats is a snapshot of the statistics of the statements run by a
+// connection, keyed by normalized SQL.
+type QueryStats map[string]QueryStat
+
+// Sorted returns the statistics ordered by decreasing total time.
+func (qs QueryStats) Sorted() []QueryStat {
+	stats := make([]QueryStat, 0, len(qs))
+	for _, s := range qs {
+		stats = append(stats, s)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].Total == stats[j].Total {
+			return stats[i].Query < stats[j].Query
+		}
+		return stats[i].Total > stats[j].Total
+	})
+	return stats
+}
+
+// MarshalJSON renders the statistics as a list, ordered by decreasing
+// total time.
+func (qs QueryStats) MarshalJSON() ([]byte, error) {
+	return json.Marshal(qs.Sorted())
+}
+
+// QueryStats returns a snapshot of the statistics of the statements run by
+// the connection. It's empty unless ConnectionDetails.CollectQueryStats is set.
+//
+//	defer func() {
+//		b, _ := json.Marshal(c.QueryStats())
+//		log.Printf("query stats: %s", b)
+//	}()
+func (c *Connection) QueryStats() QueryStats {
+	if c.queryStats == nil {
+		return QueryStats{}
+	}
+	return c.queryStats.snapshot()
+}
+
+// ResetQueryStats clears the statistics of the statements run by the connection.
+func (c *Connection) ResetQueryStats() {
+	if c.queryStats != nil {
+		c.queryStats.reset()
+	}
+}
+
+type queryStat struct {
+	calls   int64
+	total   time.Duration
+	rows    int64
+	samples []time.Duration
+	next    int
+}
+
+// queryStats collects the statistics of the statements. It keeps up to
+// limit statements: when full, the one with the lowest total time is
+// dropped for a new one.
+type queryStats struct {
+	mu    sync.Mutex
+	limit int
+	stats map[string]*queryStat
+}
+
+func newQueryStats(deets *ConnectionDetails) *queryStats {
+	if !deets.CollectQueryStats {
+		return nil
+	}
+	limit := deets.QueryStatsLimit
+	if limit <= 0 {
+		limit = defaultQueryStatsLimit
+	}
+	return &queryStats{limit: limit, stats: map[string]*queryStat{}}
+}
+
+func (qs *queryStats) record(query string, d time.Duration, rows int64) {
+	query = normalizeStatement(query)
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	s, ok := qs.stats[query]
+	if !ok {
+		if len(qs.stats) >= qs.limit {
+			qs.evict()
+		}
+		s = &queryStat{}
+		qs.stats[query] = s
+	}
+	s.calls++
+	s.total += d
+	s.rows += rows
+	if len(s.samples) < queryStatsSamples {
+		s.samples = append(s.samples, d)
+	} else {
+		s.samples[s.next] = d
+		s.next = (s.next + 1) % queryStatsSamples
+	}
+}
+
+// evict drops the statement with the lowest total time.
+func (qs *queryStats) evict() {
+	var min string
+	for q, s := range qs.stats {
+		if min == "" || s.total < qs.stats[min].total {
+			min = q
+		}
+	}
+	delete(qs.stats, min)
+}
+
+func (qs *queryStats) snapshot() QueryStats {
+	qs.mu.Lock()
+	defer qs.mu.Unlock()
+	snap := make(QueryStats, len(qs.stats))
+	for q, s := range qs.stats {
+		samples := append([]time.Duration(nil), s.samples...)
+		sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+		snap[q] = QueryStat{
+			Query: q,
+			Calls: s.calls,
+			Total: s.total,
+			Mean:  s.total / time.Duration(s.calls),
+			P95:   samples[(len(samples)*95+99)/100-1],
+			Rows:  s.rows,
+		}
+	}
+	return snap
+}
+
+func (qs *queryStats) reset() {
+	qs.mu.Lock()
+	qs.stats = map[string]*queryStat{}
+	qs.mu.Unlock()
+}
+
+// statsStore wraps a store to collect the statistics of its statements.
+type statsStore struct {
+	Store
+	stats *queryStats
+}
+
+// withQueryStats wraps the store with a statsStore, if the
+// statistics are collected.
+func withQueryStats(s Store, stats *queryStats) Store {
+	if stats == nil {
+		return s
+	}
+	return &statsStore{Store: s, stats: stats}
+}
+
+func (s *statsStore) Select(dest interface{}, query string, args ...interface{}) error {
+	start := time.Now()
+	err := s.Store.Select(dest, query, args...)
+	var rows int64
+	if v := reflect.Indirect(reflect.ValueOf(dest)); v.Kind() == reflect.Slice {
+		rows = int64(v.Len())
+	}
+	s.stats.record(query, time.Since(start), rows)
+	return err
+}
+
+func (s *statsStore) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	start := time.Now()
+	err := s.Store.SelectContext(ctx, dest, query, args...)
+	var rows int64
+	if v := reflect.Indirect(reflect.ValueOf(dest)); v.Kind() == reflect.Slice {
+		rows = int64(v.Len())
+	}
+	s.stats.record(query, time.Since(start), rows)
+	return err
+}
+
+func (s *statsStore) Get(dest interface{}, query string, args ...interface{}) error {
+	start := time.Now()
+	err := s.Store.Get(dest, query, args...)
+	var rows int64
+	if err == nil {
+		rows = 1
+	}
+	s.stats.record(query, time.Since(start), rows)
+	return err
+}
+
+func (s *statsStore) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	start := time.Now()
+	rows, err := s.Store.Queryx(query, args...)
+	// the rows are read by the caller, they can't be counted here
+	s.stats.record(query, time.Since(start), 0)
+	return rows, err
+}
+
+func (s *statsStore) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	start := time.Now()
+	row := s.Store.QueryRowContext(ctx, query, args...)
+	// the row is read by the caller, it can't be counted here
+	s.stats.record(query, time.Since(start), 0)
+	return row
+}
+
+func (s *statsStore) NamedExec(query string, arg interface{}) (sql.Result, error) {
+	start := time.Now()
+	res, err := s.Store.NamedExec(query, arg)
+	s.stats.record(query, time.Since(start), affectedRows(res, err))
+	return res, err
+}
+
+func (s *statsStore) Exec(query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	res, err := s.Store.Exec(query, args...)
+	s.stats.record(query, time.Since(start), affectedRows(res, err))
+	return res, err
+}
+
+func (s *statsStore) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	start := time.Now()
+	res, err := s.Store.ExecContext(ctx, query, args...)
+	s.stats.record(query, time.Since(start), affectedRows(res, err))
+	return res, err
+}
+
+func affectedRows(res sql.Result, err error) int64 {
+	if err != nil || res == nil {
+		return 0
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+var (
+	inListX = regexp.MustCompile(`(?i)\bIN\s*\(\s*\?(\s*,\s*\?)*\s*\)`)
+	spacesX = regexp.MustCompile(`\s+`)
+)
+
+// normalizeStatement replaces the literals of the query with "?", so the
+// statements differing only by their values are grouped together.
+// IN lists are collapsed to a single value.
+func normalizeStatement(query string) string {
+	var b strings.Builder
+	b.Grow(len(query))
+	for i := 0; i < len(query); {
+		ch := query[i]
+		j := i + 1
+		switch {
+		case ch == '\'':
+			j = skipQuoted(query, i, ch, true)
+			b.WriteByte('?')
+			i = j
+			continue
+		case ch == '"' || ch == '`':
+			j = skipQuoted(query, i, ch, false)
+		case ch >= '0' && ch <= '9' && (i == 0 || !isIdentByte(query[i-1])):
+			for j < len(query) && (isIdentByte(query[j]) || query[j] == '.') {
+				j++
+			}
+			b.WriteByte('?')
+			i = j
+			continue
+		case ch == '$' && j < len(query) && query[j] >= '0' && query[j] <= '9':
+			for j < len(query) && query[j] >= '0' && query[j] <= '9' {
+				j++
+			}
+			b.WriteByte('?')
+			i = j
+			continue
+		}
+		b.WriteString(query[i:j])
+		i = j
+	}
+	q := spacesX.ReplaceAllString(strings.TrimSpace(b.String()), " ")
+	return inListX.ReplaceAllString(q, "IN (?)")
+}
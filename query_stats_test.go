@@ -0,0 +1,81 @@
+package pop
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_QueryStats(t *testing.T) {
+	r := require.New(t)
+
+	deets := *PDB.Dialect.Details()
+	deets.CollectQueryStats = true
+	c, err := NewConnection(&deets)
+	r.NoError(err)
+	r.NoError(c.Open())
+
+	for _, name := range []string{"mark", "john"} {
+		r.NoError(c.RawQuery(fmt.Sprintf("SELECT * FROM users WHERE name = '%s'", name)).All(context.Background(), &Users{}))
+	}
+	r.NoError(c.Where("id in (?)", 1, 2, 3).All(context.Background(), &Users{}))
+
+	stats := c.QueryStats()
+	r.Len(stats, 2)
+	st, ok := stats["SELECT * FROM users WHERE name = ?"]
+	r.True(ok)
+	r.Equal(int64(2), st.Calls)
+	r.Equal(int64(0), st.Rows)
+	r.True(st.Total >= st.P95)
+	r.Equal(st.Total/2, st.Mean)
+
+	b, err := json.Marshal(stats)
+	r.NoError(err)
+	var list []map[string]interface{}
+	r.NoError(json.Unmarshal(b, &list))
+	r.Len(list, 2)
+	r.Contains(list[0], "p95")
+
+	c.ResetQueryStats()
+	r.Len(c.QueryStats(), 0)
+
+	r.Len(PDB.QueryStats(), 0)
+}
+
+func Test_queryStats_Limit(t *testing.T) {
+	r := require.New(t)
+
+	qs := newQueryStats(&ConnectionDetails{CollectQueryStats: true, QueryStatsLimit: 2})
+	qs.record("select 1", 3*time.Millisecond, 1)
+	qs.record("select a", time.Millisecond, 1)
+	qs.record("select b", 2*time.Millisecond, 1)
+
+	stats := qs.snapshot().Sorted()
+	r.Len(stats, 2)
+	r.Equal("select ?", stats[0].Query)
+	r.Equal("select b", stats[1].Query)
+}
+
+func Test_normalizeStatement(t *testing.T) {
+	table := []struct {
+		in  string
+		out string
+	}{
+		{"SELECT * FROM users WHERE id = 42", "SELECT * FROM users WHERE id = ?"},
+		{"select * from users where name = 'it''s' and price > 1.5", "select * from users where name = ? and price > ?"},
+		{"select \"t1\".a1 from t1 where id IN ($1, $2,$3)", "select \"t1\".a1 from t1 where id IN (?)"},
+		{"select *\n\tfrom users  where id in (?, ?)", "select * from users where id IN (?)"},
+		{"select a2 from users", "select a2 from users"},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.in, func(st *testing.T) {
+			r := require.New(st)
+			r.Equal(tt.out, normalizeStatement(tt.in))
+		})
+	}
+}
@@ -4,7 +4,9 @@ import (
 	"fmt"
 	"testing"
 
+	"github.com/gobuffalo/nulls"
 	"github.com/gofrs/uuid"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/require"
 )
 
@@ -13,19 +15,19 @@ func Test_Where(t *testing.T) {
 	m := &Model{Value: &Enemy{}}
 
 	q := PDB.Where("id = ?", 1)
-	sql, _ := q.ToSQL(m)
+	sql := q.ToSQLString(m)
 	a.Equal(ts("SELECT enemies.A FROM enemies AS enemies WHERE id = ?"), sql)
 
 	q.Where("first_name = ? and last_name = ?", "Mark", "Bates")
-	sql, _ = q.ToSQL(m)
+	sql = q.ToSQLString(m)
 	a.Equal(ts("SELECT enemies.A FROM enemies AS enemies WHERE id = ? AND first_name = ? and last_name = ?"), sql)
 
 	q = PDB.Where("name = ?", "Mark 'Awesome' Bates")
-	sql, _ = q.ToSQL(m)
+	sql = q.ToSQLString(m)
 	a.Equal(ts("SELECT enemies.A FROM enemies AS enemies WHERE name = ?"), sql)
 
 	q = PDB.Where("name = ?", "'; truncate users; --")
-	sql, _ = q.ToSQL(m)
+	sql = q.ToSQLString(m)
 	a.Equal(ts("SELECT enemies.A FROM enemies AS enemies WHERE name = ?"), sql)
 }
 
@@ -94,11 +96,11 @@ func Test_Order(t *testing.T) {
 
 	m := &Model{Value: &Enemy{}}
 	q := PDB.Order("id desc")
-	sql, _ := q.ToSQL(m)
+	sql := q.ToSQLString(m)
 	a.Equal(ts("SELECT enemies.A FROM enemies AS enemies ORDER BY id desc"), sql)
 
 	q.Order("name desc")
-	sql, _ = q.ToSQL(m)
+	sql = q.ToSQLString(m)
 	a.Equal(ts("SELECT enemies.A FROM enemies AS enemies ORDER BY id desc, name desc"), sql)
 }
 
@@ -108,17 +110,17 @@ func Test_GroupBy(t *testing.T) {
 	m := &Model{Value: &Enemy{}}
 	q := PDB.Q()
 	q.GroupBy("A")
-	sql, _ := q.ToSQL(m)
+	sql := q.ToSQLString(m)
 	a.Equal(ts("SELECT enemies.A FROM enemies AS enemies GROUP BY A"), sql)
 
 	q = PDB.Q()
 	q.GroupBy("A", "B")
-	sql, _ = q.ToSQL(m)
+	sql = q.ToSQLString(m)
 	a.Equal(ts("SELECT enemies.A FROM enemies AS enemies GROUP BY A, B"), sql)
 
 	q = PDB.Q()
 	q.GroupBy("A", "B").Having("enemies.A=?", "test")
-	sql, _ = q.ToSQL(m)
+	sql = q.ToSQLString(m)
 	if PDB.Dialect.Details().Dialect == "postgres" {
 		a.Equal(ts("SELECT enemies.A FROM enemies AS enemies GROUP BY A, B HAVING enemies.A=$1"), sql)
 	} else {
@@ -127,7 +129,7 @@ func Test_GroupBy(t *testing.T) {
 
 	q = PDB.Q()
 	q.GroupBy("A", "B").Having("enemies.A=?", "test").Having("enemies.B=enemies.A")
-	sql, _ = q.ToSQL(m)
+	sql = q.ToSQLString(m)
 	if PDB.Dialect.Details().Dialect == "postgres" {
 		a.Equal(ts("SELECT enemies.A FROM enemies AS enemies GROUP BY A, B HAVING enemies.A=$1 AND enemies.B=enemies.A"), sql)
 	} else {
@@ -143,46 +145,47 @@ func Test_ToSQL(t *testing.T) {
 		s := "SELECT name as full_name, users.alive, users.bio, users.birth_date, users.created_at, users.email, users.id, users.name, users.price, users.updated_at, users.user_name FROM users AS users"
 
 		query := Q(tx)
-		q, _ := query.ToSQL(user)
+		q := query.ToSQLString(user)
 		a.Equal(s, q)
 
 		query.Order("id desc")
-		q, _ = query.ToSQL(user)
+		q = query.ToSQLString(user)
 		a.Equal(fmt.Sprintf("%s ORDER BY id desc", s), q)
 
-		q, _ = query.ToSQL(&Model{Value: &User{}, As: "u"})
+		q = query.ToSQLString(&Model{Value: &User{}, As: "u"})
 		a.Equal("SELECT name as full_name, u.alive, u.bio, u.birth_date, u.created_at, u.email, u.id, u.name, u.price, u.updated_at, u.user_name FROM users AS u ORDER BY id desc", q)
 
-		q, _ = query.ToSQL(&Model{Value: &Family{}})
+		q = query.ToSQLString(&Model{Value: &Family{}})
 		a.Equal("SELECT family_members.created_at, family_members.first_name, family_members.id, family_members.last_name, family_members.updated_at FROM family.members AS family_members ORDER BY id desc", q)
 
 		query = tx.Where("id = 1")
-		q, _ = query.ToSQL(user)
+		q = query.ToSQLString(user)
 		a.Equal(fmt.Sprintf("%s WHERE id = 1", s), q)
 
 		query = tx.Where("id = 1").Where("name = 'Mark'")
-		q, _ = query.ToSQL(user)
+		q = query.ToSQLString(user)
 		a.Equal(fmt.Sprintf("%s WHERE id = 1 AND name = 'Mark'", s), q)
 
 		query.Order("id desc")
-		q, _ = query.ToSQL(user)
+		q = query.ToSQLString(user)
 		a.Equal(fmt.Sprintf("%s WHERE id = 1 AND name = 'Mark' ORDER BY id desc", s), q)
 
 		query.Order("name asc")
-		q, _ = query.ToSQL(user)
+		q = query.ToSQLString(user)
 		a.Equal(fmt.Sprintf("%s WHERE id = 1 AND name = 'Mark' ORDER BY id desc, name asc", s), q)
 
 		query = tx.Limit(10)
-		q, _ = query.ToSQL(user)
+		q = query.ToSQLString(user)
 		a.Equal(fmt.Sprintf("%s LIMIT 10", s), q)
 
 		query = tx.Paginate(3, 10)
-		q, _ = query.ToSQL(user)
+		q = query.ToSQLString(user)
 		a.Equal(fmt.Sprintf("%s LIMIT 10 OFFSET 20", s), q)
 
 		// join must come first
 		query = Q(tx).Where("id = ?", 1).Join("books b", "b.user_id=?", "xx").Order("name asc")
-		q, args := query.ToSQL(user)
+		q, args, err := query.ToSQL(user)
+		a.NoError(err)
 
 		if tx.Dialect.Details().Dialect == "postgres" {
 			a.Equal(fmt.Sprintf("%s JOIN books b ON b.user_id=$1 WHERE id = $2 ORDER BY name asc", s), q)
@@ -194,43 +197,43 @@ func Test_ToSQL(t *testing.T) {
 		a.Equal(args[1], 1)
 
 		query = Q(tx)
-		q, _ = query.ToSQL(user, "distinct on (users.name, users.email) users.*", "users.bio")
+		q = query.ToSQLString(user, "distinct on (users.name, users.email) users.*", "users.bio")
 		a.Equal("SELECT distinct on (users.name, users.email) users.*, users.bio FROM users AS users", q)
 
 		query = Q(tx)
-		q, _ = query.ToSQL(user, "distinct on (users.id) users.*", "users.bio")
+		q = query.ToSQLString(user, "distinct on (users.id) users.*", "users.bio")
 		a.Equal("SELECT distinct on (users.id) users.*, users.bio FROM users AS users", q)
 
 		query = Q(tx)
-		q, _ = query.ToSQL(user, "id,r", "users.bio,r", "users.email,w")
+		q = query.ToSQLString(user, "id,r", "users.bio,r", "users.email,w")
 		a.Equal("SELECT id, users.bio FROM users AS users", q)
 
 		query = Q(tx)
-		q, _ = query.ToSQL(user, "distinct on (id) id,r", "users.bio,r", "email,w")
+		q = query.ToSQLString(user, "distinct on (id) id,r", "users.bio,r", "email,w")
 		a.Equal("SELECT distinct on (id) id, users.bio FROM users AS users", q)
 
 		query = Q(tx)
-		q, _ = query.ToSQL(user, "distinct id", "users.bio,r", "email,w")
+		q = query.ToSQLString(user, "distinct id", "users.bio,r", "email,w")
 		a.Equal("SELECT distinct id, users.bio FROM users AS users", q)
 
 		query = Q(tx)
-		q, _ = query.ToSQL(user, "distinct id", "concat(users.name,'-',users.email)")
+		q = query.ToSQLString(user, "distinct id", "concat(users.name,'-',users.email)")
 		a.Equal("SELECT concat(users.name,'-',users.email), distinct id FROM users AS users", q)
 
 		query = Q(tx)
-		q, _ = query.ToSQL(user, "id", "concat(users.name,'-',users.email) name_email")
+		q = query.ToSQLString(user, "id", "concat(users.name,'-',users.email) name_email")
 		a.Equal("SELECT concat(users.name,'-',users.email) name_email, id FROM users AS users", q)
 
 		query = Q(tx)
-		q, _ = query.ToSQL(user, "distinct id", "concat(users.name,'-',users.email),r")
+		q = query.ToSQLString(user, "distinct id", "concat(users.name,'-',users.email),r")
 		a.Equal("SELECT concat(users.name,'-',users.email), distinct id FROM users AS users", q)
 
 		query = Q(tx)
-		q, _ = query.ToSQL(user, "distinct id", "concat(users.name,'-',users.email) AS x")
+		q = query.ToSQLString(user, "distinct id", "concat(users.name,'-',users.email) AS x")
 		a.Equal("SELECT concat(users.name,'-',users.email) AS x, distinct id FROM users AS users", q)
 
 		query = Q(tx)
-		q, _ = query.ToSQL(user, "distinct id", "users.name as english_name", "email private_email")
+		q = query.ToSQLString(user, "distinct id", "users.name as english_name", "email private_email")
 		a.Equal("SELECT distinct id, email private_email, users.name as english_name FROM users AS users", q)
 	})
 }
@@ -240,7 +243,7 @@ func Test_ToSQLInjection(t *testing.T) {
 	transaction(func(tx *Connection) {
 		user := &Model{Value: &User{}}
 		query := tx.Where("name = '?'", "\\\u0027 or 1=1 limit 1;\n-- ")
-		q, _ := query.ToSQL(user)
+		q := query.ToSQLString(user)
 		a.NotEqual("SELECT * FROM users AS users WHERE name = '\\'' or 1=1 limit 1;\n-- '", q)
 	})
 }
@@ -249,8 +252,121 @@ func Test_ToSQL_RawQuery(t *testing.T) {
 	a := require.New(t)
 	transaction(func(tx *Connection) {
 		query := tx.RawQuery("this is some ? raw ?", "random", "query")
-		q, args := query.ToSQL(nil)
+		q, args, err := query.ToSQL(nil)
+		a.NoError(err)
 		a.Equal(q, tx.Dialect.TranslateSQL("this is some ? raw ?"))
 		a.Equal(args, []interface{}{"random", "query"})
 	})
 }
+
+func Test_ToSQL_Errors(t *testing.T) {
+	a := require.New(t)
+
+	query := PDB.Where("id = ?", 1)
+	_, _, err := query.ToSQL(nil)
+	a.Error(err)
+	_, _, err = query.ToSQL(&Model{})
+	a.Error(err)
+	a.Error(query.Exec())
+	a.Panics(func() {
+		query.ToSQLString(nil)
+	})
+
+	query = PDB.RawQuery("select 1").Where("id = ?", 1)
+	_, _, err = query.ToSQL(nil)
+	a.Equal(ErrRawQueryClause, errors.Cause(err))
+}
+
+func Test_RawQuery_Clauses(t *testing.T) {
+	r := require.New(t)
+	transaction(func(tx *Connection) {
+		ctx := tx.txContext()
+		r.NoError(tx.Create(&User{Name: nulls.NewString("Mark")}))
+
+		users := Users{}
+		err := tx.RawQuery("select * from users where name = ?", "Mark").Where("id > ?", 0).All(ctx, &users)
+		r.Equal(ErrRawQueryClause, errors.Cause(err))
+
+		u := User{}
+		err = tx.RawQuery("select * from users").Order("id desc").First(ctx, &u)
+		r.Equal(ErrRawQueryClause, errors.Cause(err))
+
+		err = tx.RawQuery("delete from users where name = ?", "Mark").Where("id > ?", 0).Exec()
+		r.Equal(ErrRawQueryClause, errors.Cause(err))
+
+		// the raw query wins over the clauses set before it
+		users = Users{}
+		err = tx.Where("name = ?", "Unknown").RawQuery("select * from users where name = ?", "Mark").All(ctx, &users)
+		r.NoError(err)
+		r.Len(users, 1)
+	})
+}
+
+func Test_Clone_Isolation(t *testing.T) {
+	a := require.New(t)
+
+	q := PDB.Where("id = ?", 1).Order("id desc").Select("id", "name").
+		Join("books b", "b.user_id = users.id AND b.title = ?", "Go").
+		GroupBy("id").Having("count(id) > ?", 1)
+	// leave room in the slices, so appends to the clone
+	// would write into the original arrays if they were shared.
+	q.whereClauses = append(make(clauses, 0, 10), q.whereClauses...)
+	q.orderClauses = append(make(clauses, 0, 10), q.orderClauses...)
+	q.addColumns = append(make([]string, 0, 10), q.addColumns...)
+	before, beforeArgs, err := q.ToSQL(&Model{Value: &User{}})
+	a.NoError(err)
+
+	c := Q(PDB)
+	q.Clone(c)
+	c.Where("name = ?", "mark").Order("name").Select("email")
+	c.whereClauses[0].Arguments[0] = 2
+	c.joinClauses[0].Arguments[0] = "Python"
+	c.havingClauses[0].Arguments[0] = 2
+	c.groupClauses[0].Field = "name"
+
+	after, afterArgs, err := q.ToSQL(&Model{Value: &User{}})
+	a.NoError(err)
+	a.Equal(before, after)
+	a.Equal(beforeArgs, afterArgs)
+	a.Equal([]interface{}{"Go", 1, 1}, afterArgs)
+}
+
+func Test_ToSQL_LimitOffsetInt64(t *testing.T) {
+	a := require.New(t)
+	transaction(func(tx *Connection) {
+		user := &Model{Value: &User{}}
+		s := "SELECT name as full_name, users.alive, users.bio, users.birth_date, users.created_at, users.email, users.id, users.name, users.price, users.updated_at, users.user_name FROM users AS users"
+
+		q := tx.LimitInt64(1 << 40).OffsetInt64(1 << 41).ToSQLString(user)
+		a.Equal(fmt.Sprintf("%s LIMIT 1099511627776 OFFSET 2199023255552", s), q)
+
+		q = tx.Limit(10).Offset(20).ToSQLString(user)
+		a.Equal(fmt.Sprintf("%s LIMIT 10 OFFSET 20", s), q)
+
+		q = tx.Offset(20).ToSQLString(user)
+		switch tx.Dialect.Name() {
+		case nameMySQL:
+			a.Equal(fmt.Sprintf("%s LIMIT 18446744073709551615 OFFSET 20", s), q)
+		case nameSQLite3:
+			a.Equal(fmt.Sprintf("%s LIMIT -1 OFFSET 20", s), q)
+		default:
+			a.Equal(fmt.Sprintf("%s OFFSET 20", s), q)
+		}
+
+		// pagination takes precedence
+		q = tx.Paginate(3, 10).OffsetInt64(5).ToSQLString(user)
+		a.Equal(fmt.Sprintf("%s LIMIT 10 OFFSET 20", s), q)
+	})
+}
+
+func Test_In(t *testing.T) {
+	r := require.New(t)
+
+	query, args, err := In("select * from orders where id in (?) and status = ?", []int{1, 2, 3}, "paid")
+	r.NoError(err)
+	r.Equal("select * from orders where id in (?, ?, ?) and status = ?", query)
+	r.Equal([]interface{}{1, 2, 3, "paid"}, args)
+
+	_, _, err = In("select * from orders where id in (?)", []int{})
+	r.Error(err)
+}
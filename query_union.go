@@ -0,0 +1,57 @@
+package pop
+
+// union is a query of a union, with the operator joining it to the
+// previous queries.
+type union struct {
+	query *Query
+	all   bool
+}
+
+// Union returns the union of the rows of q and other, without the
+// duplicate rows:
+//
+//	(SELECT ... FROM orders WHERE ...) UNION (SELECT ... FROM orders WHERE ...)
+//
+// Both queries select the columns of the model the union is run with, and
+// keep their own clauses: q and other can be modified without changing
+// the union. The Order, Limit, Offset and Paginate clauses set on the
+// returned query apply to the union, which can be run with All, First,
+// Count, Exists...
+//
+//	err := active.Union(archived).Order("created_at desc").All(ctx, &orders)
+//
+// The queries of a table sharing the schema of the model's table, e.g. an
+// archive table, are built with RawQuery.
+func (q *Query) Union(other *Query) *Query {
+	return q.union(other, false)
+}
+
+// UnionAll returns the union of the rows of q and other, like Union, but
+// keeps the duplicate rows.
+func (q *Query) UnionAll(other *Query) *Query {
+	return q.union(other, true)
+}
+
+func (q *Query) union(other *Query, all bool) *Query {
+	u := Q(q.Connection)
+	if len(q.unions) > 0 && len(q.orderClauses) == 0 && q.limitResults == 0 && q.offsetResults == 0 && q.Paginator == nil {
+		// the unions are flattened, while their clauses allow it
+		u.unions = append([]union(nil), q.unions...)
+	} else {
+		u.unions = []union{{query: cloneQuery(q)}}
+	}
+	u.unions = append(u.unions, union{query: cloneQuery(other), all: all})
+
+	u.err = q.err
+	if u.err == nil {
+		u.err = other.err
+	}
+	return u
+}
+
+// cloneQuery returns a deep copy of q.
+func cloneQuery(q *Query) *Query {
+	c := Q(q.Connection)
+	q.Clone(c)
+	return c
+}
@@ -0,0 +1,84 @@
+package pop
+
+import (
+	"testing"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Query_Union_SQL(t *testing.T) {
+	r := require.New(t)
+	m := &Model{Value: &latestBook{}}
+	cols := "books.id, books.title, books.user_id"
+
+	postgres, err := NewConnection(&ConnectionDetails{Dialect: "postgres", Host: "db.local", Database: "pop_test"})
+	r.NoError(err)
+	active := postgres.Where("user_id = ?", 1).Order("id desc").Limit(5)
+	archived := postgres.Where("title = ?", "Old")
+	q := active.Union(archived).UnionAll(postgres.Where("user_id = ?", 2)).Order("title").Limit(10)
+	sql, args, err := q.ToSQL(m)
+	r.NoError(err)
+	r.Equal("(SELECT "+cols+" FROM books AS books WHERE user_id = $1 ORDER BY id desc LIMIT 5) UNION (SELECT "+cols+" FROM books AS books WHERE title = $2) UNION ALL (SELECT "+cols+" FROM books AS books WHERE user_id = $3) ORDER BY title LIMIT 10", sql)
+	r.Equal([]interface{}{1, "Old", 2}, args)
+
+	// the queries of the union are copies
+	archived.Where("user_id = ?", 3)
+	sql = q.ToSQLString(m)
+	r.NotContains(sql, "user_id = $4")
+
+	// a union with clauses is a query of the next union
+	sql = q.Union(postgres.Where("id = ?", 4)).ToSQLString(m)
+	r.Contains(sql, "((SELECT ")
+	r.Contains(sql, " ORDER BY title LIMIT 10) UNION (SELECT ")
+
+	sqlite, err := NewConnection(&ConnectionDetails{Dialect: "sqlite3", Database: "pop_test.sqlite"})
+	r.NoError(err)
+	sql = sqlite.Where("user_id = ?", 1).UnionAll(sqlite.Where("user_id = ?", 2)).ToSQLString(m)
+	r.Equal("SELECT * FROM (SELECT "+cols+" FROM books AS books WHERE user_id = ?) UNION ALL SELECT * FROM (SELECT "+cols+" FROM books AS books WHERE user_id = ?)", sql)
+
+	q = postgres.RawQuery("select * from books where id = ?", 1).Union(postgres.Where("id = ?", 2))
+	sql, args, err = q.ToSQL(m)
+	r.NoError(err)
+	r.Equal("(select * from books where id = $1) UNION (SELECT "+cols+" FROM books AS books WHERE id = $2)", sql)
+	r.Equal([]interface{}{1, 2}, args)
+	r.Error(postgres.RawQuery("select 1").Where("id = ?", 1).Union(postgres.Q()).err)
+}
+
+func Test_Query_Union(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		user := User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(&user))
+		for _, b := range []Book{
+			{Title: "Active", Isbn: "U1", UserID: nulls.NewInt(user.ID)},
+			{Title: "Archived", Isbn: "U2", UserID: nulls.NewInt(user.ID)},
+			{Title: "Other", Isbn: "U3"},
+		} {
+			r.NoError(tx.Create(&b))
+		}
+
+		mine := tx.Where("user_id = ?", user.ID)
+		titled := tx.Where("title = ?", "Archived")
+		books := []latestBook{}
+		r.NoError(mine.Union(titled).Order("title desc").All(ctx, &books))
+		r.Len(books, 2)
+		r.Equal("Archived", books[0].Title)
+
+		books = []latestBook{}
+		r.NoError(mine.UnionAll(titled).Order("title asc").Limit(2).All(ctx, &books))
+		r.Len(books, 2)
+		r.Equal("Active", books[0].Title)
+		r.Equal("Archived", books[1].Title)
+
+		count, err := mine.UnionAll(titled).Count(&latestBook{})
+		r.NoError(err)
+		r.Equal(3, count)
+
+		exists, err := tx.Where("title = ?", "None").Union(tx.Where("title = ?", "Other")).Exists(&latestBook{})
+		r.NoError(err)
+		r.True(exists)
+	})
+}
@@ -0,0 +1,80 @@
+package pop
+
+import (
+	"strings"
+)
+
+// windowColumn is a column computed by a window function, or by another
+// expression, e.g. the rank of a Search.
+type windowColumn struct {
+	expr  string
+	alias string
+	args  []interface{}
+}
+
+// latestPerGroup keeps the latest row of each group of rows.
+type latestPerGroup struct {
+	partition string
+	orderBy   string
+}
+
+// windowFunctioner is implemented by the dialects which may not support
+// the window functions, depending on the database version.
+type windowFunctioner interface {
+	windowFunctions() bool
+}
+
+// supportsWindowFunctions tells if the database of the dialect supports
+// the window functions.
+func supportsWindowFunctions(d dialect) bool {
+	if wf, ok := d.(windowFunctioner); ok {
+		return wf.windowFunctions()
+	}
+	return d.Name() == namePostgreSQL || d.Name() == nameCockroach
+}
+
+// SelectWindow adds the result of a window function to the selected
+// columns, as alias. The model needs a field for alias, e.g. a read-only
+// `db:"rn" rw:"r"` field: the expression replaces its column.
+//
+//	q.SelectWindow("row_number() over (partition by order_id order by created_at desc)", "rn").All(ctx, &statuses)
+func (q *Query) SelectWindow(expr string, alias string) *Query {
+	if q.RawSQL.Fragment != "" {
+		return q.rawClause("SelectWindow")
+	}
+	q.windowColumns = append(q.windowColumns, windowColumn{expr: expr, alias: alias})
+	return q
+}
+
+// LatestPerGroup keeps the row with the latest orderBy value of each group
+// of rows having the same partition value, e.g. the latest status of each
+// order:
+//
+//	q.Where("state <> ?", "draft").LatestPerGroup("order_id", "created_at").All(ctx, &statuses)
+//
+// The rows are grouped after the Where and Join clauses, and the Order,
+// Limit and Paginate clauses apply to the latest rows. The databases
+// supporting the window functions keep one row per group, in a ROW_NUMBER
+// sub-select. The others, MySQL before 8.0 and SQLite before 3.25, use a
+// correlated sub-query keeping all the rows tied for the latest value.
+func (q *Query) LatestPerGroup(partition string, orderBy string) *Query {
+	if q.RawSQL.Fragment != "" {
+		return q.rawClause("LatestPerGroup")
+	}
+	q.latestPerGroup = &latestPerGroup{partition: partition, orderBy: orderBy}
+	return q
+}
+
+// qualifyColumn prefixes column with the table alias, unless it's
+// qualified already.
+func qualifyColumn(alias string, column string) string {
+	if strings.Contains(column, ".") {
+		return column
+	}
+	return alias + "." + column
+}
+
+// unqualifyColumn returns the name of the column, without its table.
+func unqualifyColumn(column string) string {
+	return column[strings.LastIndex(column, ".")+1:]
+}
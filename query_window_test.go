@@ -0,0 +1,95 @@
+package pop
+
+import (
+	"testing"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+type latestBook struct {
+	ID     int       `db:"id"`
+	Title  string    `db:"title"`
+	UserID nulls.Int `db:"user_id"`
+}
+
+func (latestBook) TableName() string {
+	return "books"
+}
+
+type rankedBook struct {
+	ID     int       `db:"id"`
+	Title  string    `db:"title"`
+	UserID nulls.Int `db:"user_id"`
+	Rank   int       `db:"rank" rw:"r"`
+}
+
+func (rankedBook) TableName() string {
+	return "books"
+}
+
+func Test_LatestPerGroup_SQL(t *testing.T) {
+	r := require.New(t)
+	m := &Model{Value: &latestBook{}}
+
+	postgres, err := NewConnection(&ConnectionDetails{Dialect: "postgres", Host: "db.local", Database: "pop_test"})
+	r.NoError(err)
+	sql, args, err := postgres.Where("title <> ?", "draft").LatestPerGroup("user_id", "id").Order("id desc").ToSQL(m)
+	r.NoError(err)
+	r.Equal("SELECT books.id, books.title, books.user_id FROM (SELECT books.id, books.title, books.user_id, ROW_NUMBER() OVER (PARTITION BY books.user_id ORDER BY books.id DESC) AS pop_row_number FROM books AS books WHERE title <> $1) AS books WHERE books.pop_row_number = 1 ORDER BY id desc", sql)
+	r.Equal([]interface{}{"draft"}, args)
+
+	// the server version is unknown until the connection is opened
+	my, err := NewConnection(&ConnectionDetails{Dialect: "mysql", Host: "db.local", Database: "pop_test"})
+	r.NoError(err)
+	sql, args, err = my.Where("title <> ?", "draft").LatestPerGroup("books.user_id", "id").ToSQL(m)
+	r.NoError(err)
+	inner := "SELECT books.id, books.title, books.user_id FROM books AS books WHERE title <> ?"
+	r.Equal("SELECT books.id, books.title, books.user_id FROM ("+inner+") AS books WHERE books.id = (SELECT MAX(pop_latest.id) FROM ("+inner+") AS pop_latest WHERE pop_latest.user_id = books.user_id)", sql)
+	r.Equal([]interface{}{"draft", "draft"}, args)
+
+	for version, ok := range map[string]bool{"5.7.31": false, "8.0.22": true, "10.1.48-MariaDB": false, "10.5.8-MariaDB-1:10.5.8+maria~focal": true} {
+		my.Dialect.(*mysql).setServerVersion(version)
+		r.Equal(ok, supportsWindowFunctions(my.Dialect), version)
+	}
+}
+
+func Test_SelectWindow_LatestPerGroup(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		user := User{Name: nulls.NewString("Mark")}
+		r.NoError(tx.Create(&user))
+		other := User{Name: nulls.NewString("Other")}
+		r.NoError(tx.Create(&other))
+		for _, b := range []Book{
+			{Title: "First", Isbn: "PB1", UserID: nulls.NewInt(user.ID)},
+			{Title: "Second", Isbn: "PB2", UserID: nulls.NewInt(user.ID)},
+			{Title: "Draft", Isbn: "PB3", UserID: nulls.NewInt(user.ID)},
+			{Title: "Only", Isbn: "PB4", UserID: nulls.NewInt(other.ID)},
+		} {
+			r.NoError(tx.Create(&b))
+		}
+
+		books := []rankedBook{}
+		q := tx.Q().SelectWindow("row_number() over (partition by user_id order by id desc)", "rank").Order("user_id asc, rank asc")
+		r.NoError(q.All(ctx, &books))
+		r.Len(books, 4)
+		r.Equal("Draft", books[0].Title)
+		r.Equal(1, books[0].Rank)
+		r.Equal("First", books[2].Title)
+		r.Equal(3, books[2].Rank)
+
+		latest := []latestBook{}
+		q = tx.Where("title <> ?", "Draft").LatestPerGroup("user_id", "id").Order("user_id asc")
+		r.NoError(q.All(ctx, &latest))
+		r.Len(latest, 2)
+		r.Equal("Second", latest[0].Title)
+		r.Equal("Only", latest[1].Title)
+
+		count, err := tx.Where("title <> ?", "Draft").LatestPerGroup("user_id", "id").Count(&latestBook{})
+		r.NoError(err)
+		r.Equal(2, count)
+	})
+}
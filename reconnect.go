@@ -0,0 +1,100 @@
+package pop
+
+import (
+	"context"
+	"time"
+
+	"github.com/gobuffalo/pop/logging"
+	"github.com/pkg/errors"
+)
+
+// Reconnect replaces the connection pool with a new one, opened with the
+// ConnectionDetails, once a new connection is checked. The old pool is
+// closed: the queries and transactions in progress are completed first.
+//
+// It's useful after a database restart or failover, and with a
+// ConnectionDetails.CredentialsProvider, when the credentials are revoked
+// before they expire. The pool is kept when the new connection fails, and
+// a closed connection isn't reconnected.
+func (c *Connection) Reconnect(ctx context.Context) error {
+	if c.TX != nil {
+		return errors.New("can't reconnect a transaction")
+	}
+	db, ok := rawDB(c.Store)
+	if !ok {
+		return errors.Errorf("unable to reconnect a %T", c.Store)
+	}
+	return c.reconnect(ctx, db)
+}
+
+// reconnect replaces the pool of db, see Reconnect. The dialect is set up
+// with the new pool, like it is by Open, before it's swapped.
+func (c *Connection) reconnect(ctx context.Context, db *dB) error {
+	p, err := openDB(c.Dialect)
+	if err != nil {
+		return errors.Wrap(err, "could not reconnect")
+	}
+	if err := p.PingContext(ctx); err != nil {
+		p.Close()
+		return errors.Wrap(err, "could not reconnect")
+	}
+	if d, ok := c.Dialect.(afterOpenable); ok {
+		cn := c.copy()
+		cn.Store = newDB(p)
+		if err := d.AfterOpen(cn); err != nil {
+			p.Close()
+			return errors.Wrap(err, "could not reconnect")
+		}
+	}
+	old, err := db.swap(p)
+	if err != nil {
+		p.Close()
+		return errors.Wrap(err, "could not reconnect")
+	}
+	if err := old.Close(); err != nil {
+		c.log(logging.Warn, "could not close the replaced connection pool: %v", err)
+	}
+	return nil
+}
+
+// startHealthCheck checks the health of the pool db, opened by c, at each
+// interval, until the pool is closed: the health check is shared by the
+// copies and transactions of c, whichever closes the pool.
+func (c *Connection) startHealthCheck(db *dB, interval time.Duration) {
+	ctx, cancel := context.WithCancel(context.Background())
+	db.mu.Lock()
+	db.stopHealthCheck = cancel
+	db.mu.Unlock()
+	go func() {
+		t := time.NewTicker(interval)
+		defer t.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-t.C:
+				c.checkHealth(ctx, db, interval)
+			}
+		}
+	}()
+}
+
+// checkHealth pings the database of db, and reconnects it when the ping
+// fails. Each of them has to complete within timeout.
+func (c *Connection) checkHealth(ctx context.Context, db *dB, timeout time.Duration) error {
+	pctx, cancel := context.WithTimeout(ctx, timeout)
+	err := db.PingContext(pctx)
+	cancel()
+	if err == nil || ctx.Err() != nil {
+		return nil
+	}
+	c.log(logging.Warn, "database health check failed, reconnecting: %v", err)
+
+	rctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if err := c.reconnect(rctx, db); err != nil {
+		c.log(logging.Error, "database health check: %v", err)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,66 @@
+package pop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Connection_Reconnect(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	c, err := NewConnection(&ConnectionDetails{URL: "sqlite:///tmp/reconnect.db"})
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+
+	db, ok := rawDB(c.Store)
+	r.True(ok)
+	old := db.pool()
+	r.NoError(c.Reconnect(ctx))
+	r.NotEqual(old, db.pool())
+	r.Error(old.PingContext(ctx))
+	r.NoError(c.RawQuery("select 1").Exec())
+
+	// the health check replaces a pool which can't connect anymore
+	db.pool().Close()
+	r.Error(db.PingContext(ctx))
+	r.NoError(c.checkHealth(ctx, db, time.Second))
+	r.NoError(c.RawQuery("select 1").Exec())
+
+	r.NoError(c.Rollback(func(tx *Connection) {
+		r.Error(tx.Reconnect(ctx))
+	}))
+
+	// a closed connection isn't reconnected, even through a copy
+	r.NoError(c.copy().Close())
+	r.Error(c.Reconnect(ctx))
+	r.Error(c.checkHealth(ctx, db, time.Second))
+}
+
+func Test_Connection_HealthCheckInterval(t *testing.T) {
+	r := require.New(t)
+
+	c, err := NewConnection(&ConnectionDetails{
+		URL:                 "sqlite:///tmp/reconnect.db",
+		HealthCheckInterval: 10 * time.Millisecond,
+	})
+	r.NoError(err)
+	r.NoError(c.Open())
+
+	db, _ := rawDB(c.Store)
+	r.NotNil(db.stopHealthCheck)
+	db.pool().Close()
+	r.Eventually(func() bool {
+		return db.PingContext(context.Background()) == nil
+	}, time.Second, 10*time.Millisecond)
+
+	// closing a copy stops the health check of the shared pool
+	r.NoError(c.copy().Close())
+	old := db.pool()
+	time.Sleep(50 * time.Millisecond)
+	r.True(old == db.pool())
+}
@@ -0,0 +1,286 @@
+package pop
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/gobuffalo/pop/logging"
+	"github.com/jmoiron/sqlx/reflectx"
+)
+
+// Redact returns the placeholder of a redacted arg: "[REDACTED]" followed
+// by a short hash of the value, so the same values can be matched across
+// the logs without being revealed, e.g. "[REDACTED:9f86d081]".
+//
+// The args of the columns of the fields tagged `log:"redact"`, and of the
+// columns and positions of ConnectionDetails.RedactColumns and RedactArgs,
+// are redacted wherever the args are logged: the logging.SQL messages,
+// the slow query warnings, the args of the QueryInfo of the observers, and
+// the tags of the association spans.
+//
+//	type User struct {
+//		ID       int    `db:"id"`
+//		Email    string `db:"email"`
+//		Password string `db:"password" log:"redact"`
+//	}
+//
+//	c.Where("password = ?", p).First(ctx, &user)
+//	// sql - SELECT ... WHERE password = ? | ["[REDACTED:5e884898]"]
+//
+// The tagged columns are redacted by name, in the statements of all the
+// tables, once a model of their field has been used by a connection: the
+// statements run before, e.g. raw queries, are redacted only if the models
+// are registered first by RegisterRedactedFields. An arg is
+// matched to its column when it's compared to it, e.g. `password = ?` or
+// `token IN (?, ?)`, or inserted into it by an INSERT. The short hash of a
+// guessable value, e.g. a PIN, can be reversed by trying all the values.
+func Redact(v interface{}) string {
+	sum := sha256.Sum256([]byte(fmt.Sprint(diffValue(v))))
+	return "[REDACTED:" + hex.EncodeToString(sum[:4]) + "]"
+}
+
+// redacts tells if the connection details redact any arg.
+func (cd *ConnectionDetails) redacts() bool {
+	return cd != nil && (len(cd.RedactColumns) > 0 || len(cd.RedactArgs) > 0)
+}
+
+// redactedTags holds the columns of the fields tagged `log:"redact"` of the
+// model types used by the connections.
+var redactedTags = struct {
+	sync.RWMutex
+	types   map[reflect.Type]bool
+	columns map[string]bool
+}{types: map[reflect.Type]bool{}, columns: map[string]bool{}}
+
+// RegisterRedactedFields registers the columns of the fields tagged
+// `log:"redact"` of the models, redacted in the logs of all the tables,
+// see Redact. The models used by the connections are registered on their
+// first use; registering them at startup redacts their columns in the
+// statements run before, e.g. the raw queries.
+//
+//	pop.RegisterRedactedFields(&User{}, &Account{})
+func RegisterRedactedFields(models ...interface{}) {
+	for _, m := range models {
+		registerRedactedFields(m)
+	}
+}
+
+// registerRedactedFields records the columns of the fields of the model
+// tagged `log:"redact"`.
+func registerRedactedFields(model interface{}) {
+	if model == nil {
+		return
+	}
+	if _, ok := model.(map[string]interface{}); ok {
+		return
+	}
+	t := reflectx.Deref(reflect.TypeOf(model))
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = reflectx.Deref(t.Elem())
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	redactedTags.RLock()
+	seen := redactedTags.types[t]
+	redactedTags.RUnlock()
+	if seen {
+		return
+	}
+	var cols []string
+	for _, fi := range strictMapper.TypeMap(t).Index {
+		if !fi.Embedded && fi.Field.Tag.Get("log") == "redact" {
+			cols = append(cols, strings.ToLower(fi.Name))
+		}
+	}
+	redactedTags.Lock()
+	redactedTags.types[t] = true
+	for _, col := range cols {
+		redactedTags.columns[col] = true
+	}
+	redactedTags.Unlock()
+}
+
+// redactedColumns returns the redacted columns of the connection details,
+// and of the tagged fields.
+func redactedColumns(deets *ConnectionDetails) map[string]bool {
+	redactedTags.RLock()
+	defer redactedTags.RUnlock()
+	if len(redactedTags.columns) == 0 && (deets == nil || len(deets.RedactColumns) == 0) {
+		return nil
+	}
+	cols := make(map[string]bool, len(redactedTags.columns))
+	for col := range redactedTags.columns {
+		cols[col] = true
+	}
+	if deets != nil {
+		for _, col := range deets.RedactColumns {
+			cols[strings.ToLower(col)] = true
+		}
+	}
+	return cols
+}
+
+// redactArgs returns the args of the statement query, with the args of
+// the redacted columns and positions replaced by their Redact placeholder.
+// The struct and map args of the named statements get their redacted
+// columns replaced.
+func redactArgs(deets *ConnectionDetails, query string, args []interface{}) []interface{} {
+	if len(args) == 0 {
+		return args
+	}
+	for _, a := range args {
+		// the models of the named statements
+		registerRedactedFields(a)
+	}
+	cols := redactedColumns(deets)
+	var positions []int
+	if deets != nil {
+		positions = deets.RedactArgs
+	}
+	if len(cols) == 0 && len(positions) == 0 {
+		return args
+	}
+	redacted := make(map[int]bool, len(positions))
+	for _, p := range positions {
+		redacted[p] = true
+	}
+	if len(cols) > 0 {
+		for i, col := range argColumns(query) {
+			if cols[col] {
+				redacted[i] = true
+			}
+		}
+	}
+	out := make([]interface{}, len(args))
+	for i, a := range args {
+		if redacted[i] {
+			out[i] = Redact(a)
+			continue
+		}
+		out[i] = redactNamedArg(a, cols)
+	}
+	return out
+}
+
+var (
+	rPlaceholder  = regexp.MustCompile(`\?|\$\d+`)
+	rComparedArg  = regexp.MustCompile(`(?i)([\w."` + "`" + `]+)\s*(=|<>|!=|<=|>=|<|>|\s(NOT\s+)?I?LIKE|\s(NOT\s+)?IN\s*\()\s*$`)
+	rListedArg    = regexp.MustCompile(`^\s*,\s*$`)
+	rInsertValues = regexp.MustCompile(`(?is)^\s*INSERT\s+INTO\s+\S+\s*\(([^)]*)\)\s*VALUES\b`)
+)
+
+// argColumns returns the columns of the args of the statement query, by
+// position: the columns they're compared to, or inserted into.
+func argColumns(query string) map[int]string {
+	cols := map[int]string{}
+	var inserted []string
+	valuesAt := -1
+	if m := rInsertValues.FindStringSubmatchIndex(query); m != nil {
+		for _, col := range strings.Split(query[m[2]:m[3]], ",") {
+			inserted = append(inserted, columnName(col))
+		}
+		valuesAt = m[1]
+	}
+	var prev, n, values int
+	var listed string
+	for _, loc := range rPlaceholder.FindAllStringIndex(query, -1) {
+		i := n
+		if p := query[loc[0]:loc[1]]; p != "?" {
+			fmt.Sscanf(p, "$%d", &i)
+			i--
+		}
+		n++
+		switch before := query[prev:loc[0]]; {
+		case valuesAt >= 0 && loc[0] >= valuesAt && len(inserted) > 0:
+			cols[i] = inserted[values%len(inserted)]
+			values++
+		case listed != "" && rListedArg.MatchString(before):
+			cols[i] = listed
+		default:
+			listed = ""
+			if m := rComparedArg.FindStringSubmatch(before); m != nil {
+				cols[i] = columnName(m[1])
+				if strings.HasSuffix(m[2], "(") {
+					listed = cols[i]
+				}
+			}
+		}
+		prev = loc[1]
+	}
+	return cols
+}
+
+// columnName returns the unqualified and unquoted name of a column.
+func columnName(col string) string {
+	col = strings.TrimSpace(col)
+	if i := strings.LastIndex(col, "."); i >= 0 {
+		col = col[i+1:]
+	}
+	return strings.ToLower(strings.Trim(col, "\"`"))
+}
+
+// redactNamedArg returns the arg of a named statement, a model or a map,
+// with its redacted columns replaced, or the arg as is.
+func redactNamedArg(arg interface{}, cols map[string]bool) interface{} {
+	if arg == nil {
+		return arg
+	}
+	var fields map[string]interface{}
+	switch a := arg.(type) {
+	case map[string]interface{}:
+		fields = a
+	default:
+		v := reflect.Indirect(reflect.ValueOf(arg))
+		if v.Kind() != reflect.Struct || v.Type() == timeType {
+			return arg
+		}
+		fm := strictMapper.FieldMap(v)
+		fields = make(map[string]interface{}, len(fm))
+		for name, f := range fm {
+			if !strings.Contains(name, ".") {
+				fields[name] = f.Interface()
+			}
+		}
+	}
+	var redacted map[string]interface{}
+	for name, value := range fields {
+		if !cols[strings.ToLower(name)] {
+			continue
+		}
+		if redacted == nil {
+			redacted = make(map[string]interface{}, len(fields))
+			for k, v := range fields {
+				redacted[k] = v
+			}
+		}
+		redacted[name] = Redact(value)
+	}
+	if redacted == nil {
+		return arg
+	}
+	return redacted
+}
+
+// redactingLogger returns l, or the default logger if l is nil, logging the
+// logging.SQL messages with their args redacted, only when they're emitted.
+func redactingLogger(l Logger, deets *ConnectionDetails) Logger {
+	return func(lvl logging.Level, s string, args ...interface{}) {
+		ll := l
+		if ll == nil {
+			ll = log
+		}
+		if !emits(ll, lvl) {
+			return
+		}
+		if lvl == logging.SQL {
+			args = redactArgs(deets, s, args)
+		}
+		ll(lvl, s, args...)
+	}
+}
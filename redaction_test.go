@@ -0,0 +1,119 @@
+package pop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gobuffalo/pop/logging"
+	"github.com/stretchr/testify/require"
+)
+
+type redactedAccount struct {
+	ID       int    `db:"id"`
+	Login    string `db:"login"`
+	Passcode string `db:"passcode" log:"redact"`
+}
+
+func (redactedAccount) TableName() string {
+	return "redacted_accounts"
+}
+
+func Test_Redact(t *testing.T) {
+	r := require.New(t)
+
+	r.True(strings.HasPrefix(Redact("secret"), "[REDACTED:"))
+	r.Len(Redact("secret"), len("[REDACTED:]")+8)
+	r.Equal(Redact("secret"), Redact("secret"))
+	s := "secret"
+	r.Equal(Redact("secret"), Redact(&s))
+	r.NotEqual(Redact("secret"), Redact("other"))
+}
+
+func Test_redactArgs(t *testing.T) {
+	r := require.New(t)
+	deets := &ConnectionDetails{RedactColumns: []string{"SSN"}, RedactArgs: []int{3}}
+	x := Redact
+
+	args := redactArgs(deets, "SELECT * FROM users WHERE users.ssn = ? AND name = ? AND id IN (?, ?)", []interface{}{"123", "mark", 1, 2})
+	r.Equal([]interface{}{x("123"), "mark", 1, x(2)}, args)
+
+	args = redactArgs(deets, `SELECT * FROM users WHERE name LIKE $2 AND "ssn" IN ($1, $3)`, []interface{}{"1", "mark", "3"})
+	r.Equal([]interface{}{x("1"), "mark", x("3")}, args)
+
+	args = redactArgs(deets, "INSERT INTO users (name, ssn) VALUES (?, ?), (?, ?)", []interface{}{"a", "1", "b", "2"})
+	r.Equal([]interface{}{"a", x("1"), "b", x("2")}, args)
+
+	args = redactArgs(nil, "SELECT * FROM users WHERE ssn = ?", []interface{}{"1"})
+	r.Equal([]interface{}{"1"}, args)
+
+	RegisterRedactedFields(&redactedAccount{})
+	args = redactArgs(nil, "UPDATE accounts SET passcode = ? WHERE login = ?", []interface{}{"1", "mark"})
+	r.Equal([]interface{}{x("1"), "mark"}, args)
+
+	a := redactedAccount{ID: 1, Login: "mark", Passcode: "1234"}
+	args = redactArgs(deets, "UPDATE accounts SET passcode = :passcode WHERE id = :id", []interface{}{a})
+	r.Equal([]interface{}{map[string]interface{}{"id": 1, "login": "mark", "passcode": x("1234")}}, args)
+	r.Equal("1234", a.Passcode)
+
+	args = redactArgs(deets, "UPDATE users SET ssn = :ssn", []interface{}{map[string]interface{}{"ssn": "1"}})
+	r.Equal([]interface{}{map[string]interface{}{"ssn": x("1")}}, args)
+}
+
+func Test_emits(t *testing.T) {
+	r := require.New(t)
+	debug := Debug
+	defer func() { Debug = debug }()
+
+	// the args of the messages dropped by the default logger aren't redacted
+	Debug = false
+	r.False(emits(defaultLogger, logging.SQL))
+	r.True(emits(defaultLogger, logging.Warn))
+	r.True(emits(func(logging.Level, string, ...interface{}) {}, logging.SQL))
+	Debug = true
+	r.True(emits(defaultLogger, logging.SQL))
+}
+
+func Test_Connection_Redaction(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	deets := *PDB.Dialect.Details()
+	deets.RedactColumns = []string{"login"}
+	c, err := NewConnection(&deets)
+	r.NoError(err)
+	r.NoError(c.Open())
+	defer c.Close()
+
+	r.NoError(c.Rollback(func(tx *Connection) {
+		var logged []string
+		var infos []QueryInfo
+		lc := tx.WithLogger(func(lvl logging.Level, s string, args ...interface{}) {
+			if lvl == logging.SQL {
+				logged = append(logged, fmt.Sprint(s, args))
+			}
+		})
+		lc.Observe(func(ctx context.Context, info QueryInfo) {
+			infos = append(infos, info)
+		})
+
+		a := &redactedAccount{Login: "mark", Passcode: "1234"}
+		r.NoError(lc.Create(a))
+		r.NoError(lc.Where("passcode = ?", "1234").Where("login = ?", "mark").First(ctx, a))
+		r.NoError(lc.Where("id = ?", a.ID).First(ctx, a))
+		r.Equal("1234", a.Passcode)
+
+		r.NotEmpty(logged)
+		for _, l := range logged {
+			r.NotContains(l, "1234")
+			r.NotContains(l, "mark")
+		}
+		r.Contains(strings.Join(logged, "\n"), Redact("1234"))
+		r.Contains(strings.Join(logged, "\n"), fmt.Sprint(a.ID))
+		for _, info := range infos {
+			r.NotContains(fmt.Sprint(info.Args), "1234")
+			r.NotContains(fmt.Sprint(info.Args), "mark")
+		}
+	}))
+}
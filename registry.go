@@ -0,0 +1,206 @@
+package pop
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gobuffalo/pop/logging"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/singleflight"
+)
+
+// registry holds the connections registered with Register. They're opened
+// on their first Get.
+var registry = struct {
+	sync.Mutex
+	conns  map[string]*registered
+	opener singleflight.Group
+}{conns: map[string]*registered{}}
+
+type registered struct {
+	details *ConnectionDetails
+	conn    *Connection
+	opened  bool
+}
+
+// reset replaces the closed connection of r with a new one, opened on the
+// next Get. The closed connection may have been handed out, so it's left
+// as is: its queries fail rather than use a pool being opened.
+func (r *registered) reset() error {
+	c, err := NewConnection(r.details)
+	if err != nil {
+		return err
+	}
+	c.name = r.conn.name
+	r.conn = c
+	r.opened = false
+	return nil
+}
+
+// ConnectionHealth is the health of a registered connection.
+type ConnectionHealth struct {
+	Name string `json:"name"`
+	// Connected is false until the first Get of the connection.
+	Connected bool `json:"connected"`
+	// Error of the last open or ping of the connection, if any.
+	Error   string        `json:"error,omitempty"`
+	Latency time.Duration `json:"latency"`
+}
+
+// Register adds a named connection to the registry, e.g. for the primary,
+// analytics and audit databases of an application. The connection is only
+// opened on its first Get. Registering a name twice returns an error.
+//
+//	err := pop.Register("analytics", &pop.ConnectionDetails{
+//		Dialect: "postgres",
+//		URL:     os.Getenv("ANALYTICS_DATABASE_URL"),
+//	})
+func Register(name string, details *ConnectionDetails) error {
+	c, err := NewConnection(details)
+	if err != nil {
+		return errors.Wrapf(err, "could not register connection %s", name)
+	}
+	c.name = name
+
+	registry.Lock()
+	defer registry.Unlock()
+	if _, ok := registry.conns[name]; ok {
+		return errors.Errorf("connection %s is already registered", name)
+	}
+	registry.conns[name] = &registered{details: details, conn: c}
+	return nil
+}
+
+// Get returns the registered connection, connecting to the database on
+// the first call. Concurrent first calls share the same connection pool.
+//
+//	c, err := pop.Get(ctx, "analytics")
+func Get(ctx context.Context, name string) (*Connection, error) {
+	registry.Lock()
+	r, ok := registry.conns[name]
+	var c *Connection
+	if ok && r.opened {
+		c = r.conn
+	}
+	registry.Unlock()
+	if !ok {
+		return nil, errors.Errorf("could not find connection named %s", name)
+	}
+	if c != nil {
+		return c, nil
+	}
+
+	ch := registry.opener.DoChan(name, func() (interface{}, error) {
+		registry.Lock()
+		c, opened := r.conn, r.opened
+		registry.Unlock()
+		if opened {
+			return c, nil
+		}
+		if err := c.Open(); err != nil {
+			return nil, err
+		}
+		// check the database is reachable, with a detached context so a
+		// caller giving up doesn't fail the other ones.
+		if err := pingStore(context.Background(), c.Store); err != nil {
+			c.Close()
+			registry.Lock()
+			defer registry.Unlock()
+			if rerr := r.reset(); rerr != nil {
+				log(logging.Warn, "unable to reset connection %s: %v", name, rerr)
+			}
+			return nil, err
+		}
+		registry.Lock()
+		r.opened = true
+		registry.Unlock()
+		return c, nil
+	})
+	select {
+	case <-ctx.Done():
+		return nil, errors.WithStack(ctx.Err())
+	case res := <-ch:
+		if res.Err != nil {
+			return nil, errors.Wrapf(res.Err, "couldn't open connection for %s", name)
+		}
+		return res.Val.(*Connection), nil
+	}
+}
+
+// Health pings the opened registered connections, and returns the health
+// of all the registered connections, ordered by name.
+func Health(ctx context.Context) []ConnectionHealth {
+	registry.Lock()
+	var names []string
+	conns := map[string]*Connection{}
+	for n, r := range registry.conns {
+		names = append(names, n)
+		if r.opened {
+			conns[n] = r.conn
+		}
+	}
+	registry.Unlock()
+	sort.Strings(names)
+
+	health := make([]ConnectionHealth, 0, len(names))
+	for _, n := range names {
+		h := ConnectionHealth{Name: n}
+		if c, ok := conns[n]; ok {
+			h.Connected = true
+			start := time.Now()
+			if err := pingStore(ctx, c.Store); err != nil {
+				h.Error = err.Error()
+			}
+			h.Latency = time.Since(start)
+		}
+		health = append(health, h)
+	}
+	return health
+}
+
+// CloseAll closes the opened registered connections, e.g. for a graceful
+// shutdown. The next Get returns a new connection, opened again: the
+// closed connections are left as is, and fail their next queries.
+func CloseAll() error {
+	registry.Lock()
+	defer registry.Unlock()
+	var errs []string
+	for n, r := range registry.conns {
+		if !r.opened {
+			continue
+		}
+		if err := r.conn.Close(); err != nil {
+			log(logging.Warn, "unable to close connection %s: %v", n, err)
+			errs = append(errs, n)
+		}
+		if err := r.reset(); err != nil {
+			log(logging.Warn, "unable to reset connection %s: %v", n, err)
+			errs = append(errs, n)
+		}
+	}
+	if len(errs) > 0 {
+		sort.Strings(errs)
+		return errors.Errorf("could not close connections %v", errs)
+	}
+	return nil
+}
+
+// pingStore checks the database of the store is reachable.
+func pingStore(ctx context.Context, s Store) error {
+	db, ok := rawDB(s)
+	if !ok {
+		return errors.Errorf("unable to ping a %T", s)
+	}
+	return errors.WithStack(db.PingContext(ctx))
+}
+
+type connectionNameKey struct{}
+
+// connectionName returns the name of the connection running the
+// query, as set in the context by runMiddlewares.
+func connectionName(ctx context.Context) string {
+	name, _ := ctx.Value(connectionNameKey{}).(string)
+	return name
+}
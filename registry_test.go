@@ -0,0 +1,75 @@
+package pop
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Registry(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+	defer func() {
+		CloseAll()
+		registry.Lock()
+		delete(registry.conns, "test_analytics")
+		registry.Unlock()
+	}()
+
+	deets := *PDB.Dialect.Details()
+	r.NoError(Register("test_analytics", &deets))
+	r.Error(Register("test_analytics", &deets))
+
+	_, err := Get(ctx, "test_unknown")
+	r.Error(err)
+
+	h := Health(ctx)
+	r.Contains(h, ConnectionHealth{Name: "test_analytics"})
+
+	conns := make([]*Connection, 10)
+	var wg sync.WaitGroup
+	for i := range conns {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c, err := Get(ctx, "test_analytics")
+			r.NoError(err)
+			conns[i] = c
+		}(i)
+	}
+	wg.Wait()
+	for _, c := range conns {
+		r.True(c == conns[0])
+	}
+	r.NotNil(conns[0].Store)
+
+	var names []string
+	conns[0].Use(func(ctx context.Context, op string, next func(ctx context.Context) error) error {
+		names = append(names, connectionName(ctx))
+		return next(ctx)
+	})
+	_, err = conns[0].Count(&User{})
+	r.NoError(err)
+	r.Equal([]string{"test_analytics"}, names)
+
+	for _, h := range Health(ctx) {
+		if h.Name == "test_analytics" {
+			r.True(h.Connected)
+			r.Empty(h.Error)
+		}
+	}
+
+	r.NoError(CloseAll())
+	// the closed connection isn't reopened under its holders
+	r.NotNil(conns[0].Store)
+	_, err = conns[0].Count(&User{})
+	r.Error(err)
+	c, err := Get(ctx, "test_analytics")
+	r.NoError(err)
+	r.False(c == conns[0])
+	r.NotNil(c.Store)
+	_, err = c.Count(&User{})
+	r.NoError(err)
+}
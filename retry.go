@@ -0,0 +1,166 @@
+package pop
+
+import (
+	"context"
+	"database/sql/driver"
+	"io"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/gobuffalo/pop/logging"
+	"github.com/pkg/errors"
+	"gopkg.in/DataDog/dd-trace-go.v1/ddtrace/tracer"
+)
+
+// RetryPolicy retries the operations failing with a transient error, e.g.
+// the first query run on a connection dropped by a database failover.
+// The reads are retried; the writes only when run from a connection
+// returned by Connection.Idempotent. The operations run in a transaction
+// are never retried, as the transaction is lost with the connection.
+type RetryPolicy struct {
+	// MaxRetries is the number of retries of an operation.
+	MaxRetries int
+	// Backoff is the wait before the first retry, doubled for each of the
+	// following ones. Defaults to 50ms.
+	Backoff time.Duration
+	// MaxBackoff caps the wait between two retries. Defaults to 2s.
+	MaxBackoff time.Duration
+}
+
+// opKind tells if an operation reads or writes, see Connection.timeFunc.
+type opKind int
+
+const (
+	// readOp is an operation retried by the RetryPolicy.
+	readOp opKind = iota
+	// writeOp is an operation retried only by the idempotent connections.
+	writeOp
+)
+
+// SetRetryPolicy makes the connection retry the operations failing with an
+// error the dialect considers transient. Connections created from c
+// (transactions, copies) inherit its policy.
+//
+//	c.SetRetryPolicy(pop.RetryPolicy{MaxRetries: 3})
+func (c *Connection) SetRetryPolicy(p RetryPolicy) {
+	if p.Backoff <= 0 {
+		p.Backoff = 50 * time.Millisecond
+	}
+	if p.MaxBackoff <= 0 {
+		p.MaxBackoff = 2 * time.Second
+	}
+	c.retryPolicy = &p
+}
+
+// Idempotent returns a copy of the connection whose writes are safe to
+// run twice, so they're retried as well by the RetryPolicy.
+//
+//	err := c.Idempotent().RawQuery("UPDATE users SET active = ? WHERE id = ?", true, id).Exec()
+func (c *Connection) Idempotent() *Connection {
+	cn := c.copy()
+	cn.idempotent = true
+	return cn
+}
+
+// withRetries runs fn, and runs it again while it fails with a transient
+// error, according to the retry policy of the connection.
+func (c *Connection) withRetries(ctx context.Context, op string, kind opKind, fn func() error) error {
+	p := c.retryPolicy
+	if p == nil || c.TX != nil || (kind == writeOp && !c.idempotent) {
+		return fn()
+	}
+	backoff := p.Backoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt > p.MaxRetries || !isTransientError(c.Dialect, err) {
+			return err
+		}
+		c.log(logging.Warn, "%s failed with a transient error, retrying (%d/%d): %v", op, attempt, p.MaxRetries, err)
+		if span, ok := tracer.SpanFromContext(ctx); ok {
+			span.SetTag("pop.retries", attempt)
+		}
+		if !sleepBackoff(ctx, &backoff, p) {
+			return err
+		}
+	}
+}
+
+// sleepBackoff waits for the backoff, and doubles it up to the maximum of
+// the policy. It returns false if ctx is done first.
+func sleepBackoff(ctx context.Context, backoff *time.Duration, p *RetryPolicy) bool {
+	t := time.NewTimer(*backoff)
+	select {
+	case <-ctx.Done():
+		t.Stop()
+		return false
+	case <-t.C:
+	}
+	if *backoff *= 2; *backoff > p.MaxBackoff {
+		*backoff = p.MaxBackoff
+	}
+	return true
+}
+
+// transactionRetrier is implemented by the dialects whose transactions
+// fail with errors asking to run them again, e.g. the serialization
+// failures of CockroachDB.
+type transactionRetrier interface {
+	isRetryableTransactionError(err error) bool
+}
+
+// defaultTransactionRetries is the retry policy of the transactions of the
+// connections without one.
+var defaultTransactionRetries = RetryPolicy{MaxRetries: 5, Backoff: 50 * time.Millisecond, MaxBackoff: 2 * time.Second}
+
+// withTransactionRetries runs the transaction fn, and runs it again while
+// it fails with an error the dialect asks to retry. The nested
+// transactions are retried with the outer one.
+func (c *Connection) withTransactionRetries(ctx context.Context, fn func() error) error {
+	tr, ok := c.Dialect.(transactionRetrier)
+	if !ok || c.TX != nil {
+		return fn()
+	}
+	p := c.retryPolicy
+	if p == nil {
+		p = &defaultTransactionRetries
+	}
+	backoff := p.Backoff
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if err == nil || attempt > p.MaxRetries || !tr.isRetryableTransactionError(errors.Cause(err)) {
+			return err
+		}
+		c.log(logging.Warn, "transaction failed with a retryable error, retrying (%d/%d): %v", attempt, p.MaxRetries, err)
+		if !sleepBackoff(ctx, &backoff, p) {
+			return err
+		}
+	}
+}
+
+// transientErrorChecker is implemented by the dialects recognizing their
+// driver errors caused by a lost connection.
+type transientErrorChecker interface {
+	isTransientError(err error) bool
+}
+
+// isTransientError tells if err is caused by a lost connection, so the
+// operation could succeed on another connection.
+func isTransientError(d dialect, err error) bool {
+	err = errors.Cause(err)
+	switch err {
+	case driver.ErrBadConn, io.EOF, io.ErrUnexpectedEOF:
+		return true
+	}
+	if _, ok := err.(*net.OpError); ok {
+		return true
+	}
+	msg := err.Error()
+	if strings.Contains(msg, "connection reset by peer") || strings.Contains(msg, "broken pipe") {
+		return true
+	}
+	if tc, ok := d.(transientErrorChecker); ok {
+		return tc.isTransientError(err)
+	}
+	return false
+}
@@ -0,0 +1,177 @@
+package pop
+
+import (
+	"context"
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	_mysql "github.com/go-sql-driver/mysql"
+	"github.com/gobuffalo/nulls"
+	pg "github.com/lib/pq"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_Connection_RetryPolicy(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	c := PDB.copy()
+	c.SetRetryPolicy(RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond})
+
+	// failing is an operation failing with err, fails times
+	failing := func(fails int, err error) (func(context.Context, *Connection) error, *int) {
+		calls := 0
+		return func(context.Context, *Connection) error {
+			calls++
+			if calls <= fails {
+				return err
+			}
+			return nil
+		}, &calls
+	}
+
+	fn, calls := failing(2, driver.ErrBadConn)
+	r.NoError(c.timeFunc(ctx, "First", readOp, "", fn))
+	r.Equal(3, *calls)
+
+	fn, calls = failing(3, driver.ErrBadConn)
+	r.Equal(driver.ErrBadConn, errors.Cause(c.timeFunc(ctx, "All", readOp, "", fn)))
+	r.Equal(3, *calls)
+
+	fn, calls = failing(1, errors.New("syntax error"))
+	r.Error(c.timeFunc(ctx, "First", readOp, "", fn))
+	r.Equal(1, *calls)
+
+	// writes are only retried when idempotent
+	fn, calls = failing(1, driver.ErrBadConn)
+	r.Error(c.timeFunc(ctx, "Exec", writeOp, "", fn))
+	r.Equal(1, *calls)
+
+	fn, calls = failing(1, driver.ErrBadConn)
+	r.NoError(c.Idempotent().timeFunc(ctx, "Exec", writeOp, "", fn))
+	r.Equal(2, *calls)
+
+	// never in a transaction
+	r.NoError(c.Rollback(func(tx *Connection) {
+		fn, calls = failing(1, driver.ErrBadConn)
+		r.Error(tx.timeFunc(ctx, "First", readOp, "", fn))
+		r.Equal(1, *calls)
+	}))
+
+	// no policy, no retry
+	fn, calls = failing(1, driver.ErrBadConn)
+	r.Error(PDB.timeFunc(ctx, "First", readOp, "", fn))
+	r.Equal(1, *calls)
+}
+
+func Test_Query_Retries_DontDuplicateRows(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	c := PDB.copy()
+	c.SetRetryPolicy(RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond})
+	for _, name := range []string{"retried 1", "retried 2"} {
+		u := &User{Name: nulls.NewString(name)}
+		r.NoError(c.Create(u))
+		defer c.Destroy(u)
+	}
+
+	users := []User{{Name: nulls.NewString("kept")}}
+	q := c.Where("name LIKE ?", "retried%")
+	calls := 0
+	r.NoError(q.timeFunc(ctx, "All", readOp, &users, func(ctx context.Context) error {
+		calls++
+		if err := q.Connection.Dialect.SelectMany(q.Connection.Store, q.Connection.model(&users), *q); err != nil {
+			return err
+		}
+		if calls == 1 {
+			// the connection is lost after some rows were read
+			return driver.ErrBadConn
+		}
+		return nil
+	}))
+	r.Equal(2, calls)
+	r.Len(users, 3)
+	r.Equal("kept", users[0].Name.String)
+}
+
+// sqlStateError is a PostgreSQL error of a driver other than lib/pq, e.g.
+// pgx.
+type sqlStateError string
+
+func (e sqlStateError) Error() string    { return "ERROR (SQLSTATE " + string(e) + ")" }
+func (e sqlStateError) SQLState() string { return string(e) }
+
+func Test_isTransientError(t *testing.T) {
+	r := require.New(t)
+
+	pgd := &postgresql{}
+	crd := &cockroach{}
+	myd := &mysql{}
+
+	r.True(isTransientError(pgd, errors.Wrap(driver.ErrBadConn, "first")))
+	r.True(isTransientError(myd, errors.New("read tcp 10.0.0.1:5432: read: connection reset by peer")))
+	r.False(isTransientError(pgd, errors.New("syntax error")))
+
+	r.True(isTransientError(pgd, &pg.Error{Code: "08006"}))
+	r.True(isTransientError(pgd, &pg.Error{Code: "57P01"}))
+	r.False(isTransientError(pgd, &pg.Error{Code: "23505"}))
+	r.False(isTransientError(pgd, &pg.Error{Code: "40001"}))
+	r.True(isTransientError(crd, &pg.Error{Code: "40001"}))
+	r.True(isTransientError(pgd, sqlStateError("08006")))
+	r.False(isTransientError(pgd, sqlStateError("23505")))
+
+	r.True(isTransientError(myd, _mysql.ErrInvalidConn))
+	r.True(isTransientError(myd, &_mysql.MySQLError{Number: 1053}))
+	r.False(isTransientError(myd, &_mysql.MySQLError{Number: 1062}))
+}
+
+// retryingDialect is a dialect retrying the transactions failing with a
+// serialization failure, like CockroachDB.
+type retryingDialect struct {
+	dialect
+}
+
+func (retryingDialect) isRetryableTransactionError(err error) bool {
+	return err == sqlStateError("40001")
+}
+
+func Test_Connection_Transaction_Retries(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	c := PDB.copy()
+	c.Dialect = retryingDialect{PDB.Dialect}
+	c.SetRetryPolicy(RetryPolicy{MaxRetries: 2, Backoff: time.Millisecond})
+
+	attempts := 0
+	err := c.Transaction(ctx, func(ctx context.Context, tx *Connection) error {
+		if attempts++; attempts < 3 {
+			return sqlStateError("40001")
+		}
+		return nil
+	})
+	r.NoError(err)
+	r.Equal(3, attempts)
+
+	attempts = 0
+	err = c.Transaction(ctx, func(ctx context.Context, tx *Connection) error {
+		attempts++
+		return sqlStateError("40001")
+	})
+	r.Equal(sqlStateError("40001"), errors.Cause(err))
+	r.Equal(3, attempts)
+
+	attempts = 0
+	err = c.Transaction(ctx, func(ctx context.Context, tx *Connection) error {
+		attempts++
+		return sqlStateError("23505")
+	})
+	r.Error(err)
+	r.Equal(1, attempts)
+
+	r.True((&cockroach{}).isRetryableTransactionError(&pg.Error{Code: "40001"}))
+	r.False((&cockroach{}).isRetryableTransactionError(&pg.Error{Code: "08006"}))
+}
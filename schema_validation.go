@@ -0,0 +1,246 @@
+package pop
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+)
+
+// Schema checks the models against the schema of the database, see
+// SchemaValidator.Validate.
+var Schema = SchemaValidator{}
+
+// SchemaValidator checks that the tables of the models match their
+// structs, to catch a schema drift, e.g. a missing migration.
+type SchemaValidator struct {
+	// Nullable also reports the nullable columns of the fields which can't
+	// hold NULL, such as a string rather than a nulls.String.
+	Nullable bool
+}
+
+// SchemaMismatch is a difference between a model and its table.
+type SchemaMismatch struct {
+	// Model is the name of the type of the model, and Table its table.
+	Model string
+	Table string
+	// Field is the struct field of the column, empty when the table is
+	// missing.
+	Field  string
+	Column string
+	// Problem describes the mismatch, e.g. "no such column".
+	Problem string
+}
+
+func (m SchemaMismatch) String() string {
+	if m.Column == "" {
+		return fmt.Sprintf("%s (%s): %s", m.Table, m.Model, m.Problem)
+	}
+	return fmt.Sprintf("%s.%s (%s.%s): %s", m.Table, m.Column, m.Model, m.Field, m.Problem)
+}
+
+// SchemaError is returned by SchemaValidator.Validate with the mismatches
+// of the models and their tables.
+type SchemaError struct {
+	Mismatches []SchemaMismatch
+}
+
+func (e *SchemaError) Error() string {
+	msgs := make([]string, len(e.Mismatches))
+	for i, m := range e.Mismatches {
+		msgs[i] = m.String()
+	}
+	return fmt.Sprintf("the schema doesn't match the models: %s", strings.Join(msgs, "; "))
+}
+
+// Validate checks that the table of each model exists, and has a column
+// of a compatible type for each field with a db tag, e.g. to fail fast at
+// startup rather than on the first query:
+//
+//	if err := pop.Schema.Validate(ctx, c, &User{}, &Order{}); err != nil {
+//		log.Fatal(err)
+//	}
+//
+// The mismatches are returned as *SchemaError. The columns are read by
+// TableInfo, and cached like its tables. The types are compared loosely:
+// the columns of an integer field can be any integer, decimal or boolean
+// type, a string field any type, and the fields of a type it doesn't know,
+// e.g. a custom sql.Scanner, any type. The read-only fields (rw:"r") and
+// the fields with a select tag aren't columns, they're skipped.
+func (s SchemaValidator) Validate(ctx context.Context, c *Connection, models ...interface{}) error {
+	var mismatches []SchemaMismatch
+	for _, model := range models {
+		m := c.modelContext(ctx, model)
+		t := reflectx.Deref(reflect.TypeOf(model))
+		if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+			t = reflectx.Deref(t.Elem())
+		}
+		if t.Kind() != reflect.Struct {
+			return errors.Errorf("%T is not a model", model)
+		}
+		name := m.typeName(t)
+		ti, err := c.TableInfo(ctx, m.TableName())
+		if errors.Cause(err) == ErrTableNotFound {
+			mismatches = append(mismatches, SchemaMismatch{Model: name, Table: m.TableName(), Problem: "no such table"})
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		for _, fi := range strictMapper.TypeMap(t).Index {
+			if fi.Embedded || strings.Contains(fi.Path, ".") || fi.Field.Tag.Get("db") == "" {
+				continue
+			}
+			if fi.Field.Tag.Get("rw") == "r" || fi.Field.Tag.Get("select") != "" {
+				continue
+			}
+			mm := SchemaMismatch{Model: name, Table: ti.Name, Field: fi.Field.Name, Column: fi.Name}
+			col, ok := ti.Column(fi.Name)
+			switch {
+			case !ok:
+				mm.Problem = "no such column"
+			case !schemaCompatible(fi.Field.Type, col.Type):
+				mm.Problem = fmt.Sprintf("a %s field can't hold a %s column", fi.Field.Type, col.Type)
+			case s.Nullable && col.Nullable && !schemaNullable(fi.Field.Type):
+				mm.Problem = fmt.Sprintf("a %s field can't hold the NULLs of the column", fi.Field.Type)
+			default:
+				continue
+			}
+			mismatches = append(mismatches, mm)
+		}
+	}
+	if len(mismatches) > 0 {
+		return &SchemaError{Mismatches: mismatches}
+	}
+	return nil
+}
+
+// The kinds of values of the fields and columns compared by Validate.
+const (
+	schemaAny    = ""
+	schemaInt    = "int"
+	schemaFloat  = "float"
+	schemaBool   = "bool"
+	schemaString = "string"
+	schemaTime   = "time"
+	schemaUUID   = "uuid"
+	schemaBytes  = "bytes"
+	schemaJSON   = "json"
+)
+
+// schemaCompatibles are the kinds of the columns a field of a kind can
+// hold, the kinds missing holding any column.
+var schemaCompatibles = map[string][]string{
+	schemaInt:   {schemaInt, schemaFloat, schemaBool},
+	schemaFloat: {schemaFloat, schemaInt},
+	schemaBool:  {schemaBool, schemaInt},
+	schemaTime:  {schemaTime},
+	schemaUUID:  {schemaUUID, schemaString, schemaBytes},
+	schemaBytes: {schemaBytes, schemaString, schemaJSON},
+}
+
+// schemaCompatible tells if a field of type t can hold a column of type
+// colType.
+func schemaCompatible(t reflect.Type, colType string) bool {
+	kinds, ok := schemaCompatibles[fieldKind(t)]
+	ck := columnKind(colType)
+	if !ok || ck == schemaAny {
+		return true
+	}
+	for _, k := range kinds {
+		if k == ck {
+			return true
+		}
+	}
+	return false
+}
+
+// fieldKind returns the kind of the values of a field of type t: the one
+// of their Time, Int64, String… field for the nullable structs, such as
+// nulls.Int64 and sql.NullString.
+func fieldKind(t reflect.Type) string {
+	t = reflectx.Deref(t)
+	switch t {
+	case timeType:
+		return schemaTime
+	case uuidType:
+		return schemaUUID
+	}
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if t == durationType {
+			return schemaAny
+		}
+		return schemaInt
+	case reflect.Float32, reflect.Float64:
+		return schemaFloat
+	case reflect.Bool:
+		return schemaBool
+	case reflect.String:
+		return schemaString
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return schemaBytes
+		}
+	case reflect.Struct:
+		if vf, ok := t.FieldByName("Valid"); ok && vf.Type.Kind() == reflect.Bool && t.NumField() == 2 {
+			for i := 0; i < t.NumField(); i++ {
+				if f := t.Field(i); f.Name != "Valid" {
+					return fieldKind(f.Type)
+				}
+			}
+		}
+	}
+	return schemaAny
+}
+
+// schemaNullable tells if a field of type t can hold NULL: a pointer, a
+// slice, a map or a nullable struct.
+func schemaNullable(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Map, reflect.Interface:
+		return true
+	case reflect.Struct:
+		if vf, ok := t.FieldByName("Valid"); ok && vf.Type.Kind() == reflect.Bool {
+			return true
+		}
+	}
+	// a custom sql.Scanner may hold NULL
+	return fieldKind(t) == schemaAny
+}
+
+// columnKind returns the kind of the values of a column of the database
+// type colType, as given by TableInfo.
+func columnKind(colType string) string {
+	t := strings.ToLower(colType)
+	if strings.HasPrefix(t, "tinyint(1)") {
+		return schemaBool
+	}
+	if i := strings.Index(t, "("); i >= 0 {
+		t = t[:i]
+	}
+	t = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(t), " unsigned"))
+	switch {
+	case t == "uuid":
+		return schemaUUID
+	case t == "bigint" || t == "int8" || t == "bigserial" || genIntTypes[t]:
+		return schemaInt
+	case t == "bool" || t == "boolean":
+		return schemaBool
+	case t == "numeric" || t == "decimal" || t == "real" || strings.HasPrefix(t, "float") || strings.HasPrefix(t, "double"):
+		return schemaFloat
+	case strings.HasPrefix(t, "timestamp") || strings.HasPrefix(t, "date") || strings.HasPrefix(t, "time"):
+		return schemaTime
+	case t == "json" || t == "jsonb":
+		return schemaJSON
+	case t == "bytea" || strings.HasSuffix(t, "blob") || strings.HasSuffix(t, "binary"):
+		return schemaBytes
+	case strings.Contains(t, "char") || strings.HasSuffix(t, "text") || t == "clob":
+		return schemaString
+	}
+	return schemaAny
+}
@@ -0,0 +1,78 @@
+package pop
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+type schemaAccount struct {
+	ID        int          `db:"id"`
+	Name      string       `db:"name"`
+	Balance   float64      `db:"balance"`
+	Active    bool         `db:"active"`
+	Note      nulls.String `db:"note"`
+	Rank      int          `db:"rank" rw:"r"`
+	Cache     string       `db:"-"`
+	CreatedAt time.Time    `db:"created_at"`
+	UpdatedAt time.Time    `db:"updated_at"`
+}
+
+func (schemaAccount) TableName() string {
+	return "schema_accounts"
+}
+
+type driftedAccount struct {
+	ID        int       `db:"id"`
+	Name      string    `db:"name"`
+	Balance   time.Time `db:"balance"`
+	Email     string    `db:"email"`
+	Note      string    `db:"note"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+func (driftedAccount) TableName() string {
+	return "schema_accounts"
+}
+
+type missingAccount struct {
+	ID int `db:"id"`
+}
+
+func Test_Schema_Validate(t *testing.T) {
+	transaction(func(c *Connection) {
+		r := require.New(t)
+		ctx := context.Background()
+
+		r.NoError(Schema.Validate(ctx, c, &schemaAccount{}, &[]schemaAccount{}))
+		r.NoError(SchemaValidator{Nullable: true}.Validate(ctx, c, &schemaAccount{}))
+		if schema := testSchema(); schema != "" {
+			// the tables qualified by the schema are read from it
+			r.NoError(Schema.Validate(ctx, c.WithSchema(schema), &schemaAccount{}))
+		}
+
+		// the column types are the ones of the dialect, e.g. decimal(10,0)
+		ti, err := c.TableInfo(ctx, "schema_accounts")
+		r.NoError(err)
+		balance, ok := ti.Column("balance")
+		r.True(ok)
+
+		err = Schema.Validate(ctx, c, &driftedAccount{}, missingAccount{})
+		serr, ok := err.(*SchemaError)
+		r.True(ok, "%v", err)
+		r.Equal([]SchemaMismatch{
+			{Model: "driftedAccount", Table: "schema_accounts", Field: "Balance", Column: "balance", Problem: "a time.Time field can't hold a " + balance.Type + " column"},
+			{Model: "driftedAccount", Table: "schema_accounts", Field: "Email", Column: "email", Problem: "no such column"},
+			{Model: "missingAccount", Table: "missing_accounts", Problem: "no such table"},
+		}, serr.Mismatches)
+		r.Contains(err.Error(), "schema_accounts.email (driftedAccount.Email): no such column")
+
+		err = SchemaValidator{Nullable: true}.Validate(ctx, c, &driftedAccount{})
+		r.Contains(err.Error(), "schema_accounts.note (driftedAccount.Note): a string field can't hold the NULLs of the column")
+
+		r.Error(Schema.Validate(ctx, c, 1))
+	})
+}
@@ -13,13 +13,13 @@ func Test_Scopes(t *testing.T) {
 	m := &Model{Value: &Enemy{}}
 
 	q := PDB.Q()
-	s, _ := q.ToSQL(m)
+	s := q.ToSQLString(m)
 	r.Equal(oql, s)
 
 	q.Scope(func(qy *Query) *Query {
 		return qy.Where("id = ?", 1)
 	})
 
-	s, _ = q.ToSQL(m)
+	s = q.ToSQLString(m)
 	r.Equal(ts(oql+" WHERE id = ?"), s)
 }
@@ -0,0 +1,163 @@
+package pop
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// SQLiteWAL returns the SQLite pragmas of the concurrent applications, for
+// ConnectionDetails.SQLitePragmas: the write-ahead log, which lets the
+// reads run during a write, the NORMAL synchronous mode, safe with the
+// write-ahead log, and the checks of the foreign keys, disabled by default.
+//
+//	deets := &pop.ConnectionDetails{
+//		Dialect:       "sqlite3",
+//		Database:      "app.sqlite",
+//		SQLitePragmas: pop.SQLiteWAL(),
+//	}
+func SQLiteWAL() map[string]string {
+	return map[string]string{
+		"journal_mode": "WAL",
+		"synchronous":  "NORMAL",
+		"foreign_keys": "ON",
+	}
+}
+
+var (
+	rPragmaName  = regexp.MustCompile(`^\w+(\.\w+)?$`)
+	rPragmaValue = regexp.MustCompile(`^-?\w+$`)
+)
+
+// pragmaStatements returns the PRAGMA statements setting the pragmas, in
+// the order of their names.
+func pragmaStatements(pragmas map[string]string) ([]string, error) {
+	names := make([]string, 0, len(pragmas))
+	for name, value := range pragmas {
+		if !rPragmaName.MatchString(name) {
+			return nil, errors.Errorf("invalid pragma name %q", name)
+		}
+		if !rPragmaValue.MatchString(value) {
+			return nil, errors.Errorf("invalid value %q of pragma %s", value, name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	stmts := make([]string, 0, len(names))
+	for _, name := range names {
+		stmts = append(stmts, fmt.Sprintf("PRAGMA %s = %s", name, pragmas[name]))
+	}
+	return stmts, nil
+}
+
+// sessionConfigurer is implemented by the dialects configuring each new
+// connection of the pool with statements, e.g. the SQLite pragmas which
+// aren't persisted in the database.
+type sessionConfigurer interface {
+	// sessionStatements returns the statements to run on each new
+	// connection, if any.
+	sessionStatements() ([]string, error)
+}
+
+// sessionStatements returns the statements to run on each new connection
+// of the dialect.
+func sessionStatements(d dialect) ([]string, error) {
+	sc, ok := d.(sessionConfigurer)
+	if !ok {
+		return nil, nil
+	}
+	stmts, err := sc.sessionStatements()
+	return stmts, errors.Wrap(err, "could not configure the connections")
+}
+
+// openWithSession opens a database pool whose new connections run the
+// session statements of the dialect.
+func openWithSession(d dialect, dsn string, stmts []string) (*sqlx.DB, error) {
+	drv, err := openDriver(d.Details().driverName())
+	if err != nil {
+		return nil, err
+	}
+	cn := sessionConnector{connector: dsnConnector{driver: drv, dsn: dsn}, stmts: stmts}
+	return sqlx.NewDb(sql.OpenDB(cn), d.Details().driverName()), nil
+}
+
+// openDriver returns the registered database/sql driver.
+func openDriver(name string) (driver.Driver, error) {
+	// sql.Open doesn't connect, it's only used to get the driver
+	db, err := sql.Open(name, "")
+	if err != nil {
+		return nil, err
+	}
+	defer db.Close()
+	return db.Driver(), nil
+}
+
+// dsnConnector is a database/sql connector opening the connections to a
+// dsn with a driver.
+type dsnConnector struct {
+	driver driver.Driver
+	dsn    string
+}
+
+func (c dsnConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	if dc, ok := c.driver.(driver.DriverContext); ok {
+		cn, err := dc.OpenConnector(c.dsn)
+		if err != nil {
+			return nil, err
+		}
+		return cn.Connect(ctx)
+	}
+	return c.driver.Open(c.dsn)
+}
+
+func (c dsnConnector) Driver() driver.Driver {
+	return c.driver
+}
+
+// sessionConnector is a database/sql connector running statements on the
+// connections it opens, before they're used.
+type sessionConnector struct {
+	connector driver.Connector
+	stmts     []string
+}
+
+func (c sessionConnector) Connect(ctx context.Context) (driver.Conn, error) {
+	conn, err := c.connector.Connect(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, stmt := range c.stmts {
+		if err := execConn(ctx, conn, stmt); err != nil {
+			conn.Close()
+			return nil, errors.Wrapf(err, "could not run %q on the new connection", stmt)
+		}
+	}
+	return conn, nil
+}
+
+func (c sessionConnector) Driver() driver.Driver {
+	return c.connector.Driver()
+}
+
+// execConn runs the statement, without args, on a driver connection.
+func execConn(ctx context.Context, conn driver.Conn, stmt string) error {
+	if e, ok := conn.(driver.ExecerContext); ok {
+		_, err := e.ExecContext(ctx, stmt, nil)
+		if err != driver.ErrSkip {
+			return err
+		}
+	}
+	st, err := conn.Prepare(stmt)
+	if err != nil {
+		return err
+	}
+	defer st.Close()
+	_, err = st.Exec(nil)
+	return err
+}
@@ -0,0 +1,384 @@
+package pop
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+	"golang.org/x/sync/errgroup"
+)
+
+// ShardResolver returns the index of the shard of a shard key, in the
+// shards of a ShardedConnection.
+type ShardResolver func(shardKey interface{}) int
+
+// ShardKeyer is implemented by the models of a sharded connection: their
+// shard key routes them to their shard.
+//
+//	func (u User) ShardKey() interface{} {
+//		return u.ID
+//	}
+type ShardKeyer interface {
+	ShardKey() interface{}
+}
+
+// ErrNoShardKey is returned when creating a model on a sharded connection,
+// or on one of its shards, which has no shard key: it must implement
+// ShardKeyer, and return a non-nil key.
+var ErrNoShardKey = errors.New("no shard key")
+
+// CrossShardError is returned when a model is written on a shard other
+// than the one of its shard key, typically by a transaction: the
+// transactions are per-shard only.
+type CrossShardError struct {
+	// Shard is the index of the shard the model was written on, and
+	// KeyShard the one of the shard of its key.
+	Shard    int
+	KeyShard int
+	Key      interface{}
+}
+
+func (e *CrossShardError) Error() string {
+	return fmt.Sprintf("the shard key %v of shard %d can't be written on shard %d", e.Key, e.KeyShard, e.Shard)
+}
+
+// ShardedConnection routes the queries across the connections of a
+// horizontally sharded database, by the shard keys of their models.
+//
+//	sc, err := pop.NewShardedConnection(shards, func(key interface{}) int {
+//		return key.(int) % len(shards)
+//	})
+//	err = sc.Create(&user) // on the shard of user.ShardKey()
+//	c, err := sc.Shard(user.ID)
+//	err = c.Find(ctx, &user, user.ID)
+//	err = sc.Q().Order("name").AllShards(ctx, &users, "name")
+type ShardedConnection struct {
+	shards  []*Connection
+	resolve ShardResolver
+}
+
+// connectionShard is the shard of a connection of a ShardedConnection.
+type connectionShard struct {
+	sc    *ShardedConnection
+	index int
+}
+
+// NewShardedConnection returns a connection routing the queries across
+// shards with resolve. The shards keep their configuration, and are
+// closed by Close.
+func NewShardedConnection(shards []*Connection, resolve ShardResolver) (*ShardedConnection, error) {
+	if len(shards) == 0 {
+		return nil, errors.New("a sharded connection needs at least one shard")
+	}
+	if resolve == nil {
+		return nil, errors.New("a sharded connection needs a shard resolver")
+	}
+	sc := &ShardedConnection{resolve: resolve}
+	for i, c := range shards {
+		cn := c.copy()
+		cn.shard = &connectionShard{sc: sc, index: i}
+		sc.shards = append(sc.shards, cn)
+	}
+	return sc, nil
+}
+
+// Shards returns the connections of the shards, in the order of their
+// indexes.
+func (sc *ShardedConnection) Shards() []*Connection {
+	return append([]*Connection(nil), sc.shards...)
+}
+
+// Shard returns the connection of the shard of a shard key.
+func (sc *ShardedConnection) Shard(key interface{}) (*Connection, error) {
+	i, err := sc.index(key)
+	if err != nil {
+		return nil, err
+	}
+	return sc.shards[i], nil
+}
+
+// index resolves the index of the shard of a key.
+func (sc *ShardedConnection) index(key interface{}) (int, error) {
+	if key == nil {
+		return 0, ErrNoShardKey
+	}
+	i := sc.resolve(key)
+	if i < 0 || i >= len(sc.shards) {
+		return 0, errors.Errorf("the shard key %v resolves to shard %d, out of the %d shards", key, i, len(sc.shards))
+	}
+	return i, nil
+}
+
+// Create creates the model on the shard of its shard key, or each model of
+// a slice on its own shard. ErrNoShardKey is returned if a model has no
+// shard key.
+func (sc *ShardedConnection) Create(model interface{}, excludeColumns ...string) error {
+	return sc.shards[0].model(model).iterate(func(m *Model) error {
+		key, _ := shardKey(m)
+		c, err := sc.Shard(key)
+		if err != nil {
+			return errors.Wrapf(err, "could not create %s", m.TableName())
+		}
+		return c.Create(m.Value, excludeColumns...)
+	})
+}
+
+// Transaction runs fn in a transaction of the shard of key. The
+// transactions are per-shard only: the writes of models of other shards
+// fail with a CrossShardError.
+func (sc *ShardedConnection) Transaction(ctx context.Context, key interface{}, fn func(ctx context.Context, tx *Connection) error) error {
+	c, err := sc.Shard(key)
+	if err != nil {
+		return err
+	}
+	return c.Transaction(ctx, fn)
+}
+
+// Q returns a query run across the shards by AllShards. Its other finders
+// read the first shard only.
+func (sc *ShardedConnection) Q() *Query {
+	q := Q(sc.shards[0])
+	q.shards = sc
+	return q
+}
+
+// Where returns a query run across the shards by AllShards, see
+// Connection.Where.
+func (sc *ShardedConnection) Where(stmt string, args ...interface{}) *Query {
+	return sc.Q().Where(stmt, args...)
+}
+
+// Close closes the connections of the shards.
+func (sc *ShardedConnection) Close() error {
+	var errs []string
+	for i, c := range sc.shards {
+		if err := c.Close(); err != nil {
+			errs = append(errs, fmt.Sprintf("shard %d: %s", i, err))
+		}
+	}
+	if len(errs) > 0 {
+		return errors.Errorf("couldn't close the shards: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// checkShard returns an error if the model is written on a connection of a
+// shard other than the one of its shard key. A created model must have a
+// shard key.
+func (c *Connection) checkShard(m *Model, create bool) error {
+	if c.shard == nil {
+		return nil
+	}
+	key, ok := shardKey(m)
+	if !ok {
+		if create {
+			return errors.Wrapf(ErrNoShardKey, "could not create %s on shard %d", m.TableName(), c.shard.index)
+		}
+		return nil
+	}
+	i, err := c.shard.sc.index(key)
+	if err != nil {
+		return err
+	}
+	if i != c.shard.index {
+		return &CrossShardError{Shard: c.shard.index, KeyShard: i, Key: key}
+	}
+	return nil
+}
+
+// shardKey returns the shard key of the model, if it has one.
+func shardKey(m *Model) (interface{}, bool) {
+	sk, ok := m.Value.(ShardKeyer)
+	if !ok {
+		sk, ok = reflect.Indirect(reflect.ValueOf(m.Value)).Interface().(ShardKeyer)
+	}
+	if !ok {
+		return nil, false
+	}
+	key := sk.ShardKey()
+	return key, key != nil
+}
+
+// AllShards reads the rows of the query from every shard of its sharded
+// connection concurrently, into models, a pointer to a slice. The rows of
+// the shards are appended in the order of the shards, or merged by
+// mergeOrder, a column of the models optionally followed by "asc" or
+// "desc": each shard should then be ordered by the same column. The limit
+// and the offset of the query apply to the rows of all the shards, each
+// shard reading up to their sum. The paginated queries aren't supported.
+//
+//	sc.Q().Order("created_at desc").Limit(20).AllShards(ctx, &users, "created_at desc")
+func (q *Query) AllShards(ctx context.Context, models interface{}, mergeOrder ...string) error {
+	if q.shards == nil {
+		return errors.New("AllShards needs a query of a ShardedConnection")
+	}
+	v := reflect.ValueOf(models)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return errors.Errorf("AllShards needs a pointer to a slice, not %T", models)
+	}
+	if q.Paginator != nil {
+		return errors.New("AllShards can't paginate the rows of the shards, use Limit and Offset")
+	}
+	var column string
+	var desc bool
+	if len(mergeOrder) > 0 {
+		parts := strings.Fields(mergeOrder[0])
+		if len(parts) == 0 || len(parts) > 2 || (len(parts) == 2 && !strings.EqualFold(parts[1], "asc") && !strings.EqualFold(parts[1], "desc")) {
+			return errors.Errorf("invalid merge order %q", mergeOrder[0])
+		}
+		column = parts[0]
+		desc = len(parts) == 2 && strings.EqualFold(parts[1], "desc")
+	}
+
+	// the queries are cloned before any is run, Clone copying the
+	// connection of q
+	queries := make([]*Query, len(q.shards.shards))
+	results := make([]reflect.Value, len(queries))
+	for i, c := range q.shards.shards {
+		sq := &Query{}
+		q.Clone(sq)
+		sq.Connection = c
+		sq.shards = nil
+		// the offset is skipped in the rows of all the shards
+		sq.offsetResults = 0
+		if q.limitResults > 0 {
+			sq.limitResults = q.offsetResults + q.limitResults
+		}
+		sq.eager, sq.eagerFields, sq.eagerSelects, sq.eagerParallel = q.eager, q.eagerFields, q.eagerSelects, q.eagerParallel
+		sq.eagerLoad = q.eagerLoad
+		queries[i] = sq
+		results[i] = reflect.New(v.Elem().Type())
+	}
+	g, gctx := errgroup.WithContext(ctx)
+	for i, sq := range queries {
+		i, sq := i, sq
+		g.Go(func() error {
+			return errors.Wrapf(sq.All(gctx, results[i].Interface()), "could not read shard %d", i)
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return err
+	}
+
+	all := reflect.MakeSlice(v.Elem().Type(), 0, 0)
+	for _, r := range results {
+		all = reflect.AppendSlice(all, r.Elem())
+	}
+	if column != "" {
+		if err := mergeShards(all, column, desc); err != nil {
+			return err
+		}
+	}
+	start, end := q.offsetResults, int64(all.Len())
+	if start > end {
+		start = end
+	}
+	if q.limitResults > 0 && start+q.limitResults < end {
+		end = start + q.limitResults
+	}
+	v.Elem().Set(all.Slice(int(start), int(end)))
+	return nil
+}
+
+// mergeShards sorts the rows of the shards by their column, stably.
+func mergeShards(rows reflect.Value, column string, desc bool) error {
+	t := reflectx.Deref(rows.Type().Elem())
+	if t.Kind() != reflect.Struct {
+		return errors.Errorf("the rows of %s can't be merged by %s", rows.Type(), column)
+	}
+	fi, ok := strictMapper.TypeMap(t).Names[column]
+	if !ok {
+		return errors.Errorf("%s is not a column of %s", column, t)
+	}
+	keys := make([]interface{}, rows.Len())
+	for i := range keys {
+		keys[i] = shardMergeKey(reflectx.FieldByIndexesReadOnly(reflect.Indirect(rows.Index(i)), fi.Index))
+	}
+	// sort the indexes, so the keys are swapped with their rows
+	idx := make([]int, len(keys))
+	for i := range idx {
+		idx[i] = i
+	}
+	sort.SliceStable(idx, func(i, j int) bool {
+		c := compareMergeKeys(keys[idx[i]], keys[idx[j]])
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	})
+	sorted := reflect.MakeSlice(rows.Type(), rows.Len(), rows.Len())
+	for i, j := range idx {
+		sorted.Index(i).Set(rows.Index(j))
+	}
+	reflect.Copy(rows, sorted)
+	return nil
+}
+
+// shardMergeKey returns the comparable value of a field: the value of a
+// driver.Valuer such as nulls.String, nil for NULL.
+func shardMergeKey(f reflect.Value) interface{} {
+	if f.Kind() == reflect.Ptr {
+		if f.IsNil() {
+			return nil
+		}
+		f = f.Elem()
+	}
+	if vr, ok := f.Interface().(driver.Valuer); ok {
+		v, err := vr.Value()
+		if err != nil {
+			return nil
+		}
+		return v
+	}
+	return f.Interface()
+}
+
+// compareMergeKeys compares two merge keys, NULL first.
+func compareMergeKeys(a, b interface{}) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		}
+		return 1
+	}
+	if at, ok := a.(time.Time); ok {
+		bt, _ := b.(time.Time)
+		switch {
+		case at.Before(bt):
+			return -1
+		case at.After(bt):
+			return 1
+		}
+		return 0
+	}
+	av, bv := reflect.ValueOf(a), reflect.ValueOf(b)
+	var less, greater bool
+	switch av.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		less, greater = av.Int() < bv.Int(), av.Int() > bv.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		less, greater = av.Uint() < bv.Uint(), av.Uint() > bv.Uint()
+	case reflect.Float32, reflect.Float64:
+		less, greater = av.Float() < bv.Float(), av.Float() > bv.Float()
+	case reflect.Bool:
+		less, greater = !av.Bool() && bv.Bool(), av.Bool() && !bv.Bool()
+	default:
+		return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+	}
+	switch {
+	case less:
+		return -1
+	case greater:
+		return 1
+	}
+	return 0
+}
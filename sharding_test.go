@@ -0,0 +1,117 @@
+package pop
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+type shardedAccount struct {
+	ID        int       `db:"id"`
+	TenantID  int       `db:"tenant_id"`
+	Name      string    `db:"name"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (shardedAccount) TableName() string {
+	return "sharded_accounts"
+}
+
+func (a shardedAccount) ShardKey() interface{} {
+	if a.TenantID == 0 {
+		return nil
+	}
+	return a.TenantID
+}
+
+func Test_ShardedConnection(t *testing.T) {
+	r := require.New(t)
+	ctx := context.Background()
+
+	// the shards share the database of PDB, each with its own table:
+	// they can't run in a transaction, whose failures roll it back
+	var shards []*Connection
+	for i := 0; i < 3; i++ {
+		table := fmt.Sprintf("sharded_accounts_%d", i)
+		c := PDB.copy()
+		c.borrowed = true
+		c.AddQueryRewriter(func(ctx context.Context, op string, sql string, args []interface{}) (string, []interface{}, error) {
+			return strings.Replace(sql, "sharded_accounts", table, -1), args, nil
+		})
+		r.NoError(c.RawQuery("DELETE FROM " + table).Exec())
+		defer c.RawQuery("DELETE FROM " + table).Exec()
+		shards = append(shards, c)
+	}
+	sc, err := NewShardedConnection(shards, func(key interface{}) int {
+		return key.(int) % 3
+	})
+	r.NoError(err)
+	defer sc.Close()
+
+	for i, name := range []string{"d", "a", "e", "b", "f", "c"} {
+		r.NoError(sc.Create(&shardedAccount{TenantID: i + 1, Name: name}))
+	}
+	// the accounts are created on the shard of their tenant
+	c, err := sc.Shard(4)
+	r.NoError(err)
+	accounts := []shardedAccount{}
+	r.NoError(c.Order("tenant_id").All(ctx, &accounts))
+	r.Len(accounts, 2)
+	r.Equal([]int{1, 4}, []int{accounts[0].TenantID, accounts[1].TenantID})
+
+	// the reads are fanned out, and merged by the merge order
+	accounts = []shardedAccount{}
+	r.NoError(sc.Q().AllShards(ctx, &accounts))
+	r.Len(accounts, 6)
+	accounts = []shardedAccount{}
+	r.NoError(sc.Where("name <> ?", "f").Order("name desc").Limit(3).AllShards(ctx, &accounts, "name desc"))
+	r.Len(accounts, 3)
+	r.Equal("e", accounts[0].Name)
+	r.Equal("d", accounts[1].Name)
+	r.Equal("c", accounts[2].Name)
+
+	// the limit and the offset apply to the rows of all the shards
+	accounts = []shardedAccount{}
+	r.NoError(sc.Where("name <> ?", "f").Order("name desc").Limit(2).Offset(1).AllShards(ctx, &accounts, "name desc"))
+	r.Len(accounts, 2)
+	r.Equal("d", accounts[0].Name)
+	r.Equal("c", accounts[1].Name)
+	accounts = []shardedAccount{}
+	r.NoError(sc.Q().Limit(4).AllShards(ctx, &accounts))
+	r.Len(accounts, 4)
+	accounts = []shardedAccount{}
+	r.NoError(sc.Q().Offset(5).AllShards(ctx, &accounts))
+	r.Len(accounts, 1)
+	r.Error(sc.Q().Paginate(1, 2).AllShards(ctx, &accounts))
+
+	r.Error(sc.Q().AllShards(ctx, &accounts, "body"))
+	r.Error(c.Q().AllShards(ctx, &accounts))
+
+	// the models without shard key can't be created
+	r.True(errors.Cause(sc.Create(&shardedAccount{Name: "none"})) == ErrNoShardKey)
+	r.True(errors.Cause(c.Create(&shardedAccount{Name: "none"})) == ErrNoShardKey)
+
+	// the transactions are per-shard only
+	err = sc.Transaction(ctx, 2, func(ctx context.Context, tx *Connection) error {
+		if err := tx.Create(&shardedAccount{TenantID: 5, Name: "g"}); err != nil {
+			return err
+		}
+		return tx.Create(&shardedAccount{TenantID: 3, Name: "h"})
+	})
+	cse, ok := errors.Cause(err).(*CrossShardError)
+	r.True(ok, "%v", err)
+	r.Equal(2, cse.Shard)
+	r.Equal(0, cse.KeyShard)
+	n, err := sc.Shards()[2].Count(&shardedAccount{})
+	r.NoError(err)
+	r.Equal(2, n)
+
+	_, err = sc.Shard(nil)
+	r.Equal(ErrNoShardKey, err)
+}
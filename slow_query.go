@@ -0,0 +1,141 @@
+package pop
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/gobuffalo/pop/logging"
+	"github.com/jmoiron/sqlx"
+)
+
+// slowQueryStore wraps a store to log the queries
+// running longer than the connection SlowQueryThreshold.
+type slowQueryStore struct {
+	Store
+	deets *ConnectionDetails
+	// log is the logger of the connection, nil for the default logger
+	log Logger
+}
+
+// withSlowQueryLog wraps the store with a slowQueryStore logging with l,
+// if the connection has a slow query threshold.
+func withSlowQueryLog(s Store, l Logger, deets *ConnectionDetails) Store {
+	if deets.SlowQueryThreshold <= 0 {
+		return s
+	}
+	return &slowQueryStore{Store: s, deets: deets, log: l}
+}
+
+// withSlowQueryLogger returns s, the store of a connection unwrapped from
+// its logger, logging its slow queries with l, see Connection.WithLogger.
+func withSlowQueryLogger(s Store, l Logger) Store {
+	switch w := s.(type) {
+	case *statsStore:
+		return &statsStore{Store: withSlowQueryLogger(w.Store, l), stats: w.stats}
+	case *slowQueryStore:
+		return &slowQueryStore{Store: w.Store, deets: w.deets, log: l}
+	}
+	return s
+}
+
+func (s *slowQueryStore) Select(dest interface{}, query string, args ...interface{}) error {
+	defer s.check(time.Now(), query, args)
+	return s.Store.Select(dest, query, args...)
+}
+
+func (s *slowQueryStore) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	defer s.check(time.Now(), query, args)
+	return s.Store.SelectContext(ctx, dest, query, args...)
+}
+
+func (s *slowQueryStore) Get(dest interface{}, query string, args ...interface{}) error {
+	defer s.check(time.Now(), query, args)
+	return s.Store.Get(dest, query, args...)
+}
+
+func (s *slowQueryStore) Queryx(query string, args ...interface{}) (*sqlx.Rows, error) {
+	defer s.check(time.Now(), query, args)
+	return s.Store.Queryx(query, args...)
+}
+
+func (s *slowQueryStore) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer s.check(time.Now(), query, args)
+	return s.Store.QueryRowContext(ctx, query, args...)
+}
+
+func (s *slowQueryStore) NamedExec(query string, arg interface{}) (sql.Result, error) {
+	defer s.check(time.Now(), query, []interface{}{arg})
+	return s.Store.NamedExec(query, arg)
+}
+
+func (s *slowQueryStore) Exec(query string, args ...interface{}) (sql.Result, error) {
+	defer s.check(time.Now(), query, args)
+	return s.Store.Exec(query, args...)
+}
+
+func (s *slowQueryStore) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer s.check(time.Now(), query, args)
+	return s.Store.ExecContext(ctx, query, args...)
+}
+
+// check logs the query if it ran longer than the threshold.
+func (s *slowQueryStore) check(start time.Time, query string, args []interface{}) {
+	d := time.Since(start)
+	if d < s.deets.SlowQueryThreshold {
+		return
+	}
+	op, caller := slowQueryCaller()
+	msg := fmt.Sprintf("slow query: %s took %s (threshold %s), called from %s: %s", op, d, s.deets.SlowQueryThreshold, caller, query)
+	if s.deets.LogSQL && len(args) > 0 {
+		msg = fmt.Sprintf("%s | %v", msg, redactArgs(s.deets, query, args))
+	}
+	redactingLogger(s.log, s.deets)(logging.Warn, "%s", msg)
+}
+
+// slowQueryCaller walks the stack to find the pop operation which ran the
+// query, e.g. "(*Query).All", and the first caller outside of pop.
+func slowQueryCaller() (op string, caller string) {
+	pcs := make([]uintptr, 32)
+	n := runtime.Callers(3, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		f, more := frames.Next()
+		name := strings.TrimPrefix(f.Function, "github.com/gobuffalo/pop.")
+		inPop := name != f.Function && !strings.HasSuffix(f.File, "_test.go")
+		if !inPop {
+			return defaultOp(op), fmt.Sprintf("%s (%s:%d)", f.Function, f.File, f.Line)
+		}
+		if isExportedOp(name) {
+			op = name
+		}
+		if !more {
+			return defaultOp(op), "unknown"
+		}
+	}
+}
+
+// isExportedOp tells if the function name, relative to the pop package,
+// is an exported function or method, e.g. "(*Connection).Create".
+func isExportedOp(name string) bool {
+	if strings.Contains(name, ".func") {
+		return false
+	}
+	for _, part := range strings.Split(name, ".") {
+		part = strings.TrimSuffix(strings.TrimPrefix(part, "(*"), ")")
+		if part == "" || strings.ToUpper(part[:1]) != part[:1] {
+			return false
+		}
+	}
+	return true
+}
+
+func defaultOp(op string) string {
+	if op == "" {
+		return "query"
+	}
+	return op
+}
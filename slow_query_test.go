@@ -0,0 +1,78 @@
+package pop
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/pop/logging"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_SlowQueryThreshold(t *testing.T) {
+	r := require.New(t)
+
+	deets := *PDB.Dialect.Details()
+	deets.SlowQueryThreshold = time.Nanosecond
+	c, err := NewConnection(&deets)
+	r.NoError(err)
+	r.NoError(c.Open())
+
+	var warnings []string
+	oldLog := log
+	defer func() { log = oldLog }()
+	SetLogger(func(lvl logging.Level, s string, args ...interface{}) {
+		if lvl == logging.Warn {
+			warnings = append(warnings, fmt.Sprintf(s, args...))
+		}
+	})
+
+	r.NoError(c.Where("name = ?", "secret").All(context.Background(), &Users{}))
+	r.Len(warnings, 1)
+	r.Contains(warnings[0], "slow query: (*Query).All took")
+	r.Contains(warnings[0], "Test_SlowQueryThreshold")
+	r.Contains(warnings[0], "FROM users")
+	r.NotContains(warnings[0], "secret")
+
+	deets.LogSQL = true
+	warnings = nil
+	r.NoError(c.Where("name = ?", "secret").All(context.Background(), &Users{}))
+	r.Len(warnings, 1)
+	r.Contains(warnings[0], "secret")
+
+	// the slow queries are logged by the logger of the connection
+	var connWarnings []string
+	lc := c.WithLogger(func(lvl logging.Level, s string, args ...interface{}) {
+		if lvl == logging.Warn {
+			connWarnings = append(connWarnings, fmt.Sprintf(s, args...))
+		}
+	})
+	warnings = nil
+	r.NoError(lc.Where("name = ?", "secret").All(context.Background(), &Users{}))
+	r.NoError(lc.Rollback(func(tx *Connection) {
+		r.NoError(tx.Where("name = ?", "secret").All(context.Background(), &Users{}))
+	}))
+	r.Empty(warnings)
+	r.Len(connWarnings, 2)
+	r.Contains(connWarnings[1], "slow query: (*Query).All took")
+}
+
+func Test_SlowQueryThreshold_Disabled(t *testing.T) {
+	r := require.New(t)
+
+	_, ok := withSlowQueryLog(PDB.Store, nil, &ConnectionDetails{}).(*slowQueryStore)
+	r.False(ok)
+	_, ok = withSlowQueryLog(PDB.Store, nil, &ConnectionDetails{SlowQueryThreshold: time.Second}).(*slowQueryStore)
+	r.True(ok)
+}
+
+func Test_isExportedOp(t *testing.T) {
+	r := require.New(t)
+
+	r.True(isExportedOp("(*Query).All"))
+	r.True(isExportedOp("(*Connection).Create"))
+	r.False(isExportedOp("genericSelectMany"))
+	r.False(isExportedOp("(*Connection).Create.func1"))
+	r.False(isExportedOp("(*slowQueryStore).Select"))
+}
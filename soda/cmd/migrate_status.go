@@ -1,6 +1,8 @@
 package cmd
 
 import (
+	"os"
+
 	"github.com/gobuffalo/pop"
 	"github.com/pkg/errors"
 	"github.com/spf13/cobra"
@@ -14,7 +16,7 @@ var migrateStatusCmd = &cobra.Command{
 		if err != nil {
 			return errors.WithStack(err)
 		}
-		return mig.Status()
+		return mig.PrintStatus(os.Stdout)
 	},
 }
 
@@ -0,0 +1,163 @@
+package pop
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+)
+
+// softDeleteColumn is the column of the time the soft-deletable models are
+// deleted at, NULL for the rows not deleted.
+const softDeleteColumn = "deleted_at"
+
+// Unscoped disables the default scopes of the query: the soft-deleted rows
+// are read too.
+//
+//	c.Unscoped().All(ctx, &users)
+func (c *Connection) Unscoped() *Query {
+	return Q(c).Unscoped()
+}
+
+// Unscoped disables the default scopes of the query: the soft-deleted rows
+// are read too.
+//
+// A model is soft-deletable when it has a deleted_at column, mapped to a
+// nullable time field: a nulls.Time, sql.NullTime or *time.Time. Destroy
+// sets its deleted_at column instead of deleting its row, and the queries
+// skip the rows whose deleted_at is set, unless they're unscoped.
+//
+//	type User struct {
+//		ID        int        `db:"id"`
+//		DeletedAt nulls.Time `db:"deleted_at"`
+//	}
+//
+//	q.Where("email = ?", email).Unscoped().First(ctx, &user)
+func (q *Query) Unscoped() *Query {
+	q.unscoped = true
+	return q
+}
+
+// HardDelete deletes the row of the model, or of each model of a slice,
+// like Destroy does for the models which aren't soft-deletable.
+func (c *Connection) HardDelete(model interface{}) error {
+	return c.destroy(model, true)
+}
+
+// SoftDeleted tells if the model was soft-deleted: its deleted_at field is
+// set. It's false for a slice, or a model which isn't soft-deletable, see
+// Query.Unscoped.
+//
+//	c.Unscoped().Find(ctx, &user, id)
+//	if (&pop.Model{Value: &user}).SoftDeleted() {
+//		...
+//	}
+func (m *Model) SoftDeleted() bool {
+	if !m.softDeletable() || m.isSlice() {
+		return false
+	}
+	f := m.deletedAtField()
+	if f.Kind() == reflect.Ptr {
+		return !f.IsNil()
+	}
+	return f.FieldByName("Valid").Bool()
+}
+
+// RestoreDeleted restores the soft-deleted model, or each model of a
+// slice: it sets its deleted_at column to NULL, and clears its deleted_at
+// field. An error is returned if the model isn't soft-deletable.
+func (m *Model) RestoreDeleted(ctx context.Context, c *Connection) error {
+	if !m.softDeletable() {
+		return errors.Errorf("%s is not soft-deletable: it has no nullable %s field", m.TableName(), softDeleteColumn)
+	}
+	return m.iterate(func(m *Model) error {
+		return c.timeFunc(ctx, "RestoreDeleted", writeOp, m.TableName(), func(ctx context.Context, c *Connection) error {
+			stmt := c.Dialect.TranslateSQL(fmt.Sprintf("UPDATE %s SET %s = NULL WHERE %s", m.TableName(), softDeleteColumn, m.whereID()))
+			if _, err := c.execWrite(ctx, stmt, m.ID()); err != nil {
+				return err
+			}
+			m.setDeletedAt(nil)
+			return nil
+		})
+	})
+}
+
+// softDeletable tells if the model, or the elements of a slice, is
+// soft-deletable, see Query.Unscoped.
+func (m *Model) softDeletable() bool {
+	if m == nil || m.Value == nil {
+		return false
+	}
+	t := reflectx.Deref(reflect.TypeOf(m.Value))
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = reflectx.Deref(t.Elem())
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	fi, ok := strictMapper.TypeMap(t).Names[softDeleteColumn]
+	return ok && nullableTime(fi.Field.Type)
+}
+
+// nullableTime tells if t is a *time.Time, or a struct with Time and
+// Valid fields such as nulls.Time and sql.NullTime.
+func nullableTime(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		return t.Elem() == timeType
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	tf, ok := t.FieldByName("Time")
+	if !ok || tf.Type != timeType {
+		return false
+	}
+	vf, ok := t.FieldByName("Valid")
+	return ok && vf.Type.Kind() == reflect.Bool
+}
+
+// deletedAtField returns the deleted_at field of a soft-deletable model.
+func (m *Model) deletedAtField() reflect.Value {
+	return strictMapper.FieldByName(reflect.Indirect(reflect.ValueOf(m.Value)), softDeleteColumn)
+}
+
+// setDeletedAt sets the deleted_at field of a soft-deletable model to t,
+// or to NULL if t is nil.
+func (m *Model) setDeletedAt(t *time.Time) {
+	f := m.deletedAtField()
+	if t == nil {
+		f.Set(reflect.Zero(f.Type()))
+		return
+	}
+	if f.Kind() == reflect.Ptr {
+		v := *t
+		f.Set(reflect.ValueOf(&v))
+		return
+	}
+	f.FieldByName("Time").Set(reflect.ValueOf(*t))
+	f.FieldByName("Valid").SetBool(true)
+}
+
+// softDestroy sets the deleted_at column of the row of a soft-deletable
+// model, and its deleted_at field.
+func (c *Connection) softDestroy(ctx context.Context, m *Model) error {
+	now := c.dbTime(c.now())
+	stmt := c.Dialect.TranslateSQL(fmt.Sprintf("UPDATE %s SET %s = ? WHERE %s", m.TableName(), softDeleteColumn, m.whereID()))
+	if _, err := c.execWrite(ctx, stmt, now, m.ID()); err != nil {
+		return err
+	}
+	m.setDeletedAt(&now)
+	return nil
+}
+
+// softDeleteClause returns the where clause skipping the soft-deleted rows
+// of the model of the query, unless it's unscoped.
+func (sq *sqlBuilder) softDeleteClause() (clause, bool) {
+	if sq.Query.unscoped || !sq.Model.softDeletable() {
+		return clause{}, false
+	}
+	return clause{Fragment: fmt.Sprintf("%s.%s IS NULL", sq.Model.alias(), softDeleteColumn)}, true
+}
@@ -0,0 +1,126 @@
+package pop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+type Note struct {
+	ID        int        `db:"id"`
+	Title     string     `db:"title"`
+	DeletedAt nulls.Time `db:"deleted_at"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at"`
+}
+
+type pointerNote struct {
+	ID        int        `db:"id"`
+	Title     string     `db:"title"`
+	DeletedAt *time.Time `db:"deleted_at"`
+	CreatedAt time.Time  `db:"created_at"`
+	UpdatedAt time.Time  `db:"updated_at"`
+}
+
+func (pointerNote) TableName() string {
+	return "notes"
+}
+
+func Test_SoftDelete(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		kept := &Note{Title: "kept"}
+		deleted := &Note{Title: "deleted"}
+		r.NoError(tx.Create(kept))
+		r.NoError(tx.Create(deleted))
+
+		r.NoError(tx.Destroy(deleted))
+		r.True(deleted.DeletedAt.Valid)
+
+		notes := []Note{}
+		r.NoError(tx.All(ctx, &notes))
+		r.Len(notes, 1)
+		r.Equal(kept.ID, notes[0].ID)
+		r.True(IsNotFound(tx.Find(ctx, &Note{}, deleted.ID)))
+		n, err := tx.Count(&Note{})
+		r.NoError(err)
+		r.Equal(1, n)
+		ok, err := tx.Where("title = ?", "deleted").Exists(&Note{})
+		r.NoError(err)
+		r.False(ok)
+
+		// the unscoped queries read the soft-deleted rows
+		notes = []Note{}
+		r.NoError(tx.Unscoped().All(ctx, &notes))
+		r.Len(notes, 2)
+		found := &Note{}
+		r.NoError(tx.Unscoped().Find(ctx, found, deleted.ID))
+		r.True(found.DeletedAt.Valid)
+		n, err = tx.Unscoped().Count(&Note{})
+		r.NoError(err)
+		r.Equal(2, n)
+
+		pn := &pointerNote{Title: "pointer"}
+		r.NoError(tx.Create(pn))
+		r.NoError(tx.Destroy(pn))
+		r.NotNil(pn.DeletedAt)
+		r.True(IsNotFound(tx.Find(ctx, &pointerNote{}, pn.ID)))
+
+		r.NoError(tx.HardDelete(deleted))
+		r.True(IsNotFound(tx.Unscoped().Find(ctx, &Note{}, deleted.ID)))
+		n, err = tx.Unscoped().Count(&Note{})
+		r.NoError(err)
+		r.Equal(2, n)
+	})
+}
+
+func Test_Model_RestoreDeleted(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		notes := []Note{{Title: "first"}, {Title: "second"}}
+		r.NoError(tx.Create(&notes))
+		m := &Model{Value: &notes[0]}
+		r.False(m.SoftDeleted())
+
+		r.NoError(tx.Destroy(&notes))
+		r.True(m.SoftDeleted())
+		r.False((&Model{Value: &notes}).SoftDeleted())
+		n, err := tx.Count(&Note{})
+		r.NoError(err)
+		r.Equal(0, n)
+
+		r.NoError(m.RestoreDeleted(ctx, tx))
+		r.False(m.SoftDeleted())
+		found := &Note{}
+		r.NoError(tx.Find(ctx, found, notes[0].ID))
+		r.False((&Model{Value: found}).SoftDeleted())
+
+		// the models of a slice are restored in turn
+		r.NoError((&Model{Value: &notes}).RestoreDeleted(ctx, tx))
+		n, err = tx.Count(&Note{})
+		r.NoError(err)
+		r.Equal(2, n)
+
+		pn := &pointerNote{Title: "pointer"}
+		r.NoError(tx.Create(pn))
+		r.NoError(tx.Destroy(pn))
+		pm := &Model{Value: pn}
+		r.True(pm.SoftDeleted())
+		r.NoError(pm.RestoreDeleted(ctx, tx))
+		r.Nil(pn.DeletedAt)
+		r.NoError(tx.Find(ctx, &pointerNote{}, pn.ID))
+
+		// the models which aren't soft-deletable can't be restored
+		user := &Model{Value: &User{}}
+		r.False(user.SoftDeleted())
+		err = user.RestoreDeleted(ctx, tx)
+		r.Error(err)
+		r.Contains(err.Error(), "users is not soft-deletable")
+	})
+}
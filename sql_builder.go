@@ -3,12 +3,14 @@ package pop
 import (
 	"fmt"
 	"regexp"
+	"sort"
 	"strings"
 	"sync"
 
 	"github.com/gobuffalo/pop/columns"
 	"github.com/gobuffalo/pop/logging"
 	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
 )
 
 type sqlBuilder struct {
@@ -17,6 +19,10 @@ type sqlBuilder struct {
 	AddColumns []string
 	sql        string
 	args       []interface{}
+	// with is the WITH clause starting sql, see Query.With
+	with string
+	// err is the error building sql
+	err error
 }
 
 func newSQLBuilder(q Query, m *Model, addColumns ...string) *sqlBuilder {
@@ -72,14 +78,54 @@ func (sq *sqlBuilder) Args() []interface{} {
 
 var inRegex = regexp.MustCompile(`(?i)in\s*\(\s*\?\s*\)`)
 
+// toSQL returns the SQL and its args, or the error building the SQL.
+func (sq *sqlBuilder) toSQL() (string, []interface{}, error) {
+	sql, args := sq.String(), sq.Args()
+	if sq.err != nil {
+		return "", nil, sq.err
+	}
+	return sql, customArgs(args), nil
+}
+
 func (sq *sqlBuilder) compile() {
+	if sq.sql == "" && sq.err == nil {
+		// the models are read with reflect, which panics on invalid models
+		defer func() {
+			if r := recover(); r != nil {
+				sq.sql = ""
+				sq.err = errors.Errorf("could not build the SQL of the query: %v", r)
+			}
+		}()
+		if sq.Query.RawSQL.Fragment == "" && (sq.Model == nil || sq.Model.Value == nil) {
+			sq.err = errors.New("could not build the SQL of the query: no model given")
+			return
+		}
+		sq.build()
+		sq.sql = sq.Query.Connection.Dialect.TranslateSQL(sq.sql)
+		if sq.with != "" {
+			// the placeholders of the WITH clause come first
+			sq.with = sq.Query.Connection.Dialect.TranslateSQL(sq.with)
+		}
+		if c := sq.Query.renderedComment(); c != "" {
+			// added once translated, so its question marks are kept, and
+			// after the leading hint, which has to start the statement
+			hint, sql := leadingHint(sq.sql)
+			sq.sql = hint + c + sql
+		}
+	}
+}
+
+// build builds the SQL, with the "?" placeholders not translated to the
+// placeholders of the dialect yet.
+func (sq *sqlBuilder) build() {
 	if sq.sql == "" {
+		with, withArgs := sq.buildWith()
 		if sq.Query.RawSQL.Fragment != "" {
 			if sq.Query.Paginator != nil && !hasLimitOrOffset(sq.Query.RawSQL.Fragment) {
 				sq.sql = sq.buildPaginationClauses(sq.Query.RawSQL.Fragment)
 			} else {
 				if sq.Query.Paginator != nil {
-					log(logging.Warn, "Query already contains pagination")
+					sq.Query.Connection.log(logging.Warn, "Query already contains pagination")
 				}
 				sq.sql = sq.Query.RawSQL.Fragment
 			}
@@ -94,26 +140,165 @@ func (sq *sqlBuilder) compile() {
 				sq.args = args
 			}
 		}
-		sq.sql = sq.Query.Connection.Dialect.TranslateSQL(sq.sql)
+
+		if with != "" {
+			args := sq.Args()
+			// the leading hint has to start the statement
+			hint, sql := leadingHint(sq.sql)
+			sq.sql = hint + with + sql
+			sq.args = append(withArgs, args...)
+			sq.with = with
+		}
+	}
+}
+
+// leadingWith splits the WITH clause of the built query, if any, from the
+// statement. It's moved before the statements wrapping query.
+func (sq *sqlBuilder) leadingWith(query string) (string, string) {
+	if sq.with == "" || !strings.HasPrefix(query, sq.with) {
+		return "", query
 	}
+	return sq.with, query[len(sq.with):]
+}
+
+// buildWith returns the WITH clause of the common table expressions of the
+// query, and their args, see Query.With.
+func (sq *sqlBuilder) buildWith() (string, []interface{}) {
+	if len(sq.Query.ctes) == 0 {
+		return "", nil
+	}
+	var recursive bool
+	var args []interface{}
+	ctes := make([]string, 0, len(sq.Query.ctes))
+	for _, c := range sq.Query.ctes {
+		b := c.query.toSQLBuilder(sq.Model)
+		b.build()
+		ctes = append(ctes, fmt.Sprintf("%s AS (%s)", c.name, b.sql))
+		args = append(args, b.Args()...)
+		recursive = recursive || c.recursive
+	}
+	if recursive {
+		return "WITH RECURSIVE " + strings.Join(ctes, ", ") + " ", args
+	}
+	return "WITH " + strings.Join(ctes, ", ") + " ", args
 }
 
 func (sq *sqlBuilder) buildSelectSQL() string {
-	cols := sq.buildColumns()
+	if len(sq.Query.unions) > 0 {
+		sql := sq.buildUnion()
+		sql = sq.buildOrderClauses(sql)
+		return sq.buildPaginationClauses(sql)
+	}
 
-	fc := sq.buildfromClauses()
+	cols, sel := sq.buildColumns()
 
-	sql := fmt.Sprintf("SELECT %s FROM %s", cols.Readable().SelectString(), fc)
+	fc := sq.buildfromClauses()
 
-	sql = sq.buildJoinClauses(sql)
-	sql = sq.buildWhereClauses(sql)
-	sql = sq.buildGroupClauses(sql)
+	// the model is the first table after the from clauses of the query
+	h := sq.buildHints(fc[len(sq.Query.fromClauses)].As)
+	fc[len(sq.Query.fromClauses)].Hint = h.afterTable
+
+	var sql string
+	if sq.Query.latestPerGroup != nil {
+		sql = sq.buildLatestPerGroup(cols, fc, h)
+	} else {
+		sql = fmt.Sprintf("%sSELECT %s%s FROM %s", h.leading, h.afterSelect, sel, fc)
+		sq.args = append(sq.args, sq.columnArgs(sel)...)
+		sql = sq.buildJoinClauses(sql)
+		sql = sq.buildAsOfClause(sql)
+		sql = sq.buildWhereClauses(sql)
+		sql = sq.buildGroupClauses(sql)
+	}
 	sql = sq.buildOrderClauses(sql)
 	sql = sq.buildPaginationClauses(sql)
 
 	return sql
 }
 
+// buildLatestPerGroup returns the select of the latest rows of each group,
+// see Query.LatestPerGroup. The order and pagination clauses are added to
+// the outer select.
+func (sq *sqlBuilder) buildLatestPerGroup(cols columns.Columns, fc fromClauses, h renderedHints) string {
+	lg := sq.Query.latestPerGroup
+	alias := fc[len(sq.Query.fromClauses)].As
+
+	// the outer select reads the columns of the inner one
+	readable := cols.Readable()
+	names := make([]string, 0, len(readable.Cols))
+	for name := range readable.Cols {
+		names = append(names, alias+"."+name)
+	}
+	sort.Strings(names)
+
+	if supportsWindowFunctions(sq.Query.Connection.Dialect) {
+		inner := fmt.Sprintf("SELECT %s%s, ROW_NUMBER() OVER (PARTITION BY %s ORDER BY %s DESC) AS pop_row_number FROM %s",
+			h.afterSelect, readable.SelectString(), qualifyColumn(alias, lg.partition), qualifyColumn(alias, lg.orderBy), fc)
+		sq.args = append(sq.args, sq.columnArgs(readable.SelectString())...)
+		inner = sq.buildJoinClauses(inner)
+		inner = sq.buildWhereClauses(inner)
+		inner = sq.buildGroupClauses(inner)
+		return fmt.Sprintf("%sSELECT %s FROM (%s) AS %s%s WHERE %s.pop_row_number = 1", h.leading, strings.Join(names, ", "), inner, alias, sq.Query.asOfClause(), alias)
+	}
+
+	n := len(sq.args)
+	inner := fmt.Sprintf("SELECT %s%s FROM %s", h.afterSelect, readable.SelectString(), fc)
+	sq.args = append(sq.args, sq.columnArgs(readable.SelectString())...)
+	inner = sq.buildJoinClauses(inner)
+	inner = sq.buildWhereClauses(inner)
+	inner = sq.buildGroupClauses(inner)
+	// the correlated sub-query selects the same rows, with the same args
+	sq.args = append(sq.args, sq.args[n:]...)
+
+	partition, orderBy := unqualifyColumn(lg.partition), unqualifyColumn(lg.orderBy)
+	return fmt.Sprintf("%sSELECT %s FROM (%s) AS %s WHERE %s.%s = (SELECT MAX(pop_latest.%s) FROM (%s) AS pop_latest WHERE pop_latest.%s = %s.%s)",
+		h.leading, strings.Join(names, ", "), inner, alias, alias, orderBy, orderBy, inner, partition, alias, partition)
+}
+
+// buildUnion returns the union of the selects of the branches, see
+// Query.Union. Each branch is built with its own clauses, and with the
+// model of the union.
+func (sq *sqlBuilder) buildUnion() string {
+	if len(sq.Query.whereClauses)+len(sq.Query.joinClauses)+len(sq.Query.groupClauses)+len(sq.Query.havingClauses) > 0 {
+		sq.Query.Connection.log(logging.Warn, "the clauses of a union are set on its queries, only Order and the pagination clauses apply to the union")
+	}
+	// SQLite doesn't parenthesize the selects of a compound select
+	sqlite := sq.Query.Connection.Dialect.Name() == nameSQLite3
+
+	var sql strings.Builder
+	for i, u := range sq.Query.unions {
+		if i > 0 {
+			sql.WriteString(" UNION ")
+			if u.all {
+				sql.WriteString("ALL ")
+			}
+		}
+		b := u.query.toSQLBuilder(sq.Model, sq.AddColumns...)
+		b.build()
+		if sqlite {
+			fmt.Fprintf(&sql, "SELECT * FROM (%s)", b.sql)
+		} else {
+			fmt.Fprintf(&sql, "(%s)", b.sql)
+		}
+		sq.args = append(sq.args, b.Args()...)
+	}
+	return sql.String()
+}
+
+// buildHints renders the hints of the query in the syntax of the dialect,
+// for the table aliased as alias.
+func (sq *sqlBuilder) buildHints(alias string) renderedHints {
+	if sq.Query.hints.empty() {
+		return renderedHints{}
+	}
+	d := sq.Query.Connection.Dialect
+	qh, ok := d.(queryHinter)
+	if !ok {
+		sq.Query.Connection.log(logging.Debug, "%s doesn't support query hints, they're ignored", d.Name())
+		return renderedHints{}
+	}
+	return qh.renderHints(sq.Query.hints, alias)
+}
+
 func (sq *sqlBuilder) buildfromClauses() fromClauses {
 	models := []*Model{
 		sq.Model,
@@ -124,14 +309,9 @@ func (sq *sqlBuilder) buildfromClauses() fromClauses {
 
 	fc := sq.Query.fromClauses
 	for _, m := range models {
-		tableName := m.TableName()
-		asName := m.As
-		if asName == "" {
-			asName = strings.Replace(tableName, ".", "_", -1)
-		}
 		fc = append(fc, fromClause{
-			From: tableName,
-			As:   asName,
+			From: m.TableName(),
+			As:   m.alias(),
 		})
 	}
 
@@ -141,11 +321,18 @@ func (sq *sqlBuilder) buildfromClauses() fromClauses {
 func (sq *sqlBuilder) buildWhereClauses(sql string) string {
 	mcs := sq.Query.belongsToThroughClauses
 	for _, mc := range mcs {
-		sq.Query.Where(fmt.Sprintf("%s.%s = ?", mc.Through.TableName(), mc.BelongsTo.associationName()), mc.BelongsTo.ID())
-		sq.Query.Where(fmt.Sprintf("%s.id = %s.%s", sq.Model.TableName(), mc.Through.TableName(), sq.Model.associationName()))
+		sq.Query.Where(fmt.Sprintf("%s.%s = ?", mc.Through.alias(), mc.BelongsTo.associationName()), mc.BelongsTo.ID())
+		sq.Query.Where(fmt.Sprintf("%s.id = %s.%s", sq.Model.alias(), mc.Through.alias(), sq.Model.associationName()))
 	}
 
-	wc := sq.Query.whereClauses
+	wc, err := sq.encryptedClauses(sq.Query.whereClauses)
+	if err != nil {
+		sq.err = err
+		return sql
+	}
+	if c, ok := sq.softDeleteClause(); ok {
+		wc = append(wc[:len(wc):len(wc)], c)
+	}
 	if len(wc) > 0 {
 		sql = fmt.Sprintf("%s WHERE %s", sql, wc.Join(" AND "))
 		sq.args = append(sq.args, wc.Args()...)
@@ -189,7 +376,7 @@ func (sq *sqlBuilder) buildOrderClauses(sql string) string {
 		orderSQL := oc.Join(", ")
 		if regexpMatchNames.MatchString(orderSQL) {
 			warningMsg := fmt.Sprintf("Order clause(s) contains invalid characters: %s", orderSQL)
-			log(logging.Warn, warningMsg)
+			sq.Query.Connection.log(logging.Warn, warningMsg)
 			return sql
 		}
 
@@ -200,8 +387,21 @@ func (sq *sqlBuilder) buildOrderClauses(sql string) string {
 }
 
 func (sq *sqlBuilder) buildPaginationClauses(sql string) string {
-	if sq.Query.limitResults > 0 && sq.Query.Paginator == nil {
-		sql = fmt.Sprintf("%s LIMIT %d", sql, sq.Query.limitResults)
+	if sq.Query.Paginator == nil {
+		if sq.Query.limitResults > 0 {
+			sql = fmt.Sprintf("%s LIMIT %d", sql, sq.Query.limitResults)
+		} else if sq.Query.offsetResults > 0 {
+			// MySQL and SQLite don't support an OFFSET without a LIMIT
+			switch sq.Query.Connection.Dialect.Name() {
+			case nameMySQL:
+				sql = fmt.Sprintf("%s LIMIT 18446744073709551615", sql)
+			case nameSQLite3:
+				sql = fmt.Sprintf("%s LIMIT -1", sql)
+			}
+		}
+		if sq.Query.offsetResults > 0 {
+			sql = fmt.Sprintf("%s OFFSET %d", sql, sq.Query.offsetResults)
+		}
 	}
 	if sq.Query.Paginator != nil {
 		sql = fmt.Sprintf("%s LIMIT %d", sql, sq.Query.Paginator.PerPage)
@@ -210,34 +410,79 @@ func (sq *sqlBuilder) buildPaginationClauses(sql string) string {
 	return sql
 }
 
-// columnCache is used to prevent columns rebuilding.
-var columnCache = map[string]columns.Columns{}
+// columnArgs returns the args of the computed columns of the select
+// string sel, in their order in sel.
+func (sq *sqlBuilder) columnArgs(sel string) []interface{} {
+	type columnArgs struct {
+		index int
+		args  []interface{}
+	}
+	var cas []columnArgs
+	for _, w := range sq.Query.windowColumns {
+		if len(w.args) > 0 {
+			cas = append(cas, columnArgs{strings.Index(sel, w.expr+" AS "+w.alias), w.args})
+		}
+	}
+	sort.SliceStable(cas, func(i, j int) bool {
+		return cas[i].index < cas[j].index
+	})
+	var args []interface{}
+	for _, ca := range cas {
+		args = append(args, ca.args...)
+	}
+	return args
+}
+
+// columnCache is used to prevent columns rebuilding. The columns are
+// cached by model type and table: several models may read the same table.
+var columnCache = map[string]cachedColumns{}
 var columnCacheMutex = sync.RWMutex{}
 
-func (sq *sqlBuilder) buildColumns() columns.Columns {
+// cachedColumns are the columns of a model, with the select string of the
+// readable ones.
+type cachedColumns struct {
+	cols columns.Columns
+	sel  string
+}
+
+// buildColumns returns the columns of the query, and the select string of
+// the readable ones.
+func (sq *sqlBuilder) buildColumns() (columns.Columns, string) {
 	tableName := sq.Model.TableName()
-	asName := sq.Model.As
-	if asName == "" {
-		asName = strings.Replace(tableName, ".", "_", -1)
-	}
+	asName := sq.Model.alias()
 	acl := len(sq.AddColumns)
-	if acl == 0 {
+	if acl == 0 && len(sq.Query.windowColumns) == 0 {
+		key := fmt.Sprintf("%T %s", sq.Model.Value, tableName)
 		columnCacheMutex.RLock()
-		cols, ok := columnCache[tableName]
+		cc, ok := columnCache[key]
 		columnCacheMutex.RUnlock()
 		// if alias is the same, don't remake columns
-		if ok && cols.TableAlias == asName {
-			return cols
+		if ok && cc.cols.TableAlias == asName {
+			return cc.cols, cc.sel
 		}
-		cols = columns.ForStructWithAlias(sq.Model.Value, tableName, asName)
+		cols := columns.ForStructWithAlias(sq.Model.Value, tableName, asName)
+		cc = cachedColumns{cols: cols, sel: cols.Readable().SelectString()}
 		columnCacheMutex.Lock()
-		columnCache[tableName] = cols
+		columnCache[key] = cc
 		columnCacheMutex.Unlock()
-		return cols
+		return cc.cols, cc.sel
 	}
 
-	// acl > 0
-	cols := columns.NewColumns("")
-	cols.Add(sq.AddColumns...)
-	return cols
+	var cols columns.Columns
+	if acl == 0 {
+		// not cached, the computed columns change them
+		cols = columns.ForStructWithAlias(sq.Model.Value, tableName, asName)
+	} else {
+		cols = columns.NewColumns("")
+		cols.Add(sq.AddColumns...)
+	}
+	for _, w := range sq.Query.windowColumns {
+		sel := fmt.Sprintf("%s AS %s", w.expr, w.alias)
+		if c, ok := cols.Cols[w.alias]; ok {
+			c.SetSelectSQL(sel)
+		} else {
+			cols.Add(sel)
+		}
+	}
+	return cols, cols.Readable().SelectString()
 }
@@ -1,21 +1,83 @@
 package pop
 
 import (
+	"context"
 	"database/sql"
 
 	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
 )
 
-// Store is an interface that must be implemented in order for Pop
-// to be able to use the value as a way of talking to a datastore.
-type store interface {
-	Select(interface{}, string, ...interface{}) error
-	Get(interface{}, string, ...interface{}) error
-	NamedExec(string, interface{}) (sql.Result, error)
-	Exec(string, ...interface{}) (sql.Result, error)
-	PrepareNamed(string) (*sqlx.NamedStmt, error)
+// Store runs the statements of a connection: its Store is the connection
+// pool, or the transaction (a *Tx) of a transaction connection. The
+// finders and executors run every statement with the methods of the Store,
+// with the placeholders of the dialect, so a Store wrapping another one
+// sees them all, see Connection.WithStore.
+type Store interface {
+	// Select reads the rows of the query into dest, a pointer to a slice.
+	Select(dest interface{}, query string, args ...interface{}) error
+	// SelectContext is Select, canceled with ctx.
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	// Get reads the first row of the query into dest, and returns
+	// sql.ErrNoRows if there's none.
+	Get(dest interface{}, query string, args ...interface{}) error
+	// Queryx runs the query, its rows being read by the caller.
+	Queryx(query string, args ...interface{}) (*sqlx.Rows, error)
+	// NamedExec runs a statement with named parameters, e.g. ":name",
+	// bound to the fields of a struct or the keys of a map.
+	NamedExec(query string, arg interface{}) (sql.Result, error)
+	Exec(query string, args ...interface{}) (sql.Result, error)
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	// QueryRowContext runs a query reading a single row, its errors being
+	// deferred until the row is scanned.
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	// PrepareNamed prepares a statement with named parameters, run by the
+	// caller, e.g. the INSERT of Create.
+	PrepareNamed(query string) (*sqlx.NamedStmt, error)
+	// Transaction and TransactionContext start a transaction, or return
+	// the transaction of a *Tx.
 	Transaction() (*Tx, error)
+	TransactionContext(ctx context.Context) (*Tx, error)
 	Rollback() error
 	Commit() error
 	Close() error
 }
+
+// TxWrapper is implemented by the Stores given to Connection.WithStore,
+// wrapping the statements of their transactions too: WrapTx returns the
+// Store of the transaction tx, which must run its statements with tx.
+type TxWrapper interface {
+	WrapTx(tx *Tx) Store
+}
+
+// StoreUnwrapper is implemented by the Stores given to Connection.WithStore,
+// returning the Store they wrap: the operations on the pool itself, e.g.
+// PoolStats, Reconnect, the health check or the migration lock, use the
+// database of the Store it unwraps to.
+type StoreUnwrapper interface {
+	Unwrap() Store
+}
+
+// WithStore returns a copy of the connection running its statements with
+// s, a Store wrapping the one of c, e.g. to inject latency or errors in
+// tests, or to cache reads:
+//
+//	sc, err := c.WithStore(&chaosStore{Store: c.Store, latency: 50 * time.Millisecond})
+//	err = sc.All(ctx, &users)
+//
+// The transactions started from the returned connection run their
+// statements with the *Tx returned by s.TransactionContext, wrapped by s.
+// The other connections aren't affected. An error is returned if s isn't
+// a TxWrapper and a StoreUnwrapper: the transactions, and the operations
+// on the pool, would bypass it.
+func (c *Connection) WithStore(s Store) (*Connection, error) {
+	if _, ok := s.(TxWrapper); !ok {
+		return nil, errors.Errorf("the %T store doesn't wrap the transactions: it needs to implement TxWrapper", s)
+	}
+	if _, ok := s.(StoreUnwrapper); !ok {
+		return nil, errors.Errorf("the %T store doesn't return the store it wraps: it needs to implement StoreUnwrapper", s)
+	}
+	cn := c.copy()
+	cn.Store = withLogger(s, c.logger, c.details())
+	return cn, nil
+}
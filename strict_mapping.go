@@ -0,0 +1,107 @@
+package pop
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+)
+
+// StrictMappingError is returned by the finders when the
+// connection uses strict mapping, and the columns returned by the
+// query don't match the fields of the model.
+type StrictMappingError struct {
+	// Model is the type name of the model.
+	Model string
+	// UnmappedColumns lists the returned columns without destination field.
+	UnmappedColumns []string
+	// UnknownFields lists the db tags without matching returned column.
+	UnknownFields []string
+}
+
+func (e StrictMappingError) Error() string {
+	var msgs []string
+	if len(e.UnknownFields) > 0 {
+		msgs = append(msgs, fmt.Sprintf("fields with no matching column: %s", strings.Join(e.UnknownFields, ", ")))
+	}
+	if len(e.UnmappedColumns) > 0 {
+		msgs = append(msgs, fmt.Sprintf("columns with no destination field: %s", strings.Join(e.UnmappedColumns, ", ")))
+	}
+	return fmt.Sprintf("strict mapping failed for %s: %s", e.Model, strings.Join(msgs, "; "))
+}
+
+var strictMapper = reflectx.NewMapperFunc("db", sqlx.NameMapper)
+
+// strictMapping tells if the query connection uses strict mapping.
+func (q Query) strictMapping() bool {
+	return q.Connection != nil && q.Connection.Dialect.Details().StrictMapping
+}
+
+// strictSelect runs the query, checks the returned columns
+// against the model fields, and scans the result into the model. The
+// fields are only checked against the returned columns when the query
+// isn't narrowed by a select list, see checkMapping.
+func strictSelect(s Store, model *Model, many bool, selected bool, query string, args ...interface{}) error {
+	rows, err := s.Queryx(query, args...)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer rows.Close()
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if err := checkMapping(model.Value, cols, selected); err != nil {
+		return err
+	}
+
+	return scanRows(rows, model.Value, many)
+}
+
+// checkMapping compares the db tags of the model with the returned columns.
+// Non-struct models (e.g. []string) are not checked. When the columns are
+// selected, e.g. by Select or EagerSelect, only they are checked: the
+// fields which aren't selected aren't unknown.
+func checkMapping(model interface{}, cols []string, selected bool) error {
+	t := reflectx.Deref(reflect.TypeOf(model))
+	if t.Kind() == reflect.Slice {
+		t = reflectx.Deref(t.Elem())
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	// use the same mapping as sqlx does when scanning.
+	tm := strictMapper.TypeMap(t)
+	returned := make(map[string]bool, len(cols))
+	e := StrictMappingError{Model: t.Name()}
+	for _, c := range cols {
+		returned[c] = true
+		if _, ok := tm.Names[c]; !ok {
+			e.UnmappedColumns = append(e.UnmappedColumns, c)
+		}
+	}
+	if !selected {
+		for _, fi := range tm.Index {
+			// skip the fields of nested structs, e.g. nulls.String
+			if strings.Contains(fi.Path, ".") {
+				continue
+			}
+			if tag := fi.Field.Tag.Get("db"); tag == "" || tag == "-" {
+				continue
+			}
+			if !returned[fi.Path] {
+				e.UnknownFields = append(e.UnknownFields, fi.Path)
+			}
+		}
+	}
+
+	if len(e.UnmappedColumns) == 0 && len(e.UnknownFields) == 0 {
+		return nil
+	}
+	return e
+}
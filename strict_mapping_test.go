@@ -0,0 +1,92 @@
+package pop
+
+import (
+	"context"
+	"testing"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func strictConnection(t *testing.T) *Connection {
+	deets := *PDB.Dialect.Details()
+	deets.StrictMapping = true
+	c, err := NewConnection(&deets)
+	require.NoError(t, err)
+	require.NoError(t, c.Open())
+	return c
+}
+
+func Test_StrictMapping(t *testing.T) {
+	r := require.New(t)
+	c := strictConnection(t)
+	defer c.Close()
+
+	r.NoError(c.Rollback(func(tx *Connection) {
+		r.NoError(tx.Create(&User{Name: nulls.NewString("Mark")}))
+
+		u := User{}
+		r.NoError(tx.First(context.Background(), &u))
+		r.Equal("Mark", u.Name.String)
+
+		users := []User{}
+		r.NoError(tx.All(context.Background(), &users))
+		r.Len(users, 1)
+
+		u = User{}
+		r.NoError(tx.Select("id", "name").First(context.Background(), &u))
+		r.Equal("Mark", u.Name.String)
+	}))
+}
+
+type strictUser struct {
+	ID       int    `db:"id"`
+	Nmae     string `db:"nmae"`
+	Untagged string
+	Ignored  string `db:"-"`
+}
+
+func (strictUser) TableName() string {
+	return "users"
+}
+
+func Test_StrictMapping_Errors(t *testing.T) {
+	r := require.New(t)
+	c := strictConnection(t)
+	defer c.Close()
+
+	r.NoError(c.Rollback(func(tx *Connection) {
+		r.NoError(tx.Create(&User{Name: nulls.NewString("Mark")}))
+
+		u := strictUser{}
+		err := tx.RawQuery("SELECT id, name FROM users").First(context.Background(), &u)
+		r.Error(err)
+		serr, ok := errors.Cause(err).(StrictMappingError)
+		r.True(ok)
+		r.Equal("strictUser", serr.Model)
+		r.Equal([]string{"name"}, serr.UnmappedColumns)
+		r.Equal([]string{"nmae"}, serr.UnknownFields)
+
+		us := []strictUser{}
+		err = tx.RawQuery("SELECT id, name FROM users").All(context.Background(), &us)
+		r.Error(err)
+		_, ok = errors.Cause(err).(StrictMappingError)
+		r.True(ok)
+	}))
+}
+
+func Test_checkMapping(t *testing.T) {
+	r := require.New(t)
+
+	r.NoError(checkMapping(&strictUser{}, []string{"id", "nmae"}, false))
+	r.NoError(checkMapping(&[]string{}, []string{"name"}, false))
+
+	err := checkMapping(&[]*strictUser{}, []string{"id", "name", "untagged"}, false)
+	r.EqualError(err, "strict mapping failed for strictUser: fields with no matching column: nmae; columns with no destination field: name")
+
+	// only the selected columns are checked
+	r.NoError(checkMapping(&strictUser{}, []string{"id"}, true))
+	err = checkMapping(&strictUser{}, []string{"id", "name"}, true)
+	r.EqualError(err, "strict mapping failed for strictUser: columns with no destination field: name")
+}
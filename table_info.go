@@ -0,0 +1,220 @@
+package pop
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync"
+
+	"github.com/gobuffalo/pop/logging"
+	"github.com/pkg/errors"
+)
+
+// ErrTableNotFound is returned by TableInfo when the table
+// doesn't exist in the database.
+var ErrTableNotFound = errors.New("table not found")
+
+// TableInfo describes a database table, as seen by the database.
+type TableInfo struct {
+	Name        string
+	Columns     []ColumnInfo
+	Indexes     []IndexInfo
+	ForeignKeys []ForeignKeyInfo
+}
+
+// Column returns the column with the given name, if it exists.
+func (t TableInfo) Column(name string) (ColumnInfo, bool) {
+	for _, c := range t.Columns {
+		if c.Name == name {
+			return c, true
+		}
+	}
+	return ColumnInfo{}, false
+}
+
+// ColumnInfo describes a table column.
+type ColumnInfo struct {
+	Name     string         `db:"name"`
+	Type     string         `db:"type"`
+	Nullable bool           `db:"nullable"`
+	Default  sql.NullString `db:"default_value"`
+}
+
+// IndexInfo describes a table index.
+type IndexInfo struct {
+	Name    string
+	Unique  bool
+	Columns []string
+}
+
+// ForeignKeyInfo describes a foreign key column, and the
+// column it references.
+type ForeignKeyInfo struct {
+	Column          string `db:"column_name"`
+	ReferencedTable string `db:"ref_table"`
+	ReferencedCol   string `db:"ref_column"`
+}
+
+// indexColumn is a row of an index listing, one per indexed column.
+type indexColumn struct {
+	Name   string `db:"name"`
+	Unique bool   `db:"is_unique"`
+	Column string `db:"column_name"`
+}
+
+// schemaCache holds the tables introspected by a connection.
+type schemaCache struct {
+	mu     sync.RWMutex
+	tables map[string]*TableInfo
+}
+
+func newSchemaCache() *schemaCache {
+	return &schemaCache{tables: map[string]*TableInfo{}}
+}
+
+// TableInfo returns the columns and indexes of the table used by the model.
+// model can either be a model or a table name. Results are cached for
+// the connection, use InvalidateSchemaCache to drop them (after running
+// migrations, for instance). A table name qualified by its schema, e.g.
+// "billing.invoices", is read from that schema, and an unqualified one
+// from the current schema.
+//
+//	ti, err := c.TableInfo(ctx, &User{})
+//	ti, err := c.TableInfo(ctx, "users")
+func (c *Connection) TableInfo(ctx context.Context, model interface{}) (*TableInfo, error) {
+	table, ok := model.(string)
+	if !ok {
+		table = c.modelContext(ctx, model).TableName()
+	}
+	c.schemaCache.mu.RLock()
+	ti, ok := c.schemaCache.tables[table]
+	c.schemaCache.mu.RUnlock()
+	if ok {
+		return ti, nil
+	}
+
+	err := c.timeFunc(ctx, "TableInfo", readOp, table, func(ctx context.Context, c *Connection) error {
+		var err error
+		ti, err = c.Dialect.TableInfo(ctx, c, table)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not get info for table %s", table)
+	}
+
+	c.schemaCache.mu.Lock()
+	c.schemaCache.tables[table] = ti
+	c.schemaCache.mu.Unlock()
+	return ti, nil
+}
+
+// HasTable checks if the table used by the model exists in the database.
+// model can either be a model or a table name.
+func (c *Connection) HasTable(ctx context.Context, model interface{}) (bool, error) {
+	_, err := c.TableInfo(ctx, model)
+	if errors.Cause(err) == ErrTableNotFound {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+// HasColumn checks if the table used by the model has the given column.
+// model can either be a model or a table name.
+func (c *Connection) HasColumn(ctx context.Context, model interface{}, column string) (bool, error) {
+	ti, err := c.TableInfo(ctx, model)
+	if errors.Cause(err) == ErrTableNotFound {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	_, ok := ti.Column(column)
+	return ok, nil
+}
+
+// TableNames returns the names of the tables of the database, or of
+// the current schema, sorted by name.
+func (c *Connection) TableNames(ctx context.Context) ([]string, error) {
+	var names []string
+	err := c.timeFunc(ctx, "TableNames", readOp, "", func(ctx context.Context, c *Connection) error {
+		var err error
+		names, err = c.Dialect.TableNames(ctx, c)
+		return err
+	})
+	return names, errors.Wrap(err, "could not list tables")
+}
+
+// InvalidateSchemaCache drops the table infos cached by the connection.
+func (c *Connection) InvalidateSchemaCache() {
+	c.schemaCache.mu.Lock()
+	c.schemaCache.tables = map[string]*TableInfo{}
+	c.schemaCache.mu.Unlock()
+}
+
+// genericTableInfo builds the table info from a columns query, an indexes
+// query and a foreign keys query, all taking args as their arguments.
+func genericTableInfo(ctx context.Context, s Store, table string, args []interface{}, columnsQuery string, indexesQuery string, fksQuery string) (*TableInfo, error) {
+	ti := &TableInfo{Name: table}
+
+	storeLog(s)(logging.SQL, columnsQuery, args...)
+	if err := s.SelectContext(ctx, &ti.Columns, columnsQuery, args...); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	if len(ti.Columns) == 0 {
+		return nil, ErrTableNotFound
+	}
+
+	var ics []indexColumn
+	storeLog(s)(logging.SQL, indexesQuery, args...)
+	if err := s.SelectContext(ctx, &ics, indexesQuery, args...); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	ti.Indexes = groupIndexColumns(ics)
+
+	storeLog(s)(logging.SQL, fksQuery, args...)
+	if err := s.SelectContext(ctx, &ti.ForeignKeys, fksQuery, args...); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	return ti, nil
+}
+
+// schemaTableArgs returns the schema and the name of the table, e.g. of
+// "billing.invoices", as the arguments of the table info queries. The
+// schema is empty for an unqualified name, the queries then falling back
+// to the current schema.
+func schemaTableArgs(table string) []interface{} {
+	if i := strings.LastIndex(table, "."); i >= 0 {
+		return []interface{}{table[:i], table[i+1:]}
+	}
+	return []interface{}{"", table}
+}
+
+// genericTableNames lists the tables with the given query,
+// excluding the migration table.
+func genericTableNames(c *Connection, query string) ([]string, error) {
+	var names []string
+	c.log(logging.SQL, query)
+	if err := c.Store.Select(&names, query); err != nil {
+		return nil, errors.WithStack(err)
+	}
+	tables := names[:0]
+	for _, n := range names {
+		if n != c.MigrationTableName() {
+			tables = append(tables, n)
+		}
+	}
+	return tables, nil
+}
+
+// groupIndexColumns merges the index columns, ordered by index, into indexes.
+func groupIndexColumns(ics []indexColumn) []IndexInfo {
+	var indexes []IndexInfo
+	for _, ic := range ics {
+		if n := len(indexes); n > 0 && indexes[n-1].Name == ic.Name {
+			indexes[n-1].Columns = append(indexes[n-1].Columns, ic.Column)
+			continue
+		}
+		indexes = append(indexes, IndexInfo{Name: ic.Name, Unique: ic.Unique, Columns: []string{ic.Column}})
+	}
+	return indexes
+}
@@ -0,0 +1,136 @@
+package pop
+
+import (
+	"context"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_TableInfo(t *testing.T) {
+	r := require.New(t)
+
+	ti, err := PDB.TableInfo(context.Background(), &User{})
+	r.NoError(err)
+	r.Equal("users", ti.Name)
+
+	id, ok := ti.Column("id")
+	r.True(ok)
+	r.False(id.Nullable)
+
+	bio, ok := ti.Column("bio")
+	r.True(ok)
+	r.True(bio.Nullable)
+
+	email, ok := ti.Column("email")
+	r.True(ok)
+	r.True(email.Default.Valid)
+
+	_, ok = ti.Column("unknown")
+	r.False(ok)
+
+	cached, err := PDB.TableInfo(context.Background(), "users")
+	r.NoError(err)
+	r.True(ti == cached)
+
+	PDB.InvalidateSchemaCache()
+	fresh, err := PDB.TableInfo(context.Background(), "users")
+	r.NoError(err)
+	r.False(ti == fresh)
+	r.Equal(ti.Columns, fresh.Columns)
+}
+
+// testSchema returns the schema of the test tables, empty for SQLite.
+func testSchema() string {
+	switch PDB.Dialect.Name() {
+	case nameSQLite3:
+		return ""
+	case nameMySQL:
+		return PDB.Dialect.Details().Database
+	}
+	return "public"
+}
+
+func Test_TableInfo_Qualified(t *testing.T) {
+	r := require.New(t)
+
+	schema := testSchema()
+	if schema == "" {
+		t.Skip("the tables of SQLite aren't qualified by a schema")
+	}
+	ti, err := PDB.TableInfo(context.Background(), schema+".users")
+	r.NoError(err)
+	r.Equal(schema+".users", ti.Name)
+	_, ok := ti.Column("bio")
+	r.True(ok)
+
+	_, err = PDB.TableInfo(context.Background(), "not_a_schema.users")
+	r.Equal(ErrTableNotFound, errors.Cause(err))
+}
+
+func Test_schemaTableArgs(t *testing.T) {
+	r := require.New(t)
+
+	r.Equal([]interface{}{"", "users"}, schemaTableArgs("users"))
+	r.Equal([]interface{}{"billing", "invoices"}, schemaTableArgs("billing.invoices"))
+}
+
+func Test_TableInfo_Not_Found(t *testing.T) {
+	r := require.New(t)
+
+	_, err := PDB.TableInfo(context.Background(), "not_a_table")
+	r.Error(err)
+
+	ok, err := PDB.HasTable(context.Background(), "not_a_table")
+	r.NoError(err)
+	r.False(ok)
+
+	ok, err = PDB.HasColumn(context.Background(), "not_a_table", "id")
+	r.NoError(err)
+	r.False(ok)
+}
+
+func Test_TableInfo_Canceled(t *testing.T) {
+	r := require.New(t)
+
+	PDB.InvalidateSchemaCache()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := PDB.TableInfo(ctx, "users")
+	r.Error(err)
+	r.NotEqual(ErrTableNotFound, errors.Cause(err))
+
+	_, err = PDB.TableInfo(context.Background(), "users")
+	r.NoError(err)
+}
+
+func Test_HasTable_HasColumn(t *testing.T) {
+	r := require.New(t)
+
+	ok, err := PDB.HasTable(context.Background(), &User{})
+	r.NoError(err)
+	r.True(ok)
+
+	ok, err = PDB.HasColumn(context.Background(), &User{}, "user_name")
+	r.NoError(err)
+	r.True(ok)
+
+	ok, err = PDB.HasColumn(context.Background(), &User{}, "not_a_column")
+	r.NoError(err)
+	r.False(ok)
+}
+
+func Test_groupIndexColumns(t *testing.T) {
+	r := require.New(t)
+
+	indexes := groupIndexColumns([]indexColumn{
+		{Name: "a_idx", Unique: true, Column: "a"},
+		{Name: "bc_idx", Column: "b"},
+		{Name: "bc_idx", Column: "c"},
+	})
+	r.Equal([]IndexInfo{
+		{Name: "a_idx", Unique: true, Columns: []string{"a"}},
+		{Name: "bc_idx", Columns: []string{"b", "c"}},
+	}, indexes)
+}
@@ -0,0 +1,92 @@
+package pop
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/gobuffalo/pop/logging"
+	"github.com/jmoiron/sqlx"
+	"github.com/pkg/errors"
+)
+
+// timeoutConfigurer is implemented by the dialects supporting the
+// DialTimeout connection detail.
+type timeoutConfigurer interface {
+	// configureDialTimeout returns the dsn with the dial timeout parameter
+	// of the driver.
+	configureDialTimeout(dsn string, timeout time.Duration) (string, error)
+}
+
+// withDialTimeout returns the dsn of the dialect, with the parameter of
+// ConnectionDetails.DialTimeout, if any.
+func withDialTimeout(d dialect, dsn string) (string, error) {
+	timeout := d.Details().DialTimeout
+	if timeout <= 0 {
+		return dsn, nil
+	}
+	tc, ok := d.(timeoutConfigurer)
+	if !ok {
+		log(logging.Debug, "%s doesn't support DialTimeout, it's ignored", d.Name())
+		return dsn, nil
+	}
+	dsn, err := tc.configureDialTimeout(dsn, timeout)
+	return dsn, errors.Wrap(err, "invalid dial timeout")
+}
+
+// ping checks the database is ready within the timeouts of the details,
+// if any.
+func ping(db *sqlx.DB, deets *ConnectionDetails) error {
+	if deets.DialTimeout <= 0 && deets.ConnectionTimeout <= 0 {
+		return nil
+	}
+	ctx := context.Background()
+	if deets.ConnectionTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, deets.ConnectionTimeout)
+		defer cancel()
+	}
+
+	// the drivers don't all stop connecting when ctx is done
+	done := make(chan error, 1)
+	go func() {
+		done <- db.PingContext(ctx)
+	}()
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err == nil {
+		return nil
+	}
+	if ctx.Err() == context.DeadlineExceeded {
+		return errors.Wrapf(err, "could not connect to the database within the connection timeout of %s", deets.ConnectionTimeout)
+	}
+	if ne, ok := errors.Cause(err).(net.Error); ok && ne.Timeout() {
+		return errors.Wrapf(err, "could not connect to the database within the dial timeout of %s", deets.DialTimeout)
+	}
+	return errors.Wrap(err, "could not connect to the database")
+}
+
+// configureDialTimeout sets the connect_timeout parameter, in seconds.
+func (p *postgresql) configureDialTimeout(dsn string, timeout time.Duration) (string, error) {
+	return postgresWithParams(dsn, [][2]string{{"connect_timeout", postgresTimeout(timeout)}})
+}
+
+func (p *cockroach) configureDialTimeout(dsn string, timeout time.Duration) (string, error) {
+	return postgresWithParams(dsn, [][2]string{{"connect_timeout", postgresTimeout(timeout)}})
+}
+
+// postgresTimeout returns the timeout in seconds, rounded up: 0 would
+// disable the timeout.
+func postgresTimeout(timeout time.Duration) string {
+	return strconv.FormatInt(int64((timeout+time.Second-1)/time.Second), 10)
+}
+
+// configureDialTimeout sets the timeout parameter of the MySQL driver.
+func (m *mysql) configureDialTimeout(dsn string, timeout time.Duration) (string, error) {
+	return mysqlWithParam(dsn, "timeout", timeout.String()), nil
+}
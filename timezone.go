@@ -0,0 +1,167 @@
+package pop
+
+import (
+	"net/url"
+	"reflect"
+	"sync"
+	"time"
+
+	"github.com/gobuffalo/pop/columns"
+	"github.com/gobuffalo/pop/logging"
+	"github.com/pkg/errors"
+)
+
+var (
+	nowFunc = time.Now
+	nowMu   sync.RWMutex
+)
+
+// SetNowFunc sets the clock of the created_at and updated_at timestamps
+// of all the connections, e.g. to a fixed time in tests. A nil f restores
+// time.Now. The timestamps are set in UTC with ConnectionDetails.UTC. See
+// Connection.WithClock for the parallel tests.
+//
+//	pop.SetNowFunc(func() time.Time { return time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC) })
+//	defer pop.SetNowFunc(nil)
+func SetNowFunc(f func() time.Time) {
+	if f == nil {
+		f = time.Now
+	}
+	nowMu.Lock()
+	defer nowMu.Unlock()
+	nowFunc = f
+}
+
+// now returns the time of the timestamps.
+func now() time.Time {
+	nowMu.RLock()
+	defer nowMu.RUnlock()
+	return nowFunc()
+}
+
+// WithClock returns a copy of the connection setting the created_at and
+// updated_at timestamps with now, instead of the clock of SetNowFunc. The
+// transactions of the copy inherit its clock. Unlike SetNowFunc, it
+// doesn't change the clock of the other connections, e.g. of the parallel
+// tests:
+//
+//	tx = tx.WithClock(func() time.Time { return frozen })
+//	err := tx.Create(&user) // user.CreatedAt == frozen
+func (c *Connection) WithClock(now func() time.Time) *Connection {
+	cn := c.copy()
+	cn.clock = now
+	return cn
+}
+
+// now returns the time of the timestamps of the connection.
+func (c *Connection) now() time.Time {
+	if c.clock != nil {
+		return c.clock()
+	}
+	return now()
+}
+
+// LocalTimeAble is implemented by the models opting out of
+// ConnectionDetails.UTC: their time.Time fields are written in their time
+// zone. Their timestamps are still set in UTC.
+type LocalTimeAble interface {
+	LocalTime() bool
+}
+
+// utcConfigurer is implemented by the dialects supporting the UTC
+// connection detail.
+type utcConfigurer interface {
+	// configureUTC returns the dsn with the parameters of the driver
+	// setting the session time zone, and the time zone of the scanned
+	// times, to UTC.
+	configureUTC(dsn string) (string, error)
+}
+
+// withUTC returns the dsn of the dialect, with the UTC time zone
+// parameters if ConnectionDetails.UTC is set.
+func withUTC(d dialect, dsn string) (string, error) {
+	if !d.Details().UTC {
+		return dsn, nil
+	}
+	uc, ok := d.(utcConfigurer)
+	if !ok {
+		log(logging.Warn, "%s doesn't support UTC, the session time zone is kept", d.Name())
+		return dsn, nil
+	}
+	dsn, err := uc.configureUTC(dsn)
+	return dsn, errors.Wrap(err, "could not set the UTC time zone")
+}
+
+// configureUTC sets the time zone of the PostgreSQL session.
+func (p *postgresql) configureUTC(dsn string) (string, error) {
+	return postgresWithParams(dsn, [][2]string{{"timezone", "UTC"}})
+}
+
+func (p *cockroach) configureUTC(dsn string) (string, error) {
+	return postgresWithParams(dsn, [][2]string{{"timezone", "UTC"}})
+}
+
+// configureUTC sets the time zone of the MySQL session, and the location
+// of the times parsed by the driver.
+func (m *mysql) configureUTC(dsn string) (string, error) {
+	dsn = mysqlWithParam(dsn, "loc", "UTC")
+	return mysqlWithParam(dsn, "time_zone", url.QueryEscape("'+00:00'")), nil
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// timesToUTC converts the time.Time column fields of the model to UTC,
+// including the nullable times like nulls.Time, unless the model
+// implements LocalTimeAble. The other fields, e.g. of its associations,
+// are left as is. The pointers to time.Time are replaced with pointers to
+// their UTC time until the returned func is called, after the write: the
+// pointers, and the times they point to, are the caller's.
+func (m *Model) timesToUTC() (restore func()) {
+	var restores []func()
+	restore = func() {
+		for _, r := range restores {
+			r()
+		}
+	}
+	if lt, ok := m.Value.(LocalTimeAble); ok && lt.LocalTime() {
+		return restore
+	}
+	v := reflect.Indirect(reflect.ValueOf(m.Value))
+	if v.Kind() != reflect.Struct {
+		return restore
+	}
+	fm := strictMapper.FieldMap(v)
+	for _, col := range columnNames(columns.ForStruct(m.Value, ""), true) {
+		f, ok := fm[col]
+		if !ok || !f.CanSet() {
+			continue
+		}
+		switch {
+		case f.Type() == timeType:
+			f.Set(reflect.ValueOf(f.Interface().(time.Time).UTC()))
+		case f.Kind() == reflect.Ptr && f.Type().Elem() == timeType:
+			if f.IsNil() {
+				continue
+			}
+			old := reflect.ValueOf(f.Interface())
+			t := f.Elem().Interface().(time.Time).UTC()
+			f.Set(reflect.ValueOf(&t))
+			restores = append(restores, func() { f.Set(old) })
+		case f.Kind() == reflect.Struct:
+			// nulls.Time, sql.NullTime
+			if t := f.FieldByName("Time"); t.IsValid() && t.Type() == timeType && t.CanSet() {
+				t.Set(reflect.ValueOf(t.Interface().(time.Time).UTC()))
+			}
+		}
+	}
+	return restore
+}
+
+// timesToUTC converts the time.Time column fields of the model to UTC with
+// ConnectionDetails.UTC, see Model.timesToUTC.
+func (c *Connection) timesToUTC(m *Model) (restore func()) {
+	if !c.Dialect.Details().UTC {
+		return func() {}
+	}
+	return m.timesToUTC()
+}
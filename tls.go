@@ -0,0 +1,184 @@
+package pop
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"net/url"
+	"strings"
+
+	_mysql "github.com/go-sql-driver/mysql"
+	"github.com/pkg/errors"
+)
+
+// TLSConfig configures the encrypted connections to the database. It's
+// supported by PostgreSQL, CockroachDB and MySQL.
+//
+//	tls:
+//	  cafile: /etc/ssl/db/ca.pem
+//	  certfile: /etc/ssl/db/client.pem
+//	  keyfile: /etc/ssl/db/client.key
+type TLSConfig struct {
+	// CAFile is the PEM file of the certificate authorities checking the
+	// server certificate. Defaults to the system certificate authorities.
+	CAFile string
+	// CertFile and KeyFile are the PEM files of the client certificate.
+	CertFile string
+	KeyFile  string
+	// ServerName is the name checked against the server certificate.
+	// Defaults to the host. PostgreSQL only checks the host.
+	ServerName string
+	// InsecureSkipVerify encrypts the connections without checking the
+	// server certificate.
+	InsecureSkipVerify bool
+}
+
+// tlsConfigurer is implemented by the dialects supporting the TLS connection
+// details.
+type tlsConfigurer interface {
+	// configureTLS returns the dsn with the TLS parameters of the details.
+	configureTLS(dsn string, deets *ConnectionDetails) (string, error)
+}
+
+// withTLS returns the dsn of the dialect, with the parameters of
+// ConnectionDetails.TLS, if any. The certificate files are checked.
+func withTLS(d dialect, dsn string) (string, error) {
+	deets := d.Details()
+	if deets.TLS == nil {
+		return dsn, nil
+	}
+	tc, ok := d.(tlsConfigurer)
+	if !ok {
+		return "", errors.Errorf("%s doesn't support TLS", d.Name())
+	}
+	dsn, err := tc.configureTLS(dsn, deets)
+	return dsn, errors.Wrap(err, "invalid TLS configuration")
+}
+
+// load checks the certificate files, and returns the matching tls.Config.
+func (t *TLSConfig) load(host string) (*tls.Config, error) {
+	cfg := &tls.Config{
+		ServerName:         t.ServerName,
+		InsecureSkipVerify: t.InsecureSkipVerify,
+	}
+	if cfg.ServerName == "" {
+		cfg.ServerName = host
+	}
+	if t.CAFile != "" {
+		pem, err := ioutil.ReadFile(t.CAFile)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, errors.Errorf("no certificate found in %s", t.CAFile)
+		}
+	}
+	if (t.CertFile == "") != (t.KeyFile == "") {
+		return nil, errors.New("the client certificate needs both CertFile and KeyFile")
+	}
+	if t.CertFile != "" {
+		cert, err := tls.LoadX509KeyPair(t.CertFile, t.KeyFile)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
+}
+
+// postgresTLS sets the sslmode, sslrootcert, sslcert and sslkey parameters
+// of a PostgreSQL dsn, either an URL or a list of key=value settings.
+func postgresTLS(dsn string, deets *ConnectionDetails) (string, error) {
+	t := deets.TLS
+	if t.ServerName != "" && t.ServerName != deets.Host {
+		return "", errors.New("PostgreSQL checks the host, ServerName can't be set")
+	}
+	if _, err := t.load(deets.Host); err != nil {
+		return "", err
+	}
+
+	params := [][2]string{{"sslmode", "verify-full"}}
+	if t.InsecureSkipVerify {
+		params[0][1] = "require"
+	}
+	for _, p := range [][2]string{{"sslrootcert", t.CAFile}, {"sslcert", t.CertFile}, {"sslkey", t.KeyFile}} {
+		if p[1] != "" {
+			params = append(params, p)
+		}
+	}
+
+	return postgresWithParams(dsn, params)
+}
+
+// postgresWithParams returns the dsn, an URL or a list of key=value
+// pairs, with the given parameters.
+func postgresWithParams(dsn string, params [][2]string) (string, error) {
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		u, err := url.Parse(dsn)
+		if err != nil {
+			return "", errors.WithStack(err)
+		}
+		q := u.Query()
+		for _, p := range params {
+			q.Set(p[0], p[1])
+		}
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	}
+	r := strings.NewReplacer(`\`, `\\`, `'`, `\'`)
+	for _, p := range params {
+		dsn += fmt.Sprintf(" %s='%s'", p[0], r.Replace(p[1]))
+	}
+	return strings.TrimSpace(dsn), nil
+}
+
+func (p *postgresql) configureTLS(dsn string, deets *ConnectionDetails) (string, error) {
+	return postgresTLS(dsn, deets)
+}
+
+func (p *cockroach) configureTLS(dsn string, deets *ConnectionDetails) (string, error) {
+	return postgresTLS(dsn, deets)
+}
+
+// configureTLS registers the tls.Config of the details with the MySQL
+// driver, under a name derived from the TLS details, and refers to it in
+// the tls parameter of the dsn.
+func (m *mysql) configureTLS(dsn string, deets *ConnectionDetails) (string, error) {
+	cfg, err := _mysql.ParseDSN(dsn)
+	if err != nil {
+		return "", errors.WithStack(err)
+	}
+	if cfg.TLSConfig != "" {
+		return "", errors.New("the tls parameter of the URL can't be used with TLS")
+	}
+	t := deets.TLS
+	tc, err := t.load(deets.Host)
+	if err != nil {
+		return "", err
+	}
+
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\x00%s\x00%s\x00%s\x00%t\x00%s", t.CAFile, t.CertFile, t.KeyFile, t.ServerName, t.InsecureSkipVerify, deets.Host)
+	name := fmt.Sprintf("pop-%x", h.Sum64())
+	if err := _mysql.RegisterTLSConfig(name, tc); err != nil {
+		return "", errors.WithStack(err)
+	}
+
+	return mysqlWithParam(dsn, "tls", name), nil
+}
+
+// mysqlWithParam returns the dsn with the given parameter. It overrides
+// the parameter of the dsn, if any: the driver keeps the last one.
+func mysqlWithParam(dsn string, key string, value string) string {
+	sep := "?"
+	if strings.Contains(dsn, "?") {
+		sep = "&"
+	}
+	if strings.HasSuffix(dsn, "?") || strings.HasSuffix(dsn, "&") {
+		sep = ""
+	}
+	return dsn + sep + key + "=" + value
+}
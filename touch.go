@@ -0,0 +1,155 @@
+package pop
+
+import (
+	"context"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+
+	"github.com/gobuffalo/flect"
+	"github.com/gobuffalo/pop/columns"
+	"github.com/markbates/going/defaults"
+	"github.com/pkg/errors"
+)
+
+// Touch sets the updated_at column of the row of the model, and its
+// UpdatedAt field, to the current time of the connection, see WithClock.
+// The other columns aren't written, and the callbacks aren't run. It
+// returns the number of rows updated: 0 when the row doesn't exist.
+//
+//	n, err := c.Touch(ctx, &user)
+//	if n == 0 {
+//		// the user was deleted
+//	}
+func (c *Connection) Touch(ctx context.Context, model interface{}) (int64, error) {
+	var rows int64
+	sm := c.modelContext(ctx, model)
+	err := sm.iterate(func(m *Model) error {
+		return c.timeFunc(ctx, "Touch", writeOp, m.TableName(), func(ctx context.Context, c *Connection) error {
+			if _, err := m.fieldByName("UpdatedAt"); err != nil {
+				return errors.Errorf("%s has no UpdatedAt field to touch", m.TableName())
+			}
+			n, err := c.touchRow(ctx, m, m.whereID(), m.ID())
+			rows += n
+			return err
+		})
+	})
+	return rows, err
+}
+
+// touchRow sets the updated_at column of the rows of the model table
+// matching where, and the UpdatedAt field of the model.
+func (c *Connection) touchRow(ctx context.Context, m *Model, where string, args ...interface{}) (int64, error) {
+	m.touchUpdatedAt(c.dbTime(c.now()))
+	fbn, err := m.fieldByName("UpdatedAt")
+	if err != nil {
+		return 0, err
+	}
+	stmt := c.Dialect.TranslateSQL(fmt.Sprintf("UPDATE %s SET updated_at = ? WHERE %s", m.TableName(), where))
+	res, err := c.execWrite(ctx, stmt, append([]interface{}{fbn.Interface()}, args...)...)
+	if err != nil {
+		return 0, err
+	}
+	n, err := res.RowsAffected()
+	return n, errors.WithStack(err)
+}
+
+// touchParents touches the parents of the belongs_to associations of the
+// model tagged touch:"true", after the model was written:
+//
+//	type Comment struct {
+//		ID     int  `db:"id"`
+//		Post   Post `belongs_to:"post" touch:"true"`
+//		PostID int  `db:"post_id"`
+//	}
+//
+// The parents are touched with the connection of the write, in its
+// transaction: the writes of the models touching parents run in a new one
+// when the connection isn't in a transaction, see needsTransaction. The
+// parents of the parents aren't touched.
+func (c *Connection) touchParents(m *Model) error {
+	v := reflect.Indirect(reflect.ValueOf(m.Value))
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tags := columns.TagsFor(f)
+		if tags.Find("belongs_to").Empty() || tags.Find("touch").Value != "true" {
+			continue
+		}
+		fk := v.FieldByName(defaults.String(tags.Find("fk_id").Value, f.Name+"ID"))
+		if !fk.IsValid() {
+			return errors.Errorf("there is no '%s' defined in model '%s'", defaults.String(tags.Find("fk_id").Value, f.Name+"ID"), t.Name())
+		}
+		id, ok := touchedID(fk)
+		if !ok {
+			continue
+		}
+
+		pt := f.Type
+		if pt.Kind() == reflect.Ptr {
+			pt = pt.Elem()
+		}
+		parent := c.model(reflect.New(pt).Interface())
+		pk := "id"
+		if primaryID := tags.Find("primary_id").Value; primaryID != "" && primaryID != "ID" {
+			pf, found := pt.FieldByName(primaryID)
+			if !found {
+				return errors.Errorf("there is no primary field '%s' defined in model '%s'", primaryID, pt.Name())
+			}
+			pk = defaults.String(columns.TagsFor(pf).Find("db").Value, flect.Underscore(pf.Name))
+		}
+		if _, err := c.touchRow(c.txContext(), parent, fmt.Sprintf("%s = ?", pk), id); err != nil {
+			return errors.Wrapf(err, "could not touch the %s of %s", f.Name, t.Name())
+		}
+	}
+	return nil
+}
+
+// touchesParents tells if the models of model, a model or a slice of
+// models, touch the parents of some of their belongs_to associations, see
+// touchParents.
+func touchesParents(model interface{}) bool {
+	t := reflect.TypeOf(model)
+	if t == nil {
+		return false
+	}
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for i := 0; i < t.NumField(); i++ {
+		tags := columns.TagsFor(t.Field(i))
+		if !tags.Find("belongs_to").Empty() && tags.Find("touch").Value == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+// touchedID returns the value of the foreign key fk, and false if it's
+// zero or null.
+func touchedID(fk reflect.Value) (interface{}, bool) {
+	if fk.Kind() == reflect.Ptr {
+		if fk.IsNil() {
+			return nil, false
+		}
+		fk = fk.Elem()
+	}
+	id := fk.Interface()
+	if v, ok := id.(driver.Valuer); ok {
+		dv, err := v.Value()
+		if err != nil || dv == nil {
+			return nil, false
+		}
+		id = dv
+	}
+	if IsZeroOfUnderlyingType(id) {
+		return nil, false
+	}
+	return id, true
+}
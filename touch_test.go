@@ -0,0 +1,98 @@
+package pop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/stretchr/testify/require"
+)
+
+// touchingBook is a book touching its user when it's written.
+type touchingBook struct {
+	ID        int       `db:"id"`
+	Title     string    `db:"title"`
+	Isbn      string    `db:"isbn"`
+	User      User      `belongs_to:"user" touch:"true"`
+	UserID    nulls.Int `db:"user_id"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (touchingBook) TableName() string {
+	return "books"
+}
+
+func Test_Touch(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		u := &User{Name: nulls.NewString("Touched")}
+		r.NoError(tx.WithClock(func() time.Time { return created }).Create(u))
+
+		touched := created.Add(time.Hour)
+		n, err := tx.WithClock(func() time.Time { return touched }).Touch(ctx, u)
+		r.NoError(err)
+		r.Equal(int64(1), n)
+		r.Equal(touched, u.UpdatedAt)
+
+		found := &User{}
+		r.NoError(tx.Find(ctx, found, u.ID))
+		r.True(touched.Equal(found.UpdatedAt), found.UpdatedAt)
+		r.True(created.Equal(found.CreatedAt), found.CreatedAt)
+		r.Equal(u.Name, found.Name)
+
+		n, err = tx.Touch(ctx, &User{ID: u.ID + 1000})
+		r.NoError(err)
+		r.Zero(n)
+
+		_, err = tx.Touch(ctx, &latestBook{ID: 1})
+		r.Error(err)
+	})
+}
+
+func Test_Touch_BelongsTo(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+		ctx := tx.txContext()
+
+		created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		u := &User{Name: nulls.NewString("Parent")}
+		r.NoError(tx.WithClock(func() time.Time { return created }).Create(u))
+		updatedAt := func() time.Time {
+			found := &User{}
+			r.NoError(tx.Find(ctx, found, u.ID))
+			return found.UpdatedAt
+		}
+
+		for i, write := range []func(tx *Connection, b *touchingBook) error{
+			func(tx *Connection, b *touchingBook) error { return tx.Create(b) },
+			func(tx *Connection, b *touchingBook) error { return tx.Update(b) },
+			func(tx *Connection, b *touchingBook) error { return tx.Destroy(b) },
+		} {
+			at := created.Add(time.Duration(i+1) * time.Hour)
+			b := &touchingBook{Title: "child", Isbn: "T1", UserID: nulls.NewInt(u.ID)}
+			if i > 0 {
+				r.NoError(tx.Where("isbn = ?", "T1").First(ctx, b))
+			}
+			r.NoError(write(tx.WithClock(func() time.Time { return at }), b))
+			r.True(at.Equal(updatedAt()), i)
+		}
+
+		// a book without user touches nothing
+		r.NoError(tx.Create(&touchingBook{Title: "orphan", Isbn: "T2"}))
+	})
+}
+
+func Test_touchesParents(t *testing.T) {
+	r := require.New(t)
+
+	r.True(touchesParents(&touchingBook{}))
+	r.True(touchesParents(&[]touchingBook{}))
+	r.True(touchesParents([]*touchingBook{}))
+	r.False(touchesParents(&Book{}))
+	r.False(touchesParents(&[]Book{}))
+	r.False(touchesParents(nil))
+}
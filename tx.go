@@ -1,7 +1,9 @@
 package pop
 
 import (
+	"context"
 	"math/rand"
+	"sync"
 	"time"
 
 	"github.com/jmoiron/sqlx"
@@ -12,17 +14,24 @@ func init() {
 	rand.Seed(time.Now().UnixNano())
 }
 
-// Tx stores a transaction with an ID to keep track.
+// Tx is the Store of a transaction connection, with an ID to keep track
+// of it: the TX of the connections returned by NewTransaction, and given
+// to the function of Transaction. Its statements are run by the embedded
+// sqlx.Tx.
 type Tx struct {
 	ID int
 	*sqlx.Tx
+
+	mu         sync.Mutex
+	onCommit   []func()
+	onRollback []func(err error)
 }
 
-func newTX(db *dB) (*Tx, error) {
+func newTX(ctx context.Context, db *dB) (*Tx, error) {
 	t := &Tx{
 		ID: rand.Int(),
 	}
-	tx, err := db.Beginx()
+	tx, err := db.pool().BeginTxx(ctx, nil)
 	t.Tx = tx
 	return t, errors.Wrap(err, "could not create new transaction")
 }
@@ -33,7 +42,93 @@ func (tx *Tx) Transaction() (*Tx, error) {
 	return tx, nil
 }
 
+// TransactionContext simply returns the current transaction,
+// this is defined so it implements the `Store` interface.
+func (tx *Tx) TransactionContext(context.Context) (*Tx, error) {
+	return tx, nil
+}
+
 // Close does nothing. This is defined so it implements the `Store` interface.
 func (tx *Tx) Close() error {
 	return nil
 }
+
+// Commit commits the transaction, then calls the OnCommit callbacks. If the
+// commit fails, the OnRollback callbacks are called with its error instead.
+func (tx *Tx) Commit() error {
+	err := tx.Tx.Commit()
+	if err != nil {
+		tx.rolledBack(err)
+		return err
+	}
+	tx.mu.Lock()
+	fns := tx.onCommit
+	tx.onCommit, tx.onRollback = nil, nil
+	tx.mu.Unlock()
+	for _, fn := range fns {
+		fn()
+	}
+	return nil
+}
+
+// Rollback rolls back the transaction, then calls the OnRollback callbacks
+// with a nil error.
+func (tx *Tx) Rollback() error {
+	return tx.rollback(nil)
+}
+
+// rollback rolls back the transaction, then calls the OnRollback callbacks
+// with cause, the error which made the transaction fail.
+func (tx *Tx) rollback(cause error) error {
+	err := tx.Tx.Rollback()
+	tx.rolledBack(cause)
+	return err
+}
+
+func (tx *Tx) rolledBack(cause error) {
+	tx.mu.Lock()
+	fns := tx.onRollback
+	tx.onCommit, tx.onRollback = nil, nil
+	tx.mu.Unlock()
+	for _, fn := range fns {
+		fn(cause)
+	}
+}
+
+// OnCommit registers fn to be called once the transaction of the connection
+// is committed, e.g. to enqueue a job processing the saved records. The
+// callbacks are called in their registration order, after the transaction
+// is closed. Outside of a transaction, fn is called right away.
+//
+//	err := c.Transaction(ctx, func(ctx context.Context, tx *pop.Connection) error {
+//		if err := tx.Create(&order); err != nil {
+//			return err
+//		}
+//		tx.OnCommit(func() {
+//			jobs.Enqueue("send_receipt", order.ID)
+//		})
+//		return nil
+//	})
+func (c *Connection) OnCommit(fn func()) {
+	if c.TX == nil {
+		fn()
+		return
+	}
+	c.TX.mu.Lock()
+	c.TX.onCommit = append(c.TX.onCommit, fn)
+	c.TX.mu.Unlock()
+}
+
+// OnRollback registers fn to be called once the transaction of the
+// connection is rolled back, in registration order. err is the error
+// returned by the function given to Transaction, or the error of a failed
+// commit; it's nil for the other rollbacks. Outside of a transaction, fn
+// is never called.
+func (c *Connection) OnRollback(fn func(err error)) {
+	if c.TX == nil {
+		return
+	}
+	c.TX.mu.Lock()
+	c.TX.onRollback = append(c.TX.onRollback, fn)
+	c.TX.mu.Unlock()
+}
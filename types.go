@@ -0,0 +1,281 @@
+package pop
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/jmoiron/sqlx"
+	"github.com/jmoiron/sqlx/reflectx"
+	"github.com/pkg/errors"
+)
+
+// CustomScanner returns the value of the registered type read from a
+// column, src being the value returned by the driver: nil, int64, float64,
+// bool, []byte, string or time.Time.
+type CustomScanner func(src interface{}) (interface{}, error)
+
+// CustomValuer returns the value to write to a column for v, a value of
+// the registered type. The value must be supported by the driver, e.g. a
+// string or a []byte.
+type CustomValuer func(v interface{}) (driver.Value, error)
+
+// TypeRegistry maps the Go types which don't implement sql.Scanner and
+// driver.Valuer, e.g. the types of a third-party geometry package, to the
+// functions reading and writing them.
+type TypeRegistry struct {
+	mu    sync.RWMutex
+	types map[reflect.Type]customType
+}
+
+type customType struct {
+	scanner CustomScanner
+	valuer  CustomValuer
+}
+
+// Types is the registry of the custom types of all the connections, see
+// RegisterType.
+var Types = &TypeRegistry{types: map[reflect.Type]customType{}}
+
+// RegisterType registers the scanner and valuer of goType in Types: the
+// model fields of goType are read with scanner, and written with valuer,
+// like the query args of goType.
+//
+//	pop.RegisterType(reflect.TypeOf(geom.Point{}), func(src interface{}) (interface{}, error) {
+//		return geom.ParseWKB(src.([]byte))
+//	}, func(v interface{}) (driver.Value, error) {
+//		return v.(geom.Point).WKB(), nil
+//	})
+func RegisterType(goType reflect.Type, scanner CustomScanner, valuer CustomValuer) {
+	Types.Register(goType, scanner, valuer)
+}
+
+// Register registers the scanner and valuer of goType, replacing the
+// previous ones.
+func (r *TypeRegistry) Register(goType reflect.Type, scanner CustomScanner, valuer CustomValuer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.types[goType] = customType{scanner: scanner, valuer: valuer}
+}
+
+func (r *TypeRegistry) lookup(t reflect.Type) (customType, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	ct, ok := r.types[t]
+	return ct, ok
+}
+
+func (r *TypeRegistry) empty() bool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return len(r.types) == 0
+}
+
+// hasFields tells if the model, a struct or a slice of structs, has
+// fields of a registered type.
+func (r *TypeRegistry) hasFields(model interface{}) bool {
+	if r.empty() || model == nil {
+		return false
+	}
+	t := reflectx.Deref(reflect.TypeOf(model))
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Array {
+		t = reflectx.Deref(t.Elem())
+	}
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+	for _, fi := range strictMapper.TypeMap(t).Index {
+		if _, ok := r.lookup(fi.Field.Type); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// customValue writes a value of a registered type with its valuer.
+type customValue struct {
+	valuer CustomValuer
+	v      interface{}
+}
+
+func (c customValue) Value() (driver.Value, error) {
+	return c.valuer(c.v)
+}
+
+// customArg returns the arg written with the valuer of its type, if it's
+// registered.
+func customArg(arg interface{}) interface{} {
+	if arg == nil {
+		return nil
+	}
+	if ct, ok := Types.lookup(reflect.TypeOf(arg)); ok && ct.valuer != nil {
+		return customValue{valuer: ct.valuer, v: arg}
+	}
+	return arg
+}
+
+// customArgs returns the args, written with the valuers of their types.
+func customArgs(args []interface{}) []interface{} {
+	if Types.empty() {
+		return args
+	}
+	converted := make([]interface{}, len(args))
+	for i, arg := range args {
+		converted[i] = customArg(arg)
+	}
+	return converted
+}
+
+// bindArg returns the arg binding the named parameters of the statements
+// writing the model: the model, or the values of its columns when it has
+// fields of a registered type.
+func (m *Model) bindArg() interface{} {
+	if !Types.hasFields(m.Value) {
+		return m.Value
+	}
+	fm := strictMapper.FieldMap(reflect.ValueOf(m.Value))
+	arg := make(map[string]interface{}, len(fm))
+	for name, f := range fm {
+		arg[name] = customArg(f.Interface())
+	}
+	return arg
+}
+
+// selectRows runs the query, and scans its rows into the model with
+// scanRows. The rows read are recorded by the store of an operation.
+func selectRows(s Store, model *Model, many bool, query string, args ...interface{}) error {
+	rows, err := s.Queryx(query, args...)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer rows.Close()
+	if err := scanRows(rows, model.Value, many); err != nil {
+		return err
+	}
+	observeRows(s, model.Value, many)
+	return nil
+}
+
+// scanRows scans the rows into dest, a struct or a slice of structs if
+// many is true, with the scanners of the fields of a registered type. The
+// structs of a slice are scanned in place, in its spare capacity.
+func scanRows(rows *sqlx.Rows, dest interface{}, many bool) error {
+	if !Types.hasFields(dest) && !(many && structElements(dest)) {
+		if many {
+			return errors.WithStack(sqlx.StructScan(rows, dest))
+		}
+		if !rows.Next() {
+			if err := rows.Err(); err != nil {
+				return errors.WithStack(err)
+			}
+			return errors.WithStack(sql.ErrNoRows)
+		}
+		return errors.WithStack(rows.StructScan(dest))
+	}
+
+	cols, err := rows.Columns()
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	v := reflect.ValueOf(dest)
+	if v.Kind() != reflect.Ptr || v.IsNil() {
+		return errors.Errorf("must pass a non-nil pointer to scan into, got %T", dest)
+	}
+	v = v.Elem()
+	if !many {
+		if !rows.Next() {
+			if err := rows.Err(); err != nil {
+				return errors.WithStack(err)
+			}
+			return errors.WithStack(sql.ErrNoRows)
+		}
+		values := make([]interface{}, len(cols))
+		return scanRow(rows, v, cols, strictMapper.TraversalsByName(v.Type(), cols), values)
+	}
+
+	el := v.Type().Elem()
+	base := reflectx.Deref(el)
+	traversals := strictMapper.TraversalsByName(base, cols)
+	values := make([]interface{}, len(cols))
+	for rows.Next() {
+		if el.Kind() == reflect.Ptr {
+			vp := reflect.New(base)
+			if err := scanRow(rows, vp.Elem(), cols, traversals, values); err != nil {
+				return err
+			}
+			v.Set(reflect.Append(v, vp))
+			continue
+		}
+		// reflect.Append allocates, even within the capacity of the slice
+		if n := v.Len(); n < v.Cap() {
+			v.SetLen(n + 1)
+			v.Index(n).Set(reflect.Zero(base))
+		} else {
+			v.Set(reflect.Append(v, reflect.Zero(base)))
+		}
+		if err := scanRow(rows, v.Index(v.Len()-1), cols, traversals, values); err != nil {
+			v.SetLen(v.Len() - 1)
+			return err
+		}
+	}
+	return errors.WithStack(rows.Err())
+}
+
+// structElements tells if dest is a pointer to a slice of structs, or of
+// pointers to structs, scanned field by field: sqlx scans the Scanner and
+// non-struct elements as a single column.
+func structElements(dest interface{}) bool {
+	t := reflect.TypeOf(dest)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Slice {
+		return false
+	}
+	base := reflectx.Deref(t.Elem().Elem())
+	if base.Kind() != reflect.Struct || reflect.PtrTo(base).Implements(scannerType) {
+		return false
+	}
+	return len(strictMapper.TypeMap(base).Index) > 0
+}
+
+var scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+
+// scanRow scans the current row into the struct v, whose fields are found
+// by the traversals of the columns, with values holding the destinations.
+func scanRow(rows *sqlx.Rows, v reflect.Value, cols []string, traversals [][]int, values []interface{}) error {
+	for i, t := range traversals {
+		if len(t) == 0 {
+			return errors.Errorf("missing destination name %s in %s", cols[i], v.Type())
+		}
+		f := reflectx.FieldByIndexes(v, t)
+		if ct, ok := Types.lookup(f.Type()); ok && ct.scanner != nil {
+			values[i] = customScan{scanner: ct.scanner, dest: f}
+			continue
+		}
+		values[i] = f.Addr().Interface()
+	}
+	return errors.WithStack(rows.Scan(values...))
+}
+
+// customScan reads a value of a registered type with its scanner.
+type customScan struct {
+	scanner CustomScanner
+	dest    reflect.Value
+}
+
+func (c customScan) Scan(src interface{}) error {
+	v, err := c.scanner(src)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		c.dest.Set(reflect.Zero(c.dest.Type()))
+		return nil
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Type() != c.dest.Type() {
+		return fmt.Errorf("the scanner of %s returned a %s", c.dest.Type(), rv.Type())
+	}
+	c.dest.Set(rv)
+	return nil
+}
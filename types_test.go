@@ -0,0 +1,76 @@
+package pop
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// bookCode implements neither sql.Scanner nor driver.Valuer.
+type bookCode struct {
+	Prefix string
+	Number int
+}
+
+type codedBook struct {
+	ID        int       `db:"id"`
+	Title     string    `db:"title"`
+	Isbn      bookCode  `db:"isbn"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (codedBook) TableName() string {
+	return "books"
+}
+
+func Test_RegisterType(t *testing.T) {
+	r := require.New(t)
+
+	ct := reflect.TypeOf(bookCode{})
+	RegisterType(ct, func(src interface{}) (interface{}, error) {
+		var code bookCode
+		if b, ok := src.([]byte); ok {
+			src = string(b)
+		}
+		_, err := fmt.Sscanf(src.(string), "%s %d", &code.Prefix, &code.Number)
+		return code, err
+	}, func(v interface{}) (driver.Value, error) {
+		code := v.(bookCode)
+		return fmt.Sprintf("%s %d", code.Prefix, code.Number), nil
+	})
+	defer func() {
+		Types.mu.Lock()
+		delete(Types.types, ct)
+		Types.mu.Unlock()
+	}()
+	r.True(Types.hasFields(&[]codedBook{}))
+	r.False(Types.hasFields(&Book{}))
+
+	transaction(func(tx *Connection) {
+		ctx := tx.txContext()
+
+		b := &codedBook{Title: "Typed", Isbn: bookCode{Prefix: "ISBN", Number: 42}}
+		r.NoError(tx.Create(b))
+		var isbn string
+		r.NoError(tx.QueryRow(ctx, "SELECT isbn FROM books WHERE id = ?", b.ID).Scan(&isbn))
+		r.Equal("ISBN 42", isbn)
+
+		found := &codedBook{}
+		r.NoError(tx.Find(ctx, found, b.ID))
+		r.Equal(b.Isbn, found.Isbn)
+
+		found.Isbn.Number = 43
+		r.NoError(tx.Update(found))
+
+		books := []codedBook{}
+		r.NoError(tx.Where("isbn = ?", bookCode{Prefix: "ISBN", Number: 43}).All(ctx, &books))
+		r.Len(books, 1)
+		r.Equal(b.ID, books[0].ID)
+		r.Equal(found.Isbn, books[0].Isbn)
+	})
+}
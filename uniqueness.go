@@ -0,0 +1,75 @@
+package pop
+
+import (
+	"fmt"
+
+	"github.com/gobuffalo/flect"
+	"github.com/gobuffalo/validate"
+)
+
+// UniquenessValidator returns a validator checking that no other row of the
+// table of the model has the value of its column, among the rows having the
+// same values of the scope columns, e.g. an email unique per tenant:
+//
+//	func (u *User) Validate(tx *pop.Connection) (*validate.Errors, error) {
+//		return validate.Validate(
+//			pop.UniquenessValidator(tx, u, "email", "tenant_id"),
+//		), nil
+//	}
+//
+// The row of the model itself is excluded by its ID, when it has one. A
+// NULL value is never taken, and a NULL scope value matches the NULL
+// values. The query runs with the connection, the one given to Validate:
+// in the transaction of ValidateAndCreate, it sees its uncommitted rows.
+// A failed query is added to the errors of the column.
+//
+// The validation is advisory: a concurrent transaction can write the same
+// value between the validation and the write. The unique index of the
+// column remains the source of truth, its violations being returned as
+// *ModelErrors by ValidateAndCreate, see FieldErrors.
+func UniquenessValidator(c *Connection, model interface{}, column string, scope ...string) validate.Validator {
+	return &uniquenessValidator{c: c, model: model, column: column, scope: scope}
+}
+
+type uniquenessValidator struct {
+	c      *Connection
+	model  interface{}
+	column string
+	scope  []string
+}
+
+func (v *uniquenessValidator) IsValid(errs *validate.Errors) {
+	m := v.c.model(v.model)
+	table := m.TableName()
+	cols := append([]string{v.column}, v.scope...)
+	values := columnValues(v.model, cols)
+	if value, ok := values[v.column]; ok && dbValue(value) == nil {
+		return
+	}
+
+	q := v.c.Q()
+	for _, col := range cols {
+		value, ok := values[col]
+		if !ok {
+			errs.Add(v.column, fmt.Sprintf("%s is not a column of %T.", col, v.model))
+			return
+		}
+		if dbValue(value) == nil {
+			q = q.Where(fmt.Sprintf("%s.%s IS NULL", table, col))
+			continue
+		}
+		q = q.Where(fmt.Sprintf("%s.%s = ?", table, col), value)
+	}
+	if id, err := m.PrimaryKeyValue(); err == nil {
+		q = q.Where(fmt.Sprintf("%s.id != ?", table), id)
+	}
+
+	exists, err := q.Exists(v.model)
+	if err != nil {
+		errs.Add(v.column, fmt.Sprintf("%s could not be checked: %s.", flect.Humanize(v.column), err))
+		return
+	}
+	if exists {
+		errs.Add(v.column, fmt.Sprintf("%s has already been taken.", flect.Humanize(v.column)))
+	}
+}
@@ -0,0 +1,40 @@
+package pop
+
+import (
+	"testing"
+
+	"github.com/gobuffalo/nulls"
+	"github.com/gobuffalo/validate"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_UniquenessValidator(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+
+		b := &Book{Title: "Unique", Isbn: "U1", UserID: nulls.NewInt(1)}
+		r.NoError(tx.Create(b))
+
+		isValid := func(model interface{}, scope ...string) *validate.Errors {
+			verrs := validate.NewErrors()
+			UniquenessValidator(tx, model, "isbn", scope...).IsValid(verrs)
+			return verrs
+		}
+
+		// the uncommitted row is seen by the transaction
+		verrs := isValid(&Book{Isbn: "U1"})
+		r.Equal([]string{"Isbn has already been taken."}, verrs.Get("isbn"))
+		r.False(isValid(&Book{Isbn: "U2"}).HasAny())
+
+		// the row of the model is excluded
+		r.False(isValid(b).HasAny())
+
+		// unique per user
+		r.False(isValid(&Book{Isbn: "U1", UserID: nulls.NewInt(2)}, "user_id").HasAny())
+		r.True(isValid(&Book{Isbn: "U1", UserID: nulls.NewInt(1)}, "user_id").HasAny())
+		r.False(isValid(&Book{Isbn: "U1"}, "user_id").HasAny())
+
+		verrs = isValid(&Book{Isbn: "U1"}, "tenant_id")
+		r.Equal([]string{"tenant_id is not a column of *pop.Book."}, verrs.Get("isbn"))
+	})
+}
@@ -0,0 +1,269 @@
+package pop
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gobuffalo/pop/columns"
+	"github.com/gobuffalo/pop/logging"
+	"github.com/pkg/errors"
+)
+
+// upserter is implemented by the dialects supporting Upsert.
+type upserter interface {
+	// upsert inserts the model, or updates the row conflicting with it on
+	// the conflict columns, and sets the id of the model.
+	upsert(s Store, model *Model, cols columns.Columns, conflict []string) error
+}
+
+// Upsert inserts the model, or updates the existing row having the same
+// conflictColumns, a unique key defaulting to "id". All the writeable
+// columns of the existing row are updated, except created_at: the
+// creation time of the row is kept. The ID of the model is set to the id
+// of the inserted or updated row.
+//
+//	setting := &Setting{UserID: user.ID, Name: "theme", Value: "dark"}
+//	err := c.Upsert(setting, "user_id", "name")
+//
+// MySQL updates the row conflicting on any of the unique keys of the
+// table, and ignores conflictColumns. The model is validated, and its
+// BeforeSave and AfterSave callbacks are run.
+func (c *Connection) Upsert(model interface{}, conflictColumns ...string) error {
+	up, ok := c.Dialect.(upserter)
+	if !ok {
+		return errors.Errorf("%s doesn't support Upsert", c.Dialect.Name())
+	}
+	if err := c.checkTableContext(model); err != nil {
+		return err
+	}
+	if len(conflictColumns) == 0 {
+		conflictColumns = []string{"id"}
+	}
+	if c.needsTransaction(model) {
+		return c.Transaction(c.txContext(), func(_ context.Context, tx *Connection) error {
+			return tx.Upsert(model, conflictColumns...)
+		})
+	}
+
+	sm := c.model(model)
+	ctx := c.txContext()
+	if err := sm.iterate(func(m *Model) error {
+		if err := m.validateEnums(); err != nil {
+			return err
+		}
+		return m.validateContext(ctx)
+	}); err != nil {
+		return err
+	}
+	return sm.iterate(func(m *Model) error {
+		return c.timeFunc(ctx, "Upsert", writeOp, m.TableName(), func(ctx context.Context, c *Connection) error {
+			if err := m.beforeSave(c); err != nil {
+				return err
+			}
+
+			cols := columns.ForStructWithAlias(m.Value, m.TableName(), m.As)
+			now := c.dbTime(c.now())
+			m.touchCreatedAt(now)
+			m.touchUpdatedAt(now)
+			defer c.timesToUTC(m)()
+
+			restore, err := c.encryptFields(m)
+			if err != nil {
+				return err
+			}
+			err = up.upsert(c.Store, m, cols, conflictColumns)
+			restore()
+			if err != nil {
+				return err
+			}
+			if err := c.touchParents(m); err != nil {
+				return err
+			}
+			return m.afterSave(c)
+		})
+	})
+}
+
+// upsertColumns returns the inserted columns of an upsert: the writeable
+// columns, and the id unless it's generated by the database.
+func upsertColumns(model *Model, cols columns.Columns) (*columns.WriteableColumns, error) {
+	w := cols.Writeable()
+	switch keyType := model.PrimaryKeyType(); keyType {
+	case "int", "int64":
+		if !IsZeroOfUnderlyingType(model.ID()) {
+			w.Add("id")
+		}
+	case "UUID", "string":
+		if err := setClientID(model, keyType); err != nil {
+			return nil, err
+		}
+		w.Add("id")
+	default:
+		return nil, errors.Errorf("can not use %s as a primary key type!", keyType)
+	}
+	return w, nil
+}
+
+// upsertUpdates returns the columns updated by an upsert of an existing
+// row: the inserted columns, except the id, created_at and the conflict
+// columns.
+func upsertUpdates(w *columns.WriteableColumns, conflict []string) []string {
+	skip := map[string]bool{"id": true, "created_at": true}
+	for _, c := range conflict {
+		skip[c] = true
+	}
+	var names []string
+	for name := range w.Cols {
+		if !skip[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// genericUpsert upserts the model with INSERT ... ON CONFLICT DO UPDATE.
+// The id of an int key is read with RETURNING if returning is set, or
+// with a select of the conflicting row otherwise.
+func genericUpsert(s Store, model *Model, cols columns.Columns, conflict []string, returning bool) error {
+	w, err := upsertColumns(model, cols)
+	if err != nil {
+		return err
+	}
+	updates := upsertUpdates(w, conflict)
+	if len(updates) == 0 {
+		// DO NOTHING wouldn't return the existing row
+		updates = conflict[:1]
+	}
+	set := make([]string, 0, len(updates))
+	for _, name := range updates {
+		set = append(set, fmt.Sprintf("%s = excluded.%s", name, name))
+	}
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s) ON CONFLICT (%s) DO UPDATE SET %s",
+		model.TableName(), w.String(), w.SymbolizedString(), strings.Join(conflict, ", "), strings.Join(set, ", "))
+
+	keyType := model.PrimaryKeyType()
+	intKey := keyType == "int" || keyType == "int64"
+	if intKey && returning {
+		query += " RETURNING id"
+		storeLog(s)(logging.SQL, query)
+		stmt, err := s.PrepareNamed(query)
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		defer stmt.Close()
+		var id int64
+		if err := stmt.Get(&id, model.bindArg()); err != nil {
+			return errors.WithStack(err)
+		}
+		model.setID(id)
+		return nil
+	}
+
+	storeLog(s)(logging.SQL, query)
+	res, err := s.NamedExec(query, model.bindArg())
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	if !intKey || !IsZeroOfUnderlyingType(model.ID()) {
+		return nil
+	}
+	if len(conflict) == 1 && conflict[0] == "id" {
+		// without id, the row was inserted
+		id, err := res.LastInsertId()
+		if err != nil {
+			return errors.WithStack(err)
+		}
+		model.setID(id)
+		return nil
+	}
+
+	where := make([]string, 0, len(conflict))
+	for _, c := range conflict {
+		where = append(where, fmt.Sprintf("%s = :%s", c, c))
+	}
+	query = fmt.Sprintf("SELECT id FROM %s WHERE %s", model.TableName(), strings.Join(where, " AND "))
+	storeLog(s)(logging.SQL, query)
+	stmt, err := s.PrepareNamed(query)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+	defer stmt.Close()
+	var id int64
+	if err := stmt.Get(&id, model.bindArg()); err != nil {
+		return errors.Wrap(err, "could not read the id of the upserted row")
+	}
+	model.setID(id)
+	return nil
+}
+
+func (p *postgresql) upsert(s Store, model *Model, cols columns.Columns, conflict []string) error {
+	return genericUpsert(s, model, cols, conflict, true)
+}
+
+func (p *cockroach) upsert(s Store, model *Model, cols columns.Columns, conflict []string) error {
+	return genericUpsert(s, model, cols, conflict, true)
+}
+
+// upsert updates the row conflicting on any unique key, with ON DUPLICATE
+// KEY UPDATE. The id of the updated row is returned as the last insert id
+// by LAST_INSERT_ID(id).
+func (m *mysql) upsert(s Store, model *Model, cols columns.Columns, conflict []string) error {
+	w, err := upsertColumns(model, cols)
+	if err != nil {
+		return err
+	}
+	rowAlias := m.rowAlias()
+	var set []string
+	for _, name := range upsertUpdates(w, conflict) {
+		if rowAlias {
+			set = append(set, fmt.Sprintf("%s = pop_new.%s", name, name))
+		} else {
+			set = append(set, fmt.Sprintf("%s = VALUES(%s)", name, name))
+		}
+	}
+	keyType := model.PrimaryKeyType()
+	intKey := keyType == "int" || keyType == "int64"
+	if intKey {
+		set = append(set, "id = LAST_INSERT_ID(id)")
+	} else if len(set) == 0 {
+		set = append(set, "id = id")
+	}
+
+	query := fmt.Sprintf("INSERT INTO %s (%s) VALUES (%s)", model.TableName(), w.String(), w.SymbolizedString())
+	if rowAlias {
+		query += " AS pop_new"
+	}
+	query += " ON DUPLICATE KEY UPDATE " + strings.Join(set, ", ")
+	storeLog(s)(logging.SQL, query)
+	res, err := s.NamedExec(query, model.bindArg())
+	if err != nil {
+		return errors.Wrap(err, "mysql upsert")
+	}
+	if intKey {
+		id, err := res.LastInsertId()
+		if err != nil {
+			return errors.Wrap(err, "mysql upsert")
+		}
+		model.setID(id)
+	}
+	return nil
+}
+
+// rowAlias tells if the server supports the row alias of INSERT, added in
+// MySQL 8.0.19: the VALUES() function of ON DUPLICATE KEY UPDATE is
+// deprecated from 8.0.20. MariaDB, and the servers whose version isn't
+// known, use VALUES().
+func (m *mysql) rowAlias() bool {
+	version := m.serverVersion()
+	if strings.Contains(version, "MariaDB") {
+		return false
+	}
+	var major, minor, patch int
+	if _, err := fmt.Sscanf(version, "%d.%d.%d", &major, &minor, &patch); err != nil {
+		return false
+	}
+	return major > 8 || (major == 8 && (minor > 0 || patch >= 19))
+}
@@ -0,0 +1,82 @@
+package pop
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type upsertSetting struct {
+	ID        int       `db:"id"`
+	UserID    int       `db:"user_id"`
+	Name      string    `db:"name"`
+	Value     string    `db:"value"`
+	CreatedAt time.Time `db:"created_at"`
+	UpdatedAt time.Time `db:"updated_at"`
+}
+
+func (upsertSetting) TableName() string {
+	return "settings"
+}
+
+func Test_Upsert_MySQL(t *testing.T) {
+	r := require.New(t)
+
+	c, err := NewConnection(&ConnectionDetails{Dialect: "mysql", Host: "db.local", Database: "pop_test"})
+	r.NoError(err)
+
+	dr := c.DryRun()
+	r.NoError(dr.Upsert(&upsertSetting{UserID: 1, Name: "theme", Value: "dark"}, "user_id", "name"))
+	c.Dialect.(*mysql).setServerVersion("8.0.23")
+	r.NoError(dr.Upsert(&upsertSetting{ID: 2, UserID: 1, Name: "theme", Value: "dark"}))
+	c.Dialect.(*mysql).setServerVersion("10.5.8-MariaDB")
+	r.NoError(dr.Upsert(&upsertSetting{UserID: 1, Name: "theme", Value: "dark"}))
+
+	stmts := dr.Statements()
+	r.Len(stmts, 3)
+	r.Equal("INSERT INTO settings (created_at, name, updated_at, user_id, value) VALUES (?, ?, ?, ?, ?) ON DUPLICATE KEY UPDATE updated_at = VALUES(updated_at), value = VALUES(value), id = LAST_INSERT_ID(id)", stmts[0].SQL)
+	r.Equal("INSERT INTO settings (created_at, id, name, updated_at, user_id, value) VALUES (?, ?, ?, ?, ?, ?) AS pop_new ON DUPLICATE KEY UPDATE name = pop_new.name, updated_at = pop_new.updated_at, user_id = pop_new.user_id, value = pop_new.value, id = LAST_INSERT_ID(id)", stmts[1].SQL)
+	r.Contains(stmts[2].SQL, "VALUES(value)")
+
+	m := &mysql{}
+	for v, alias := range map[string]bool{"8.0.18": false, "8.0.19": true, "8.0.23-0ubuntu0.20.04.1": true, "5.7.31-log": false, "": false} {
+		m.setServerVersion(v)
+		r.Equal(alias, m.rowAlias(), v)
+	}
+}
+
+func Test_Upsert(t *testing.T) {
+	transaction(func(tx *Connection) {
+		r := require.New(t)
+
+		created := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+		tx = tx.WithClock(func() time.Time { return created })
+		b := &Book{Title: "Upsert", Isbn: "U1"}
+		r.NoError(tx.Upsert(b))
+		r.NotZero(b.ID)
+
+		updated := created.Add(time.Hour)
+		tx = tx.WithClock(func() time.Time { return updated })
+		r.NoError(tx.Upsert(&Book{ID: b.ID, Title: "Upserted", Isbn: "U1"}))
+
+		found := &Book{}
+		r.NoError(tx.Find(tx.txContext(), found, b.ID))
+		r.Equal("Upserted", found.Title)
+		r.True(created.Equal(found.CreatedAt), found.CreatedAt)
+		r.True(updated.Equal(found.UpdatedAt), found.UpdatedAt)
+
+		count, err := tx.Where("isbn = ?", "U1").Count(&Book{})
+		r.NoError(err)
+		r.Equal(1, count)
+
+		if tx.Dialect.Name() == nameMySQL {
+			// the DDL would commit the transaction
+			return
+		}
+		r.NoError(tx.RawQuery("CREATE UNIQUE INDEX books_isbn_upsert ON books (isbn)").Exec())
+		again := &Book{Title: "Again", Isbn: "U1"}
+		r.NoError(tx.Upsert(again, "isbn"))
+		r.Equal(b.ID, again.ID)
+	})
+}
@@ -1,12 +1,34 @@
 package pop
 
 import (
+	"context"
 	"reflect"
 
 	"github.com/gobuffalo/validate"
 	"github.com/pkg/errors"
 )
 
+// Validatable is implemented by the models validating themselves, with
+// any validation library. Create, Update and Save call Validate before
+// running any query, and return its error as is.
+//
+//	func (u *User) Validate(ctx context.Context) error {
+//		if u.Email == "" {
+//			return ErrEmailRequired
+//		}
+//		return nil
+//	}
+type Validatable interface {
+	Validate(ctx context.Context) error
+}
+
+func (m *Model) validateContext(ctx context.Context) error {
+	if x, ok := m.Value.(Validatable); ok {
+		return x.Validate(ctx)
+	}
+	return nil
+}
+
 type beforeValidatable interface {
 	BeforeValidations(*Connection) error
 }
@@ -23,10 +45,15 @@ func (m *Model) validate(c *Connection) (*validate.Errors, error) {
 			return validate.NewErrors(), errors.WithStack(err)
 		}
 	}
+	verrs := m.enumValidationErrors()
 	if x, ok := m.Value.(validateable); ok {
-		return x.Validate(c)
+		vs, err := x.Validate(c)
+		if vs != nil {
+			verrs.Append(vs)
+		}
+		return verrs, err
 	}
-	return validate.NewErrors(), nil
+	return verrs, nil
 }
 
 type validateCreateable interface {
@@ -35,6 +62,10 @@ type validateCreateable interface {
 
 func (m *Model) validateCreate(c *Connection) (*validate.Errors, error) {
 	return m.iterateAndValidate(func(model *Model) (*validate.Errors, error) {
+		// the defaults are validated too
+		if _, err := model.applyDefaults(); err != nil {
+			return validate.NewErrors(), err
+		}
 		verrs, err := model.validate(c)
 		if err != nil {
 			return verrs, errors.WithStack(err)
@@ -62,6 +93,9 @@ func (m *Model) validateAndOnlyCreate(c *Connection) (*validate.Errors, error) {
 		if !IsZeroOfUnderlyingType(id.Interface()) {
 			return validate.NewErrors(), nil
 		}
+		if _, err := model.applyDefaults(); err != nil {
+			return validate.NewErrors(), err
+		}
 
 		verrs, err := model.validate(c)
 		if err != nil {
@@ -87,6 +121,12 @@ type validateSaveable interface {
 
 func (m *Model) validateSave(c *Connection) (*validate.Errors, error) {
 	return m.iterateAndValidate(func(model *Model) (*validate.Errors, error) {
+		// the models to create get their defaults, see Connection.Save
+		if id, err := model.fieldByName("ID"); err == nil && IsZeroOfUnderlyingType(id.Interface()) {
+			if _, err := model.applyDefaults(); err != nil {
+				return validate.NewErrors(), err
+			}
+		}
 		verrs, err := model.validate(c)
 		if err != nil {
 			return verrs, errors.WithStack(err)
@@ -134,7 +174,7 @@ func (m *Model) iterateAndValidate(fn modelIterableValidator) (*validate.Errors,
 	if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
 		for i := 0; i < v.Len(); i++ {
 			val := v.Index(i)
-			newModel := &Model{Value: val.Addr().Interface()}
+			newModel := &Model{Value: val.Addr().Interface(), schema: m.schema, ctx: m.ctx}
 			verrs, err := fn(newModel)
 
 			if err != nil || verrs.HasAny() {